@@ -0,0 +1,570 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"url-shortener/cmd/urlshortener/configs"
+	"url-shortener/pkg/abuse"
+	"url-shortener/pkg/app/urlshortener/backup"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/bloom"
+	"url-shortener/pkg/db"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/redis"
+)
+
+// command define urlshortener sub command
+type command func(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error
+
+// commands register all sub commands by name
+var commands = map[string]command{
+	"rotate-keys":      rotateKeysCommand,
+	"rehash-canonical": rehashCanonicalCommand,
+	"export":           exportCommand,
+	"import":           importCommand,
+	"rebuild-bloom":    rebuildBloomCommand,
+	"backfill":         backfillCommand,
+	"apikey":           apiKeyCommand,
+	"workspace":        workspaceCommand,
+}
+
+// runCommand find sub command by name and run it until finish or interrupt
+func runCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		return errors.Wrapf(errors.ErrInvalidInput, "unknown command %v", name)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	return cmd(logger.WithContext(ctx), logger, config, args)
+}
+
+// rotateKeysCommand re-encrypt all shortened URL with the primary key of keyring
+//
+//	urlshortener rotate-keys -batch 500 -interval 100ms
+func rotateKeysCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	fs := flag.NewFlagSet("rotate-keys", flag.ContinueOnError)
+	batchSize := fs.Int("batch", 500, "rows re-encrypt in one transaction")
+	interval := fs.Duration("interval", 100*time.Millisecond, "pause between batches to reduce database load")
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	if !config.Encryption.Enabled {
+		return errors.Wrap(errors.ErrInvalidInput, "encryption is not enabled")
+	}
+
+	dbConn, err := db.NewConnection(config.Database)
+	if err != nil {
+		return err
+	}
+
+	repoOpts, err := newRepositoryOptions(config)
+	if err != nil {
+		return err
+	}
+
+	repo := repository.NewRepoImpl(dbConn, repoOpts...)
+
+	total := 0
+	for {
+		rotated, err := repo.RotateKeys(ctx, *batchSize)
+		if err != nil {
+			return err
+		}
+
+		total += rotated
+		logger.Info().Int("rotated", rotated).Int("total", total).Msg("rotate keys batch finish")
+
+		if rotated == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// rehashCanonicalCommand recompute the dedupe hash of all shortened URL from their canonical URL,
+// rows hashed before canonical URL or before encryption are deduplicated after it finish
+//
+//	urlshortener rehash-canonical -batch 500 -interval 100ms [-cursor LsE2ypFI]
+func rehashCanonicalCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	fs := flag.NewFlagSet("rehash-canonical", flag.ContinueOnError)
+	cursor := fs.String("cursor", "", "resume after this short id")
+	batchSize := fs.Int("batch", 500, "rows rehash in one batch")
+	interval := fs.Duration("interval", 100*time.Millisecond, "pause between batches to reduce database load")
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	dbConn, err := db.NewConnection(config.Database)
+	if err != nil {
+		return err
+	}
+
+	repoOpts, err := newRepositoryOptions(config)
+	if err != nil {
+		return err
+	}
+
+	repo := repository.NewRepoImpl(dbConn, repoOpts...)
+
+	total := 0
+	next := *cursor
+	for {
+		var (
+			rehashed int
+			done     bool
+		)
+
+		next, rehashed, done, err = repo.RehashCanonical(ctx, next, *batchSize)
+		if err != nil {
+			logger.Error().Str("cursor", next).Msg("rehash canonical stop, resume with -cursor")
+			return err
+		}
+
+		total += rehashed
+		logger.Info().Int("rehashed", rehashed).Int("total", total).Str("cursor", next).Msg("rehash canonical batch finish")
+
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info().Str("cursor", next).Msg("rehash canonical interrupted, resume with -cursor")
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// exportCommand stream all shortened URL into a backup file
+//
+//	urlshortener export -out links.jsonl [-format jsonl|csv] [-resume]
+func exportCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "", "backup file path, format is detected by extension")
+	format := fs.String("format", "", "backup file format, jsonl or csv")
+	batchSize := fs.Int("batch", 1000, "rows read in one query")
+	resume := fs.Bool("resume", false, "continue the last interrupted export")
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	f, err := backupFormat(*out, *format)
+	if err != nil {
+		return err
+	}
+
+	repo, err := newCommandRepository(config)
+	if err != nil {
+		return err
+	}
+
+	result, err := backup.ExportFile(ctx, repo, backup.ExportOptions{
+		Path:      *out,
+		Format:    f,
+		BatchSize: *batchSize,
+		Resume:    *resume,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().
+		Int("exported", result.Exported).
+		Str("sha256", result.Checksum).
+		Msgf("export to %v finish", *out)
+
+	return nil
+}
+
+// importCommand stream all shortened URL from a backup file, then rebuild bloom filter
+//
+//	urlshortener import -in links.jsonl [-format jsonl|csv] [-conflict skip|overwrite|fail] [-resume]
+func importCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := fs.String("in", "", "backup file path, format is detected by extension")
+	format := fs.String("format", "", "backup file format, jsonl or csv")
+	conflict := fs.String("conflict", string(repository.ConflictSkip), "how to handle existing short id, skip, overwrite or fail")
+	batchSize := fs.Int("batch", 1000, "rows between cursor checkpoints")
+	resume := fs.Bool("resume", false, "continue the last interrupted import")
+	verify := fs.Bool("verify", true, "require checksum file exist and match")
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	f, err := backupFormat(*in, *format)
+	if err != nil {
+		return err
+	}
+
+	repo, err := newCommandRepository(config)
+	if err != nil {
+		return err
+	}
+
+	result, err := backup.ImportFile(ctx, repo, backup.ImportOptions{
+		Path:      *in,
+		Format:    f,
+		Mode:      repository.ConflictMode(*conflict),
+		BatchSize: *batchSize,
+		Resume:    *resume,
+		Verify:    *verify,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().
+		Int("stored", result.Stored).
+		Int("skipped", result.Skipped).
+		Msgf("import from %v finish", *in)
+
+	return rebuildBloom(ctx, logger, config, repo, *batchSize)
+}
+
+// rebuildBloomCommand rebuild bloom filter from all short id in database
+//
+//	urlshortener rebuild-bloom
+func rebuildBloomCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	fs := flag.NewFlagSet("rebuild-bloom", flag.ContinueOnError)
+	batchSize := fs.Int("batch", 1000, "rows read in one query")
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	repo, err := newCommandRepository(config)
+	if err != nil {
+		return err
+	}
+
+	return rebuildBloom(ctx, logger, config, repo, *batchSize)
+}
+
+// backfillCommand copy historical rows from database to migration target
+//
+//	urlshortener backfill [-cursor LsE2ypFI]
+func backfillCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	cursor := fs.String("cursor", "", "resume after this short id")
+	batchSize := fs.Int("batch", 500, "rows copy in one batch")
+	interval := fs.Duration("interval", 100*time.Millisecond, "pause between batches to reduce database load")
+	if err := fs.Parse(args); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	if !config.Migration.Enabled {
+		return errors.Wrap(errors.ErrInvalidInput, "migration is not enabled")
+	}
+
+	old, err := newCommandRepository(config)
+	if err != nil {
+		return err
+	}
+
+	targetConn, err := db.NewConnection(config.Migration.Target)
+	if err != nil {
+		return err
+	}
+
+	repoOpts, err := newRepositoryOptions(config)
+	if err != nil {
+		return err
+	}
+
+	new := repository.New(targetConn, repoOpts...)
+
+	total := 0
+	next := *cursor
+	for {
+		var (
+			copied int
+			done   bool
+		)
+
+		next, copied, done, err = repository.Backfill(ctx, old, new, next, *batchSize)
+		if err != nil {
+			logger.Error().Str("cursor", next).Msg("backfill stop, resume with -cursor")
+			return err
+		}
+
+		total += copied
+		logger.Info().Int("copied", copied).Int("total", total).Str("cursor", next).Msg("backfill batch finish")
+
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info().Str("cursor", next).Msg("backfill interrupted, resume with -cursor")
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// apiKeyCommand issue, revoke or list api keys
+//
+//	urlshortener apikey issue -owner team-a -name ci -scopes create,read
+//	urlshortener apikey revoke -id Xa8LmQ2pRt0z
+//	urlshortener apikey list [-owner team-a]
+func apiKeyCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	if len(args) == 0 {
+		return errors.Wrap(errors.ErrInvalidInput, "apikey command require issue, revoke or list")
+	}
+
+	fs := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+	owner := fs.String("owner", "", "owner id of urls created by the key")
+	name := fs.String("name", "", "display name of the key")
+	scopes := fs.String("scopes", string(auth.ScopeCreate), "comma separated scopes, create, read, manage or admin")
+	id := fs.String("id", "", "key id to revoke")
+	if err := fs.Parse(args[1:]); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	dbConn, err := db.NewConnection(config.Database)
+	if err != nil {
+		return err
+	}
+
+	store := auth.NewAPIKeyStore(dbConn)
+
+	switch args[0] {
+	case "issue":
+		s, err := auth.ParseScopes(*scopes)
+		if err != nil {
+			return err
+		}
+
+		plaintext, key, err := auth.IssueAPIKey(ctx, store, *owner, *name, s)
+		if err != nil {
+			return err
+		}
+
+		logger.Info().
+			Str("id", key.ID).
+			Str("owner", key.OwnerID).
+			Interface("scopes", key.Scopes).
+			Msg("issue api key finish, the key is only shown once")
+
+		// the key is printed to stdout only, so it is not kept in logs
+		fmt.Println(plaintext)
+		return nil
+
+	case "revoke":
+		if *id == "" {
+			return errors.Wrap(errors.ErrInvalidInput, "key id is required")
+		}
+
+		if err := store.RevokeAPIKey(ctx, *id); err != nil {
+			return err
+		}
+
+		logger.Info().Str("id", *id).Msg("revoke api key finish")
+		return nil
+
+	case "list":
+		keys, err := store.ListAPIKeys(ctx, *owner)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			logger.Info().
+				Str("id", key.ID).
+				Str("owner", key.OwnerID).
+				Str("name", key.Name).
+				Interface("scopes", key.Scopes).
+				Time("createdAt", key.CreatedAt).
+				Bool("revoked", key.RevokedAt != nil).
+				Msg("api key")
+		}
+		return nil
+
+	default:
+		return errors.Wrapf(errors.ErrInvalidInput, "unknown apikey command %v", args[0])
+	}
+}
+
+// workspaceCommand create workspaces, add and verify their domains
+//
+//	urlshortener workspace create -id team-a -name "Team A" -owner alice
+//	urlshortener workspace set-fallback -id team-a -fallback https://www.example.com/expired
+//	urlshortener workspace add-domain -id team-a -domain go.example.com
+//	urlshortener workspace verify -domain go.example.com
+//	urlshortener workspace list [-owner alice]
+func workspaceCommand(ctx context.Context, logger zerolog.Logger, config configs.Configurations, args []string) error {
+	if len(args) == 0 {
+		return errors.Wrap(errors.ErrInvalidInput, "workspace command require create, set-fallback, add-domain, verify or list")
+	}
+
+	fs := flag.NewFlagSet("workspace "+args[0], flag.ContinueOnError)
+	id := fs.String("id", "", "workspace id, lower case letters, digits or hyphens")
+	name := fs.String("name", "", "display name of the workspace")
+	owner := fs.String("owner", "", "owner id who can short urls in the workspace")
+	domain := fs.String("domain", "", "custom domain of the workspace")
+	fallback := fs.String("fallback", "", "url which expired urls of the workspace redirect to, empty to remove")
+	if err := fs.Parse(args[1:]); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to parse args %v", err)
+	}
+
+	dbConn, err := db.NewConnection(config.Database)
+	if err != nil {
+		return err
+	}
+
+	srv := service.NewWorkspaceService(repository.NewWorkspaceRepository(dbConn), net.DefaultResolver)
+	if args[0] == "set-fallback" {
+		var counter abuse.Counter
+		if config.Abuse.Enabled {
+			rds, err := redis.NewRedis(config.Redis)
+			if err != nil {
+				return err
+			}
+			defer rds.Close()
+
+			counter = abuse.NewRedisCounter(rds)
+		}
+
+		// fallback url is checked like the original url of short urls
+		checkOpts := newURLCheckOptions(ctx, logger, config, dbConn, counter, srv)
+		srv = service.NewWorkspaceService(repository.NewWorkspaceRepository(dbConn), net.DefaultResolver, service.WithURLCheck(checkOpts...))
+	}
+
+	switch args[0] {
+	case "create":
+		workspace, err := srv.CreateWorkspace(ctx, *id, *name, *owner)
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Str("id", workspace.ID).Str("owner", workspace.OwnerID).Msg("create workspace finish")
+		return nil
+
+	case "set-fallback":
+		workspace, err := srv.SetFallbackURL(ctx, *id, *fallback)
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Str("id", workspace.ID).Str("fallback", workspace.FallbackURL).Msg("set workspace fallback finish")
+		return nil
+
+	case "add-domain":
+		d, err := srv.AddDomain(ctx, *id, *domain)
+		if err != nil {
+			return err
+		}
+
+		logger.Info().
+			Str("host", d.Host).
+			Str("workspace", d.WorkspaceID).
+			Msg("add domain finish, publish the TXT record then run workspace verify")
+
+		fmt.Printf("%v%v TXT %q\n", service.VerificationRecordPrefix, d.Host, service.VerificationValuePrefix+d.Token)
+		return nil
+
+	case "verify":
+		d, err := srv.VerifyDomain(ctx, *domain)
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Str("host", d.Host).Str("workspace", d.WorkspaceID).Msg("verify domain finish")
+		return nil
+
+	case "list":
+		workspaces, err := srv.ListWorkspaces(ctx, *owner)
+		if err != nil {
+			return err
+		}
+
+		for _, workspace := range workspaces {
+			domains := make([]string, 0, len(workspace.Domains))
+			for _, d := range workspace.Domains {
+				if d.Verified() {
+					domains = append(domains, d.Host)
+				} else {
+					domains = append(domains, d.Host+" (unverified)")
+				}
+			}
+
+			logger.Info().
+				Str("id", workspace.ID).
+				Str("name", workspace.Name).
+				Str("owner", workspace.OwnerID).
+				Strs("domains", domains).
+				Str("fallback", workspace.FallbackURL).
+				Time("createdAt", workspace.CreatedAt).
+				Msg("workspace")
+		}
+		return nil
+
+	default:
+		return errors.Wrapf(errors.ErrInvalidInput, "unknown workspace command %v", args[0])
+	}
+}
+
+func rebuildBloom(ctx context.Context, logger zerolog.Logger, config configs.Configurations, repo repository.Repository, batchSize int) error {
+	rds, err := redis.NewRedis(config.Redis)
+	if err != nil {
+		return err
+	}
+	defer rds.Close()
+
+	bf, ok := bloom.NewRedisFilter(config.BloomFilterNamespace, rds).(bloom.Rebuilder)
+	if !ok {
+		return errors.Wrap(errors.ErrInternal, "bloom filter can not rebuild")
+	}
+
+	if err := backup.RebuildBloomFilter(ctx, repo, bf, batchSize); err != nil {
+		return err
+	}
+
+	logger.Info().Msg("rebuild bloom filter finish")
+	return nil
+}
+
+func newCommandRepository(config configs.Configurations) (repository.Repository, error) {
+	dbConn, err := db.NewConnection(config.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	repoOpts, err := newRepositoryOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.New(dbConn, repoOpts...), nil
+}
+
+func backupFormat(path string, format string) (backup.Format, error) {
+	if path == "" {
+		return "", errors.Wrap(errors.ErrInvalidInput, "backup file path is required")
+	}
+
+	if format != "" {
+		return backup.Format(format), nil
+	}
+	return backup.FormatFromPath(path)
+}
@@ -1,23 +1,102 @@
 package configs
 
 import (
+	"time"
+
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 
+	"url-shortener/pkg/abuse"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/auth"
 	"url-shortener/pkg/db"
+	"url-shortener/pkg/grpc"
+	"url-shortener/pkg/guard"
 	"url-shortener/pkg/http"
+	"url-shortener/pkg/http/middleware"
+	"url-shortener/pkg/idempotency"
+	"url-shortener/pkg/keyring"
+	"url-shortener/pkg/lockout"
 	"url-shortener/pkg/logging"
+	"url-shortener/pkg/policy"
+	"url-shortener/pkg/quota"
+	"url-shortener/pkg/ratelimit"
 	"url-shortener/pkg/redis"
 )
 
 // Configurations define this application need configs
 type Configurations struct {
-	Database             db.Config      `mapstructure:"database"`
-	HTTP                 http.Config    `mapstructure:"http"`
-	Log                  logging.Config `mapstructure:"log"`
-	Redis                redis.Config   `mapstructure:"redis"`
-	BloomFilterNamespace string         `mapstructure:"bloomFilterNamespace"`
-	ServerHost           string         `mapstructure:"serverHost"`
+	Database             db.Config          `mapstructure:"database"`
+	HTTP                 http.Config        `mapstructure:"http"`
+	GRPC                 grpc.Config        `mapstructure:"grpc"`
+	Log                  logging.Config     `mapstructure:"log"`
+	Redis                redis.Config       `mapstructure:"redis"`
+	BloomFilterNamespace string             `mapstructure:"bloomFilterNamespace"`
+	ServerHost           string             `mapstructure:"serverHost"`
+	Encryption           keyring.Config     `mapstructure:"encryption"`
+	Migration            Migration          `mapstructure:"migration"`
+	Auth                 Auth               `mapstructure:"auth"`
+	Workspaces           Workspaces         `mapstructure:"workspaces"`
+	Quota                quota.Config       `mapstructure:"quota"`
+	RateLimit            ratelimit.Config   `mapstructure:"rateLimit"`
+	Guard                guard.Config       `mapstructure:"guard"`
+	Policy               policy.Config      `mapstructure:"policy"`
+	Abuse                abuse.Config       `mapstructure:"abuse"`
+	Interstitial         Interstitial       `mapstructure:"interstitial"`
+	Canonical            Canonical          `mapstructure:"canonical"`
+	Idempotency          idempotency.Config `mapstructure:"idempotency"`
+	Protection           Protection         `mapstructure:"protection"`
+	Fallback             Fallback           `mapstructure:"fallback"`
+}
+
+// Auth define api key and jwt bearer token authentication
+type Auth struct {
+	auth.Config `mapstructure:",squash"`
+
+	JWT middleware.JWTConfig `mapstructure:"jwt"`
+}
+
+// Workspaces define workspaces which short urls on their own verified domains
+type Workspaces struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// Interstitial define warning page shown instead of redirect for interstitial and flagged urls
+type Interstitial struct {
+	// Delay is how long the continue button is disabled, zero enable it at once
+	Delay time.Duration `mapstructure:"delay"`
+}
+
+// Canonical define how original urls are canonicalized before they are checked and deduplicated
+type Canonical struct {
+	// TrackingParams is query params ignored by the canonical url, "utm_*" match by prefix, empty keeps all params
+	TrackingParams []string `mapstructure:"trackingParams"`
+}
+
+// Protection define password form of protected urls
+type Protection struct {
+	// CookieSecret sign unlock cookies, instances must share it, empty generates one per process
+	CookieSecret string `mapstructure:"cookieSecret"`
+
+	// CookieTTL is how long a protected url stays unlocked after the password is entered
+	CookieTTL time.Duration `mapstructure:"cookieTTL"`
+
+	// Lockout limit wrong passwords per url and per client
+	Lockout lockout.Config `mapstructure:"lockout"`
+}
+
+// Fallback define where expired urls redirect to when neither the url nor its workspace has a fallback url
+type Fallback struct {
+	// URL is the default landing page of expired urls, empty keeps the 404 response
+	URL string `mapstructure:"url"`
+}
+
+// Migration define online migration from database to target storage
+type Migration struct {
+	Enabled bool      `mapstructure:"enabled"`
+	Target  db.Config `mapstructure:"target"`
+
+	repository.MigrationConfig `mapstructure:",squash"`
 }
 
 // NewConfig read configs and create new instance
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"net"
+	"net/url"
 	"os"
 	"os/signal"
 	"sync"
@@ -9,17 +11,31 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 
 	"url-shortener/cmd/urlshortener/configs"
+	"url-shortener/pkg/abuse"
 	"url-shortener/pkg/app/urlshortener/endpoints"
 	"url-shortener/pkg/app/urlshortener/repository"
 	"url-shortener/pkg/app/urlshortener/service"
+	tg "url-shortener/pkg/app/urlshortener/transports/grpc"
+	"url-shortener/pkg/app/urlshortener/transports/grpc/pb"
 	th "url-shortener/pkg/app/urlshortener/transports/http"
+	"url-shortener/pkg/auth"
 	"url-shortener/pkg/bloom"
+	"url-shortener/pkg/clicklimit"
 	"url-shortener/pkg/db"
+	pg "url-shortener/pkg/grpc"
+	"url-shortener/pkg/guard"
 	"url-shortener/pkg/http"
 	"url-shortener/pkg/http/middleware"
+	"url-shortener/pkg/idempotency"
+	"url-shortener/pkg/keyring"
+	"url-shortener/pkg/lockout"
 	"url-shortener/pkg/logging"
+	"url-shortener/pkg/policy"
+	"url-shortener/pkg/quota"
+	"url-shortener/pkg/ratelimit"
 	"url-shortener/pkg/redis"
 )
 
@@ -28,6 +44,7 @@ type Application struct {
 	logger     zerolog.Logger
 	config     configs.Configurations
 	httpServer *echo.Echo
+	grpcServer *grpc.Server
 	handler    *th.Handler
 
 	Close func()
@@ -56,23 +73,192 @@ func NewApplication(
 			Msg("failed to connection redis")
 	}
 
+	repoOpts, err := newRepositoryOptions(config)
+	if err != nil {
+		logger.
+			Panic().
+			Err(err).
+			Msg("failed to setup repository")
+	}
+
 	bf := bloom.NewRedisFilter(config.BloomFilterNamespace, rds)
-	repo := repository.New(dbConn)
-	svc := service.New(repo, bf)
+	repo := repository.New(dbConn, repoOpts...)
+	if config.Migration.Enabled {
+		targetConn, err := db.NewConnection(config.Migration.Target)
+		if err != nil {
+			logger.
+				Panic().
+				Err(err).
+				Msg("failed to connection migration target database")
+		}
+
+		repo = repository.NewMigrating(repo, repository.New(targetConn, repoOpts...), config.Migration.MigrationConfig)
+	}
+	var workspaces service.WorkspaceService
+	if config.Workspaces.Enabled {
+		workspaces = service.NewWorkspaceService(repository.NewWorkspaceRepository(dbConn), net.DefaultResolver)
+	}
+
+	var svcOpts []service.Option
+	if config.Quota.Enabled {
+		svcOpts = append(svcOpts, service.WithQuota(quota.NewRedisQuota(rds, config.Quota)))
+	}
+	var g guard.Guard
+	if config.Guard.Enabled {
+		g = guard.NewRedisGuard(rds, config.Guard)
+		svcOpts = append(svcOpts, service.WithGuard(g))
+	}
+	watchCtx, stopWatch := context.WithCancel(logger.WithContext(context.Background()))
+	checkOpts := newURLCheckOptions(watchCtx, logger, config, dbConn, abuse.NewRedisCounter(rds), workspaces)
+	if workspaces != nil {
+		// the policy only resolve hosts by the workspace service above, so the one checking fallback urls by the policy is made after it
+		workspaces = service.NewWorkspaceService(repository.NewWorkspaceRepository(dbConn), net.DefaultResolver, service.WithURLCheck(checkOpts...))
+	}
+	svcOpts = append(svcOpts, checkOpts...)
+	svcOpts = append(svcOpts, service.WithReports(repository.NewReportRepository(dbConn)))
+	svcOpts = append(svcOpts, service.WithClickCounter(clicklimit.NewRedisCounter(rds)))
+	svcOpts = append(svcOpts, service.WithLockout(lockout.NewRedisLockout(rds, config.Protection.Lockout)))
+	svc := service.New(repo, bf, svcOpts...)
 	e := endpoints.New(svc)
-	h := th.NewHandler(e)
+
+	var (
+		handlerOpts []th.Option
+		grpcOpts    []grpc.ServerOption
+	)
+	if config.Auth.Enabled {
+		authenticators := []auth.Authenticator{auth.NewAPIKeyAuthenticator(auth.NewAPIKeyStore(dbConn))}
+		if config.Auth.JWT.Enabled {
+			jwt, err := middleware.NewJWTAuthenticator(logger.WithContext(context.Background()), config.Auth.JWT)
+			if err != nil {
+				logger.
+					Panic().
+					Err(err).
+					Msg("failed to setup jwt authenticator")
+			}
+			authenticators = append(authenticators, jwt)
+		}
+
+		handlerOpts = append(handlerOpts, th.WithAuthenticators(authenticators...))
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(
+			auth.UnaryServerInterceptor(map[string]auth.Scope{
+				pb.URLShortener_ShortURL_FullMethodName:  auth.ScopeCreate,
+				pb.URLShortener_UpdateURL_FullMethodName: auth.ScopeManage,
+				pb.URLShortener_DeleteURL_FullMethodName: auth.ScopeManage,
+			}, authenticators...),
+		))
+	}
+	if config.RateLimit.Enabled {
+		limiter := ratelimit.NewFallbackLimiter(ratelimit.NewRedisLimiter(rds), ratelimit.NewMemoryLimiter())
+		policies := map[string]ratelimit.Policy{
+			th.RateLimitCreate:   config.RateLimit.Create,
+			th.RateLimitRedirect: config.RateLimit.Redirect,
+			th.RateLimitReport:   config.RateLimit.Report,
+		}
+		handlerOpts = append(handlerOpts, th.WithRateLimit(limiter, policies))
+		// after auth, so authenticated request is limited by its credential, grpc share the policy counters of http
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(
+			ratelimit.UnaryServerInterceptor(limiter, map[string]string{
+				pb.URLShortener_ShortURL_FullMethodName: th.RateLimitCreate,
+				pb.URLShortener_Resolve_FullMethodName:  th.RateLimitRedirect,
+			}, policies),
+		))
+	}
+	if g != nil {
+		handlerOpts = append(handlerOpts, th.WithGuard(g))
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(
+			guard.UnaryServerInterceptor(g, pb.URLShortener_Resolve_FullMethodName),
+		))
+	}
+	if workspaces != nil {
+		handlerOpts = append(handlerOpts, th.WithWorkspaces(workspaces))
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(tg.UnaryWorkspaceInterceptor(workspaces)))
+	}
+	if config.Idempotency.Enabled {
+		handlerOpts = append(handlerOpts, th.WithIdempotency(idempotency.NewRedisStore(rds, config.Idempotency), config.Idempotency.Wait))
+	}
+	handlerOpts = append(handlerOpts, th.WithInterstitialDelay(config.Interstitial.Delay))
+	if config.Protection.CookieSecret == "" {
+		logger.Warn().Msg("protection cookie secret is empty, protected urls are unlocked only on the instance which checked the password")
+	}
+	if config.Protection.CookieTTL <= 0 {
+		config.Protection.CookieTTL = th.DefaultUnlockTTL
+	}
+	handlerOpts = append(handlerOpts, th.WithUnlockCookie([]byte(config.Protection.CookieSecret), config.Protection.CookieTTL))
+	handlerOpts = append(handlerOpts, th.WithFallbackURL(config.Fallback.URL))
+	h := th.NewHandler(e, handlerOpts...)
+
+	grpcServer := pg.NewServer(logger, grpcOpts...)
+	pb.RegisterURLShortenerServer(grpcServer, tg.NewServer(e))
 
 	return &Application{
 		logger:     logger,
 		config:     config,
 		httpServer: http.NewEcho(config.HTTP),
+		grpcServer: grpcServer,
 		handler:    h,
 		Close: func() {
+			stopWatch()
 			rds.Close()
 		},
 	}
 }
 
+// newPolicy make destination url policy which watch its blocklists until ctx is done,
+// serverHost and verified workspace domains are our own hosts
+// newURLCheckOptions make the service options which check urls before they are shorted, policy, abuse and canonical url
+func newURLCheckOptions(
+	ctx context.Context,
+	logger zerolog.Logger,
+	config configs.Configurations,
+	dbConn db.Connection,
+	counter abuse.Counter,
+	workspaces service.WorkspaceService,
+) []service.Option {
+	var opts []service.Option
+	if config.Policy.Enabled {
+		opts = append(opts, service.WithPolicy(newPolicy(ctx, logger, config, workspaces)))
+	}
+	if config.Abuse.Enabled {
+		scorer := abuse.New(config.Abuse, abuse.DefaultRules(config.Abuse, counter)...)
+		opts = append(opts, service.WithAbuse(scorer, repository.NewReviewRepository(dbConn)))
+	}
+	opts = append(opts, service.WithTrackingParams(config.Canonical.TrackingParams...))
+
+	return opts
+}
+
+func newPolicy(ctx context.Context, logger zerolog.Logger, config configs.Configurations, workspaces service.WorkspaceService) policy.Policy {
+	policyConfig := config.Policy
+	if u, err := url.Parse(config.ServerHost); err == nil && u.Hostname() != "" {
+		policyConfig.SelfHosts = append(policyConfig.SelfHosts, u.Hostname())
+	}
+
+	var opts []policy.Option
+	if workspaces != nil {
+		opts = append(opts, policy.WithOwnHost(func(ctx context.Context, host string) bool {
+			workspace, err := workspaces.ResolveHost(ctx, host)
+			return err == nil && workspace != nil
+		}))
+	}
+
+	p, err := policy.New(policyConfig, opts...)
+	if err != nil {
+		logger.
+			Panic().
+			Err(err).
+			Msg("failed to setup url policy")
+	}
+
+	if err := p.Watch(ctx); err != nil {
+		logger.
+			Panic().
+			Err(err).
+			Msg("failed to watch url blocklists")
+	}
+
+	return p
+}
+
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -83,6 +269,18 @@ func main() {
 	// setup default logger
 	logger := logging.Setup(config.Log)
 
+	// run sub command instead of http server
+	if len(os.Args) > 1 {
+		err := runCommand(ctx, logger, config, os.Args[1], os.Args[2:])
+		if err != nil {
+			logger.
+				Fatal().
+				Err(err).
+				Msgf("failed to run command %v", os.Args[1])
+		}
+		return
+	}
+
 	// make app
 	app := NewApplication(logger, config)
 	app.httpServer.Pre(middleware.NewLoggerMiddleware(logger))
@@ -92,7 +290,9 @@ func main() {
 
 	wg := &sync.WaitGroup{}
 
+	wg.Add(2)
 	go app.startHttpServer(ctx, wg)
+	go app.startGRPCServer(ctx, wg)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
@@ -100,11 +300,12 @@ func main() {
 
 	cancel()
 	wg.Wait()
+
+	app.Close()
 }
 
 // startHttpServer wrap http start
 func (app *Application) startHttpServer(ctx context.Context, wg *sync.WaitGroup) {
-	wg.Add(1)
 	defer wg.Done()
 
 	app.logger.Info().Msgf("start http server on %v", app.config.HTTP.Port)
@@ -130,13 +331,66 @@ func (app *Application) startHttpServer(ctx context.Context, wg *sync.WaitGroup)
 			Err(err).
 			Msg("failed to http shutdown")
 	}
+}
 
-	app.Close()
+// startGRPCServer wrap grpc start
+func (app *Application) startGRPCServer(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	app.logger.Info().Msgf("start grpc server on %v", app.config.GRPC.Port)
+
+	go func() {
+		lis, err := net.Listen("tcp", app.config.GRPC.Port)
+		if err != nil {
+			app.logger.Error().Err(err).Msg("failed to listen grpc port")
+			return
+		}
+
+		err = app.grpcServer.Serve(lis)
+		if err != nil {
+			app.logger.Error().Err(err).Msg("grpc server shutdown ...")
+		}
+	}()
+
+	<-ctx.Done()
+
+	// gracefulShutdown wrap graceful shutdown grpc server
+	// timeout 5 sec will direct close server
+	stopped := make(chan struct{})
+	go func() {
+		app.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		app.logger.Error().Msg("failed to grpc graceful shutdown")
+		app.grpcServer.Stop()
+	}
+}
+
+// newRepositoryOptions make repository options from configs
+func newRepositoryOptions(config configs.Configurations) ([]repository.Option, error) {
+	opts := []repository.Option{
+		repository.WithCanonical(func(rawURL string) string {
+			return service.CanonicalURL(rawURL, config.Canonical.TrackingParams)
+		}),
+	}
+
+	if config.Encryption.Enabled {
+		k, err := keyring.Load(config.Encryption.KeyringPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, repository.WithKeyring(k))
+	}
+
+	return opts, nil
 }
 
 // MakeRouter register router into echo http server
 func (app *Application) MakeRouter() *Application {
-	app.httpServer.POST("/api/v1/urls", app.handler.ShortURL)
-	app.httpServer.GET("/:url", app.handler.RedirectURL)
+	app.handler.MakeRouter(app.httpServer)
 	return app
 }
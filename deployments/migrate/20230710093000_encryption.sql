@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ALTER COLUMN original_url TYPE text,
+    ADD COLUMN IF NOT EXISTS key_id varchar(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.original_url IS 'OriginalURL original URL which input by the user, ciphertext when key_id is not empty';
+COMMENT ON COLUMN shortened_urls.key_id IS 'KeyID the keyring key id which encrypt original_url, empty means plaintext';
+
+CREATE INDEX IF NOT EXISTS shortened_urls_key_id_idx ON shortened_urls (key_id);
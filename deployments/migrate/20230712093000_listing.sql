@@ -0,0 +1,31 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS original_host varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS title         varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tags          jsonb        NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS notes         text         NOT NULL DEFAULT '';
+
+-- encrypted original_url is not searchable, only title is indexed for those rows
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('simple', title || ' ' || CASE WHEN key_id = '' THEN original_url ELSE '' END)
+    ) STORED;
+
+UPDATE shortened_urls
+SET original_host = lower(substring(original_url from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)'))
+WHERE key_id = ''
+  AND original_host = '';
+
+COMMENT ON COLUMN shortened_urls.original_host IS 'OriginalHost lower case host of original_url, kept in plaintext for filtering';
+COMMENT ON COLUMN shortened_urls.title IS 'Title is display name of the url';
+COMMENT ON COLUMN shortened_urls.tags IS 'Tags is json array of labels';
+COMMENT ON COLUMN shortened_urls.notes IS 'Notes is free text of the url';
+COMMENT ON COLUMN shortened_urls.search_vector IS 'SearchVector full text search of title and plaintext original_url';
+
+CREATE INDEX IF NOT EXISTS shortened_urls_created_at_short_idx ON shortened_urls (created_at, short);
+CREATE INDEX IF NOT EXISTS shortened_urls_expired_at_idx ON shortened_urls (expired_at);
+CREATE INDEX IF NOT EXISTS shortened_urls_original_host_idx ON shortened_urls (original_host);
+CREATE INDEX IF NOT EXISTS shortened_urls_tags_idx ON shortened_urls USING gin (tags jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS shortened_urls_search_vector_idx ON shortened_urls USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS shortened_urls_original_url_prefix_idx ON shortened_urls (original_url text_pattern_ops) WHERE key_id = '';
+CREATE INDEX IF NOT EXISTS shortened_urls_title_prefix_idx ON shortened_urls (lower(title) text_pattern_ops);
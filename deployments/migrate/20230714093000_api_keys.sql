@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         varchar(12)  NOT NULL UNIQUE PRIMARY KEY,
+    owner_id   varchar(64)  NOT NULL,
+    name       varchar(100) NOT NULL DEFAULT '',
+    hash       varchar(64)  NOT NULL,
+    scopes     varchar(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    revoked_at BIGINT       NOT NULL DEFAULT 0
+);
+
+COMMENT ON COLUMN api_keys.id IS 'ID is the public part of api key and Primary key';
+COMMENT ON COLUMN api_keys.owner_id IS 'OwnerID the owner of urls created by the key';
+COMMENT ON COLUMN api_keys.name IS 'Name is display name of the key';
+COMMENT ON COLUMN api_keys.hash IS 'Hash sha256 hex of the whole key, the key itself is never stored';
+COMMENT ON COLUMN api_keys.scopes IS 'Scopes comma separated scopes, create, read, manage or admin';
+COMMENT ON COLUMN api_keys.created_at IS 'CreatedAt the key created at';
+COMMENT ON COLUMN api_keys.revoked_at IS 'RevokedAt the key revoked at, zero means active';
+
+CREATE INDEX IF NOT EXISTS api_keys_owner_id_idx ON api_keys (owner_id);
+
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS owner_id varchar(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.owner_id IS 'OwnerID the principal which created the url, empty means anonymous';
+
+CREATE INDEX IF NOT EXISTS shortened_urls_owner_id_idx ON shortened_urls (owner_id);
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS workspaces
+(
+    id         varchar(32)  NOT NULL UNIQUE PRIMARY KEY,
+    name       varchar(100) NOT NULL DEFAULT '',
+    owner_id   varchar(64)  NOT NULL,
+    created_at BIGINT       NOT NULL
+);
+
+COMMENT ON COLUMN workspaces.id IS 'ID is url safe slug of workspace and Primary key';
+COMMENT ON COLUMN workspaces.name IS 'Name is display name of workspace';
+COMMENT ON COLUMN workspaces.owner_id IS 'OwnerID the principal which can short urls in the workspace';
+COMMENT ON COLUMN workspaces.created_at IS 'CreatedAt the workspace created at';
+
+CREATE INDEX IF NOT EXISTS workspaces_owner_id_idx ON workspaces (owner_id);
+
+CREATE TABLE IF NOT EXISTS workspace_domains
+(
+    host         varchar(255) NOT NULL UNIQUE PRIMARY KEY,
+    workspace_id varchar(32)  NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    token        varchar(64)  NOT NULL,
+    created_at   BIGINT       NOT NULL,
+    verified_at  BIGINT       NOT NULL DEFAULT 0
+);
+
+COMMENT ON COLUMN workspace_domains.host IS 'Host is lower case host name and Primary key';
+COMMENT ON COLUMN workspace_domains.workspace_id IS 'WorkspaceID the workspace which the domain belong to';
+COMMENT ON COLUMN workspace_domains.token IS 'Token is the value of DNS TXT record which prove the domain ownership';
+COMMENT ON COLUMN workspace_domains.created_at IS 'CreatedAt the domain added at';
+COMMENT ON COLUMN workspace_domains.verified_at IS 'VerifiedAt the domain ownership verified at, zero means not verified';
+
+CREATE INDEX IF NOT EXISTS workspace_domains_workspace_id_idx ON workspace_domains (workspace_id);
+
+-- short id is unique in a workspace, the same short id can exist on two domains
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS workspace_id varchar(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.workspace_id IS 'WorkspaceID the workspace which the short id belong to, empty means the default workspace';
+
+ALTER TABLE shortened_urls
+    DROP CONSTRAINT IF EXISTS shortened_urls_short_key,
+    DROP CONSTRAINT IF EXISTS shortened_urls_pkey,
+    ADD PRIMARY KEY (workspace_id, short);
@@ -0,0 +1,27 @@
+-- +goose Up
+-- original url is not copied, so encrypted urls stay encrypted at rest
+CREATE TABLE IF NOT EXISTS link_reviews
+(
+    workspace_id varchar(32) NOT NULL DEFAULT '',
+    short        varchar(8)  NOT NULL,
+    owner_id     varchar(64) NOT NULL DEFAULT '',
+    score        INTEGER     NOT NULL,
+    signals      TEXT        NOT NULL DEFAULT '[]',
+    status       varchar(16) NOT NULL DEFAULT 'pending',
+    created_at   BIGINT      NOT NULL,
+    reviewed_at  BIGINT      NOT NULL DEFAULT 0,
+    reviewer_id  varchar(64) NOT NULL DEFAULT '',
+    PRIMARY KEY (workspace_id, short)
+);
+
+COMMENT ON COLUMN link_reviews.workspace_id IS 'WorkspaceID the workspace of the flagged url, empty means the default workspace';
+COMMENT ON COLUMN link_reviews.short IS 'Short the short id of the flagged url';
+COMMENT ON COLUMN link_reviews.owner_id IS 'OwnerID the principal which created the url';
+COMMENT ON COLUMN link_reviews.score IS 'Score the total abuse score';
+COMMENT ON COLUMN link_reviews.signals IS 'Signals JSON array of matched abuse rules';
+COMMENT ON COLUMN link_reviews.status IS 'Status pending, approved or rejected';
+COMMENT ON COLUMN link_reviews.created_at IS 'CreatedAt the url flagged at';
+COMMENT ON COLUMN link_reviews.reviewed_at IS 'ReviewedAt the review resolved at, zero means pending';
+COMMENT ON COLUMN link_reviews.reviewer_id IS 'ReviewerID the admin which resolved the review';
+
+CREATE INDEX IF NOT EXISTS link_reviews_status_created_at_idx ON link_reviews (status, created_at);
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS interstitial varchar(16) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.interstitial IS 'Interstitial warning page mode, empty redirects at once, always or flagged show the page';
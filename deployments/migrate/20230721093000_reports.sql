@@ -0,0 +1,49 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS disabled_at BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN shortened_urls.disabled_at IS 'DisabledAt the url disabled for abuse at, zero means enabled';
+
+CREATE TABLE IF NOT EXISTS abuse_reports
+(
+    id           BIGSERIAL PRIMARY KEY,
+    workspace_id varchar(32)   NOT NULL DEFAULT '',
+    short        varchar(8)    NOT NULL,
+    reason       varchar(1000) NOT NULL,
+    contact      varchar(200)  NOT NULL DEFAULT '',
+    status       varchar(16)   NOT NULL DEFAULT 'open',
+    resolution   varchar(16)   NOT NULL DEFAULT '',
+    created_at   BIGINT        NOT NULL,
+    updated_at   BIGINT        NOT NULL
+);
+
+COMMENT ON COLUMN abuse_reports.workspace_id IS 'WorkspaceID the workspace of the reported url, empty means the default workspace';
+COMMENT ON COLUMN abuse_reports.short IS 'Short the short id of the reported url';
+COMMENT ON COLUMN abuse_reports.reason IS 'Reason why the reporter think the url is abuse';
+COMMENT ON COLUMN abuse_reports.contact IS 'Contact of the reporter, optional';
+COMMENT ON COLUMN abuse_reports.status IS 'Status open, triaged or resolved';
+COMMENT ON COLUMN abuse_reports.resolution IS 'Resolution disabled or dismissed when the report is resolved';
+COMMENT ON COLUMN abuse_reports.created_at IS 'CreatedAt the report created at';
+COMMENT ON COLUMN abuse_reports.updated_at IS 'UpdatedAt the last moderator action at';
+
+CREATE INDEX IF NOT EXISTS abuse_reports_status_created_at_idx ON abuse_reports (status, created_at);
+CREATE INDEX IF NOT EXISTS abuse_reports_workspace_id_short_idx ON abuse_reports (workspace_id, short);
+
+-- moderation_actions is append only audit trail of moderators
+CREATE TABLE IF NOT EXISTS moderation_actions
+(
+    id         BIGSERIAL PRIMARY KEY,
+    report_id  BIGINT        NOT NULL REFERENCES abuse_reports (id),
+    actor_id   varchar(64)   NOT NULL DEFAULT '',
+    action     varchar(16)   NOT NULL,
+    note       varchar(1000) NOT NULL DEFAULT '',
+    created_at BIGINT        NOT NULL
+);
+
+COMMENT ON COLUMN moderation_actions.report_id IS 'ReportID the report which the action is taken on';
+COMMENT ON COLUMN moderation_actions.actor_id IS 'ActorID the moderator which take the action';
+COMMENT ON COLUMN moderation_actions.action IS 'Action triage, disable or dismiss';
+COMMENT ON COLUMN moderation_actions.note IS 'Note of the moderator';
+COMMENT ON COLUMN moderation_actions.created_at IS 'CreatedAt the action taken at';
+
+CREATE INDEX IF NOT EXISTS moderation_actions_report_id_idx ON moderation_actions (report_id, id);
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS canonical_hash varchar(64) NOT NULL DEFAULT '';
+
+-- only plaintext rows are backfilled, their url is already readable so plain sha256 reveals nothing more.
+-- encrypted rows are never hashed here and are never returned by dedupe,
+-- plaintext rows sealed later by key rotation get the keyed hash instead
+UPDATE shortened_urls
+SET canonical_hash = encode(sha256(convert_to(original_url, 'UTF8')), 'hex')
+WHERE key_id = ''
+  AND canonical_hash = '';
+
+COMMENT ON COLUMN shortened_urls.canonical_hash IS 'CanonicalHash hex hmac-sha256 keyed by keyring of the canonical original_url for dedupe, plain sha256 without keyring';
+
+CREATE INDEX IF NOT EXISTS shortened_urls_canonical_hash_idx ON shortened_urls (workspace_id, owner_id, canonical_hash);
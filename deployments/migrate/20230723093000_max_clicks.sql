@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS max_clicks BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS clicks     BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN shortened_urls.max_clicks IS 'MaxClicks how many redirects the url serves, 0 means unlimited';
+COMMENT ON COLUMN shortened_urls.clicks IS 'Clicks redirects counted against max_clicks, never greater than it';
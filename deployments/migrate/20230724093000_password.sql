@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS password_hash varchar(100) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.password_hash IS 'PasswordHash bcrypt hash of the password which unlocks the redirect, empty means not protected';
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS activate_at BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS schedule text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.activate_at IS 'ActivateAt unix milliseconds which the url starts to redirect at, zero means at once';
+COMMENT ON COLUMN shortened_urls.schedule IS 'Schedule json array of destination switches order by time, sealed with key_id like original_url when encrypted, empty means none';
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE shortened_urls
+    ADD COLUMN IF NOT EXISTS fallback_url text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN shortened_urls.fallback_url IS 'FallbackURL the url redirect to after it is expired, empty means the fallback of workspace or server';
+
+ALTER TABLE workspaces
+    ADD COLUMN IF NOT EXISTS fallback_url text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN workspaces.fallback_url IS 'FallbackURL expired urls of the workspace redirect to when they have no fallback, empty means none';
@@ -0,0 +1,188 @@
+package abuse
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	ReasonRejected = "ABUSE_SUSPECTED" // ReasonRejected is error detail reason of rejected url
+	Domain         = "abuse"           // Domain is error detail domain
+)
+
+// Action is what to do with a new url
+type Action string
+
+const (
+	ActionAllow  Action = "allow"  // ActionAllow create the url
+	ActionFlag   Action = "flag"   // ActionFlag create the url and queue it for review
+	ActionReject Action = "reject" // ActionReject refuse to create the url
+)
+
+// Config phishing and abuse scoring config
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// FlagThreshold and RejectThreshold are total scores which flag or reject url, default 40 and 80
+	FlagThreshold   int `mapstructure:"flagThreshold"`
+	RejectThreshold int `mapstructure:"rejectThreshold"`
+
+	// Scores override score of rule by name, zero disables the rule
+	Scores map[string]int `mapstructure:"scores"`
+
+	// SuspiciousTLDs is top level domains often used by phishing, e.g. zip
+	SuspiciousTLDs []string `mapstructure:"suspiciousTLDs"`
+
+	// Shorteners is domains of other url shorteners which hide the final destination
+	Shorteners []string `mapstructure:"shorteners"`
+
+	// CredentialKeywords is words of path and query which look like credential pages, e.g. login
+	CredentialKeywords []string `mapstructure:"credentialKeywords"`
+
+	// Velocity is max new urls of a credential in window before it is suspicious
+	Velocity Velocity `mapstructure:"velocity"`
+}
+
+// Velocity is max new urls in window
+type Velocity struct {
+	Limit  int64         `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+// withDefault fill zero thresholds with default
+func (c Config) withDefault() Config {
+	if c.FlagThreshold <= 0 {
+		c.FlagThreshold = 40
+	}
+	if c.RejectThreshold <= 0 {
+		c.RejectThreshold = 80
+	}
+	return c
+}
+
+// score return score of rule from config, or its default score.
+// Rule name is case insensitive as viper lower case map keys
+func (c Config) score(rule string, defaultScore int) int {
+	for name, score := range c.Scores {
+		if strings.EqualFold(name, rule) {
+			return score
+		}
+	}
+	return defaultScore
+}
+
+// Input is a new url to score
+type Input struct {
+	URL *url.URL
+
+	// Subject is who create the url, e.g. apikey:Xa8LmQ2pRt0z, empty means anonymous
+	Subject string
+}
+
+// Signal is a matched rule
+type Signal struct {
+	Rule   string
+	Score  int
+	Reason string
+}
+
+// Verdict is result of scoring, signals are sorted by score from high to low
+type Verdict struct {
+	Action  Action
+	Score   int
+	Signals []Signal
+}
+
+// Rule score one aspect of url, zero score means not matched
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, in *Input) (score int, reason string, err error)
+}
+
+// Scorer score new url
+//
+//go:generate mockery --name Scorer --with-expecter
+type Scorer interface {
+	// Score return verdict of url created by subject
+	Score(ctx context.Context, rawURL string, subject string) (Verdict, error)
+}
+
+var _ Scorer = &Pipeline{}
+
+// Pipeline sum scores of rules and decide action by thresholds
+type Pipeline struct {
+	config Config
+	rules  []Rule
+}
+
+// New Pipeline constructor, see DefaultRules
+func New(config Config, rules ...Rule) *Pipeline {
+	return &Pipeline{
+		config: config.withDefault(),
+		rules:  rules,
+	}
+}
+
+// Score is implementation for Scorer, a failed rule is skipped
+func (p *Pipeline) Score(ctx context.Context, rawURL string, subject string) (Verdict, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Verdict{}, errors.Wrapf(errors.ErrInvalidInput, "failed to parse url err = %v", err)
+	}
+
+	in := &Input{URL: u, Subject: subject}
+	verdict := Verdict{Action: ActionAllow}
+	for _, rule := range p.rules {
+		score, reason, err := rule.Evaluate(ctx, in)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to evaluate abuse rule %v", rule.Name())
+			continue
+		}
+		if score <= 0 {
+			continue
+		}
+
+		verdict.Score += score
+		verdict.Signals = append(verdict.Signals, Signal{Rule: rule.Name(), Score: score, Reason: reason})
+	}
+
+	sort.SliceStable(verdict.Signals, func(i, j int) bool {
+		return verdict.Signals[i].Score > verdict.Signals[j].Score
+	})
+
+	switch {
+	case verdict.Score >= p.config.RejectThreshold:
+		verdict.Action = ActionReject
+	case verdict.Score >= p.config.FlagThreshold:
+		verdict.Action = ActionFlag
+	}
+
+	return verdict, nil
+}
+
+// Rejected return ErrInvalidInput with score and signals in detail metadata
+func Rejected(verdict Verdict) error {
+	rules := make([]string, 0, len(verdict.Signals))
+	for _, signal := range verdict.Signals {
+		rules = append(rules, signal.Rule)
+	}
+
+	return errors.Wrapf(
+		errors.ErrInvalidInput.WithDetails(errors.Detail{
+			Reason: ReasonRejected,
+			Domain: Domain,
+			Metadata: map[string]interface{}{
+				"score": verdict.Score,
+				"rules": rules,
+			},
+		}),
+		"url is rejected by abuse score %v, rules = %v", verdict.Score, rules,
+	)
+}
@@ -0,0 +1,118 @@
+package abuse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pe "url-shortener/pkg/errors"
+)
+
+// fakeCounter count in memory, or always fail like redis is down
+type fakeCounter struct {
+	counts map[string]int64
+	err    error
+}
+
+func (c *fakeCounter) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.counts[key]++
+	return c.counts[key], nil
+}
+
+func newTestPipeline(counter Counter) *Pipeline {
+	config := Config{
+		SuspiciousTLDs:     []string{"zip", ".top"},
+		Shorteners:         []string{"bit.ly"},
+		CredentialKeywords: []string{"login", "verify"},
+		Velocity:           Velocity{Limit: 2, Window: time.Minute},
+	}
+	return New(config, DefaultRules(config, counter)...)
+}
+
+func TestPipeline_Score(t *testing.T) {
+	p := newTestPipeline(nil)
+
+	tests := []struct {
+		url    string
+		action Action
+		rules  []string
+	}{
+		{url: "https://www.dcard.tw/f", action: ActionAllow},
+		{url: "https://bücher.example/", action: ActionAllow, rules: []string{RuleHomograph}},
+		{url: "https://xn--bcher-kva.example/", action: ActionAllow, rules: []string{RuleHomograph}},
+		{url: "https://files.zip/", action: ActionAllow, rules: []string{RuleTLD}},
+		{url: "https://203.0.113.7/", action: ActionFlag, rules: []string{RuleIPHost}},
+		{url: "https://bit.ly/3xYz", action: ActionFlag, rules: []string{RuleShortener}},
+		// Cyrillic а in аpple
+		{url: "https://xn--pple-43d.com/", action: ActionFlag, rules: []string{RuleHomograph}},
+		{url: "https://pаypal.com/signin", action: ActionFlag, rules: []string{RuleHomograph}},
+		{url: "https://paypal.com@evil.example/", action: ActionFlag, rules: []string{RuleCredential}},
+		{url: "https://paypal.com@evil.top/", action: ActionReject, rules: []string{RuleCredential, RuleTLD}},
+		{url: "https://203.0.113.7/account/verify?next=/login", action: ActionFlag, rules: []string{RuleIPHost, RuleCredential}},
+		{url: "https://pаypal.com.evil.top/login", action: ActionReject, rules: []string{RuleHomograph, RuleCredential, RuleTLD}},
+	}
+	for _, tt := range tests {
+		verdict, err := p.Score(context.Background(), tt.url, "")
+		if !assert.NoErrorf(t, err, "Score(%v)", tt.url) {
+			continue
+		}
+
+		rules := make([]string, 0, len(verdict.Signals))
+		for _, signal := range verdict.Signals {
+			rules = append(rules, signal.Rule)
+		}
+		assert.Equalf(t, tt.action, verdict.Action, "Score(%v) = %+v", tt.url, verdict)
+		assert.ElementsMatchf(t, tt.rules, rules, "Score(%v) = %+v", tt.url, verdict)
+	}
+}
+
+func TestPipeline_ScoreVelocity(t *testing.T) {
+	p := newTestPipeline(&fakeCounter{counts: map[string]int64{}})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		verdict, _ := p.Score(ctx, "https://www.dcard.tw/", "apikey:Xa8LmQ2pRt0z")
+		assert.Equal(t, ActionAllow, verdict.Action)
+	}
+
+	verdict, _ := p.Score(ctx, "https://www.dcard.tw/", "apikey:Xa8LmQ2pRt0z")
+	assert.Equal(t, ActionFlag, verdict.Action)
+	assert.Equal(t, RuleVelocity, verdict.Signals[0].Rule)
+
+	// anonymous and other credentials are not counted together
+	verdict, _ = p.Score(ctx, "https://www.dcard.tw/", "")
+	assert.Equal(t, ActionAllow, verdict.Action)
+	verdict, _ = p.Score(ctx, "https://www.dcard.tw/", "owner:alice")
+	assert.Equal(t, ActionAllow, verdict.Action)
+
+	// a failed rule is skipped
+	p = newTestPipeline(&fakeCounter{err: errors.New("connection refused")})
+	verdict, err := p.Score(ctx, "https://203.0.113.7/", "owner:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 40, verdict.Score)
+}
+
+func TestConfig_score(t *testing.T) {
+	config := Config{Scores: map[string]int{RuleTLD: 50, "iphost": 0}}
+	p := New(config, DefaultRules(config, nil)...)
+
+	verdict, _ := p.Score(context.Background(), "https://203.0.113.7/", "")
+	assert.Equal(t, ActionAllow, verdict.Action)
+	assert.Empty(t, verdict.Signals)
+}
+
+func TestRejected(t *testing.T) {
+	err := Rejected(Verdict{Action: ActionReject, Score: 90, Signals: []Signal{{Rule: RuleHomograph, Score: 60}, {Rule: RuleCredential, Score: 30}}})
+
+	assert.True(t, pe.Is(err, pe.ErrInvalidInput))
+	if e := pe.TryConvert(err); assert.NotNil(t, e) && assert.Len(t, e.Details, 1) {
+		assert.Equal(t, ReasonRejected, e.Details[0].Reason)
+		assert.Equal(t, []string{RuleHomograph, RuleCredential}, e.Details[0].Metadata["rules"])
+	}
+}
@@ -0,0 +1,92 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	abuse "url-shortener/pkg/abuse"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Scorer is an autogenerated mock type for the Scorer type
+type Scorer struct {
+	mock.Mock
+}
+
+type Scorer_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Scorer) EXPECT() *Scorer_Expecter {
+	return &Scorer_Expecter{mock: &_m.Mock}
+}
+
+// Score provides a mock function with given fields: ctx, rawURL, subject
+func (_m *Scorer) Score(ctx context.Context, rawURL string, subject string) (abuse.Verdict, error) {
+	ret := _m.Called(ctx, rawURL, subject)
+
+	var r0 abuse.Verdict
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (abuse.Verdict, error)); ok {
+		return rf(ctx, rawURL, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) abuse.Verdict); ok {
+		r0 = rf(ctx, rawURL, subject)
+	} else {
+		r0 = ret.Get(0).(abuse.Verdict)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, rawURL, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scorer_Score_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Score'
+type Scorer_Score_Call struct {
+	*mock.Call
+}
+
+// Score is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rawURL string
+//   - subject string
+func (_e *Scorer_Expecter) Score(ctx interface{}, rawURL interface{}, subject interface{}) *Scorer_Score_Call {
+	return &Scorer_Score_Call{Call: _e.mock.On("Score", ctx, rawURL, subject)}
+}
+
+func (_c *Scorer_Score_Call) Run(run func(ctx context.Context, rawURL string, subject string)) *Scorer_Score_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Scorer_Score_Call) Return(_a0 abuse.Verdict, _a1 error) *Scorer_Score_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Scorer_Score_Call) RunAndReturn(run func(context.Context, string, string) (abuse.Verdict, error)) *Scorer_Score_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewScorer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewScorer creates a new instance of Scorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewScorer(t mockConstructorTestingTNewScorer) *Scorer {
+	mock := &Scorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,211 @@
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	RuleHomograph  = "homograph"  // RuleHomograph match punycode or mixed script host
+	RuleIPHost     = "ipHost"     // RuleIPHost match raw ip host
+	RuleTLD        = "tld"        // RuleTLD match suspicious top level domain
+	RuleShortener  = "shortener"  // RuleShortener match another url shortener
+	RuleCredential = "credential" // RuleCredential match credential looking path or userinfo
+	RuleVelocity   = "velocity"   // RuleVelocity match credential which create too many urls
+)
+
+// DefaultRules return built in rules of config, velocity is only checked with a counter
+func DefaultRules(config Config, counter Counter) []Rule {
+	rules := []Rule{
+		&homographRule{punycode: config.score(RuleHomograph, 30)},
+		&scoreRule{name: RuleIPHost, score: config.score(RuleIPHost, 40), match: matchIPHost},
+		&scoreRule{name: RuleTLD, score: config.score(RuleTLD, 25), match: matchTLD(domainSet(config.SuspiciousTLDs))},
+		&scoreRule{name: RuleShortener, score: config.score(RuleShortener, 40), match: matchShortener(domainSet(config.Shorteners))},
+		&credentialRule{score: config.score(RuleCredential, 30), keywords: config.CredentialKeywords},
+	}
+
+	if counter != nil && config.Velocity.Limit > 0 && config.Velocity.Window > 0 {
+		rules = append(rules, &velocityRule{
+			score:    config.score(RuleVelocity, 40),
+			velocity: config.Velocity,
+			counter:  counter,
+		})
+	}
+
+	return rules
+}
+
+// scoreRule give a fixed score when match
+type scoreRule struct {
+	name  string
+	score int
+	match func(host string) (reason string, ok bool)
+}
+
+func (r *scoreRule) Name() string { return r.name }
+
+func (r *scoreRule) Evaluate(ctx context.Context, in *Input) (int, string, error) {
+	if reason, ok := r.match(hostOf(in)); ok {
+		return r.score, reason, nil
+	}
+	return 0, "", nil
+}
+
+// homographRule score punycode or non ascii host, and double when a label mix scripts like Latin and Cyrillic
+type homographRule struct {
+	punycode int
+}
+
+func (r *homographRule) Name() string { return RuleHomograph }
+
+func (r *homographRule) Evaluate(ctx context.Context, in *Input) (int, string, error) {
+	host := hostOf(in)
+
+	unicodeHost, err := idna.ToUnicode(host)
+	if err != nil || unicodeHost == host && isASCII(host) {
+		return 0, "", nil
+	}
+
+	for _, label := range strings.Split(unicodeHost, ".") {
+		if scripts := scriptsOf(label); len(scripts) > 1 {
+			return 2 * r.punycode, fmt.Sprintf("label %v mix %v scripts", label, strings.Join(scripts, " and ")), nil
+		}
+	}
+
+	return r.punycode, fmt.Sprintf("host %v is internationalized", unicodeHost), nil
+}
+
+// credentialRule score userinfo in url, e.g. https://paypal.com@evil.example, and credential keywords in path or query
+type credentialRule struct {
+	score    int
+	keywords []string
+}
+
+func (r *credentialRule) Name() string { return RuleCredential }
+
+func (r *credentialRule) Evaluate(ctx context.Context, in *Input) (int, string, error) {
+	if in.URL.User != nil {
+		return 2 * r.score, "url has userinfo which hide the real host", nil
+	}
+
+	text := strings.ToLower(in.URL.Path + "?" + in.URL.RawQuery)
+	for _, keyword := range r.keywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return r.score, fmt.Sprintf("path or query contain %q", keyword), nil
+		}
+	}
+
+	return 0, "", nil
+}
+
+// velocityRule score subject which create more than limit urls in window
+type velocityRule struct {
+	score    int
+	velocity Velocity
+	counter  Counter
+}
+
+func (r *velocityRule) Name() string { return RuleVelocity }
+
+func (r *velocityRule) Evaluate(ctx context.Context, in *Input) (int, string, error) {
+	if in.Subject == "" {
+		return 0, "", nil
+	}
+
+	n, err := r.counter.Incr(ctx, in.Subject, r.velocity.Window)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if n <= r.velocity.Limit {
+		return 0, "", nil
+	}
+	return r.score, fmt.Sprintf("%v created %v urls in %v", in.Subject, n, r.velocity.Window), nil
+}
+
+func matchIPHost(host string) (string, bool) {
+	if net.ParseIP(host) != nil {
+		return fmt.Sprintf("host %v is an ip", host), true
+	}
+	return "", false
+}
+
+func matchTLD(tlds map[string]bool) func(host string) (string, bool) {
+	return func(host string) (string, bool) {
+		tld := host[strings.LastIndexByte(host, '.')+1:]
+		if tlds[tld] {
+			return fmt.Sprintf("top level domain %v is suspicious", tld), true
+		}
+		return "", false
+	}
+}
+
+func matchShortener(shorteners map[string]bool) func(host string) (string, bool) {
+	return func(host string) (string, bool) {
+		for h := host; ; {
+			if shorteners[h] {
+				return fmt.Sprintf("host %v is url shortener", host), true
+			}
+			i := strings.IndexByte(h, '.')
+			if i < 0 {
+				return "", false
+			}
+			h = h[i+1:]
+		}
+	}
+}
+
+// hostOf return lower case host of input without the trailing dot
+func hostOf(in *Input) string {
+	return strings.TrimSuffix(strings.ToLower(in.URL.Hostname()), ".")
+}
+
+// domainSet return set of lower case domains without leading and trailing dots
+func domainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		if domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), "."); domain != "" {
+			set[domain] = true
+		}
+	}
+	return set
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// confusableScripts are scripts whose letters look like Latin
+var confusableScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{name: "Latin", table: unicode.Latin},
+	{name: "Cyrillic", table: unicode.Cyrillic},
+	{name: "Greek", table: unicode.Greek},
+	{name: "Armenian", table: unicode.Armenian},
+}
+
+// scriptsOf return confusable scripts used by letters of label
+func scriptsOf(label string) []string {
+	var scripts []string
+	for _, s := range confusableScripts {
+		for _, r := range label {
+			if unicode.Is(s.table, r) {
+				scripts = append(scripts, s.name)
+				break
+			}
+		}
+	}
+	return scripts
+}
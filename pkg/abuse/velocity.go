@@ -0,0 +1,53 @@
+package abuse
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"url-shortener/pkg/errors"
+)
+
+// velocityKeyPrefix is prefix of velocity counter redis key
+const velocityKeyPrefix = "abuse:velocity:"
+
+// incrScript count an event and start the window at the first event
+//
+// KEYS: counter key
+// ARGV: window in milliseconds
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Counter count events of key in fixed window
+type Counter interface {
+	// Incr add one event of key and return events in the current window
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+var _ Counter = &RedisCounter{}
+
+// RedisCounter is Counter shared by all instances through redis
+type RedisCounter struct {
+	rds *redis.Client
+}
+
+// NewRedisCounter Counter constructor
+func NewRedisCounter(rds *redis.Client) *RedisCounter {
+	return &RedisCounter{rds: rds}
+}
+
+// Incr is implementation for Counter, the window start at the first event
+func (c *RedisCounter) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	n, err := incrScript.Run(ctx, c.rds, []string{velocityKeyPrefix + key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, errors.Wrapf(errors.ErrInternal, "failed to count velocity of %v err = %v", key, err)
+	}
+
+	return n, nil
+}
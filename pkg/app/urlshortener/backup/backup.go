@@ -0,0 +1,690 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/bloom"
+	"url-shortener/pkg/errors"
+)
+
+// Format define backup file format
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl" // FormatJSONL one json object per line
+	FormatCSV   Format = "csv"   // FormatCSV csv with header
+)
+
+const (
+	checksumSuffix = ".sha256" // checksumSuffix is checksum file suffix, same format as sha256sum
+	cursorSuffix   = ".cursor" // cursorSuffix is resume cursor file suffix
+
+	defaultBatchSize = 1000
+)
+
+var csvHeader = []string{"short", "original_url", "created_at", "expired_at", "title", "tags", "notes", "owner_id", "workspace_id", "interstitial", "disabled_at", "max_clicks", "clicks", "password_hash", "activate_at", "schedule", "fallback_url"}
+
+// legacyCSVHeader is csv header before title, tags and notes are exported,
+// file exported by older version has a prefix of csvHeader which is not shorter than it
+var legacyCSVHeader = csvHeader[:4]
+
+// Record is a shortened URL in backup file
+type Record struct {
+	Short       string    `json:"short"`
+	OriginalURL string    `json:"originalUrl"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiredAt   time.Time `json:"expiredAt"`
+	Title       string    `json:"title,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	OwnerID     string    `json:"ownerId,omitempty"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+
+	Interstitial entity.Interstitial `json:"interstitial,omitempty"`
+	DisabledAt   *time.Time          `json:"disabledAt,omitempty"`
+
+	// MaxClicks and Clicks keep the click limit, so a restored single use link is not used again
+	MaxClicks int64 `json:"maxClicks,omitempty"`
+	Clicks    int64 `json:"clicks,omitempty"`
+
+	// PasswordHash is kept as the bcrypt hash, the password itself is never stored
+	PasswordHash string `json:"passwordHash,omitempty"`
+
+	// ActivateAt and Schedule keep the scheduled activation and destination switches
+	ActivateAt *time.Time           `json:"activateAt,omitempty"`
+	Schedule   []entity.Destination `json:"schedule,omitempty"`
+
+	FallbackURL string `json:"fallbackUrl,omitempty"`
+}
+
+// Cursor is resume point of export or import
+type Cursor struct {
+	Short  string `json:"short"`  // Short is the key of the last handled short id, see entity.ShortenedURL.Key
+	Offset int64  `json:"offset"` // Offset is bytes handled of backup file
+}
+
+// ExportOptions export file options
+type ExportOptions struct {
+	Path      string
+	Format    Format
+	BatchSize int
+	Resume    bool // Resume continue from cursor file of the last interrupted export
+}
+
+// ImportOptions import file options
+type ImportOptions struct {
+	Path      string
+	Format    Format
+	Mode      repository.ConflictMode
+	BatchSize int
+	Resume    bool // Resume continue from cursor file of the last interrupted import
+	Verify    bool // Verify require checksum file exist and match
+}
+
+// Result is export or import summary
+type Result struct {
+	Exported int
+	Stored   int
+	Skipped  int
+	Checksum string
+}
+
+// FormatFromPath detect format by file extension
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".csv":
+		return FormatCSV, nil
+	default:
+		return "", errors.Wrapf(errors.ErrInvalidInput, "unknown backup format of %v", path)
+	}
+}
+
+// ExportFile stream all shortened URL from repository into file
+// it writes cursor file after each batch, and checksum file when finish
+func ExportFile(ctx context.Context, repo repository.Repository, opts ExportOptions) (*Result, error) {
+	logger := log.Ctx(ctx)
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	cursor, err := loadCursor(opts.Path, opts.Resume)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(opts.Path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to open %v err = %v", opts.Path, err)
+	}
+	defer f.Close()
+
+	// drop bytes written after the last checkpoint, then hash the kept part
+	h := sha256.New()
+	if err := f.Truncate(cursor.Offset); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to truncate %v err = %v", opts.Path, err)
+	}
+	if _, err := io.CopyN(h, f, cursor.Offset); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to hash %v err = %v", opts.Path, err)
+	}
+
+	w := &countWriter{w: io.MultiWriter(f, h), n: cursor.Offset}
+	enc := newEncoder(w, opts.Format)
+
+	result := &Result{}
+	if cursor.Offset == 0 {
+		if err := enc.header(); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		shortenedURLs, err := repo.ListShortenedURLs(ctx, cursor.Short, opts.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(shortenedURLs) == 0 {
+			break
+		}
+
+		for _, shortenedURL := range shortenedURLs {
+			if err := enc.encode(toRecord(shortenedURL)); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.flush(); err != nil {
+			return nil, err
+		}
+
+		result.Exported += len(shortenedURLs)
+		cursor = Cursor{
+			Short:  shortenedURLs[len(shortenedURLs)-1].Key(),
+			Offset: w.n,
+		}
+		if err := saveCursor(opts.Path, cursor); err != nil {
+			return nil, err
+		}
+
+		logger.Info().Int("exported", result.Exported).Str("cursor", cursor.Short).Msg("export batch finish")
+	}
+
+	if err := enc.flush(); err != nil {
+		return nil, err
+	}
+
+	result.Checksum = hex.EncodeToString(h.Sum(nil))
+	if err := writeChecksum(opts.Path, result.Checksum); err != nil {
+		return nil, err
+	}
+
+	return result, removeCursor(opts.Path)
+}
+
+// ImportFile stream all shortened URL from file into repository
+func ImportFile(ctx context.Context, repo repository.Repository, opts ImportOptions) (*Result, error) {
+	logger := log.Ctx(ctx)
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	checksum, err := verifyChecksum(opts.Path, opts.Verify)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := loadCursor(opts.Path, opts.Resume)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to open %v err = %v", opts.Path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(cursor.Offset, io.SeekStart); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to seek %v err = %v", opts.Path, err)
+	}
+
+	dec := newDecoder(bufio.NewReader(f), opts.Format, cursor.Offset)
+	if cursor.Offset == 0 {
+		if err := dec.header(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{Checksum: checksum}
+	for {
+		record, err := dec.decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		stored, err := repo.ImportShortenedURL(ctx, toEntity(record), opts.Mode)
+		if err != nil {
+			return nil, err
+		}
+
+		if stored {
+			result.Stored++
+		} else {
+			result.Skipped++
+		}
+
+		if (result.Stored+result.Skipped)%opts.BatchSize == 0 {
+			if err := saveCursor(opts.Path, Cursor{Short: entity.JoinKey(record.WorkspaceID, record.Short), Offset: dec.offset}); err != nil {
+				return nil, err
+			}
+
+			logger.Info().Int("stored", result.Stored).Int("skipped", result.Skipped).Msg("import batch finish")
+		}
+	}
+
+	return result, removeCursor(opts.Path)
+}
+
+// RebuildBloomFilter replace bloom filter with keys of all short id in repository
+func RebuildBloomFilter(ctx context.Context, repo repository.Repository, rebuilder bloom.Rebuilder, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	cursor := ""
+	return rebuilder.Rebuild(ctx, func(ctx context.Context) ([]string, error) {
+		shortenedURLs, err := repo.ListShortenedURLs(ctx, cursor, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]string, 0, len(shortenedURLs))
+		for _, shortenedURL := range shortenedURLs {
+			items = append(items, shortenedURL.Key())
+		}
+
+		if len(items) > 0 {
+			cursor = items[len(items)-1]
+		}
+
+		return items, nil
+	})
+}
+
+func toRecord(shortenedURL *entity.ShortenedURL) *Record {
+	record := &Record{
+		Short:       shortenedURL.Short,
+		OriginalURL: shortenedURL.OriginalURL,
+		CreatedAt:   shortenedURL.CreatedAt.UTC(),
+		ExpiredAt:   shortenedURL.ExpiredAt.UTC(),
+		Title:       shortenedURL.Title,
+		Tags:        shortenedURL.Tags,
+		Notes:       shortenedURL.Notes,
+		OwnerID:     shortenedURL.OwnerID,
+		WorkspaceID: shortenedURL.WorkspaceID,
+
+		Interstitial: shortenedURL.Interstitial,
+		MaxClicks:    shortenedURL.MaxClicks,
+		Clicks:       shortenedURL.Clicks,
+		PasswordHash: shortenedURL.PasswordHash,
+		Schedule:     shortenedURL.Schedule,
+		FallbackURL:  shortenedURL.FallbackURL,
+	}
+	if !shortenedURL.DisabledAt.IsZero() {
+		disabledAt := shortenedURL.DisabledAt.UTC()
+		record.DisabledAt = &disabledAt
+	}
+	if !shortenedURL.ActivateAt.IsZero() {
+		activateAt := shortenedURL.ActivateAt.UTC()
+		record.ActivateAt = &activateAt
+	}
+	return record
+}
+
+func toEntity(record *Record) *entity.ShortenedURL {
+	shortenedURL := &entity.ShortenedURL{
+		Short:       record.Short,
+		OriginalURL: record.OriginalURL,
+		CreatedAt:   record.CreatedAt,
+		ExpiredAt:   record.ExpiredAt,
+		Title:       record.Title,
+		Tags:        record.Tags,
+		Notes:       record.Notes,
+		OwnerID:     record.OwnerID,
+		WorkspaceID: record.WorkspaceID,
+
+		Interstitial: record.Interstitial,
+		MaxClicks:    record.MaxClicks,
+		Clicks:       record.Clicks,
+		PasswordHash: record.PasswordHash,
+		Schedule:     record.Schedule,
+		FallbackURL:  record.FallbackURL,
+	}
+	if record.DisabledAt != nil {
+		shortenedURL.DisabledAt = *record.DisabledAt
+	}
+	if record.ActivateAt != nil {
+		shortenedURL.ActivateAt = *record.ActivateAt
+	}
+	return shortenedURL
+}
+
+// encoder write records in format
+type encoder struct {
+	format Format
+	bw     *bufio.Writer
+	cw     *csv.Writer
+}
+
+func newEncoder(w io.Writer, format Format) *encoder {
+	bw := bufio.NewWriter(w)
+	return &encoder{
+		format: format,
+		bw:     bw,
+		cw:     csv.NewWriter(bw),
+	}
+}
+
+func (enc *encoder) header() error {
+	if enc.format == FormatCSV {
+		return enc.cw.Write(csvHeader)
+	}
+	return nil
+}
+
+func (enc *encoder) encode(record *Record) error {
+	switch enc.format {
+	case FormatJSONL:
+		data, err := json.Marshal(record)
+		if err != nil {
+			return errors.Wrapf(errors.ErrInternal, "failed to encode short = %v err = %v", record.Short, err)
+		}
+		_, _ = enc.bw.Write(data)
+		return enc.bw.WriteByte('\n')
+	case FormatCSV:
+		// tags and schedule are json array and notes is json string in csv,
+		// so every record is still one line when notes contain line breaks
+		tags, notes, disabledAt, activateAt, schedule := "", "", "", "", ""
+		if len(record.Tags) != 0 {
+			data, _ := json.Marshal(record.Tags)
+			tags = string(data)
+		}
+		if record.Notes != "" {
+			data, _ := json.Marshal(record.Notes)
+			notes = string(data)
+		}
+		if record.DisabledAt != nil {
+			disabledAt = record.DisabledAt.Format(time.RFC3339Nano)
+		}
+		if record.ActivateAt != nil {
+			activateAt = record.ActivateAt.Format(time.RFC3339Nano)
+		}
+		if len(record.Schedule) != 0 {
+			data, _ := json.Marshal(record.Schedule)
+			schedule = string(data)
+		}
+
+		return enc.cw.Write([]string{
+			record.Short,
+			record.OriginalURL,
+			record.CreatedAt.Format(time.RFC3339Nano),
+			record.ExpiredAt.Format(time.RFC3339Nano),
+			record.Title,
+			tags,
+			notes,
+			record.OwnerID,
+			record.WorkspaceID,
+			string(record.Interstitial),
+			disabledAt,
+			strconv.FormatInt(record.MaxClicks, 10),
+			strconv.FormatInt(record.Clicks, 10),
+			record.PasswordHash,
+			activateAt,
+			schedule,
+			record.FallbackURL,
+		})
+	default:
+		return errors.Wrapf(errors.ErrInvalidInput, "unknown backup format %v", enc.format)
+	}
+}
+
+func (enc *encoder) flush() error {
+	enc.cw.Flush()
+	if err := enc.cw.Error(); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to write csv err = %v", err)
+	}
+
+	if err := enc.bw.Flush(); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to write backup err = %v", err)
+	}
+	return nil
+}
+
+// decoder read records line by line, so offset always point to a record boundary
+type decoder struct {
+	format Format
+	r      *bufio.Reader
+	offset int64
+	fields int // fields is csv columns of the file header
+}
+
+func newDecoder(r *bufio.Reader, format Format, offset int64) *decoder {
+	return &decoder{
+		format: format,
+		r:      r,
+		offset: offset,
+		fields: len(csvHeader),
+	}
+}
+
+func (dec *decoder) readLine() (string, error) {
+	line, err := dec.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		if err == io.EOF {
+			return "", err
+		}
+		return "", errors.Wrapf(errors.ErrInternal, "failed to read backup err = %v", err)
+	}
+
+	dec.offset += int64(len(line))
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (dec *decoder) header() error {
+	if dec.format != FormatCSV {
+		return nil
+	}
+
+	line, err := dec.readLine()
+	if err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "csv header is missing")
+	}
+
+	fields, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "csv header = %v is not expected", line)
+	}
+
+	if len(fields) < len(legacyCSVHeader) || len(fields) > len(csvHeader) ||
+		strings.Join(fields, ",") != strings.Join(csvHeader[:len(fields)], ",") {
+		return errors.Wrapf(errors.ErrInvalidInput, "csv header = %v is not expected", line)
+	}
+
+	dec.fields = len(fields)
+	return nil
+}
+
+func (dec *decoder) decode() (*Record, error) {
+	line, err := dec.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{}
+	switch dec.format {
+	case FormatJSONL:
+		if err := json.Unmarshal([]byte(line), record); err != nil {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "failed to decode line at offset %v err = %v", dec.offset, err)
+		}
+	case FormatCSV:
+		fields, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil || len(fields) != dec.fields {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "failed to decode line at offset %v err = %v", dec.offset, err)
+		}
+
+		record.Short = fields[0]
+		record.OriginalURL = fields[1]
+		if record.CreatedAt, err = time.Parse(time.RFC3339Nano, fields[2]); err != nil {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "created_at of short = %v is invalid", record.Short)
+		}
+		if record.ExpiredAt, err = time.Parse(time.RFC3339Nano, fields[3]); err != nil {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "expired_at of short = %v is invalid", record.Short)
+		}
+		if len(fields) > 4 {
+			record.Title = fields[4]
+		}
+		if len(fields) > 5 && fields[5] != "" {
+			if err := json.Unmarshal([]byte(fields[5]), &record.Tags); err != nil {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "tags of short = %v is invalid", record.Short)
+			}
+		}
+		if len(fields) > 6 && fields[6] != "" {
+			if err := json.Unmarshal([]byte(fields[6]), &record.Notes); err != nil {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "notes of short = %v is invalid", record.Short)
+			}
+		}
+		if len(fields) > 7 {
+			record.OwnerID = fields[7]
+		}
+		if len(fields) > 8 {
+			record.WorkspaceID = fields[8]
+		}
+		if len(fields) > 9 {
+			record.Interstitial = entity.Interstitial(fields[9])
+		}
+		if len(fields) > 10 && fields[10] != "" {
+			disabledAt, err := time.Parse(time.RFC3339Nano, fields[10])
+			if err != nil {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "disabled_at of short = %v is invalid", record.Short)
+			}
+			record.DisabledAt = &disabledAt
+		}
+		if len(fields) > 11 {
+			if record.MaxClicks, err = strconv.ParseInt(fields[11], 10, 64); err != nil || record.MaxClicks < 0 {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "max_clicks of short = %v is invalid", record.Short)
+			}
+		}
+		if len(fields) > 12 {
+			if record.Clicks, err = strconv.ParseInt(fields[12], 10, 64); err != nil || record.Clicks < 0 {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "clicks of short = %v is invalid", record.Short)
+			}
+		}
+		if len(fields) > 13 {
+			record.PasswordHash = fields[13]
+		}
+		if len(fields) > 14 && fields[14] != "" {
+			activateAt, err := time.Parse(time.RFC3339Nano, fields[14])
+			if err != nil {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "activate_at of short = %v is invalid", record.Short)
+			}
+			record.ActivateAt = &activateAt
+		}
+		if len(fields) > 15 && fields[15] != "" {
+			if err := json.Unmarshal([]byte(fields[15]), &record.Schedule); err != nil {
+				return nil, errors.Wrapf(errors.ErrInvalidInput, "schedule of short = %v is invalid", record.Short)
+			}
+		}
+		if len(fields) > 16 {
+			record.FallbackURL = fields[16]
+		}
+	default:
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "unknown backup format %v", dec.format)
+	}
+
+	if record.Short == "" || record.OriginalURL == "" {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "record at offset %v is incomplete", dec.offset)
+	}
+
+	return record, nil
+}
+
+// countWriter count bytes written
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func loadCursor(path string, resume bool) (Cursor, error) {
+	var cursor Cursor
+	if !resume {
+		return cursor, removeCursor(path)
+	}
+
+	data, err := os.ReadFile(path + cursorSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cursor, nil
+		}
+		return cursor, errors.Wrapf(errors.ErrInternal, "failed to read cursor err = %v", err)
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, errors.Wrapf(errors.ErrInternal, "failed to decode cursor err = %v", err)
+	}
+	return cursor, nil
+}
+
+func saveCursor(path string, cursor Cursor) error {
+	data, _ := json.Marshal(cursor)
+
+	// write then rename, a crash never leave a half written cursor
+	tmp := path + cursorSuffix + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to write cursor err = %v", err)
+	}
+	if err := os.Rename(tmp, path+cursorSuffix); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to write cursor err = %v", err)
+	}
+	return nil
+}
+
+func removeCursor(path string) error {
+	err := os.Remove(path + cursorSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(errors.ErrInternal, "failed to remove cursor err = %v", err)
+	}
+	return nil
+}
+
+func writeChecksum(path string, checksum string) error {
+	content := checksum + "  " + filepath.Base(path) + "\n"
+	if err := os.WriteFile(path+checksumSuffix, []byte(content), 0600); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to write checksum err = %v", err)
+	}
+	return nil
+}
+
+// verifyChecksum compare file sha256 with checksum file
+// missing checksum file is allowed when required is false
+func verifyChecksum(path string, required bool) (string, error) {
+	data, err := os.ReadFile(path + checksumSuffix)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return "", nil
+		}
+		return "", errors.Wrapf(errors.ErrInvalidInput, "failed to read checksum of %v err = %v", path, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", errors.Wrapf(errors.ErrInvalidInput, "checksum of %v is empty", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(errors.ErrInternal, "failed to open %v err = %v", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(errors.ErrInternal, "failed to hash %v err = %v", path, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != fields[0] {
+		return "", errors.Wrapf(errors.ErrInvalidInput, "checksum mismatch of %v, expected %v actual %v", path, fields[0], actual)
+	}
+
+	return actual, nil
+}
@@ -0,0 +1,176 @@
+package backup_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	. "url-shortener/pkg/app/urlshortener/backup"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/logging"
+)
+
+func TestExportImport(t *testing.T) {
+	shortenedURLs := []*entity.ShortenedURL{
+		{
+			Short:       "6Xme5Xwp",
+			OriginalURL: "https://www.dcard.tw/f",
+			CreatedAt:   time.Date(2023, time.June, 30, 11, 00, 00, 000, time.UTC),
+			ExpiredAt:   time.Date(2023, time.July, 3, 11, 00, 00, 000, time.UTC),
+		},
+		{
+			Short:       "K2MY8LEp",
+			OriginalURL: "https://www.dcard.tw/f?a=1,2&b=\"3\"",
+			CreatedAt:   time.Date(2023, time.June, 30, 12, 00, 00, 000, time.UTC),
+			ExpiredAt:   time.Date(2023, time.July, 3, 12, 00, 00, 000, time.UTC),
+			Title:       "Dcard, forum",
+			Tags:        []string{"campaign 2023", "forum"},
+			Notes:       "line 1\nline 2",
+			OwnerID:     "team-a",
+			MaxClicks:   1,
+			Clicks:      1,
+
+			PasswordHash: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			ActivateAt:   time.Date(2023, time.July, 1, 9, 00, 00, 000, time.UTC),
+			Schedule: []entity.Destination{
+				{At: time.Date(2023, time.July, 2, 9, 00, 00, 000, time.UTC), URL: "https://www.dcard.tw/f/sale"},
+			},
+			FallbackURL: "https://www.dcard.tw/f/expired",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+	}{
+		{
+			name:   "JSONL",
+			format: FormatJSONL,
+		},
+		{
+			name:   "CSV",
+			format: FormatCSV,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logging.SetupWithOption(
+				logging.WithDebug(true),
+				logging.WithLevel(logging.TraceLevel),
+			)
+			ctx := context.Background()
+			ctx = logger.WithContext(ctx)
+
+			path := filepath.Join(t.TempDir(), "links."+string(tt.format))
+
+			src := mocks.NewRepository(t)
+			src.EXPECT().ListShortenedURLs(mock.Anything, "", 1).Return(shortenedURLs[:1], nil)
+			src.EXPECT().ListShortenedURLs(mock.Anything, "6Xme5Xwp", 1).Return(shortenedURLs[1:], nil)
+			src.EXPECT().ListShortenedURLs(mock.Anything, "K2MY8LEp", 1).Return(nil, nil)
+
+			exported, err := ExportFile(ctx, src, ExportOptions{
+				Path:      path,
+				Format:    tt.format,
+				BatchSize: 1,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 2, exported.Exported)
+			assert.FileExists(t, path+".sha256")
+			assert.NoFileExists(t, path+".cursor")
+
+			imported := make([]*entity.ShortenedURL, 0)
+			dst := mocks.NewRepository(t)
+			dst.EXPECT().
+				ImportShortenedURL(mock.Anything, mock.Anything, repository.ConflictSkip).
+				RunAndReturn(func(ctx context.Context, shortenedURL *entity.ShortenedURL, mode repository.ConflictMode) (bool, error) {
+					imported = append(imported, shortenedURL)
+					return true, nil
+				})
+
+			result, err := ImportFile(ctx, dst, ImportOptions{
+				Path:   path,
+				Format: tt.format,
+				Mode:   repository.ConflictSkip,
+				Verify: true,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 2, result.Stored)
+			assert.Equal(t, exported.Checksum, result.Checksum)
+			assert.Equal(t, shortenedURLs, imported)
+		})
+	}
+}
+
+func TestImportFile_LegacyCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.csv")
+	content := "short,original_url,created_at,expired_at\n" +
+		"6Xme5Xwp,https://www.dcard.tw/f,2023-06-30T11:00:00Z,2023-07-03T11:00:00Z\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().
+		ImportShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+			return shortenedURL.Short == "6Xme5Xwp" && shortenedURL.Title == "" && shortenedURL.Tags == nil
+		}), repository.ConflictSkip).
+		Return(true, nil)
+
+	result, err := ImportFile(context.Background(), repo, ImportOptions{
+		Path:   path,
+		Format: FormatCSV,
+		Mode:   repository.ConflictSkip,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Stored)
+}
+
+func TestImportFile_ChecksumMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+	content := `{"short":"6Xme5Xwp","originalUrl":"https://www.dcard.tw/f","createdAt":"2023-06-30T11:00:00Z","expiredAt":"2023-07-03T11:00:00Z"}` + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	assert.NoError(t, os.WriteFile(path+".sha256", []byte("0000  links.jsonl\n"), 0600))
+
+	_, err := ImportFile(context.Background(), mocks.NewRepository(t), ImportOptions{
+		Path:   path,
+		Format: FormatJSONL,
+		Mode:   repository.ConflictFail,
+	})
+	assert.True(t, errors.Is(err, errors.ErrInvalidInput), "ImportFile() error = %v", err)
+}
+
+func TestImportFile_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+	first := `{"short":"6Xme5Xwp","originalUrl":"https://www.dcard.tw/f","createdAt":"2023-06-30T11:00:00Z","expiredAt":"2023-07-03T11:00:00Z"}` + "\n"
+	second := `{"short":"K2MY8LEp","originalUrl":"https://www.dcard.tw/f","createdAt":"2023-06-30T11:00:00Z","expiredAt":"2023-07-03T11:00:00Z"}` + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(first+second), 0600))
+
+	// the first record is imported by an interrupted run
+	cursor := `{"short":"6Xme5Xwp","offset":` + strconv.Itoa(len(first)) + `}`
+	assert.NoError(t, os.WriteFile(path+".cursor", []byte(cursor), 0600))
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().
+		ImportShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+			return shortenedURL.Short == "K2MY8LEp"
+		}), repository.ConflictOverwrite).
+		Return(true, nil).
+		Once()
+
+	result, err := ImportFile(context.Background(), repo, ImportOptions{
+		Path:   path,
+		Format: FormatJSONL,
+		Mode:   repository.ConflictOverwrite,
+		Resume: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Stored)
+	assert.NoFileExists(t, path+".cursor")
+}
@@ -2,20 +2,43 @@ package endpoints
 
 import (
 	"context"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/spf13/viper"
 
 	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/repository"
 	"url-shortener/pkg/app/urlshortener/service"
 	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+	"url-shortener/pkg/quota"
 )
 
 // Endpoints contain all url shortener endpoint
 type Endpoints struct {
-	ShortURLEndpoint    endpoint.Endpoint
-	RedirectURLEndpoint endpoint.Endpoint
+	ShortURLEndpoint           endpoint.Endpoint
+	BatchShortURLEndpoint      endpoint.Endpoint
+	RedirectURLEndpoint        endpoint.Endpoint
+	UnlockURLEndpoint          endpoint.Endpoint
+	GetShortenedURLEndpoint    endpoint.Endpoint
+	ListShortenedURLsEndpoint  endpoint.Endpoint
+	ResolveURLsEndpoint        endpoint.Endpoint
+	UpdateShortenedURLEndpoint endpoint.Endpoint
+	DeleteShortenedURLEndpoint endpoint.Endpoint
+	QuotaUsageEndpoint         endpoint.Endpoint
+	ListBlocksEndpoint         endpoint.Endpoint
+	BlockClientEndpoint        endpoint.Endpoint
+	UnblockClientEndpoint      endpoint.Endpoint
+	ListReviewsEndpoint        endpoint.Endpoint
+	ResolveReviewEndpoint      endpoint.Endpoint
+	ReportURLEndpoint          endpoint.Endpoint
+	ListReportsEndpoint        endpoint.Endpoint
+	GetReportEndpoint          endpoint.Endpoint
+	TriageReportEndpoint       endpoint.Endpoint
+	ResolveReportEndpoint      endpoint.Endpoint
 }
 
 // New endpoints
@@ -26,19 +49,182 @@ func New(svc service.ShortenedURLService) (ep Endpoints) {
 	)(shortURLEndpoint)
 	ep.ShortURLEndpoint = shortURLEndpoint
 
+	batchShortURLEndpoint := MakeBatchShortURLEndpoint(svc)
+	batchShortURLEndpoint = endpoint.Chain(
+		LoggingMiddleware("batchShortURL"),
+	)(batchShortURLEndpoint)
+	ep.BatchShortURLEndpoint = batchShortURLEndpoint
+
 	redirectURLEndpoint := MakeRedirectURLEndpoint(svc)
 	redirectURLEndpoint = endpoint.Chain(
 		LoggingMiddleware("redirectURL"),
 	)(redirectURLEndpoint)
 	ep.RedirectURLEndpoint = redirectURLEndpoint
 
+	unlockURLEndpoint := MakeUnlockURLEndpoint(svc)
+	unlockURLEndpoint = endpoint.Chain(
+		LoggingMiddleware("unlockURL"),
+	)(unlockURLEndpoint)
+	ep.UnlockURLEndpoint = unlockURLEndpoint
+
+	getShortenedURLEndpoint := MakeGetShortenedURLEndpoint(svc)
+	getShortenedURLEndpoint = endpoint.Chain(
+		LoggingMiddleware("getShortenedURL"),
+	)(getShortenedURLEndpoint)
+	ep.GetShortenedURLEndpoint = getShortenedURLEndpoint
+
+	listShortenedURLsEndpoint := MakeListShortenedURLsEndpoint(svc)
+	listShortenedURLsEndpoint = endpoint.Chain(
+		LoggingMiddleware("listShortenedURLs"),
+	)(listShortenedURLsEndpoint)
+	ep.ListShortenedURLsEndpoint = listShortenedURLsEndpoint
+
+	resolveURLsEndpoint := MakeResolveURLsEndpoint(svc)
+	resolveURLsEndpoint = endpoint.Chain(
+		LoggingMiddleware("resolveURLs"),
+	)(resolveURLsEndpoint)
+	ep.ResolveURLsEndpoint = resolveURLsEndpoint
+
+	updateShortenedURLEndpoint := MakeUpdateShortenedURLEndpoint(svc)
+	updateShortenedURLEndpoint = endpoint.Chain(
+		LoggingMiddleware("updateShortenedURL"),
+	)(updateShortenedURLEndpoint)
+	ep.UpdateShortenedURLEndpoint = updateShortenedURLEndpoint
+
+	deleteShortenedURLEndpoint := MakeDeleteShortenedURLEndpoint(svc)
+	deleteShortenedURLEndpoint = endpoint.Chain(
+		LoggingMiddleware("deleteShortenedURL"),
+	)(deleteShortenedURLEndpoint)
+	ep.DeleteShortenedURLEndpoint = deleteShortenedURLEndpoint
+
+	quotaUsageEndpoint := MakeQuotaUsageEndpoint(svc)
+	quotaUsageEndpoint = endpoint.Chain(
+		LoggingMiddleware("quotaUsage"),
+	)(quotaUsageEndpoint)
+	ep.QuotaUsageEndpoint = quotaUsageEndpoint
+
+	listBlocksEndpoint := MakeListBlocksEndpoint(svc)
+	listBlocksEndpoint = endpoint.Chain(
+		LoggingMiddleware("listBlocks"),
+	)(listBlocksEndpoint)
+	ep.ListBlocksEndpoint = listBlocksEndpoint
+
+	blockClientEndpoint := MakeBlockClientEndpoint(svc)
+	blockClientEndpoint = endpoint.Chain(
+		LoggingMiddleware("blockClient"),
+	)(blockClientEndpoint)
+	ep.BlockClientEndpoint = blockClientEndpoint
+
+	unblockClientEndpoint := MakeUnblockClientEndpoint(svc)
+	unblockClientEndpoint = endpoint.Chain(
+		LoggingMiddleware("unblockClient"),
+	)(unblockClientEndpoint)
+	ep.UnblockClientEndpoint = unblockClientEndpoint
+
+	listReviewsEndpoint := MakeListReviewsEndpoint(svc)
+	listReviewsEndpoint = endpoint.Chain(
+		LoggingMiddleware("listReviews"),
+	)(listReviewsEndpoint)
+	ep.ListReviewsEndpoint = listReviewsEndpoint
+
+	resolveReviewEndpoint := MakeResolveReviewEndpoint(svc)
+	resolveReviewEndpoint = endpoint.Chain(
+		LoggingMiddleware("resolveReview"),
+	)(resolveReviewEndpoint)
+	ep.ResolveReviewEndpoint = resolveReviewEndpoint
+
+	reportURLEndpoint := MakeReportURLEndpoint(svc)
+	reportURLEndpoint = endpoint.Chain(
+		LoggingMiddleware("reportURL"),
+	)(reportURLEndpoint)
+	ep.ReportURLEndpoint = reportURLEndpoint
+
+	listReportsEndpoint := MakeListReportsEndpoint(svc)
+	listReportsEndpoint = endpoint.Chain(
+		LoggingMiddleware("listReports"),
+	)(listReportsEndpoint)
+	ep.ListReportsEndpoint = listReportsEndpoint
+
+	getReportEndpoint := MakeGetReportEndpoint(svc)
+	getReportEndpoint = endpoint.Chain(
+		LoggingMiddleware("getReport"),
+	)(getReportEndpoint)
+	ep.GetReportEndpoint = getReportEndpoint
+
+	triageReportEndpoint := MakeTriageReportEndpoint(svc)
+	triageReportEndpoint = endpoint.Chain(
+		LoggingMiddleware("triageReport"),
+	)(triageReportEndpoint)
+	ep.TriageReportEndpoint = triageReportEndpoint
+
+	resolveReportEndpoint := MakeResolveReportEndpoint(svc)
+	resolveReportEndpoint = endpoint.Chain(
+		LoggingMiddleware("resolveReport"),
+	)(resolveReportEndpoint)
+	ep.ResolveReportEndpoint = resolveReportEndpoint
+
 	return ep
 }
 
 // ShortURLRequest is define short url request
 type ShortURLRequest struct {
-	URL       string  `json:"url" validate:"http_url,required"`
-	ExpiredAt *string `json:"expireAt"`
+	URL       string   `json:"url" validate:"http_url,required"`
+	ExpiredAt *string  `json:"expireAt"`
+	Title     string   `json:"title,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+
+	// Interstitial show a warning page with the destination instead of redirect at once
+	Interstitial bool `json:"interstitial,omitempty"`
+
+	// Dedupe return the unexpired short url of the same owner and destination instead of creating a new one
+	Dedupe bool `json:"dedupe,omitempty"`
+
+	// MaxClicks is how many redirects the short url serves, e.g. 1 for single use link, zero means unlimited
+	MaxClicks int64 `json:"maxClicks,omitempty"`
+
+	// Password protect the redirect by a password form, lookup never return the original url of protected url
+	Password string `json:"password,omitempty"`
+
+	// ActivateAt the short url start to redirect at, default at once
+	ActivateAt *time.Time `json:"activateAt,omitempty"`
+
+	// Schedule switch the destination at the time of every item
+	Schedule []*Destination `json:"schedule,omitempty" validate:"max=20"`
+
+	// FallbackURL the short url redirect to after it is expired, default the fallback of workspace or server
+	FallbackURL string `json:"fallbackUrl,omitempty" validate:"omitempty,http_url"`
+}
+
+// Destination is a scheduled destination of short url, it redirect to url from at
+type Destination struct {
+	At  time.Time `json:"at" validate:"required"`
+	URL string    `json:"url" validate:"http_url,required"`
+}
+
+// option make service option from request, expire time is parsed before
+func (req *ShortURLRequest) option(expireAt *time.Time) *service.ShortURLOption {
+	var schedule []entity.Destination
+	for _, d := range req.Schedule {
+		if d != nil {
+			schedule = append(schedule, entity.Destination{At: d.At, URL: d.URL})
+		}
+	}
+
+	return &service.ShortURLOption{
+		ExpiredAt: expireAt,
+		Title:     req.Title,
+		Tags:      req.Tags,
+		Notes:     req.Notes,
+
+		Interstitial: req.Interstitial,
+		Dedupe:       req.Dedupe,
+		MaxClicks:    req.MaxClicks,
+		Password:     req.Password,
+		ActivateAt:   req.ActivateAt,
+		Schedule:     schedule,
+		FallbackURL:  req.FallbackURL,
+	}
 }
 
 // ShortURLResponse is define short url response
@@ -50,35 +236,126 @@ type ShortURLResponse struct {
 // MakeShortURLEndpoint make short url endpoint
 func MakeShortURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		var (
-			expireAt *time.Time
-		)
 		req := request.(*ShortURLRequest)
 
-		if req.ExpiredAt != nil {
-			t, err := time.Parse(`2006-01-02T15:04:05Z`, *req.ExpiredAt)
-			if err != nil {
-				return nil, errors.Wrap(errors.ErrInvalidInput, "input time format is not correct")
+		expireAt, err := parseExpireAt(req.ExpiredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		shortenedURL, err := svc.ShortURL(ctx, req.URL, req.option(expireAt))
+		if err != nil {
+			return nil, err
+		}
+
+		return newShortURLResponse(ctx, shortenedURL), nil
+	}
+}
+
+// newShortURLResponse make short url response from entity
+func newShortURLResponse(ctx context.Context, shortenedURL *entity.ShortenedURL) *ShortURLResponse {
+	return &ShortURLResponse{
+		ID:       shortenedURL.Short,
+		ShortURL: baseURL(ctx) + "/" + shortenedURL.Short,
+	}
+}
+
+// baseURL return short url prefix of the workspace of context,
+// the default workspace and workspace without verified domain use serverHost
+func baseURL(ctx context.Context) string {
+	if workspace, ok := service.WorkspaceFromContext(ctx); ok {
+		if base := workspace.BaseURL(); base != "" {
+			return base
+		}
+	}
+	return viper.GetString("serverHost")
+}
+
+// parseExpireAt parse expire time of short url request, nil means default expire time
+func parseExpireAt(expiredAt *string) (*time.Time, error) {
+	if expiredAt == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(`2006-01-02T15:04:05Z`, *expiredAt)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "input time format is not correct")
+	}
+
+	if t.Before(time.Now()) {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "input time before now")
+	}
+
+	return &t, nil
+}
+
+// MaxBatchShortURLs is the max number of urls in one batch short url request
+const MaxBatchShortURLs = 1000
+
+// BatchShortURLRequest is define batch short url request
+type BatchShortURLRequest struct {
+	URLs []*ShortURLRequest `json:"urls" validate:"required,min=1,max=1000"`
+}
+
+// BatchShortURLResult is the result of one url, either url or error is set
+type BatchShortURLResult struct {
+	URL   *ShortURLResponse `json:"url,omitempty"`
+	Error *errors.View      `json:"error,omitempty"`
+}
+
+// BatchShortURLResponse is define batch short url response, results keep the order of request urls
+type BatchShortURLResponse struct {
+	Results []*BatchShortURLResult `json:"results"`
+}
+
+// MakeBatchShortURLEndpoint make batch short url endpoint
+// invalid url only fail its own result, other urls are still created
+func MakeBatchShortURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*BatchShortURLRequest)
+
+		if len(req.URLs) > MaxBatchShortURLs {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "short at most %v urls", MaxBatchShortURLs)
+		}
+
+		resp := &BatchShortURLResponse{
+			Results: make([]*BatchShortURLResult, len(req.URLs)),
+		}
+
+		items := make([]*service.ShortURLItem, 0, len(req.URLs))
+		indexes := make([]int, 0, len(req.URLs))
+		for i, r := range req.URLs {
+			if r == nil {
+				resp.Results[i] = &BatchShortURLResult{Error: errorView(errors.Wrap(errors.ErrInvalidInput, "url is null"))}
+				continue
 			}
 
-			if t.Before(time.Now()) {
-				return nil, errors.Wrap(errors.ErrInvalidInput, "input time before now")
+			expireAt, err := parseExpireAt(r.ExpiredAt)
+			if err != nil {
+				resp.Results[i] = &BatchShortURLResult{Error: errorView(err)}
+				continue
 			}
 
-			expireAt = &t
+			items = append(items, &service.ShortURLItem{
+				URL:  r.URL,
+				Opts: r.option(expireAt),
+			})
+			indexes = append(indexes, i)
 		}
 
-		shortenedURL, err := svc.ShortURL(ctx, req.URL, &service.ShortURLOption{
-			ExpiredAt: expireAt,
-		})
-		if err != nil {
-			return nil, err
+		if len(items) != 0 {
+			for j, r := range svc.ShortURLs(ctx, items) {
+				result := &BatchShortURLResult{}
+				if r.Err != nil {
+					result.Error = errorView(r.Err)
+				} else {
+					result.URL = newShortURLResponse(ctx, r.ShortenedURL)
+				}
+				resp.Results[indexes[j]] = result
+			}
 		}
 
-		return &ShortURLResponse{
-			ID:       shortenedURL.Short,
-			ShortURL: viper.GetString("serverHost") + "/" + shortenedURL.Short,
-		}, nil
+		return resp, nil
 	}
 }
 
@@ -107,3 +384,737 @@ func MakeRedirectURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint
 		}, nil
 	}
 }
+
+// UnlockURLRequest is unlock protected short url request, client is set by transport for lockout
+type UnlockURLRequest struct {
+	ShortURL string `param:"url"`
+	Password string `form:"password"`
+	Client   string `form:"-" json:"-"`
+}
+
+// UnlockURLResponse is unlock protected short url response
+type UnlockURLResponse struct{}
+
+// MakeUnlockURLEndpoint make unlock url endpoint
+func MakeUnlockURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*UnlockURLRequest)
+
+		if err := svc.UnlockShortenedURL(ctx, req.ShortURL, req.Password, req.Client); err != nil {
+			return nil, err
+		}
+
+		return &UnlockURLResponse{}, nil
+	}
+}
+
+// ShortenedURLResponse is define shortened url metadata
+type ShortenedURLResponse struct {
+	ID          string        `json:"id"`
+	ShortURL    string        `json:"shortUrl"`
+	OriginalURL string        `json:"originalUrl"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	ExpiredAt   time.Time     `json:"expireAt"`
+	Status      entity.Status `json:"status"`
+	Title       string        `json:"title"`
+	Tags        []string      `json:"tags"`
+	Notes       string        `json:"notes"`
+
+	// Interstitial is true when redirect show a warning page, flagged url show it until its review is approved
+	Interstitial bool `json:"interstitial"`
+
+	// MaxClicks and Clicks are only set for click limited url
+	MaxClicks int64 `json:"maxClicks,omitempty"`
+	Clicks    int64 `json:"clicks,omitempty"`
+
+	// PasswordProtected is true when redirect ask for password, original url of protected url is always empty
+	PasswordProtected bool `json:"passwordProtected"`
+
+	// ActivateAt and Schedule are only set for scheduled url, urls of protected url schedule are empty
+	ActivateAt *time.Time     `json:"activateAt,omitempty"`
+	Schedule   []*Destination `json:"schedule,omitempty"`
+
+	// FallbackURL is only set when the url has its own fallback, the fallback of workspace or server is not included
+	FallbackURL string `json:"fallbackUrl,omitempty"`
+}
+
+// NewShortenedURLResponse make shortened url metadata from entity, short url use the workspace of context
+func NewShortenedURLResponse(ctx context.Context, shortenedURL *entity.ShortenedURL, now time.Time) *ShortenedURLResponse {
+	tags := shortenedURL.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	resp := &ShortenedURLResponse{
+		ID:          shortenedURL.Short,
+		ShortURL:    baseURL(ctx) + "/" + shortenedURL.Short,
+		OriginalURL: shortenedURL.OriginalURL,
+		CreatedAt:   shortenedURL.CreatedAt.UTC(),
+		ExpiredAt:   shortenedURL.ExpiredAt.UTC(),
+		Status:      shortenedURL.StatusAt(now),
+		Title:       shortenedURL.Title,
+		Tags:        tags,
+		Notes:       shortenedURL.Notes,
+
+		Interstitial: shortenedURL.Interstitial != entity.InterstitialOff,
+		MaxClicks:    shortenedURL.MaxClicks,
+		Clicks:       shortenedURL.Clicks,
+
+		PasswordProtected: shortenedURL.Protected(),
+		FallbackURL:       shortenedURL.FallbackURL,
+	}
+	if !shortenedURL.ActivateAt.IsZero() {
+		activateAt := shortenedURL.ActivateAt.UTC()
+		resp.ActivateAt = &activateAt
+	}
+	for _, d := range shortenedURL.Schedule {
+		resp.Schedule = append(resp.Schedule, &Destination{At: d.At.UTC(), URL: d.URL})
+	}
+
+	return resp
+}
+
+// GetShortenedURLRequest is get shortened url metadata request
+type GetShortenedURLRequest struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// MakeGetShortenedURLEndpoint make get shortened url metadata endpoint
+func MakeGetShortenedURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*GetShortenedURLRequest)
+
+		shortenedURL, err := svc.LookupShortenedURL(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewShortenedURLResponse(ctx, shortenedURL, time.Now()), nil
+	}
+}
+
+// ListShortenedURLsRequest is list shortened urls request, time is RFC3339 format
+type ListShortenedURLsRequest struct {
+	Tag         string `query:"tag"`
+	CreatedFrom string `query:"createdFrom"`
+	CreatedTo   string `query:"createdTo"`
+	State       string `query:"state" validate:"omitempty,oneof=active expired"`
+	Host        string `query:"host"`
+	Query       string `query:"q"`
+	Mode        string `query:"mode" validate:"omitempty,oneof=prefix fulltext"`
+	Cursor      string `query:"cursor"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ListShortenedURLsResponse is list shortened urls response, empty next cursor means the last page
+type ListShortenedURLsResponse struct {
+	URLs       []*ShortenedURLResponse `json:"urls"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+// MakeListShortenedURLsEndpoint make list shortened urls endpoint
+func MakeListShortenedURLsEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ListShortenedURLsRequest)
+
+		filter := &repository.SearchFilter{
+			Tag:    req.Tag,
+			State:  repository.ExpiryState(req.State),
+			Host:   req.Host,
+			Query:  req.Query,
+			Mode:   repository.SearchMode(req.Mode),
+			Cursor: req.Cursor,
+			Limit:  req.Limit,
+		}
+
+		if filter.CreatedFrom, err = parseTime("createdFrom", req.CreatedFrom); err != nil {
+			return nil, err
+		}
+		if filter.CreatedTo, err = parseTime("createdTo", req.CreatedTo); err != nil {
+			return nil, err
+		}
+
+		shortenedURLs, next, err := svc.SearchShortenedURLs(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		resp := &ListShortenedURLsResponse{
+			URLs:       make([]*ShortenedURLResponse, 0, len(shortenedURLs)),
+			NextCursor: next,
+		}
+		for _, shortenedURL := range shortenedURLs {
+			resp.URLs = append(resp.URLs, NewShortenedURLResponse(ctx, shortenedURL, now))
+		}
+
+		return resp, nil
+	}
+}
+
+// parseTime parse optional RFC3339 time of request field
+func parseTime(field string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "%v = %v is not RFC3339 time", field, value)
+	}
+
+	return &t, nil
+}
+
+// MaxResolveURLs is the max number of ids in one resolve request
+const MaxResolveURLs = 100
+
+// ResolveURLsRequest is resolve many short urls request
+type ResolveURLsRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// ResolveURLResult is the result of one id, either url or error is set
+type ResolveURLResult struct {
+	ID    string                `json:"id"`
+	URL   *ShortenedURLResponse `json:"url,omitempty"`
+	Error *errors.View          `json:"error,omitempty"`
+}
+
+// ResolveURLsResponse is resolve many short urls response, results keep the order of request ids
+type ResolveURLsResponse struct {
+	Results []*ResolveURLResult `json:"results"`
+}
+
+// MakeResolveURLsEndpoint make resolve many short urls endpoint
+func MakeResolveURLsEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ResolveURLsRequest)
+
+		if len(req.IDs) > MaxResolveURLs {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "resolve at most %v ids", MaxResolveURLs)
+		}
+
+		now := time.Now()
+		results := svc.LookupShortenedURLs(ctx, req.IDs)
+
+		resp := &ResolveURLsResponse{
+			Results: make([]*ResolveURLResult, 0, len(results)),
+		}
+		for _, r := range results {
+			result := &ResolveURLResult{ID: r.Short}
+			if r.Err != nil {
+				result.Error = errorView(r.Err)
+			} else {
+				result.URL = NewShortenedURLResponse(ctx, r.ShortenedURL, now)
+			}
+			resp.Results = append(resp.Results, result)
+		}
+
+		return resp, nil
+	}
+}
+
+// UpdateShortenedURLRequest is update shortened url request, field not set keep the current value
+type UpdateShortenedURLRequest struct {
+	ID        string    `param:"id" validate:"required"`
+	ExpiredAt *string   `json:"expireAt,omitempty"`
+	Title     *string   `json:"title,omitempty"`
+	Tags      *[]string `json:"tags,omitempty"`
+	Notes     *string   `json:"notes,omitempty"`
+
+	Interstitial *bool `json:"interstitial,omitempty"`
+
+	// FallbackURL set the url redirect to after it is expired, empty string remove it
+	FallbackURL *string `json:"fallbackUrl,omitempty"`
+}
+
+// MakeUpdateShortenedURLEndpoint make update shortened url endpoint
+func MakeUpdateShortenedURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*UpdateShortenedURLRequest)
+
+		expireAt, err := parseExpireAt(req.ExpiredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		shortenedURL, err := svc.UpdateShortenedURL(ctx, req.ID, &service.UpdateOption{
+			ExpiredAt: expireAt,
+			Title:     req.Title,
+			Tags:      req.Tags,
+			Notes:     req.Notes,
+
+			Interstitial: req.Interstitial,
+			FallbackURL:  req.FallbackURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return NewShortenedURLResponse(ctx, shortenedURL, time.Now()), nil
+	}
+}
+
+// DeleteShortenedURLRequest is delete shortened url request
+type DeleteShortenedURLRequest struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// DeleteShortenedURLResponse is delete shortened url response, it has no body
+type DeleteShortenedURLResponse struct{}
+
+// MakeDeleteShortenedURLEndpoint make delete shortened url endpoint
+func MakeDeleteShortenedURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*DeleteShortenedURLRequest)
+
+		if err := svc.DeleteShortenedURL(ctx, req.ID); err != nil {
+			return nil, err
+		}
+
+		return &DeleteShortenedURLResponse{}, nil
+	}
+}
+
+// QuotaCounter is consumption of one quota window, zero limit means unlimited
+type QuotaCounter struct {
+	Used    int64      `json:"used"`
+	Limit   int64      `json:"limit"`
+	ResetAt *time.Time `json:"resetAt,omitempty"`
+}
+
+// QuotaUsage is quota consumption of a subject, e.g. apikey:Xa8LmQ2pRt0z or workspace:team-a
+type QuotaUsage struct {
+	Subject string        `json:"subject"`
+	Day     *QuotaCounter `json:"day"`
+	Month   *QuotaCounter `json:"month"`
+	Active  *QuotaCounter `json:"active"`
+}
+
+// QuotaUsageResponse is quota usage response, empty when quota is disabled
+type QuotaUsageResponse struct {
+	Quotas []*QuotaUsage `json:"quotas"`
+}
+
+// MakeQuotaUsageEndpoint make quota usage endpoint
+func MakeQuotaUsageEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		usages, err := svc.QuotaUsage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &QuotaUsageResponse{
+			Quotas: make([]*QuotaUsage, 0, len(usages)),
+		}
+		for _, usage := range usages {
+			resp.Quotas = append(resp.Quotas, &QuotaUsage{
+				Subject: usage.Subject,
+				Day:     newQuotaCounter(usage.Day),
+				Month:   newQuotaCounter(usage.Month),
+				Active:  newQuotaCounter(usage.Active),
+			})
+		}
+
+		return resp, nil
+	}
+}
+
+func newQuotaCounter(c quota.Counter) *QuotaCounter {
+	counter := &QuotaCounter{
+		Used:  c.Used,
+		Limit: c.Limit,
+	}
+	if !c.ResetAt.IsZero() {
+		resetAt := c.ResetAt.UTC()
+		counter.ResetAt = &resetAt
+	}
+	return counter
+}
+
+// Block is a client blocked by the enumeration guard, client is an ip or IPv6 /64
+type Block struct {
+	Client    string    `json:"client"`
+	Reason    string    `json:"reason"`
+	ExpiredAt time.Time `json:"expireAt"`
+}
+
+func newBlock(b *guard.Block) *Block {
+	return &Block{
+		Client:    b.Client,
+		Reason:    b.Reason,
+		ExpiredAt: b.ExpiredAt.UTC(),
+	}
+}
+
+// ListBlocksResponse is list blocked clients response
+type ListBlocksResponse struct {
+	Blocks []*Block `json:"blocks"`
+}
+
+// MakeListBlocksEndpoint make list blocked clients endpoint
+func MakeListBlocksEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		blocks, err := svc.ListBlockedClients(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &ListBlocksResponse{
+			Blocks: make([]*Block, 0, len(blocks)),
+		}
+		for _, b := range blocks {
+			resp.Blocks = append(resp.Blocks, newBlock(b))
+		}
+
+		return resp, nil
+	}
+}
+
+// BlockClientRequest is block client request, duration is Go duration, e.g. 30m or 24h
+type BlockClientRequest struct {
+	Client   string `json:"client" validate:"required"`
+	Duration string `json:"duration" validate:"required"`
+}
+
+// MakeBlockClientEndpoint make block client endpoint
+func MakeBlockClientEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*BlockClientRequest)
+
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "failed to parse duration = %v", req.Duration)
+		}
+
+		block, err := svc.BlockClient(ctx, req.Client, duration)
+		if err != nil {
+			return nil, err
+		}
+
+		return newBlock(block), nil
+	}
+}
+
+// UnblockClientRequest is unblock client request, IPv6 /64 is path escaped, e.g. 2001:db8::%2F64
+type UnblockClientRequest struct {
+	Client string `param:"client" validate:"required"`
+}
+
+// UnblockClientResponse is unblock client response, it has no body
+type UnblockClientResponse struct{}
+
+// MakeUnblockClientEndpoint make unblock client endpoint
+func MakeUnblockClientEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*UnblockClientRequest)
+
+		if err := svc.UnblockClient(ctx, req.Client); err != nil {
+			return nil, err
+		}
+
+		return &UnblockClientResponse{}, nil
+	}
+}
+
+// Signal is an abuse heuristic matched by a flagged url
+type Signal struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// Review is a flagged url in the review queue
+type Review struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspaceId,omitempty"`
+	OriginalURL string     `json:"originalUrl,omitempty"`
+	OwnerID     string     `json:"ownerId,omitempty"`
+	Score       int        `json:"score"`
+	Signals     []*Signal  `json:"signals"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ReviewedAt  *time.Time `json:"reviewedAt,omitempty"`
+	ReviewerID  string     `json:"reviewerId,omitempty"`
+}
+
+func newReview(r *entity.Review) *Review {
+	review := &Review{
+		ID:          r.Short,
+		WorkspaceID: r.WorkspaceID,
+		OriginalURL: r.OriginalURL,
+		OwnerID:     r.OwnerID,
+		Score:       r.Score,
+		Signals:     make([]*Signal, 0, len(r.Signals)),
+		Status:      string(r.Status),
+		CreatedAt:   r.CreatedAt.UTC(),
+		ReviewerID:  r.ReviewerID,
+	}
+	for _, signal := range r.Signals {
+		review.Signals = append(review.Signals, &Signal{
+			Rule:   signal.Rule,
+			Score:  signal.Score,
+			Reason: signal.Reason,
+		})
+	}
+	if r.ReviewedAt != nil {
+		reviewedAt := r.ReviewedAt.UTC()
+		review.ReviewedAt = &reviewedAt
+	}
+	return review
+}
+
+// ListReviewsRequest is list reviews request, pending reviews are listed when status is empty
+type ListReviewsRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ListReviewsResponse is list reviews response, oldest first
+type ListReviewsResponse struct {
+	Reviews []*Review `json:"reviews"`
+}
+
+// MakeListReviewsEndpoint make list reviews endpoint
+func MakeListReviewsEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ListReviewsRequest)
+
+		reviews, err := svc.ListReviews(ctx, entity.ReviewStatus(req.Status), req.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &ListReviewsResponse{
+			Reviews: make([]*Review, 0, len(reviews)),
+		}
+		for _, r := range reviews {
+			resp.Reviews = append(resp.Reviews, newReview(r))
+		}
+
+		return resp, nil
+	}
+}
+
+// ResolveReviewRequest is resolve review request, rejected url is deleted
+type ResolveReviewRequest struct {
+	ID     string `param:"id" validate:"required"`
+	Status string `json:"status" validate:"required,oneof=approved rejected"`
+}
+
+// MakeResolveReviewEndpoint make resolve review endpoint
+func MakeResolveReviewEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ResolveReviewRequest)
+
+		review, err := svc.ResolveReview(ctx, req.ID, entity.ReviewStatus(req.Status))
+		if err != nil {
+			return nil, err
+		}
+
+		return newReview(review), nil
+	}
+}
+
+// ReportURLRequest is abuse report request, code is the short id or the whole short url
+type ReportURLRequest struct {
+	Code    string `json:"code" validate:"required"`
+	Reason  string `json:"reason" validate:"required,max=1000"`
+	Contact string `json:"contact" validate:"omitempty,max=200"`
+}
+
+// ReportURLResponse is abuse report response
+type ReportURLResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// MakeReportURLEndpoint make abuse report endpoint
+func MakeReportURLEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ReportURLRequest)
+
+		report, err := svc.ReportShortenedURL(ctx, reportedShort(req.Code), req.Reason, req.Contact)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ReportURLResponse{
+			ID:     report.ID,
+			Status: string(report.Status),
+		}, nil
+	}
+}
+
+// reportedShort return short id of code, reporter often paste the whole short url
+func reportedShort(code string) string {
+	code = strings.TrimSpace(code)
+	if u, err := url.Parse(code); err == nil && u.Host != "" {
+		code = u.Path
+	}
+
+	code = strings.TrimRight(code, "/")
+	if i := strings.LastIndex(code, "/"); i >= 0 {
+		code = code[i+1:]
+	}
+
+	return code
+}
+
+// Report is an abuse report of url
+type Report struct {
+	ID          int64     `json:"id"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	Short       string    `json:"short"`
+	Reason      string    `json:"reason"`
+	Contact     string    `json:"contact,omitempty"`
+	Status      string    `json:"status"`
+	Resolution  string    `json:"resolution,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newReport(r *entity.Report) *Report {
+	return &Report{
+		ID:          r.ID,
+		WorkspaceID: r.WorkspaceID,
+		Short:       r.Short,
+		Reason:      r.Reason,
+		Contact:     r.Contact,
+		Status:      string(r.Status),
+		Resolution:  string(r.Resolution),
+		CreatedAt:   r.CreatedAt.UTC(),
+		UpdatedAt:   r.UpdatedAt.UTC(),
+	}
+}
+
+// ModerationAction is a moderator action in the audit trail of report
+type ModerationAction struct {
+	ActorID   string    `json:"actorId"`
+	Action    string    `json:"action"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListReportsRequest is list reports request, open reports are listed when status is empty
+type ListReportsRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=open triaged resolved"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ListReportsResponse is list reports response, oldest first
+type ListReportsResponse struct {
+	Reports []*Report `json:"reports"`
+}
+
+// MakeListReportsEndpoint make list reports endpoint
+func MakeListReportsEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ListReportsRequest)
+
+		reports, err := svc.ListReports(ctx, entity.ReportStatus(req.Status), req.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &ListReportsResponse{
+			Reports: make([]*Report, 0, len(reports)),
+		}
+		for _, r := range reports {
+			resp.Reports = append(resp.Reports, newReport(r))
+		}
+
+		return resp, nil
+	}
+}
+
+// GetReportRequest is get report request
+type GetReportRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+// GetReportResponse is report with its audit trail, oldest action first
+type GetReportResponse struct {
+	*Report
+	Actions []*ModerationAction `json:"actions"`
+}
+
+// MakeGetReportEndpoint make get report endpoint
+func MakeGetReportEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*GetReportRequest)
+
+		report, actions, err := svc.GetReport(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &GetReportResponse{
+			Report:  newReport(report),
+			Actions: make([]*ModerationAction, 0, len(actions)),
+		}
+		for _, a := range actions {
+			resp.Actions = append(resp.Actions, &ModerationAction{
+				ActorID:   a.ActorID,
+				Action:    string(a.Action),
+				Note:      a.Note,
+				CreatedAt: a.CreatedAt.UTC(),
+			})
+		}
+
+		return resp, nil
+	}
+}
+
+// TriageReportRequest is triage report request
+type TriageReportRequest struct {
+	ID   int64  `param:"id" validate:"required,min=1"`
+	Note string `json:"note" validate:"omitempty,max=1000"`
+}
+
+// MakeTriageReportEndpoint make triage report endpoint
+func MakeTriageReportEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*TriageReportRequest)
+
+		report, err := svc.TriageReport(ctx, req.ID, req.Note)
+		if err != nil {
+			return nil, err
+		}
+
+		return newReport(report), nil
+	}
+}
+
+// ResolveReportRequest is resolve report request, disabled url answer 410 on redirect
+type ResolveReportRequest struct {
+	ID         int64  `param:"id" validate:"required,min=1"`
+	Resolution string `json:"resolution" validate:"required,oneof=disabled dismissed"`
+	Note       string `json:"note" validate:"omitempty,max=1000"`
+}
+
+// MakeResolveReportEndpoint make resolve report endpoint
+func MakeResolveReportEndpoint(svc service.ShortenedURLService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(*ResolveReportRequest)
+
+		report, err := svc.ResolveReport(ctx, req.ID, entity.Resolution(req.Resolution), req.Note)
+		if err != nil {
+			return nil, err
+		}
+
+		return newReport(report), nil
+	}
+}
+
+// errorView convert error into error view model
+func errorView(err error) *errors.View {
+	e := errors.TryConvert(err)
+	if e == nil {
+		e = errors.ErrInternal
+	}
+
+	_, view := e.ToViewModel()
+	return view
+}
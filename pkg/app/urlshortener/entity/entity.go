@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strings"
 	"time"
 )
 
@@ -8,6 +9,33 @@ const (
 	DefaultShortenedURLExpireDur = time.Hour * 24 * 3 // DefaultShortenedURLExpireDur
 )
 
+// Status define shortened URL status
+type Status string
+
+const (
+	StatusActive  Status = "active"  // StatusActive the url can be redirected
+	StatusExpired Status = "expired" // StatusExpired the url is expired
+
+	StatusDisabled  Status = "disabled"  // StatusDisabled the url is disabled for abuse
+	StatusExhausted Status = "exhausted" // StatusExhausted the url has used up its max clicks
+	StatusScheduled Status = "scheduled" // StatusScheduled the url is not active until its activate time
+)
+
+// Destination is a scheduled switch of the original URL, the url redirect to URL from At
+type Destination struct {
+	At  time.Time `json:"at"`
+	URL string    `json:"url"`
+}
+
+// Interstitial define whether redirect show a warning page before leaving to the original URL
+type Interstitial string
+
+const (
+	InterstitialOff     Interstitial = ""        // InterstitialOff redirect at once
+	InterstitialAlways  Interstitial = "always"  // InterstitialAlways the owner ask to show the page
+	InterstitialFlagged Interstitial = "flagged" // InterstitialFlagged the url is flagged by abuse scoring until it is approved
+)
+
 // ShortenedURL define shortened URL entity
 type ShortenedURL struct {
 	// Short is shortened URL and Primary key
@@ -21,6 +49,45 @@ type ShortenedURL struct {
 
 	// ExpiredAt the url expired at
 	ExpiredAt time.Time `gorm:"column:expired_at"`
+
+	// Title is display name of the url
+	Title string `gorm:"column:title"`
+
+	// Tags is labels to group urls, lower case and unique
+	Tags []string `gorm:"column:tags"`
+
+	// Notes is free text of the url
+	Notes string `gorm:"column:notes"`
+
+	// OwnerID the principal which created the url, empty means anonymous
+	OwnerID string `gorm:"column:owner_id"`
+
+	// WorkspaceID the workspace which the short id belong to, empty means the default workspace
+	WorkspaceID string `gorm:"column:workspace_id"`
+
+	// Interstitial show a warning page instead of redirect at once when it is not off
+	Interstitial Interstitial `gorm:"column:interstitial"`
+
+	// DisabledAt the url disabled for abuse at, zero means enabled
+	DisabledAt time.Time `gorm:"column:disabled_at"`
+
+	// MaxClicks is how many redirects the url serves, zero means unlimited
+	MaxClicks int64 `gorm:"column:max_clicks"`
+
+	// Clicks is redirects counted against MaxClicks, it is only counted when MaxClicks is set
+	Clicks int64 `gorm:"column:clicks"`
+
+	// PasswordHash is bcrypt hash of the password which unlock the redirect, empty means not protected
+	PasswordHash string `gorm:"column:password_hash"`
+
+	// ActivateAt the url start to redirect at, zero means at once
+	ActivateAt time.Time `gorm:"column:activate_at"`
+
+	// Schedule is destination switches order by time, OriginalURL is the destination before the first one
+	Schedule []Destination `gorm:"column:schedule"`
+
+	// FallbackURL the url redirect to after it is expired, empty means the fallback of workspace or server
+	FallbackURL string `gorm:"column:fallback_url"`
 }
 
 func NewShortenedURL(short string, originalURL string, expiredAt time.Time) *ShortenedURL {
@@ -33,3 +100,73 @@ func NewShortenedURL(short string, originalURL string, expiredAt time.Time) *Sho
 		ExpiredAt:   expiredAt,
 	}
 }
+
+// Key return unique key of shortened URL across workspaces,
+// it is short id in the default workspace and workspace id/short id in others
+func (s *ShortenedURL) Key() string {
+	return JoinKey(s.WorkspaceID, s.Short)
+}
+
+// JoinKey return unique key of short id in the workspace
+func JoinKey(workspaceID string, short string) string {
+	if workspaceID == "" {
+		return short
+	}
+	return workspaceID + "/" + short
+}
+
+// SplitKey split unique key into workspace id and short id
+func SplitKey(key string) (workspaceID string, short string) {
+	if workspaceID, short, ok := strings.Cut(key, "/"); ok {
+		return workspaceID, short
+	}
+	return "", key
+}
+
+// Protected check redirect of shortened URL need a password
+func (s *ShortenedURL) Protected() bool {
+	return s.PasswordHash != ""
+}
+
+// StatusAt return the status of shortened URL at the time
+func (s *ShortenedURL) StatusAt(now time.Time) Status {
+	if !s.DisabledAt.IsZero() {
+		return StatusDisabled
+	}
+	if s.ExpiredAt.UnixMilli() <= now.UnixMilli() {
+		return StatusExpired
+	}
+	if s.ActivateAt.UnixMilli() > now.UnixMilli() {
+		return StatusScheduled
+	}
+	if s.MaxClicks > 0 && s.Clicks >= s.MaxClicks {
+		return StatusExhausted
+	}
+	return StatusActive
+}
+
+// DestinationAt return the original URL which the url redirect to at the time by its schedule
+func (s *ShortenedURL) DestinationAt(now time.Time) string {
+	destination := s.OriginalURL
+	for _, d := range s.Schedule {
+		if d.At.UnixMilli() > now.UnixMilli() {
+			break
+		}
+		destination = d.URL
+	}
+	return destination
+}
+
+// NextTransitionAt return when the url is activated or switch destination after the time, zero means never.
+// Redirect of the url can be cached until then
+func (s *ShortenedURL) NextTransitionAt(now time.Time) time.Time {
+	if s.ActivateAt.UnixMilli() > now.UnixMilli() {
+		return s.ActivateAt
+	}
+	for _, d := range s.Schedule {
+		if d.At.UnixMilli() > now.UnixMilli() {
+			return d.At
+		}
+	}
+	return time.Time{}
+}
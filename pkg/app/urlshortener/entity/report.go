@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+)
+
+// ReportStatus define moderation status of abuse report
+type ReportStatus string
+
+const (
+	ReportOpen     ReportStatus = "open"     // ReportOpen the report waits for a moderator
+	ReportTriaged  ReportStatus = "triaged"  // ReportTriaged a moderator has looked at the report
+	ReportResolved ReportStatus = "resolved" // ReportResolved the report is closed with a resolution
+)
+
+// Resolution define how abuse report is resolved
+type Resolution string
+
+const (
+	ResolutionDisabled  Resolution = "disabled"  // ResolutionDisabled the url is disabled for abuse
+	ResolutionDismissed Resolution = "dismissed" // ResolutionDismissed the url is kept
+)
+
+// ModerationActionType define what a moderator did
+type ModerationActionType string
+
+const (
+	ActionTriage  ModerationActionType = "triage"  // ActionTriage the moderator triaged the report
+	ActionDisable ModerationActionType = "disable" // ActionDisable the moderator disabled the url
+	ActionDismiss ModerationActionType = "dismiss" // ActionDismiss the moderator dismissed the report
+)
+
+// Report define abuse report of a shortened URL
+type Report struct {
+	// ID is Primary key
+	ID int64 `gorm:"column:id"`
+
+	// WorkspaceID and Short are the reported url
+	WorkspaceID string `gorm:"column:workspace_id"`
+	Short       string `gorm:"column:short"`
+
+	// Reason why the reporter think the url is abuse
+	Reason string `gorm:"column:reason"`
+
+	// Contact of the reporter, optional
+	Contact string `gorm:"column:contact"`
+
+	Status     ReportStatus `gorm:"column:status"`
+	Resolution Resolution   `gorm:"column:resolution"`
+
+	// CreatedAt the report created at
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	// UpdatedAt the last moderator action at
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// Key return unique key of the reported url across workspaces
+func (r *Report) Key() string {
+	return JoinKey(r.WorkspaceID, r.Short)
+}
+
+// ModerationAction define audit trail of moderator action on a report
+type ModerationAction struct {
+	ID       int64                `gorm:"column:id"`
+	ReportID int64                `gorm:"column:report_id"`
+	ActorID  string               `gorm:"column:actor_id"`
+	Action   ModerationActionType `gorm:"column:action"`
+	Note     string               `gorm:"column:note"`
+
+	// CreatedAt the action taken at
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
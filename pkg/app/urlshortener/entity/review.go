@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"time"
+)
+
+// ReviewStatus define review status of flagged url
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"  // ReviewPending the url waits for review and can be redirected
+	ReviewApproved ReviewStatus = "approved" // ReviewApproved the url is reviewed as safe
+	ReviewRejected ReviewStatus = "rejected" // ReviewRejected the url is reviewed as abuse and deleted
+)
+
+// Signal is a matched abuse rule of flagged url
+type Signal struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// Review define a flagged url in review queue
+type Review struct {
+	// WorkspaceID and Short are the flagged url and Primary key
+	WorkspaceID string `gorm:"column:workspace_id"`
+	Short       string `gorm:"column:short"`
+
+	// OwnerID the principal which created the url
+	OwnerID string `gorm:"column:owner_id"`
+
+	// OriginalURL is loaded from the url, empty after the url is deleted
+	OriginalURL string `gorm:"-"`
+
+	// Score is total abuse score and Signals are the matched rules
+	Score   int      `gorm:"column:score"`
+	Signals []Signal `gorm:"column:signals"`
+
+	Status ReviewStatus `gorm:"column:status"`
+
+	// CreatedAt the url flagged at
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	// ReviewedAt and ReviewerID are set when the review is resolved
+	ReviewedAt *time.Time `gorm:"column:reviewed_at"`
+	ReviewerID string     `gorm:"column:reviewer_id"`
+}
+
+// Key return unique key of the flagged url across workspaces
+func (r *Review) Key() string {
+	return JoinKey(r.WorkspaceID, r.Short)
+}
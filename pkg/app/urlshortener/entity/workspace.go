@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+)
+
+// Workspace define a team which has its own domains and short id namespace
+type Workspace struct {
+	// ID is url safe slug of workspace and Primary key
+	ID string `gorm:"column:id"`
+
+	// Name is display name of workspace
+	Name string `gorm:"column:name"`
+
+	// OwnerID the principal which can short urls in the workspace
+	OwnerID string `gorm:"column:owner_id"`
+
+	// CreatedAt the workspace created at
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	// FallbackURL expired urls of the workspace redirect to when they have no fallback, empty means none
+	FallbackURL string `gorm:"column:fallback_url"`
+
+	// Domains is custom short domains of workspace
+	Domains []*Domain `gorm:"-"`
+}
+
+// BaseURL return short url prefix of workspace, empty when no domain is verified
+func (w *Workspace) BaseURL() string {
+	for _, d := range w.Domains {
+		if d.Verified() {
+			return "https://" + d.Host
+		}
+	}
+	return ""
+}
+
+// Domain define a custom short domain of workspace
+type Domain struct {
+	// Host is lower case host name and Primary key
+	Host string `gorm:"column:host"`
+
+	// WorkspaceID the workspace which the domain belong to
+	WorkspaceID string `gorm:"column:workspace_id"`
+
+	// Token is the value of DNS TXT record which prove the domain ownership
+	Token string `gorm:"column:token"`
+
+	// CreatedAt the domain added at
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	// VerifiedAt the domain ownership verified at, nil means not verified
+	VerifiedAt *time.Time `gorm:"column:verified_at"`
+}
+
+// Verified return whether the domain ownership is verified
+func (d *Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
@@ -0,0 +1,90 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KeyRotator is an autogenerated mock type for the KeyRotator type
+type KeyRotator struct {
+	mock.Mock
+}
+
+type KeyRotator_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *KeyRotator) EXPECT() *KeyRotator_Expecter {
+	return &KeyRotator_Expecter{mock: &_m.Mock}
+}
+
+// RotateKeys provides a mock function with given fields: ctx, batchSize
+func (_m *KeyRotator) RotateKeys(ctx context.Context, batchSize int) (int, error) {
+	ret := _m.Called(ctx, batchSize)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, batchSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, batchSize)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, batchSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KeyRotator_RotateKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateKeys'
+type KeyRotator_RotateKeys_Call struct {
+	*mock.Call
+}
+
+// RotateKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - batchSize int
+func (_e *KeyRotator_Expecter) RotateKeys(ctx interface{}, batchSize interface{}) *KeyRotator_RotateKeys_Call {
+	return &KeyRotator_RotateKeys_Call{Call: _e.mock.On("RotateKeys", ctx, batchSize)}
+}
+
+func (_c *KeyRotator_RotateKeys_Call) Run(run func(ctx context.Context, batchSize int)) *KeyRotator_RotateKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *KeyRotator_RotateKeys_Call) Return(rotated int, err error) *KeyRotator_RotateKeys_Call {
+	_c.Call.Return(rotated, err)
+	return _c
+}
+
+func (_c *KeyRotator_RotateKeys_Call) RunAndReturn(run func(context.Context, int) (int, error)) *KeyRotator_RotateKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewKeyRotator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewKeyRotator creates a new instance of KeyRotator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewKeyRotator(t mockConstructorTestingTNewKeyRotator) *KeyRotator {
+	mock := &KeyRotator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
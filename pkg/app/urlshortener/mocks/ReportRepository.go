@@ -0,0 +1,291 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "url-shortener/pkg/app/urlshortener/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReportRepository is an autogenerated mock type for the ReportRepository type
+type ReportRepository struct {
+	mock.Mock
+}
+
+type ReportRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ReportRepository) EXPECT() *ReportRepository_Expecter {
+	return &ReportRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateReport provides a mock function with given fields: ctx, report
+func (_m *ReportRepository) CreateReport(ctx context.Context, report *entity.Report) error {
+	ret := _m.Called(ctx, report)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Report) error); ok {
+		r0 = rf(ctx, report)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReportRepository_CreateReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateReport'
+type ReportRepository_CreateReport_Call struct {
+	*mock.Call
+}
+
+// CreateReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - report *entity.Report
+func (_e *ReportRepository_Expecter) CreateReport(ctx interface{}, report interface{}) *ReportRepository_CreateReport_Call {
+	return &ReportRepository_CreateReport_Call{Call: _e.mock.On("CreateReport", ctx, report)}
+}
+
+func (_c *ReportRepository_CreateReport_Call) Run(run func(ctx context.Context, report *entity.Report)) *ReportRepository_CreateReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Report))
+	})
+	return _c
+}
+
+func (_c *ReportRepository_CreateReport_Call) Return(err error) *ReportRepository_CreateReport_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ReportRepository_CreateReport_Call) RunAndReturn(run func(context.Context, *entity.Report) error) *ReportRepository_CreateReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindReport provides a mock function with given fields: ctx, id
+func (_m *ReportRepository) FindReport(ctx context.Context, id int64) (*entity.Report, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *entity.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*entity.Report, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *entity.Report); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportRepository_FindReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindReport'
+type ReportRepository_FindReport_Call struct {
+	*mock.Call
+}
+
+// FindReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *ReportRepository_Expecter) FindReport(ctx interface{}, id interface{}) *ReportRepository_FindReport_Call {
+	return &ReportRepository_FindReport_Call{Call: _e.mock.On("FindReport", ctx, id)}
+}
+
+func (_c *ReportRepository_FindReport_Call) Run(run func(ctx context.Context, id int64)) *ReportRepository_FindReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *ReportRepository_FindReport_Call) Return(report *entity.Report, err error) *ReportRepository_FindReport_Call {
+	_c.Call.Return(report, err)
+	return _c
+}
+
+func (_c *ReportRepository_FindReport_Call) RunAndReturn(run func(context.Context, int64) (*entity.Report, error)) *ReportRepository_FindReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListActions provides a mock function with given fields: ctx, reportID
+func (_m *ReportRepository) ListActions(ctx context.Context, reportID int64) ([]*entity.ModerationAction, error) {
+	ret := _m.Called(ctx, reportID)
+
+	var r0 []*entity.ModerationAction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*entity.ModerationAction, error)); ok {
+		return rf(ctx, reportID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*entity.ModerationAction); ok {
+		r0 = rf(ctx, reportID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.ModerationAction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, reportID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportRepository_ListActions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListActions'
+type ReportRepository_ListActions_Call struct {
+	*mock.Call
+}
+
+// ListActions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - reportID int64
+func (_e *ReportRepository_Expecter) ListActions(ctx interface{}, reportID interface{}) *ReportRepository_ListActions_Call {
+	return &ReportRepository_ListActions_Call{Call: _e.mock.On("ListActions", ctx, reportID)}
+}
+
+func (_c *ReportRepository_ListActions_Call) Run(run func(ctx context.Context, reportID int64)) *ReportRepository_ListActions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *ReportRepository_ListActions_Call) Return(actions []*entity.ModerationAction, err error) *ReportRepository_ListActions_Call {
+	_c.Call.Return(actions, err)
+	return _c
+}
+
+func (_c *ReportRepository_ListActions_Call) RunAndReturn(run func(context.Context, int64) ([]*entity.ModerationAction, error)) *ReportRepository_ListActions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListReports provides a mock function with given fields: ctx, status, limit
+func (_m *ReportRepository) ListReports(ctx context.Context, status entity.ReportStatus, limit int) ([]*entity.Report, error) {
+	ret := _m.Called(ctx, status, limit)
+
+	var r0 []*entity.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReportStatus, int) ([]*entity.Report, error)); ok {
+		return rf(ctx, status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReportStatus, int) []*entity.Report); ok {
+		r0 = rf(ctx, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.ReportStatus, int) error); ok {
+		r1 = rf(ctx, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportRepository_ListReports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListReports'
+type ReportRepository_ListReports_Call struct {
+	*mock.Call
+}
+
+// ListReports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - status entity.ReportStatus
+//   - limit int
+func (_e *ReportRepository_Expecter) ListReports(ctx interface{}, status interface{}, limit interface{}) *ReportRepository_ListReports_Call {
+	return &ReportRepository_ListReports_Call{Call: _e.mock.On("ListReports", ctx, status, limit)}
+}
+
+func (_c *ReportRepository_ListReports_Call) Run(run func(ctx context.Context, status entity.ReportStatus, limit int)) *ReportRepository_ListReports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.ReportStatus), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ReportRepository_ListReports_Call) Return(reports []*entity.Report, err error) *ReportRepository_ListReports_Call {
+	_c.Call.Return(reports, err)
+	return _c
+}
+
+func (_c *ReportRepository_ListReports_Call) RunAndReturn(run func(context.Context, entity.ReportStatus, int) ([]*entity.Report, error)) *ReportRepository_ListReports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ModerateReport provides a mock function with given fields: ctx, report, action
+func (_m *ReportRepository) ModerateReport(ctx context.Context, report *entity.Report, action *entity.ModerationAction) error {
+	ret := _m.Called(ctx, report, action)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Report, *entity.ModerationAction) error); ok {
+		r0 = rf(ctx, report, action)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReportRepository_ModerateReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ModerateReport'
+type ReportRepository_ModerateReport_Call struct {
+	*mock.Call
+}
+
+// ModerateReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - report *entity.Report
+//   - action *entity.ModerationAction
+func (_e *ReportRepository_Expecter) ModerateReport(ctx interface{}, report interface{}, action interface{}) *ReportRepository_ModerateReport_Call {
+	return &ReportRepository_ModerateReport_Call{Call: _e.mock.On("ModerateReport", ctx, report, action)}
+}
+
+func (_c *ReportRepository_ModerateReport_Call) Run(run func(ctx context.Context, report *entity.Report, action *entity.ModerationAction)) *ReportRepository_ModerateReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Report), args[2].(*entity.ModerationAction))
+	})
+	return _c
+}
+
+func (_c *ReportRepository_ModerateReport_Call) Return(err error) *ReportRepository_ModerateReport_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ReportRepository_ModerateReport_Call) RunAndReturn(run func(context.Context, *entity.Report, *entity.ModerationAction) error) *ReportRepository_ModerateReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewReportRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewReportRepository creates a new instance of ReportRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewReportRepository(t mockConstructorTestingTNewReportRepository) *ReportRepository {
+	mock := &ReportRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,7 +4,9 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 	entity "url-shortener/pkg/app/urlshortener/entity"
+	repository "url-shortener/pkg/app/urlshortener/repository"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -22,25 +24,172 @@ func (_m *Repository) EXPECT() *Repository_Expecter {
 	return &Repository_Expecter{mock: &_m.Mock}
 }
 
-// FindShortenedURL provides a mock function with given fields: ctx, short
-func (_m *Repository) FindShortenedURL(ctx context.Context, short string) (*entity.ShortenedURL, error) {
-	ret := _m.Called(ctx, short)
+// DeleteShortenedURL provides a mock function with given fields: ctx, workspaceID, short
+func (_m *Repository) DeleteShortenedURL(ctx context.Context, workspaceID string, short string) error {
+	ret := _m.Called(ctx, workspaceID, short)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, workspaceID, short)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DeleteShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteShortenedURL'
+type Repository_DeleteShortenedURL_Call struct {
+	*mock.Call
+}
+
+// DeleteShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - short string
+func (_e *Repository_Expecter) DeleteShortenedURL(ctx interface{}, workspaceID interface{}, short interface{}) *Repository_DeleteShortenedURL_Call {
+	return &Repository_DeleteShortenedURL_Call{Call: _e.mock.On("DeleteShortenedURL", ctx, workspaceID, short)}
+}
+
+func (_c *Repository_DeleteShortenedURL_Call) Run(run func(ctx context.Context, workspaceID string, short string)) *Repository_DeleteShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteShortenedURL_Call) Return(err error) *Repository_DeleteShortenedURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Repository_DeleteShortenedURL_Call) RunAndReturn(run func(context.Context, string, string) error) *Repository_DeleteShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DisableShortenedURL provides a mock function with given fields: ctx, workspaceID, short, disabledAt
+func (_m *Repository) DisableShortenedURL(ctx context.Context, workspaceID string, short string, disabledAt time.Time) error {
+	ret := _m.Called(ctx, workspaceID, short, disabledAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, workspaceID, short, disabledAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DisableShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableShortenedURL'
+type Repository_DisableShortenedURL_Call struct {
+	*mock.Call
+}
+
+// DisableShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - short string
+//   - disabledAt time.Time
+func (_e *Repository_Expecter) DisableShortenedURL(ctx interface{}, workspaceID interface{}, short interface{}, disabledAt interface{}) *Repository_DisableShortenedURL_Call {
+	return &Repository_DisableShortenedURL_Call{Call: _e.mock.On("DisableShortenedURL", ctx, workspaceID, short, disabledAt)}
+}
+
+func (_c *Repository_DisableShortenedURL_Call) Run(run func(ctx context.Context, workspaceID string, short string, disabledAt time.Time)) *Repository_DisableShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Repository_DisableShortenedURL_Call) Return(err error) *Repository_DisableShortenedURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Repository_DisableShortenedURL_Call) RunAndReturn(run func(context.Context, string, string, time.Time) error) *Repository_DisableShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindDuplicateShortenedURL provides a mock function with given fields: ctx, workspaceID, ownerID, originalURL, now
+func (_m *Repository) FindDuplicateShortenedURL(ctx context.Context, workspaceID string, ownerID string, originalURL string, now time.Time) (*entity.ShortenedURL, error) {
+	ret := _m.Called(ctx, workspaceID, ownerID, originalURL, now)
+
+	var r0 *entity.ShortenedURL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) (*entity.ShortenedURL, error)); ok {
+		return rf(ctx, workspaceID, ownerID, originalURL, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) *entity.ShortenedURL); ok {
+		r0 = rf(ctx, workspaceID, ownerID, originalURL, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ShortenedURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, time.Time) error); ok {
+		r1 = rf(ctx, workspaceID, ownerID, originalURL, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_FindDuplicateShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindDuplicateShortenedURL'
+type Repository_FindDuplicateShortenedURL_Call struct {
+	*mock.Call
+}
+
+// FindDuplicateShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - ownerID string
+//   - originalURL string
+//   - now time.Time
+func (_e *Repository_Expecter) FindDuplicateShortenedURL(ctx interface{}, workspaceID interface{}, ownerID interface{}, originalURL interface{}, now interface{}) *Repository_FindDuplicateShortenedURL_Call {
+	return &Repository_FindDuplicateShortenedURL_Call{Call: _e.mock.On("FindDuplicateShortenedURL", ctx, workspaceID, ownerID, originalURL, now)}
+}
+
+func (_c *Repository_FindDuplicateShortenedURL_Call) Run(run func(ctx context.Context, workspaceID string, ownerID string, originalURL string, now time.Time)) *Repository_FindDuplicateShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Repository_FindDuplicateShortenedURL_Call) Return(shortenedURL *entity.ShortenedURL, err error) *Repository_FindDuplicateShortenedURL_Call {
+	_c.Call.Return(shortenedURL, err)
+	return _c
+}
+
+func (_c *Repository_FindDuplicateShortenedURL_Call) RunAndReturn(run func(context.Context, string, string, string, time.Time) (*entity.ShortenedURL, error)) *Repository_FindDuplicateShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindShortenedURL provides a mock function with given fields: ctx, workspaceID, short
+func (_m *Repository) FindShortenedURL(ctx context.Context, workspaceID string, short string) (*entity.ShortenedURL, error) {
+	ret := _m.Called(ctx, workspaceID, short)
 
 	var r0 *entity.ShortenedURL
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.ShortenedURL, error)); ok {
-		return rf(ctx, short)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.ShortenedURL, error)); ok {
+		return rf(ctx, workspaceID, short)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.ShortenedURL); ok {
-		r0 = rf(ctx, short)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.ShortenedURL); ok {
+		r0 = rf(ctx, workspaceID, short)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.ShortenedURL)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, short)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspaceID, short)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -55,14 +204,15 @@ type Repository_FindShortenedURL_Call struct {
 
 // FindShortenedURL is a helper method to define mock.On call
 //   - ctx context.Context
+//   - workspaceID string
 //   - short string
-func (_e *Repository_Expecter) FindShortenedURL(ctx interface{}, short interface{}) *Repository_FindShortenedURL_Call {
-	return &Repository_FindShortenedURL_Call{Call: _e.mock.On("FindShortenedURL", ctx, short)}
+func (_e *Repository_Expecter) FindShortenedURL(ctx interface{}, workspaceID interface{}, short interface{}) *Repository_FindShortenedURL_Call {
+	return &Repository_FindShortenedURL_Call{Call: _e.mock.On("FindShortenedURL", ctx, workspaceID, short)}
 }
 
-func (_c *Repository_FindShortenedURL_Call) Run(run func(ctx context.Context, short string)) *Repository_FindShortenedURL_Call {
+func (_c *Repository_FindShortenedURL_Call) Run(run func(ctx context.Context, workspaceID string, short string)) *Repository_FindShortenedURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -72,7 +222,233 @@ func (_c *Repository_FindShortenedURL_Call) Return(shortenedURL *entity.Shortene
 	return _c
 }
 
-func (_c *Repository_FindShortenedURL_Call) RunAndReturn(run func(context.Context, string) (*entity.ShortenedURL, error)) *Repository_FindShortenedURL_Call {
+func (_c *Repository_FindShortenedURL_Call) RunAndReturn(run func(context.Context, string, string) (*entity.ShortenedURL, error)) *Repository_FindShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportShortenedURL provides a mock function with given fields: ctx, shortenedURL, mode
+func (_m *Repository) ImportShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL, mode repository.ConflictMode) (bool, error) {
+	ret := _m.Called(ctx, shortenedURL, mode)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.ShortenedURL, repository.ConflictMode) (bool, error)); ok {
+		return rf(ctx, shortenedURL, mode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.ShortenedURL, repository.ConflictMode) bool); ok {
+		r0 = rf(ctx, shortenedURL, mode)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.ShortenedURL, repository.ConflictMode) error); ok {
+		r1 = rf(ctx, shortenedURL, mode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ImportShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportShortenedURL'
+type Repository_ImportShortenedURL_Call struct {
+	*mock.Call
+}
+
+// ImportShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortenedURL *entity.ShortenedURL
+//   - mode repository.ConflictMode
+func (_e *Repository_Expecter) ImportShortenedURL(ctx interface{}, shortenedURL interface{}, mode interface{}) *Repository_ImportShortenedURL_Call {
+	return &Repository_ImportShortenedURL_Call{Call: _e.mock.On("ImportShortenedURL", ctx, shortenedURL, mode)}
+}
+
+func (_c *Repository_ImportShortenedURL_Call) Run(run func(ctx context.Context, shortenedURL *entity.ShortenedURL, mode repository.ConflictMode)) *Repository_ImportShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.ShortenedURL), args[2].(repository.ConflictMode))
+	})
+	return _c
+}
+
+func (_c *Repository_ImportShortenedURL_Call) Return(stored bool, err error) *Repository_ImportShortenedURL_Call {
+	_c.Call.Return(stored, err)
+	return _c
+}
+
+func (_c *Repository_ImportShortenedURL_Call) RunAndReturn(run func(context.Context, *entity.ShortenedURL, repository.ConflictMode) (bool, error)) *Repository_ImportShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementClicks provides a mock function with given fields: ctx, workspaceID, short
+func (_m *Repository) IncrementClicks(ctx context.Context, workspaceID string, short string) (int64, error) {
+	ret := _m.Called(ctx, workspaceID, short)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return rf(ctx, workspaceID, short)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, workspaceID, short)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspaceID, short)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_IncrementClicks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementClicks'
+type Repository_IncrementClicks_Call struct {
+	*mock.Call
+}
+
+// IncrementClicks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - short string
+func (_e *Repository_Expecter) IncrementClicks(ctx interface{}, workspaceID interface{}, short interface{}) *Repository_IncrementClicks_Call {
+	return &Repository_IncrementClicks_Call{Call: _e.mock.On("IncrementClicks", ctx, workspaceID, short)}
+}
+
+func (_c *Repository_IncrementClicks_Call) Run(run func(ctx context.Context, workspaceID string, short string)) *Repository_IncrementClicks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_IncrementClicks_Call) Return(clicks int64, err error) *Repository_IncrementClicks_Call {
+	_c.Call.Return(clicks, err)
+	return _c
+}
+
+func (_c *Repository_IncrementClicks_Call) RunAndReturn(run func(context.Context, string, string) (int64, error)) *Repository_IncrementClicks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListShortenedURLs provides a mock function with given fields: ctx, cursor, limit
+func (_m *Repository) ListShortenedURLs(ctx context.Context, cursor string, limit int) ([]*entity.ShortenedURL, error) {
+	ret := _m.Called(ctx, cursor, limit)
+
+	var r0 []*entity.ShortenedURL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]*entity.ShortenedURL, error)); ok {
+		return rf(ctx, cursor, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []*entity.ShortenedURL); ok {
+		r0 = rf(ctx, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.ShortenedURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListShortenedURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListShortenedURLs'
+type Repository_ListShortenedURLs_Call struct {
+	*mock.Call
+}
+
+// ListShortenedURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cursor string
+//   - limit int
+func (_e *Repository_Expecter) ListShortenedURLs(ctx interface{}, cursor interface{}, limit interface{}) *Repository_ListShortenedURLs_Call {
+	return &Repository_ListShortenedURLs_Call{Call: _e.mock.On("ListShortenedURLs", ctx, cursor, limit)}
+}
+
+func (_c *Repository_ListShortenedURLs_Call) Run(run func(ctx context.Context, cursor string, limit int)) *Repository_ListShortenedURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListShortenedURLs_Call) Return(shortenedURLs []*entity.ShortenedURL, err error) *Repository_ListShortenedURLs_Call {
+	_c.Call.Return(shortenedURLs, err)
+	return _c
+}
+
+func (_c *Repository_ListShortenedURLs_Call) RunAndReturn(run func(context.Context, string, int) ([]*entity.ShortenedURL, error)) *Repository_ListShortenedURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchShortenedURLs provides a mock function with given fields: ctx, filter
+func (_m *Repository) SearchShortenedURLs(ctx context.Context, filter *repository.SearchFilter) ([]*entity.ShortenedURL, string, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*entity.ShortenedURL
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.SearchFilter) ([]*entity.ShortenedURL, string, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.SearchFilter) []*entity.ShortenedURL); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.ShortenedURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.SearchFilter) string); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *repository.SearchFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_SearchShortenedURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchShortenedURLs'
+type Repository_SearchShortenedURLs_Call struct {
+	*mock.Call
+}
+
+// SearchShortenedURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter *repository.SearchFilter
+func (_e *Repository_Expecter) SearchShortenedURLs(ctx interface{}, filter interface{}) *Repository_SearchShortenedURLs_Call {
+	return &Repository_SearchShortenedURLs_Call{Call: _e.mock.On("SearchShortenedURLs", ctx, filter)}
+}
+
+func (_c *Repository_SearchShortenedURLs_Call) Run(run func(ctx context.Context, filter *repository.SearchFilter)) *Repository_SearchShortenedURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*repository.SearchFilter))
+	})
+	return _c
+}
+
+func (_c *Repository_SearchShortenedURLs_Call) Return(shortenedURLs []*entity.ShortenedURL, next string, err error) *Repository_SearchShortenedURLs_Call {
+	_c.Call.Return(shortenedURLs, next, err)
+	return _c
+}
+
+func (_c *Repository_SearchShortenedURLs_Call) RunAndReturn(run func(context.Context, *repository.SearchFilter) ([]*entity.ShortenedURL, string, error)) *Repository_SearchShortenedURLs_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -120,6 +496,148 @@ func (_c *Repository_StoreShortenedURL_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// StoreShortenedURLs provides a mock function with given fields: ctx, shortenedURLs
+func (_m *Repository) StoreShortenedURLs(ctx context.Context, shortenedURLs []*entity.ShortenedURL) ([]string, error) {
+	ret := _m.Called(ctx, shortenedURLs)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*entity.ShortenedURL) ([]string, error)); ok {
+		return rf(ctx, shortenedURLs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*entity.ShortenedURL) []string); ok {
+		r0 = rf(ctx, shortenedURLs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*entity.ShortenedURL) error); ok {
+		r1 = rf(ctx, shortenedURLs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_StoreShortenedURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StoreShortenedURLs'
+type Repository_StoreShortenedURLs_Call struct {
+	*mock.Call
+}
+
+// StoreShortenedURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortenedURLs []*entity.ShortenedURL
+func (_e *Repository_Expecter) StoreShortenedURLs(ctx interface{}, shortenedURLs interface{}) *Repository_StoreShortenedURLs_Call {
+	return &Repository_StoreShortenedURLs_Call{Call: _e.mock.On("StoreShortenedURLs", ctx, shortenedURLs)}
+}
+
+func (_c *Repository_StoreShortenedURLs_Call) Run(run func(ctx context.Context, shortenedURLs []*entity.ShortenedURL)) *Repository_StoreShortenedURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]*entity.ShortenedURL))
+	})
+	return _c
+}
+
+func (_c *Repository_StoreShortenedURLs_Call) Return(stored []string, err error) *Repository_StoreShortenedURLs_Call {
+	_c.Call.Return(stored, err)
+	return _c
+}
+
+func (_c *Repository_StoreShortenedURLs_Call) RunAndReturn(run func(context.Context, []*entity.ShortenedURL) ([]string, error)) *Repository_StoreShortenedURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnflagShortenedURL provides a mock function with given fields: ctx, workspaceID, short
+func (_m *Repository) UnflagShortenedURL(ctx context.Context, workspaceID string, short string) error {
+	ret := _m.Called(ctx, workspaceID, short)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, workspaceID, short)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_UnflagShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnflagShortenedURL'
+type Repository_UnflagShortenedURL_Call struct {
+	*mock.Call
+}
+
+// UnflagShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - short string
+func (_e *Repository_Expecter) UnflagShortenedURL(ctx interface{}, workspaceID interface{}, short interface{}) *Repository_UnflagShortenedURL_Call {
+	return &Repository_UnflagShortenedURL_Call{Call: _e.mock.On("UnflagShortenedURL", ctx, workspaceID, short)}
+}
+
+func (_c *Repository_UnflagShortenedURL_Call) Run(run func(ctx context.Context, workspaceID string, short string)) *Repository_UnflagShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_UnflagShortenedURL_Call) Return(err error) *Repository_UnflagShortenedURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Repository_UnflagShortenedURL_Call) RunAndReturn(run func(context.Context, string, string) error) *Repository_UnflagShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateShortenedURL provides a mock function with given fields: ctx, shortenedURL
+func (_m *Repository) UpdateShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL) error {
+	ret := _m.Called(ctx, shortenedURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.ShortenedURL) error); ok {
+		r0 = rf(ctx, shortenedURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_UpdateShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateShortenedURL'
+type Repository_UpdateShortenedURL_Call struct {
+	*mock.Call
+}
+
+// UpdateShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortenedURL *entity.ShortenedURL
+func (_e *Repository_Expecter) UpdateShortenedURL(ctx interface{}, shortenedURL interface{}) *Repository_UpdateShortenedURL_Call {
+	return &Repository_UpdateShortenedURL_Call{Call: _e.mock.On("UpdateShortenedURL", ctx, shortenedURL)}
+}
+
+func (_c *Repository_UpdateShortenedURL_Call) Run(run func(ctx context.Context, shortenedURL *entity.ShortenedURL)) *Repository_UpdateShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.ShortenedURL))
+	})
+	return _c
+}
+
+func (_c *Repository_UpdateShortenedURL_Call) Return(err error) *Repository_UpdateShortenedURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *Repository_UpdateShortenedURL_Call) RunAndReturn(run func(context.Context, *entity.ShortenedURL) error) *Repository_UpdateShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 type mockConstructorTestingTNewRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,242 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "url-shortener/pkg/app/urlshortener/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ReviewRepository is an autogenerated mock type for the ReviewRepository type
+type ReviewRepository struct {
+	mock.Mock
+}
+
+type ReviewRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ReviewRepository) EXPECT() *ReviewRepository_Expecter {
+	return &ReviewRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateReview provides a mock function with given fields: ctx, review
+func (_m *ReviewRepository) CreateReview(ctx context.Context, review *entity.Review) error {
+	ret := _m.Called(ctx, review)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Review) error); ok {
+		r0 = rf(ctx, review)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReviewRepository_CreateReview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateReview'
+type ReviewRepository_CreateReview_Call struct {
+	*mock.Call
+}
+
+// CreateReview is a helper method to define mock.On call
+//   - ctx context.Context
+//   - review *entity.Review
+func (_e *ReviewRepository_Expecter) CreateReview(ctx interface{}, review interface{}) *ReviewRepository_CreateReview_Call {
+	return &ReviewRepository_CreateReview_Call{Call: _e.mock.On("CreateReview", ctx, review)}
+}
+
+func (_c *ReviewRepository_CreateReview_Call) Run(run func(ctx context.Context, review *entity.Review)) *ReviewRepository_CreateReview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Review))
+	})
+	return _c
+}
+
+func (_c *ReviewRepository_CreateReview_Call) Return(err error) *ReviewRepository_CreateReview_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ReviewRepository_CreateReview_Call) RunAndReturn(run func(context.Context, *entity.Review) error) *ReviewRepository_CreateReview_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindReview provides a mock function with given fields: ctx, workspaceID, short
+func (_m *ReviewRepository) FindReview(ctx context.Context, workspaceID string, short string) (*entity.Review, error) {
+	ret := _m.Called(ctx, workspaceID, short)
+
+	var r0 *entity.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.Review, error)); ok {
+		return rf(ctx, workspaceID, short)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.Review); ok {
+		r0 = rf(ctx, workspaceID, short)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspaceID, short)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewRepository_FindReview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindReview'
+type ReviewRepository_FindReview_Call struct {
+	*mock.Call
+}
+
+// FindReview is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - short string
+func (_e *ReviewRepository_Expecter) FindReview(ctx interface{}, workspaceID interface{}, short interface{}) *ReviewRepository_FindReview_Call {
+	return &ReviewRepository_FindReview_Call{Call: _e.mock.On("FindReview", ctx, workspaceID, short)}
+}
+
+func (_c *ReviewRepository_FindReview_Call) Run(run func(ctx context.Context, workspaceID string, short string)) *ReviewRepository_FindReview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *ReviewRepository_FindReview_Call) Return(review *entity.Review, err error) *ReviewRepository_FindReview_Call {
+	_c.Call.Return(review, err)
+	return _c
+}
+
+func (_c *ReviewRepository_FindReview_Call) RunAndReturn(run func(context.Context, string, string) (*entity.Review, error)) *ReviewRepository_FindReview_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListReviews provides a mock function with given fields: ctx, status, limit
+func (_m *ReviewRepository) ListReviews(ctx context.Context, status entity.ReviewStatus, limit int) ([]*entity.Review, error) {
+	ret := _m.Called(ctx, status, limit)
+
+	var r0 []*entity.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReviewStatus, int) ([]*entity.Review, error)); ok {
+		return rf(ctx, status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReviewStatus, int) []*entity.Review); ok {
+		r0 = rf(ctx, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.ReviewStatus, int) error); ok {
+		r1 = rf(ctx, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewRepository_ListReviews_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListReviews'
+type ReviewRepository_ListReviews_Call struct {
+	*mock.Call
+}
+
+// ListReviews is a helper method to define mock.On call
+//   - ctx context.Context
+//   - status entity.ReviewStatus
+//   - limit int
+func (_e *ReviewRepository_Expecter) ListReviews(ctx interface{}, status interface{}, limit interface{}) *ReviewRepository_ListReviews_Call {
+	return &ReviewRepository_ListReviews_Call{Call: _e.mock.On("ListReviews", ctx, status, limit)}
+}
+
+func (_c *ReviewRepository_ListReviews_Call) Run(run func(ctx context.Context, status entity.ReviewStatus, limit int)) *ReviewRepository_ListReviews_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.ReviewStatus), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ReviewRepository_ListReviews_Call) Return(reviews []*entity.Review, err error) *ReviewRepository_ListReviews_Call {
+	_c.Call.Return(reviews, err)
+	return _c
+}
+
+func (_c *ReviewRepository_ListReviews_Call) RunAndReturn(run func(context.Context, entity.ReviewStatus, int) ([]*entity.Review, error)) *ReviewRepository_ListReviews_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveReview provides a mock function with given fields: ctx, workspaceID, short, status, reviewerID, reviewedAt
+func (_m *ReviewRepository) ResolveReview(ctx context.Context, workspaceID string, short string, status entity.ReviewStatus, reviewerID string, reviewedAt time.Time) error {
+	ret := _m.Called(ctx, workspaceID, short, status, reviewerID, reviewedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, entity.ReviewStatus, string, time.Time) error); ok {
+		r0 = rf(ctx, workspaceID, short, status, reviewerID, reviewedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReviewRepository_ResolveReview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveReview'
+type ReviewRepository_ResolveReview_Call struct {
+	*mock.Call
+}
+
+// ResolveReview is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - short string
+//   - status entity.ReviewStatus
+//   - reviewerID string
+//   - reviewedAt time.Time
+func (_e *ReviewRepository_Expecter) ResolveReview(ctx interface{}, workspaceID interface{}, short interface{}, status interface{}, reviewerID interface{}, reviewedAt interface{}) *ReviewRepository_ResolveReview_Call {
+	return &ReviewRepository_ResolveReview_Call{Call: _e.mock.On("ResolveReview", ctx, workspaceID, short, status, reviewerID, reviewedAt)}
+}
+
+func (_c *ReviewRepository_ResolveReview_Call) Run(run func(ctx context.Context, workspaceID string, short string, status entity.ReviewStatus, reviewerID string, reviewedAt time.Time)) *ReviewRepository_ResolveReview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(entity.ReviewStatus), args[4].(string), args[5].(time.Time))
+	})
+	return _c
+}
+
+func (_c *ReviewRepository_ResolveReview_Call) Return(err error) *ReviewRepository_ResolveReview_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ReviewRepository_ResolveReview_Call) RunAndReturn(run func(context.Context, string, string, entity.ReviewStatus, string, time.Time) error) *ReviewRepository_ResolveReview_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewReviewRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewReviewRepository creates a new instance of ReviewRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewReviewRepository(t mockConstructorTestingTNewReviewRepository) *ReviewRepository {
+	mock := &ReviewRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
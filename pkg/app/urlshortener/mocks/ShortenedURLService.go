@@ -4,24 +4,680 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 	entity "url-shortener/pkg/app/urlshortener/entity"
+	repository "url-shortener/pkg/app/urlshortener/repository"
+	service "url-shortener/pkg/app/urlshortener/service"
+	guard "url-shortener/pkg/guard"
+	quota "url-shortener/pkg/quota"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ShortenedURLService is an autogenerated mock type for the ShortenedURLService type
+type ShortenedURLService struct {
+	mock.Mock
+}
+
+type ShortenedURLService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ShortenedURLService) EXPECT() *ShortenedURLService_Expecter {
+	return &ShortenedURLService_Expecter{mock: &_m.Mock}
+}
+
+// BlockClient provides a mock function with given fields: ctx, client, duration
+func (_m *ShortenedURLService) BlockClient(ctx context.Context, client string, duration time.Duration) (*guard.Block, error) {
+	ret := _m.Called(ctx, client, duration)
+
+	var r0 *guard.Block
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (*guard.Block, error)); ok {
+		return rf(ctx, client, duration)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) *guard.Block); ok {
+		r0 = rf(ctx, client, duration)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*guard.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, client, duration)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_BlockClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BlockClient'
+type ShortenedURLService_BlockClient_Call struct {
+	*mock.Call
+}
+
+// BlockClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+//   - duration time.Duration
+func (_e *ShortenedURLService_Expecter) BlockClient(ctx interface{}, client interface{}, duration interface{}) *ShortenedURLService_BlockClient_Call {
+	return &ShortenedURLService_BlockClient_Call{Call: _e.mock.On("BlockClient", ctx, client, duration)}
+}
+
+func (_c *ShortenedURLService_BlockClient_Call) Run(run func(ctx context.Context, client string, duration time.Duration)) *ShortenedURLService_BlockClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_BlockClient_Call) Return(block *guard.Block, err error) *ShortenedURLService_BlockClient_Call {
+	_c.Call.Return(block, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_BlockClient_Call) RunAndReturn(run func(context.Context, string, time.Duration) (*guard.Block, error)) *ShortenedURLService_BlockClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteShortenedURL provides a mock function with given fields: ctx, short
+func (_m *ShortenedURLService) DeleteShortenedURL(ctx context.Context, short string) error {
+	ret := _m.Called(ctx, short)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, short)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ShortenedURLService_DeleteShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteShortenedURL'
+type ShortenedURLService_DeleteShortenedURL_Call struct {
+	*mock.Call
+}
+
+// DeleteShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - short string
+func (_e *ShortenedURLService_Expecter) DeleteShortenedURL(ctx interface{}, short interface{}) *ShortenedURLService_DeleteShortenedURL_Call {
+	return &ShortenedURLService_DeleteShortenedURL_Call{Call: _e.mock.On("DeleteShortenedURL", ctx, short)}
+}
+
+func (_c *ShortenedURLService_DeleteShortenedURL_Call) Run(run func(ctx context.Context, short string)) *ShortenedURLService_DeleteShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_DeleteShortenedURL_Call) Return(err error) *ShortenedURLService_DeleteShortenedURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ShortenedURLService_DeleteShortenedURL_Call) RunAndReturn(run func(context.Context, string) error) *ShortenedURLService_DeleteShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetReport provides a mock function with given fields: ctx, id
+func (_m *ShortenedURLService) GetReport(ctx context.Context, id int64) (*entity.Report, []*entity.ModerationAction, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *entity.Report
+	var r1 []*entity.ModerationAction
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*entity.Report, []*entity.ModerationAction, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *entity.Report); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) []*entity.ModerationAction); ok {
+		r1 = rf(ctx, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*entity.ModerationAction)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64) error); ok {
+		r2 = rf(ctx, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ShortenedURLService_GetReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetReport'
+type ShortenedURLService_GetReport_Call struct {
+	*mock.Call
+}
+
+// GetReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *ShortenedURLService_Expecter) GetReport(ctx interface{}, id interface{}) *ShortenedURLService_GetReport_Call {
+	return &ShortenedURLService_GetReport_Call{Call: _e.mock.On("GetReport", ctx, id)}
+}
+
+func (_c *ShortenedURLService_GetReport_Call) Run(run func(ctx context.Context, id int64)) *ShortenedURLService_GetReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_GetReport_Call) Return(report *entity.Report, actions []*entity.ModerationAction, err error) *ShortenedURLService_GetReport_Call {
+	_c.Call.Return(report, actions, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_GetReport_Call) RunAndReturn(run func(context.Context, int64) (*entity.Report, []*entity.ModerationAction, error)) *ShortenedURLService_GetReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBlockedClients provides a mock function with given fields: ctx
+func (_m *ShortenedURLService) ListBlockedClients(ctx context.Context) ([]*guard.Block, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*guard.Block
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*guard.Block, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*guard.Block); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*guard.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_ListBlockedClients_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBlockedClients'
+type ShortenedURLService_ListBlockedClients_Call struct {
+	*mock.Call
+}
+
+// ListBlockedClients is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *ShortenedURLService_Expecter) ListBlockedClients(ctx interface{}) *ShortenedURLService_ListBlockedClients_Call {
+	return &ShortenedURLService_ListBlockedClients_Call{Call: _e.mock.On("ListBlockedClients", ctx)}
+}
+
+func (_c *ShortenedURLService_ListBlockedClients_Call) Run(run func(ctx context.Context)) *ShortenedURLService_ListBlockedClients_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_ListBlockedClients_Call) Return(blocks []*guard.Block, err error) *ShortenedURLService_ListBlockedClients_Call {
+	_c.Call.Return(blocks, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_ListBlockedClients_Call) RunAndReturn(run func(context.Context) ([]*guard.Block, error)) *ShortenedURLService_ListBlockedClients_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListReports provides a mock function with given fields: ctx, status, limit
+func (_m *ShortenedURLService) ListReports(ctx context.Context, status entity.ReportStatus, limit int) ([]*entity.Report, error) {
+	ret := _m.Called(ctx, status, limit)
+
+	var r0 []*entity.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReportStatus, int) ([]*entity.Report, error)); ok {
+		return rf(ctx, status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReportStatus, int) []*entity.Report); ok {
+		r0 = rf(ctx, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.ReportStatus, int) error); ok {
+		r1 = rf(ctx, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_ListReports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListReports'
+type ShortenedURLService_ListReports_Call struct {
+	*mock.Call
+}
+
+// ListReports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - status entity.ReportStatus
+//   - limit int
+func (_e *ShortenedURLService_Expecter) ListReports(ctx interface{}, status interface{}, limit interface{}) *ShortenedURLService_ListReports_Call {
+	return &ShortenedURLService_ListReports_Call{Call: _e.mock.On("ListReports", ctx, status, limit)}
+}
+
+func (_c *ShortenedURLService_ListReports_Call) Run(run func(ctx context.Context, status entity.ReportStatus, limit int)) *ShortenedURLService_ListReports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.ReportStatus), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_ListReports_Call) Return(reports []*entity.Report, err error) *ShortenedURLService_ListReports_Call {
+	_c.Call.Return(reports, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_ListReports_Call) RunAndReturn(run func(context.Context, entity.ReportStatus, int) ([]*entity.Report, error)) *ShortenedURLService_ListReports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListReviews provides a mock function with given fields: ctx, status, limit
+func (_m *ShortenedURLService) ListReviews(ctx context.Context, status entity.ReviewStatus, limit int) ([]*entity.Review, error) {
+	ret := _m.Called(ctx, status, limit)
+
+	var r0 []*entity.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReviewStatus, int) ([]*entity.Review, error)); ok {
+		return rf(ctx, status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ReviewStatus, int) []*entity.Review); ok {
+		r0 = rf(ctx, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.ReviewStatus, int) error); ok {
+		r1 = rf(ctx, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_ListReviews_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListReviews'
+type ShortenedURLService_ListReviews_Call struct {
+	*mock.Call
+}
+
+// ListReviews is a helper method to define mock.On call
+//   - ctx context.Context
+//   - status entity.ReviewStatus
+//   - limit int
+func (_e *ShortenedURLService_Expecter) ListReviews(ctx interface{}, status interface{}, limit interface{}) *ShortenedURLService_ListReviews_Call {
+	return &ShortenedURLService_ListReviews_Call{Call: _e.mock.On("ListReviews", ctx, status, limit)}
+}
+
+func (_c *ShortenedURLService_ListReviews_Call) Run(run func(ctx context.Context, status entity.ReviewStatus, limit int)) *ShortenedURLService_ListReviews_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.ReviewStatus), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_ListReviews_Call) Return(reviews []*entity.Review, err error) *ShortenedURLService_ListReviews_Call {
+	_c.Call.Return(reviews, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_ListReviews_Call) RunAndReturn(run func(context.Context, entity.ReviewStatus, int) ([]*entity.Review, error)) *ShortenedURLService_ListReviews_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LookupShortenedURL provides a mock function with given fields: ctx, short
+func (_m *ShortenedURLService) LookupShortenedURL(ctx context.Context, short string) (*entity.ShortenedURL, error) {
+	ret := _m.Called(ctx, short)
+
+	var r0 *entity.ShortenedURL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.ShortenedURL, error)); ok {
+		return rf(ctx, short)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.ShortenedURL); ok {
+		r0 = rf(ctx, short)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ShortenedURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, short)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_LookupShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LookupShortenedURL'
+type ShortenedURLService_LookupShortenedURL_Call struct {
+	*mock.Call
+}
+
+// LookupShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - short string
+func (_e *ShortenedURLService_Expecter) LookupShortenedURL(ctx interface{}, short interface{}) *ShortenedURLService_LookupShortenedURL_Call {
+	return &ShortenedURLService_LookupShortenedURL_Call{Call: _e.mock.On("LookupShortenedURL", ctx, short)}
+}
+
+func (_c *ShortenedURLService_LookupShortenedURL_Call) Run(run func(ctx context.Context, short string)) *ShortenedURLService_LookupShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_LookupShortenedURL_Call) Return(shortenedURL *entity.ShortenedURL, err error) *ShortenedURLService_LookupShortenedURL_Call {
+	_c.Call.Return(shortenedURL, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_LookupShortenedURL_Call) RunAndReturn(run func(context.Context, string) (*entity.ShortenedURL, error)) *ShortenedURLService_LookupShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LookupShortenedURLs provides a mock function with given fields: ctx, shorts
+func (_m *ShortenedURLService) LookupShortenedURLs(ctx context.Context, shorts []string) []*service.LookupResult {
+	ret := _m.Called(ctx, shorts)
+
+	var r0 []*service.LookupResult
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*service.LookupResult); ok {
+		r0 = rf(ctx, shorts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*service.LookupResult)
+		}
+	}
+
+	return r0
+}
+
+// ShortenedURLService_LookupShortenedURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LookupShortenedURLs'
+type ShortenedURLService_LookupShortenedURLs_Call struct {
+	*mock.Call
+}
+
+// LookupShortenedURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shorts []string
+func (_e *ShortenedURLService_Expecter) LookupShortenedURLs(ctx interface{}, shorts interface{}) *ShortenedURLService_LookupShortenedURLs_Call {
+	return &ShortenedURLService_LookupShortenedURLs_Call{Call: _e.mock.On("LookupShortenedURLs", ctx, shorts)}
+}
+
+func (_c *ShortenedURLService_LookupShortenedURLs_Call) Run(run func(ctx context.Context, shorts []string)) *ShortenedURLService_LookupShortenedURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_LookupShortenedURLs_Call) Return(results []*service.LookupResult) *ShortenedURLService_LookupShortenedURLs_Call {
+	_c.Call.Return(results)
+	return _c
+}
+
+func (_c *ShortenedURLService_LookupShortenedURLs_Call) RunAndReturn(run func(context.Context, []string) []*service.LookupResult) *ShortenedURLService_LookupShortenedURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QuotaUsage provides a mock function with given fields: ctx
+func (_m *ShortenedURLService) QuotaUsage(ctx context.Context) ([]*quota.Usage, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*quota.Usage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*quota.Usage, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*quota.Usage); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*quota.Usage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_QuotaUsage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QuotaUsage'
+type ShortenedURLService_QuotaUsage_Call struct {
+	*mock.Call
+}
+
+// QuotaUsage is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *ShortenedURLService_Expecter) QuotaUsage(ctx interface{}) *ShortenedURLService_QuotaUsage_Call {
+	return &ShortenedURLService_QuotaUsage_Call{Call: _e.mock.On("QuotaUsage", ctx)}
+}
+
+func (_c *ShortenedURLService_QuotaUsage_Call) Run(run func(ctx context.Context)) *ShortenedURLService_QuotaUsage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_QuotaUsage_Call) Return(usages []*quota.Usage, err error) *ShortenedURLService_QuotaUsage_Call {
+	_c.Call.Return(usages, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_QuotaUsage_Call) RunAndReturn(run func(context.Context) ([]*quota.Usage, error)) *ShortenedURLService_QuotaUsage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReportShortenedURL provides a mock function with given fields: ctx, short, reason, contact
+func (_m *ShortenedURLService) ReportShortenedURL(ctx context.Context, short string, reason string, contact string) (*entity.Report, error) {
+	ret := _m.Called(ctx, short, reason, contact)
+
+	var r0 *entity.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*entity.Report, error)); ok {
+		return rf(ctx, short, reason, contact)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *entity.Report); ok {
+		r0 = rf(ctx, short, reason, contact)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, short, reason, contact)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_ReportShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReportShortenedURL'
+type ShortenedURLService_ReportShortenedURL_Call struct {
+	*mock.Call
+}
+
+// ReportShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - short string
+//   - reason string
+//   - contact string
+func (_e *ShortenedURLService_Expecter) ReportShortenedURL(ctx interface{}, short interface{}, reason interface{}, contact interface{}) *ShortenedURLService_ReportShortenedURL_Call {
+	return &ShortenedURLService_ReportShortenedURL_Call{Call: _e.mock.On("ReportShortenedURL", ctx, short, reason, contact)}
+}
+
+func (_c *ShortenedURLService_ReportShortenedURL_Call) Run(run func(ctx context.Context, short string, reason string, contact string)) *ShortenedURLService_ReportShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
 
-	mock "github.com/stretchr/testify/mock"
+func (_c *ShortenedURLService_ReportShortenedURL_Call) Return(report *entity.Report, err error) *ShortenedURLService_ReportShortenedURL_Call {
+	_c.Call.Return(report, err)
+	return _c
+}
 
-	service "url-shortener/pkg/app/urlshortener/service"
-)
+func (_c *ShortenedURLService_ReportShortenedURL_Call) RunAndReturn(run func(context.Context, string, string, string) (*entity.Report, error)) *ShortenedURLService_ReportShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
 
-// ShortenedURLService is an autogenerated mock type for the ShortenedURLService type
-type ShortenedURLService struct {
-	mock.Mock
+// ResolveReport provides a mock function with given fields: ctx, id, resolution, note
+func (_m *ShortenedURLService) ResolveReport(ctx context.Context, id int64, resolution entity.Resolution, note string) (*entity.Report, error) {
+	ret := _m.Called(ctx, id, resolution, note)
+
+	var r0 *entity.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, entity.Resolution, string) (*entity.Report, error)); ok {
+		return rf(ctx, id, resolution, note)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, entity.Resolution, string) *entity.Report); ok {
+		r0 = rf(ctx, id, resolution, note)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, entity.Resolution, string) error); ok {
+		r1 = rf(ctx, id, resolution, note)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-type ShortenedURLService_Expecter struct {
-	mock *mock.Mock
+// ShortenedURLService_ResolveReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveReport'
+type ShortenedURLService_ResolveReport_Call struct {
+	*mock.Call
 }
 
-func (_m *ShortenedURLService) EXPECT() *ShortenedURLService_Expecter {
-	return &ShortenedURLService_Expecter{mock: &_m.Mock}
+// ResolveReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - resolution entity.Resolution
+//   - note string
+func (_e *ShortenedURLService_Expecter) ResolveReport(ctx interface{}, id interface{}, resolution interface{}, note interface{}) *ShortenedURLService_ResolveReport_Call {
+	return &ShortenedURLService_ResolveReport_Call{Call: _e.mock.On("ResolveReport", ctx, id, resolution, note)}
+}
+
+func (_c *ShortenedURLService_ResolveReport_Call) Run(run func(ctx context.Context, id int64, resolution entity.Resolution, note string)) *ShortenedURLService_ResolveReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(entity.Resolution), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_ResolveReport_Call) Return(report *entity.Report, err error) *ShortenedURLService_ResolveReport_Call {
+	_c.Call.Return(report, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_ResolveReport_Call) RunAndReturn(run func(context.Context, int64, entity.Resolution, string) (*entity.Report, error)) *ShortenedURLService_ResolveReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveReview provides a mock function with given fields: ctx, short, status
+func (_m *ShortenedURLService) ResolveReview(ctx context.Context, short string, status entity.ReviewStatus) (*entity.Review, error) {
+	ret := _m.Called(ctx, short, status)
+
+	var r0 *entity.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.ReviewStatus) (*entity.Review, error)); ok {
+		return rf(ctx, short, status)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.ReviewStatus) *entity.Review); ok {
+		r0 = rf(ctx, short, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.ReviewStatus) error); ok {
+		r1 = rf(ctx, short, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_ResolveReview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveReview'
+type ShortenedURLService_ResolveReview_Call struct {
+	*mock.Call
+}
+
+// ResolveReview is a helper method to define mock.On call
+//   - ctx context.Context
+//   - short string
+//   - status entity.ReviewStatus
+func (_e *ShortenedURLService_Expecter) ResolveReview(ctx interface{}, short interface{}, status interface{}) *ShortenedURLService_ResolveReview_Call {
+	return &ShortenedURLService_ResolveReview_Call{Call: _e.mock.On("ResolveReview", ctx, short, status)}
+}
+
+func (_c *ShortenedURLService_ResolveReview_Call) Run(run func(ctx context.Context, short string, status entity.ReviewStatus)) *ShortenedURLService_ResolveReview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(entity.ReviewStatus))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_ResolveReview_Call) Return(review *entity.Review, err error) *ShortenedURLService_ResolveReview_Call {
+	_c.Call.Return(review, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_ResolveReview_Call) RunAndReturn(run func(context.Context, string, entity.ReviewStatus) (*entity.Review, error)) *ShortenedURLService_ResolveReview_Call {
+	_c.Call.Return(run)
+	return _c
 }
 
 // RetrieveShortenedURL provides a mock function with given fields: ctx, short
@@ -79,6 +735,68 @@ func (_c *ShortenedURLService_RetrieveShortenedURL_Call) RunAndReturn(run func(c
 	return _c
 }
 
+// SearchShortenedURLs provides a mock function with given fields: ctx, filter
+func (_m *ShortenedURLService) SearchShortenedURLs(ctx context.Context, filter *repository.SearchFilter) ([]*entity.ShortenedURL, string, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*entity.ShortenedURL
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.SearchFilter) ([]*entity.ShortenedURL, string, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *repository.SearchFilter) []*entity.ShortenedURL); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.ShortenedURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *repository.SearchFilter) string); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *repository.SearchFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ShortenedURLService_SearchShortenedURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchShortenedURLs'
+type ShortenedURLService_SearchShortenedURLs_Call struct {
+	*mock.Call
+}
+
+// SearchShortenedURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter *repository.SearchFilter
+func (_e *ShortenedURLService_Expecter) SearchShortenedURLs(ctx interface{}, filter interface{}) *ShortenedURLService_SearchShortenedURLs_Call {
+	return &ShortenedURLService_SearchShortenedURLs_Call{Call: _e.mock.On("SearchShortenedURLs", ctx, filter)}
+}
+
+func (_c *ShortenedURLService_SearchShortenedURLs_Call) Run(run func(ctx context.Context, filter *repository.SearchFilter)) *ShortenedURLService_SearchShortenedURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*repository.SearchFilter))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_SearchShortenedURLs_Call) Return(shortenedURLs []*entity.ShortenedURL, next string, err error) *ShortenedURLService_SearchShortenedURLs_Call {
+	_c.Call.Return(shortenedURLs, next, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_SearchShortenedURLs_Call) RunAndReturn(run func(context.Context, *repository.SearchFilter) ([]*entity.ShortenedURL, string, error)) *ShortenedURLService_SearchShortenedURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ShortURL provides a mock function with given fields: ctx, url, opts
 func (_m *ShortenedURLService) ShortURL(ctx context.Context, url string, opts *service.ShortURLOption) (*entity.ShortenedURL, error) {
 	ret := _m.Called(ctx, url, opts)
@@ -135,6 +853,251 @@ func (_c *ShortenedURLService_ShortURL_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// ShortURLs provides a mock function with given fields: ctx, items
+func (_m *ShortenedURLService) ShortURLs(ctx context.Context, items []*service.ShortURLItem) []*service.ShortURLResult {
+	ret := _m.Called(ctx, items)
+
+	var r0 []*service.ShortURLResult
+	if rf, ok := ret.Get(0).(func(context.Context, []*service.ShortURLItem) []*service.ShortURLResult); ok {
+		r0 = rf(ctx, items)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*service.ShortURLResult)
+		}
+	}
+
+	return r0
+}
+
+// ShortenedURLService_ShortURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShortURLs'
+type ShortenedURLService_ShortURLs_Call struct {
+	*mock.Call
+}
+
+// ShortURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - items []*service.ShortURLItem
+func (_e *ShortenedURLService_Expecter) ShortURLs(ctx interface{}, items interface{}) *ShortenedURLService_ShortURLs_Call {
+	return &ShortenedURLService_ShortURLs_Call{Call: _e.mock.On("ShortURLs", ctx, items)}
+}
+
+func (_c *ShortenedURLService_ShortURLs_Call) Run(run func(ctx context.Context, items []*service.ShortURLItem)) *ShortenedURLService_ShortURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]*service.ShortURLItem))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_ShortURLs_Call) Return(results []*service.ShortURLResult) *ShortenedURLService_ShortURLs_Call {
+	_c.Call.Return(results)
+	return _c
+}
+
+func (_c *ShortenedURLService_ShortURLs_Call) RunAndReturn(run func(context.Context, []*service.ShortURLItem) []*service.ShortURLResult) *ShortenedURLService_ShortURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TriageReport provides a mock function with given fields: ctx, id, note
+func (_m *ShortenedURLService) TriageReport(ctx context.Context, id int64, note string) (*entity.Report, error) {
+	ret := _m.Called(ctx, id, note)
+
+	var r0 *entity.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*entity.Report, error)); ok {
+		return rf(ctx, id, note)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *entity.Report); ok {
+		r0 = rf(ctx, id, note)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, id, note)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_TriageReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TriageReport'
+type ShortenedURLService_TriageReport_Call struct {
+	*mock.Call
+}
+
+// TriageReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - note string
+func (_e *ShortenedURLService_Expecter) TriageReport(ctx interface{}, id interface{}, note interface{}) *ShortenedURLService_TriageReport_Call {
+	return &ShortenedURLService_TriageReport_Call{Call: _e.mock.On("TriageReport", ctx, id, note)}
+}
+
+func (_c *ShortenedURLService_TriageReport_Call) Run(run func(ctx context.Context, id int64, note string)) *ShortenedURLService_TriageReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_TriageReport_Call) Return(report *entity.Report, err error) *ShortenedURLService_TriageReport_Call {
+	_c.Call.Return(report, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_TriageReport_Call) RunAndReturn(run func(context.Context, int64, string) (*entity.Report, error)) *ShortenedURLService_TriageReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnblockClient provides a mock function with given fields: ctx, client
+func (_m *ShortenedURLService) UnblockClient(ctx context.Context, client string) error {
+	ret := _m.Called(ctx, client)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ShortenedURLService_UnblockClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnblockClient'
+type ShortenedURLService_UnblockClient_Call struct {
+	*mock.Call
+}
+
+// UnblockClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+func (_e *ShortenedURLService_Expecter) UnblockClient(ctx interface{}, client interface{}) *ShortenedURLService_UnblockClient_Call {
+	return &ShortenedURLService_UnblockClient_Call{Call: _e.mock.On("UnblockClient", ctx, client)}
+}
+
+func (_c *ShortenedURLService_UnblockClient_Call) Run(run func(ctx context.Context, client string)) *ShortenedURLService_UnblockClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_UnblockClient_Call) Return(err error) *ShortenedURLService_UnblockClient_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ShortenedURLService_UnblockClient_Call) RunAndReturn(run func(context.Context, string) error) *ShortenedURLService_UnblockClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlockShortenedURL provides a mock function with given fields: ctx, short, password, client
+func (_m *ShortenedURLService) UnlockShortenedURL(ctx context.Context, short string, password string, client string) error {
+	ret := _m.Called(ctx, short, password, client)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, short, password, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ShortenedURLService_UnlockShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlockShortenedURL'
+type ShortenedURLService_UnlockShortenedURL_Call struct {
+	*mock.Call
+}
+
+// UnlockShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - short string
+//   - password string
+//   - client string
+func (_e *ShortenedURLService_Expecter) UnlockShortenedURL(ctx interface{}, short interface{}, password interface{}, client interface{}) *ShortenedURLService_UnlockShortenedURL_Call {
+	return &ShortenedURLService_UnlockShortenedURL_Call{Call: _e.mock.On("UnlockShortenedURL", ctx, short, password, client)}
+}
+
+func (_c *ShortenedURLService_UnlockShortenedURL_Call) Run(run func(ctx context.Context, short string, password string, client string)) *ShortenedURLService_UnlockShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_UnlockShortenedURL_Call) Return(err error) *ShortenedURLService_UnlockShortenedURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ShortenedURLService_UnlockShortenedURL_Call) RunAndReturn(run func(context.Context, string, string, string) error) *ShortenedURLService_UnlockShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateShortenedURL provides a mock function with given fields: ctx, short, opts
+func (_m *ShortenedURLService) UpdateShortenedURL(ctx context.Context, short string, opts *service.UpdateOption) (*entity.ShortenedURL, error) {
+	ret := _m.Called(ctx, short, opts)
+
+	var r0 *entity.ShortenedURL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *service.UpdateOption) (*entity.ShortenedURL, error)); ok {
+		return rf(ctx, short, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *service.UpdateOption) *entity.ShortenedURL); ok {
+		r0 = rf(ctx, short, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ShortenedURL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *service.UpdateOption) error); ok {
+		r1 = rf(ctx, short, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ShortenedURLService_UpdateShortenedURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateShortenedURL'
+type ShortenedURLService_UpdateShortenedURL_Call struct {
+	*mock.Call
+}
+
+// UpdateShortenedURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - short string
+//   - opts *service.UpdateOption
+func (_e *ShortenedURLService_Expecter) UpdateShortenedURL(ctx interface{}, short interface{}, opts interface{}) *ShortenedURLService_UpdateShortenedURL_Call {
+	return &ShortenedURLService_UpdateShortenedURL_Call{Call: _e.mock.On("UpdateShortenedURL", ctx, short, opts)}
+}
+
+func (_c *ShortenedURLService_UpdateShortenedURL_Call) Run(run func(ctx context.Context, short string, opts *service.UpdateOption)) *ShortenedURLService_UpdateShortenedURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*service.UpdateOption))
+	})
+	return _c
+}
+
+func (_c *ShortenedURLService_UpdateShortenedURL_Call) Return(shortenedURL *entity.ShortenedURL, err error) *ShortenedURLService_UpdateShortenedURL_Call {
+	_c.Call.Return(shortenedURL, err)
+	return _c
+}
+
+func (_c *ShortenedURLService_UpdateShortenedURL_Call) RunAndReturn(run func(context.Context, string, *service.UpdateOption) (*entity.ShortenedURL, error)) *ShortenedURLService_UpdateShortenedURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 type mockConstructorTestingTNewShortenedURLService interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,379 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "url-shortener/pkg/app/urlshortener/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// WorkspaceRepository is an autogenerated mock type for the WorkspaceRepository type
+type WorkspaceRepository struct {
+	mock.Mock
+}
+
+type WorkspaceRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *WorkspaceRepository) EXPECT() *WorkspaceRepository_Expecter {
+	return &WorkspaceRepository_Expecter{mock: &_m.Mock}
+}
+
+// AddDomain provides a mock function with given fields: ctx, domain
+func (_m *WorkspaceRepository) AddDomain(ctx context.Context, domain *entity.Domain) error {
+	ret := _m.Called(ctx, domain)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Domain) error); ok {
+		r0 = rf(ctx, domain)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WorkspaceRepository_AddDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddDomain'
+type WorkspaceRepository_AddDomain_Call struct {
+	*mock.Call
+}
+
+// AddDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - domain *entity.Domain
+func (_e *WorkspaceRepository_Expecter) AddDomain(ctx interface{}, domain interface{}) *WorkspaceRepository_AddDomain_Call {
+	return &WorkspaceRepository_AddDomain_Call{Call: _e.mock.On("AddDomain", ctx, domain)}
+}
+
+func (_c *WorkspaceRepository_AddDomain_Call) Run(run func(ctx context.Context, domain *entity.Domain)) *WorkspaceRepository_AddDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Domain))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_AddDomain_Call) Return(err error) *WorkspaceRepository_AddDomain_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_AddDomain_Call) RunAndReturn(run func(context.Context, *entity.Domain) error) *WorkspaceRepository_AddDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWorkspace provides a mock function with given fields: ctx, workspace
+func (_m *WorkspaceRepository) CreateWorkspace(ctx context.Context, workspace *entity.Workspace) error {
+	ret := _m.Called(ctx, workspace)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Workspace) error); ok {
+		r0 = rf(ctx, workspace)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WorkspaceRepository_CreateWorkspace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWorkspace'
+type WorkspaceRepository_CreateWorkspace_Call struct {
+	*mock.Call
+}
+
+// CreateWorkspace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspace *entity.Workspace
+func (_e *WorkspaceRepository_Expecter) CreateWorkspace(ctx interface{}, workspace interface{}) *WorkspaceRepository_CreateWorkspace_Call {
+	return &WorkspaceRepository_CreateWorkspace_Call{Call: _e.mock.On("CreateWorkspace", ctx, workspace)}
+}
+
+func (_c *WorkspaceRepository_CreateWorkspace_Call) Run(run func(ctx context.Context, workspace *entity.Workspace)) *WorkspaceRepository_CreateWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Workspace))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_CreateWorkspace_Call) Return(err error) *WorkspaceRepository_CreateWorkspace_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_CreateWorkspace_Call) RunAndReturn(run func(context.Context, *entity.Workspace) error) *WorkspaceRepository_CreateWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindDomain provides a mock function with given fields: ctx, host
+func (_m *WorkspaceRepository) FindDomain(ctx context.Context, host string) (*entity.Domain, error) {
+	ret := _m.Called(ctx, host)
+
+	var r0 *entity.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Domain, error)); ok {
+		return rf(ctx, host)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Domain); ok {
+		r0 = rf(ctx, host)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceRepository_FindDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindDomain'
+type WorkspaceRepository_FindDomain_Call struct {
+	*mock.Call
+}
+
+// FindDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - host string
+func (_e *WorkspaceRepository_Expecter) FindDomain(ctx interface{}, host interface{}) *WorkspaceRepository_FindDomain_Call {
+	return &WorkspaceRepository_FindDomain_Call{Call: _e.mock.On("FindDomain", ctx, host)}
+}
+
+func (_c *WorkspaceRepository_FindDomain_Call) Run(run func(ctx context.Context, host string)) *WorkspaceRepository_FindDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_FindDomain_Call) Return(domain *entity.Domain, err error) *WorkspaceRepository_FindDomain_Call {
+	_c.Call.Return(domain, err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_FindDomain_Call) RunAndReturn(run func(context.Context, string) (*entity.Domain, error)) *WorkspaceRepository_FindDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindWorkspace provides a mock function with given fields: ctx, id
+func (_m *WorkspaceRepository) FindWorkspace(ctx context.Context, id string) (*entity.Workspace, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Workspace, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Workspace); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceRepository_FindWorkspace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindWorkspace'
+type WorkspaceRepository_FindWorkspace_Call struct {
+	*mock.Call
+}
+
+// FindWorkspace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *WorkspaceRepository_Expecter) FindWorkspace(ctx interface{}, id interface{}) *WorkspaceRepository_FindWorkspace_Call {
+	return &WorkspaceRepository_FindWorkspace_Call{Call: _e.mock.On("FindWorkspace", ctx, id)}
+}
+
+func (_c *WorkspaceRepository_FindWorkspace_Call) Run(run func(ctx context.Context, id string)) *WorkspaceRepository_FindWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_FindWorkspace_Call) Return(workspace *entity.Workspace, err error) *WorkspaceRepository_FindWorkspace_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_FindWorkspace_Call) RunAndReturn(run func(context.Context, string) (*entity.Workspace, error)) *WorkspaceRepository_FindWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWorkspaces provides a mock function with given fields: ctx, ownerID
+func (_m *WorkspaceRepository) ListWorkspaces(ctx context.Context, ownerID string) ([]*entity.Workspace, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []*entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*entity.Workspace, error)); ok {
+		return rf(ctx, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*entity.Workspace); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceRepository_ListWorkspaces_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWorkspaces'
+type WorkspaceRepository_ListWorkspaces_Call struct {
+	*mock.Call
+}
+
+// ListWorkspaces is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ownerID string
+func (_e *WorkspaceRepository_Expecter) ListWorkspaces(ctx interface{}, ownerID interface{}) *WorkspaceRepository_ListWorkspaces_Call {
+	return &WorkspaceRepository_ListWorkspaces_Call{Call: _e.mock.On("ListWorkspaces", ctx, ownerID)}
+}
+
+func (_c *WorkspaceRepository_ListWorkspaces_Call) Run(run func(ctx context.Context, ownerID string)) *WorkspaceRepository_ListWorkspaces_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_ListWorkspaces_Call) Return(workspaces []*entity.Workspace, err error) *WorkspaceRepository_ListWorkspaces_Call {
+	_c.Call.Return(workspaces, err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_ListWorkspaces_Call) RunAndReturn(run func(context.Context, string) ([]*entity.Workspace, error)) *WorkspaceRepository_ListWorkspaces_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFallbackURL provides a mock function with given fields: ctx, id, fallbackURL
+func (_m *WorkspaceRepository) SetFallbackURL(ctx context.Context, id string, fallbackURL string) error {
+	ret := _m.Called(ctx, id, fallbackURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, fallbackURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WorkspaceRepository_SetFallbackURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFallbackURL'
+type WorkspaceRepository_SetFallbackURL_Call struct {
+	*mock.Call
+}
+
+// SetFallbackURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - fallbackURL string
+func (_e *WorkspaceRepository_Expecter) SetFallbackURL(ctx interface{}, id interface{}, fallbackURL interface{}) *WorkspaceRepository_SetFallbackURL_Call {
+	return &WorkspaceRepository_SetFallbackURL_Call{Call: _e.mock.On("SetFallbackURL", ctx, id, fallbackURL)}
+}
+
+func (_c *WorkspaceRepository_SetFallbackURL_Call) Run(run func(ctx context.Context, id string, fallbackURL string)) *WorkspaceRepository_SetFallbackURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_SetFallbackURL_Call) Return(err error) *WorkspaceRepository_SetFallbackURL_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_SetFallbackURL_Call) RunAndReturn(run func(context.Context, string, string) error) *WorkspaceRepository_SetFallbackURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyDomain provides a mock function with given fields: ctx, host, verifiedAt
+func (_m *WorkspaceRepository) VerifyDomain(ctx context.Context, host string, verifiedAt time.Time) error {
+	ret := _m.Called(ctx, host, verifiedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, host, verifiedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WorkspaceRepository_VerifyDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyDomain'
+type WorkspaceRepository_VerifyDomain_Call struct {
+	*mock.Call
+}
+
+// VerifyDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - host string
+//   - verifiedAt time.Time
+func (_e *WorkspaceRepository_Expecter) VerifyDomain(ctx interface{}, host interface{}, verifiedAt interface{}) *WorkspaceRepository_VerifyDomain_Call {
+	return &WorkspaceRepository_VerifyDomain_Call{Call: _e.mock.On("VerifyDomain", ctx, host, verifiedAt)}
+}
+
+func (_c *WorkspaceRepository_VerifyDomain_Call) Run(run func(ctx context.Context, host string, verifiedAt time.Time)) *WorkspaceRepository_VerifyDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *WorkspaceRepository_VerifyDomain_Call) Return(err error) *WorkspaceRepository_VerifyDomain_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *WorkspaceRepository_VerifyDomain_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *WorkspaceRepository_VerifyDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewWorkspaceRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWorkspaceRepository creates a new instance of WorkspaceRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWorkspaceRepository(t mockConstructorTestingTNewWorkspaceRepository) *WorkspaceRepository {
+	mock := &WorkspaceRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
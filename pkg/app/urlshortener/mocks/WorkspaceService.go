@@ -0,0 +1,427 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "url-shortener/pkg/app/urlshortener/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkspaceService is an autogenerated mock type for the WorkspaceService type
+type WorkspaceService struct {
+	mock.Mock
+}
+
+type WorkspaceService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *WorkspaceService) EXPECT() *WorkspaceService_Expecter {
+	return &WorkspaceService_Expecter{mock: &_m.Mock}
+}
+
+// AddDomain provides a mock function with given fields: ctx, workspaceID, host
+func (_m *WorkspaceService) AddDomain(ctx context.Context, workspaceID string, host string) (*entity.Domain, error) {
+	ret := _m.Called(ctx, workspaceID, host)
+
+	var r0 *entity.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.Domain, error)); ok {
+		return rf(ctx, workspaceID, host)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.Domain); ok {
+		r0 = rf(ctx, workspaceID, host)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workspaceID, host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_AddDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddDomain'
+type WorkspaceService_AddDomain_Call struct {
+	*mock.Call
+}
+
+// AddDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workspaceID string
+//   - host string
+func (_e *WorkspaceService_Expecter) AddDomain(ctx interface{}, workspaceID interface{}, host interface{}) *WorkspaceService_AddDomain_Call {
+	return &WorkspaceService_AddDomain_Call{Call: _e.mock.On("AddDomain", ctx, workspaceID, host)}
+}
+
+func (_c *WorkspaceService_AddDomain_Call) Run(run func(ctx context.Context, workspaceID string, host string)) *WorkspaceService_AddDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_AddDomain_Call) Return(domain *entity.Domain, err error) *WorkspaceService_AddDomain_Call {
+	_c.Call.Return(domain, err)
+	return _c
+}
+
+func (_c *WorkspaceService_AddDomain_Call) RunAndReturn(run func(context.Context, string, string) (*entity.Domain, error)) *WorkspaceService_AddDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWorkspace provides a mock function with given fields: ctx, id, name, ownerID
+func (_m *WorkspaceService) CreateWorkspace(ctx context.Context, id string, name string, ownerID string) (*entity.Workspace, error) {
+	ret := _m.Called(ctx, id, name, ownerID)
+
+	var r0 *entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*entity.Workspace, error)); ok {
+		return rf(ctx, id, name, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *entity.Workspace); ok {
+		r0 = rf(ctx, id, name, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, id, name, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_CreateWorkspace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWorkspace'
+type WorkspaceService_CreateWorkspace_Call struct {
+	*mock.Call
+}
+
+// CreateWorkspace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - name string
+//   - ownerID string
+func (_e *WorkspaceService_Expecter) CreateWorkspace(ctx interface{}, id interface{}, name interface{}, ownerID interface{}) *WorkspaceService_CreateWorkspace_Call {
+	return &WorkspaceService_CreateWorkspace_Call{Call: _e.mock.On("CreateWorkspace", ctx, id, name, ownerID)}
+}
+
+func (_c *WorkspaceService_CreateWorkspace_Call) Run(run func(ctx context.Context, id string, name string, ownerID string)) *WorkspaceService_CreateWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_CreateWorkspace_Call) Return(workspace *entity.Workspace, err error) *WorkspaceService_CreateWorkspace_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *WorkspaceService_CreateWorkspace_Call) RunAndReturn(run func(context.Context, string, string, string) (*entity.Workspace, error)) *WorkspaceService_CreateWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindWorkspace provides a mock function with given fields: ctx, id
+func (_m *WorkspaceService) FindWorkspace(ctx context.Context, id string) (*entity.Workspace, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Workspace, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Workspace); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_FindWorkspace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindWorkspace'
+type WorkspaceService_FindWorkspace_Call struct {
+	*mock.Call
+}
+
+// FindWorkspace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *WorkspaceService_Expecter) FindWorkspace(ctx interface{}, id interface{}) *WorkspaceService_FindWorkspace_Call {
+	return &WorkspaceService_FindWorkspace_Call{Call: _e.mock.On("FindWorkspace", ctx, id)}
+}
+
+func (_c *WorkspaceService_FindWorkspace_Call) Run(run func(ctx context.Context, id string)) *WorkspaceService_FindWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_FindWorkspace_Call) Return(workspace *entity.Workspace, err error) *WorkspaceService_FindWorkspace_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *WorkspaceService_FindWorkspace_Call) RunAndReturn(run func(context.Context, string) (*entity.Workspace, error)) *WorkspaceService_FindWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWorkspaces provides a mock function with given fields: ctx, ownerID
+func (_m *WorkspaceService) ListWorkspaces(ctx context.Context, ownerID string) ([]*entity.Workspace, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []*entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*entity.Workspace, error)); ok {
+		return rf(ctx, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*entity.Workspace); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_ListWorkspaces_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWorkspaces'
+type WorkspaceService_ListWorkspaces_Call struct {
+	*mock.Call
+}
+
+// ListWorkspaces is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ownerID string
+func (_e *WorkspaceService_Expecter) ListWorkspaces(ctx interface{}, ownerID interface{}) *WorkspaceService_ListWorkspaces_Call {
+	return &WorkspaceService_ListWorkspaces_Call{Call: _e.mock.On("ListWorkspaces", ctx, ownerID)}
+}
+
+func (_c *WorkspaceService_ListWorkspaces_Call) Run(run func(ctx context.Context, ownerID string)) *WorkspaceService_ListWorkspaces_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_ListWorkspaces_Call) Return(workspaces []*entity.Workspace, err error) *WorkspaceService_ListWorkspaces_Call {
+	_c.Call.Return(workspaces, err)
+	return _c
+}
+
+func (_c *WorkspaceService_ListWorkspaces_Call) RunAndReturn(run func(context.Context, string) ([]*entity.Workspace, error)) *WorkspaceService_ListWorkspaces_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveHost provides a mock function with given fields: ctx, host
+func (_m *WorkspaceService) ResolveHost(ctx context.Context, host string) (*entity.Workspace, error) {
+	ret := _m.Called(ctx, host)
+
+	var r0 *entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Workspace, error)); ok {
+		return rf(ctx, host)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Workspace); ok {
+		r0 = rf(ctx, host)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_ResolveHost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveHost'
+type WorkspaceService_ResolveHost_Call struct {
+	*mock.Call
+}
+
+// ResolveHost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - host string
+func (_e *WorkspaceService_Expecter) ResolveHost(ctx interface{}, host interface{}) *WorkspaceService_ResolveHost_Call {
+	return &WorkspaceService_ResolveHost_Call{Call: _e.mock.On("ResolveHost", ctx, host)}
+}
+
+func (_c *WorkspaceService_ResolveHost_Call) Run(run func(ctx context.Context, host string)) *WorkspaceService_ResolveHost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_ResolveHost_Call) Return(workspace *entity.Workspace, err error) *WorkspaceService_ResolveHost_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *WorkspaceService_ResolveHost_Call) RunAndReturn(run func(context.Context, string) (*entity.Workspace, error)) *WorkspaceService_ResolveHost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFallbackURL provides a mock function with given fields: ctx, id, fallbackURL
+func (_m *WorkspaceService) SetFallbackURL(ctx context.Context, id string, fallbackURL string) (*entity.Workspace, error) {
+	ret := _m.Called(ctx, id, fallbackURL)
+
+	var r0 *entity.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.Workspace, error)); ok {
+		return rf(ctx, id, fallbackURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.Workspace); ok {
+		r0 = rf(ctx, id, fallbackURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, fallbackURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_SetFallbackURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFallbackURL'
+type WorkspaceService_SetFallbackURL_Call struct {
+	*mock.Call
+}
+
+// SetFallbackURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - fallbackURL string
+func (_e *WorkspaceService_Expecter) SetFallbackURL(ctx interface{}, id interface{}, fallbackURL interface{}) *WorkspaceService_SetFallbackURL_Call {
+	return &WorkspaceService_SetFallbackURL_Call{Call: _e.mock.On("SetFallbackURL", ctx, id, fallbackURL)}
+}
+
+func (_c *WorkspaceService_SetFallbackURL_Call) Run(run func(ctx context.Context, id string, fallbackURL string)) *WorkspaceService_SetFallbackURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_SetFallbackURL_Call) Return(workspace *entity.Workspace, err error) *WorkspaceService_SetFallbackURL_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *WorkspaceService_SetFallbackURL_Call) RunAndReturn(run func(context.Context, string, string) (*entity.Workspace, error)) *WorkspaceService_SetFallbackURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyDomain provides a mock function with given fields: ctx, host
+func (_m *WorkspaceService) VerifyDomain(ctx context.Context, host string) (*entity.Domain, error) {
+	ret := _m.Called(ctx, host)
+
+	var r0 *entity.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Domain, error)); ok {
+		return rf(ctx, host)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Domain); ok {
+		r0 = rf(ctx, host)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WorkspaceService_VerifyDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyDomain'
+type WorkspaceService_VerifyDomain_Call struct {
+	*mock.Call
+}
+
+// VerifyDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - host string
+func (_e *WorkspaceService_Expecter) VerifyDomain(ctx interface{}, host interface{}) *WorkspaceService_VerifyDomain_Call {
+	return &WorkspaceService_VerifyDomain_Call{Call: _e.mock.On("VerifyDomain", ctx, host)}
+}
+
+func (_c *WorkspaceService_VerifyDomain_Call) Run(run func(ctx context.Context, host string)) *WorkspaceService_VerifyDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *WorkspaceService_VerifyDomain_Call) Return(domain *entity.Domain, err error) *WorkspaceService_VerifyDomain_Call {
+	_c.Call.Return(domain, err)
+	return _c
+}
+
+func (_c *WorkspaceService_VerifyDomain_Call) RunAndReturn(run func(context.Context, string) (*entity.Domain, error)) *WorkspaceService_VerifyDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewWorkspaceService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWorkspaceService creates a new instance of WorkspaceService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWorkspaceService(t mockConstructorTestingTNewWorkspaceService) *WorkspaceService {
+	mock := &WorkspaceService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
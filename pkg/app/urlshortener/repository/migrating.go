@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+// MigrationSource define which store is the source of truth
+type MigrationSource string
+
+const (
+	MigrationSourceOld MigrationSource = "old" // MigrationSourceOld read from the old store
+	MigrationSourceNew MigrationSource = "new" // MigrationSourceNew read from the new store
+)
+
+const (
+	shadowReadTimeout = 3 * time.Second
+)
+
+// MigrationConfig define online migration between two stores
+type MigrationConfig struct {
+	// Primary is the source of truth, reads are served by it and its write error fail the request
+	Primary MigrationSource `mapstructure:"primary"`
+
+	// ShadowReadRate is the ratio of reads which also read the secondary and compare, 0 ~ 1
+	ShadowReadRate float64 `mapstructure:"shadowReadRate"`
+}
+
+var _ Repository = &migratingRepo{}
+
+// migratingRepo is a Repository decorator which dual-write to old and new store
+type migratingRepo struct {
+	primary   Repository
+	secondary Repository
+	config    MigrationConfig
+}
+
+// NewMigrating Repository constructor which write to both old and new store
+func NewMigrating(old Repository, new Repository, config MigrationConfig) Repository {
+	repo := &migratingRepo{
+		primary:   old,
+		secondary: new,
+		config:    config,
+	}
+
+	if config.Primary == MigrationSourceNew {
+		repo.primary, repo.secondary = new, old
+	}
+
+	return repo
+}
+
+// StoreShortenedURL method is implementation for Repository
+// secondary write failure only log, backfill will copy the missing row
+func (repo *migratingRepo) StoreShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL) (err error) {
+	if err := repo.primary.StoreShortenedURL(ctx, shortenedURL); err != nil {
+		return err
+	}
+
+	if err := repo.secondary.StoreShortenedURL(ctx, shortenedURL); err != nil {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", shortenedURL.Key()).
+			Msg("migration failed to write secondary store")
+	}
+
+	return nil
+}
+
+// StoreShortenedURLs method is implementation for Repository
+// only rows stored by primary are written to secondary
+func (repo *migratingRepo) StoreShortenedURLs(ctx context.Context, shortenedURLs []*entity.ShortenedURL) (stored []string, err error) {
+	stored, err = repo.primary.StoreShortenedURLs(ctx, shortenedURLs)
+	if err != nil || len(stored) == 0 {
+		return stored, err
+	}
+
+	inserted := make(map[string]bool, len(stored))
+	for _, key := range stored {
+		inserted[key] = true
+	}
+
+	secondary := make([]*entity.ShortenedURL, 0, len(stored))
+	for _, shortenedURL := range shortenedURLs {
+		if inserted[shortenedURL.Key()] {
+			secondary = append(secondary, shortenedURL)
+		}
+	}
+
+	if _, err := repo.secondary.StoreShortenedURLs(ctx, secondary); err != nil {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Int("count", len(secondary)).
+			Msg("migration failed to write secondary store")
+	}
+
+	return stored, nil
+}
+
+// FindShortenedURL method is implementation for Repository
+func (repo *migratingRepo) FindShortenedURL(ctx context.Context, workspaceID string, short string) (shortenedURL *entity.ShortenedURL, err error) {
+	shortenedURL, err = repo.primary.FindShortenedURL(ctx, workspaceID, short)
+
+	if repo.config.ShadowReadRate > 0 && rand.Float64() < repo.config.ShadowReadRate {
+		go repo.shadowRead(log.Ctx(ctx).WithContext(context.Background()), workspaceID, short, shortenedURL, err)
+	}
+
+	return shortenedURL, err
+}
+
+// FindDuplicateShortenedURL method is implementation for Repository
+func (repo *migratingRepo) FindDuplicateShortenedURL(
+	ctx context.Context,
+	workspaceID string,
+	ownerID string,
+	originalURL string,
+	now time.Time,
+) (shortenedURL *entity.ShortenedURL, err error) {
+	return repo.primary.FindDuplicateShortenedURL(ctx, workspaceID, ownerID, originalURL, now)
+}
+
+// ListShortenedURLs method is implementation for Repository
+func (repo *migratingRepo) ListShortenedURLs(ctx context.Context, cursor string, limit int) (shortenedURLs []*entity.ShortenedURL, err error) {
+	return repo.primary.ListShortenedURLs(ctx, cursor, limit)
+}
+
+// SearchShortenedURLs method is implementation for Repository
+func (repo *migratingRepo) SearchShortenedURLs(ctx context.Context, filter *SearchFilter) (shortenedURLs []*entity.ShortenedURL, next string, err error) {
+	return repo.primary.SearchShortenedURLs(ctx, filter)
+}
+
+// UpdateShortenedURL method is implementation for Repository
+func (repo *migratingRepo) UpdateShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL) (err error) {
+	if err := repo.primary.UpdateShortenedURL(ctx, shortenedURL); err != nil {
+		return err
+	}
+
+	if err := repo.secondary.UpdateShortenedURL(ctx, shortenedURL); err != nil {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", shortenedURL.Key()).
+			Msg("migration failed to write secondary store")
+	}
+
+	return nil
+}
+
+// DisableShortenedURL method is implementation for Repository
+func (repo *migratingRepo) DisableShortenedURL(ctx context.Context, workspaceID string, short string, disabledAt time.Time) (err error) {
+	if err := repo.primary.DisableShortenedURL(ctx, workspaceID, short, disabledAt); err != nil {
+		return err
+	}
+
+	if err := repo.secondary.DisableShortenedURL(ctx, workspaceID, short, disabledAt); err != nil {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", entity.JoinKey(workspaceID, short)).
+			Msg("migration failed to write secondary store")
+	}
+
+	return nil
+}
+
+// UnflagShortenedURL method is implementation for Repository
+func (repo *migratingRepo) UnflagShortenedURL(ctx context.Context, workspaceID string, short string) (err error) {
+	if err := repo.primary.UnflagShortenedURL(ctx, workspaceID, short); err != nil {
+		return err
+	}
+
+	if err := repo.secondary.UnflagShortenedURL(ctx, workspaceID, short); err != nil {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", entity.JoinKey(workspaceID, short)).
+			Msg("migration failed to write secondary store")
+	}
+
+	return nil
+}
+
+// IncrementClicks method is implementation for Repository
+// clicks of primary is returned, secondary only follow it
+func (repo *migratingRepo) IncrementClicks(ctx context.Context, workspaceID string, short string) (clicks int64, err error) {
+	clicks, err = repo.primary.IncrementClicks(ctx, workspaceID, short)
+	if err != nil {
+		return clicks, err
+	}
+
+	// exhausted in secondary means backfill has not copied it yet or copied it after the click
+	if _, err := repo.secondary.IncrementClicks(ctx, workspaceID, short); err != nil && !errors.Is(err, errors.ErrShortenedURLExhausted) {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", entity.JoinKey(workspaceID, short)).
+			Msg("migration failed to write secondary store")
+	}
+
+	return clicks, nil
+}
+
+// DeleteShortenedURL method is implementation for Repository
+func (repo *migratingRepo) DeleteShortenedURL(ctx context.Context, workspaceID string, short string) (err error) {
+	if err := repo.primary.DeleteShortenedURL(ctx, workspaceID, short); err != nil {
+		return err
+	}
+
+	// not found in secondary means backfill has not copied it yet, nothing to delete
+	if err := repo.secondary.DeleteShortenedURL(ctx, workspaceID, short); err != nil && !errors.Is(err, errors.ErrResourceNotFound) {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", entity.JoinKey(workspaceID, short)).
+			Msg("migration failed to write secondary store")
+	}
+
+	return nil
+}
+
+// ImportShortenedURL method is implementation for Repository
+func (repo *migratingRepo) ImportShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL, mode ConflictMode) (stored bool, err error) {
+	stored, err = repo.primary.ImportShortenedURL(ctx, shortenedURL, mode)
+	if err != nil || !stored {
+		return stored, err
+	}
+
+	if _, err := repo.secondary.ImportShortenedURL(ctx, shortenedURL, ConflictOverwrite); err != nil {
+		log.Ctx(ctx).
+			Error().
+			Err(err).
+			Str("short", shortenedURL.Key()).
+			Msg("migration failed to write secondary store")
+	}
+
+	return stored, nil
+}
+
+// shadowRead read secondary store and log mismatch with the primary result
+func (repo *migratingRepo) shadowRead(ctx context.Context, workspaceID string, short string, expected *entity.ShortenedURL, expectedErr error) {
+	logger := log.Ctx(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, shadowReadTimeout)
+	defer cancel()
+
+	actual, err := repo.secondary.FindShortenedURL(ctx, workspaceID, short)
+
+	event := logger.Warn().Str("short", entity.JoinKey(workspaceID, short)).Str("primary", string(repo.config.Primary))
+	switch {
+	case expectedErr != nil && err != nil:
+		if errors.Is(expectedErr, errors.ErrResourceNotFound) != errors.Is(err, errors.ErrResourceNotFound) {
+			event.AnErr("primaryErr", expectedErr).AnErr("secondaryErr", err).Msg("migration shadow read error mismatch")
+		}
+	case expectedErr != nil:
+		event.AnErr("primaryErr", expectedErr).Msg("migration shadow read only found in secondary")
+	case err != nil:
+		event.AnErr("secondaryErr", err).Msg("migration shadow read not found in secondary")
+	default:
+		if diff := diffShortenedURL(expected, actual); diff != "" {
+			event.Str("field", diff).Msg("migration shadow read mismatch")
+		}
+	}
+}
+
+// diffShortenedURL return the first mismatch field name, empty means equal
+// timestamps compare in seconds because stores may keep different precision
+func diffShortenedURL(a, b *entity.ShortenedURL) string {
+	switch {
+	case a.Short != b.Short:
+		return "short"
+	case a.WorkspaceID != b.WorkspaceID:
+		return "workspaceID"
+	case a.OriginalURL != b.OriginalURL:
+		return "originalURL"
+	case a.CreatedAt.Unix() != b.CreatedAt.Unix():
+		return "createdAt"
+	case a.ExpiredAt.Unix() != b.ExpiredAt.Unix():
+		return "expiredAt"
+	case a.Title != b.Title:
+		return "title"
+	case strings.Join(a.Tags, ",") != strings.Join(b.Tags, ","):
+		return "tags"
+	case a.Notes != b.Notes:
+		return "notes"
+	case a.OwnerID != b.OwnerID:
+		return "ownerID"
+	case a.Interstitial != b.Interstitial:
+		return "interstitial"
+	case a.DisabledAt.Unix() != b.DisabledAt.Unix():
+		return "disabledAt"
+	case a.MaxClicks != b.MaxClicks:
+		return "maxClicks"
+	case a.PasswordHash != b.PasswordHash:
+		return "passwordHash"
+	case a.ActivateAt.Unix() != b.ActivateAt.Unix():
+		return "activateAt"
+	case !equalSchedule(a.Schedule, b.Schedule):
+		return "schedule"
+	case a.FallbackURL != b.FallbackURL:
+		return "fallbackURL"
+	default:
+		return ""
+	}
+}
+
+// equalSchedule compare destinations of schedule, times compare in seconds like diffShortenedURL
+func equalSchedule(a, b []entity.Destination) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].At.Unix() != b[i].At.Unix() || a[i].URL != b[i].URL {
+			return false
+		}
+	}
+	return true
+}
+
+// Backfill copy one batch of historical rows after cursor from old store to new store.
+// Rows already in the new store are kept, they are written by dual-write and never older.
+// next is the cursor of the next batch, done is true when no more rows.
+func Backfill(ctx context.Context, old Repository, new Repository, cursor string, batchSize int) (next string, copied int, done bool, err error) {
+	shortenedURLs, err := old.ListShortenedURLs(ctx, cursor, batchSize)
+	if err != nil {
+		return cursor, 0, false, err
+	}
+
+	if len(shortenedURLs) == 0 {
+		return cursor, 0, true, nil
+	}
+
+	for _, shortenedURL := range shortenedURLs {
+		stored, err := new.ImportShortenedURL(ctx, shortenedURL, ConflictSkip)
+		if err != nil {
+			return cursor, copied, false, errors.WithMessagef(err, "backfill stop at short = %v", shortenedURL.Key())
+		}
+
+		if stored {
+			copied++
+		}
+		cursor = shortenedURL.Key()
+	}
+
+	return cursor, copied, false, nil
+}
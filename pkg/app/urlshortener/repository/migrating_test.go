@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/errors"
+)
+
+func TestMigrating_StoreShortenedURL(t *testing.T) {
+	shortenedURL := &entity.ShortenedURL{
+		Short:       "LsE2ypFI",
+		OriginalURL: "https://www.dcard.tw/",
+		CreatedAt:   time.Now(),
+		ExpiredAt:   time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		primary repository.MigrationSource
+		old     func() *mocks.Repository
+		new     func() *mocks.Repository
+		err     error
+	}{
+		{
+			name:    "DualWrite",
+			primary: repository.MigrationSourceOld,
+			old: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, shortenedURL).Return(nil)
+				return repo
+			},
+			new: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, shortenedURL).Return(nil)
+				return repo
+			},
+			err: nil,
+		},
+		{
+			name:    "SecondaryFailIgnored",
+			primary: repository.MigrationSourceOld,
+			old: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, shortenedURL).Return(nil)
+				return repo
+			},
+			new: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, shortenedURL).Return(errors.ErrInternal)
+				return repo
+			},
+			err: nil,
+		},
+		{
+			name:    "PrimaryFail",
+			primary: repository.MigrationSourceNew,
+			old: func() *mocks.Repository {
+				return mocks.NewRepository(t)
+			},
+			new: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, shortenedURL).Return(errors.ErrInternal)
+				return repo
+			},
+			err: errors.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMigrating(tt.old(), tt.new(), repository.MigrationConfig{
+				Primary: tt.primary,
+			})
+
+			err := repo.StoreShortenedURL(context.Background(), shortenedURL)
+			if err != nil || tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "StoreShortenedURL() error = %v, expected error %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMigrating_FindShortenedURL(t *testing.T) {
+	shortenedURL := &entity.ShortenedURL{
+		Short:       "LsE2ypFI",
+		OriginalURL: "https://www.dcard.tw/",
+	}
+
+	old := mocks.NewRepository(t)
+	new := mocks.NewRepository(t)
+	new.EXPECT().FindShortenedURL(mock.Anything, "", "LsE2ypFI").Return(shortenedURL, nil)
+
+	repo := repository.NewMigrating(old, new, repository.MigrationConfig{
+		Primary: repository.MigrationSourceNew,
+	})
+
+	actual, err := repo.FindShortenedURL(context.Background(), "", "LsE2ypFI")
+	assert.NoError(t, err)
+	assert.Equal(t, shortenedURL, actual)
+}
+
+func TestBackfill(t *testing.T) {
+	shortenedURLs := []*entity.ShortenedURL{
+		{Short: "6Xme5Xwp", OriginalURL: "https://www.dcard.tw/f"},
+		{Short: "K2MY8LEp", OriginalURL: "https://www.dcard.tw/"},
+	}
+
+	old := mocks.NewRepository(t)
+	old.EXPECT().ListShortenedURLs(mock.Anything, "", 2).Return(shortenedURLs, nil)
+	old.EXPECT().ListShortenedURLs(mock.Anything, "K2MY8LEp", 2).Return(nil, nil)
+
+	new := mocks.NewRepository(t)
+	new.EXPECT().ImportShortenedURL(mock.Anything, shortenedURLs[0], repository.ConflictSkip).Return(true, nil)
+	new.EXPECT().ImportShortenedURL(mock.Anything, shortenedURLs[1], repository.ConflictSkip).Return(false, nil)
+
+	ctx := context.Background()
+
+	next, copied, done, err := repository.Backfill(ctx, old, new, "", 2)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 1, copied)
+	assert.Equal(t, "K2MY8LEp", next)
+
+	_, copied, done, err = repository.Backfill(ctx, old, new, next, 2)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, 0, copied)
+}
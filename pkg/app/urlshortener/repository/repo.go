@@ -2,7 +2,12 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/coocood/freecache"
 	"github.com/rs/zerolog/log"
@@ -11,6 +16,7 @@ import (
 	"url-shortener/pkg/app/urlshortener/entity"
 	"url-shortener/pkg/db"
 	"url-shortener/pkg/errors"
+	"url-shortener/pkg/keyring"
 )
 
 // Repository define url shortener repository layer
@@ -21,11 +27,120 @@ type Repository interface {
 		shortenedURL *entity.ShortenedURL,
 	) (err error)
 
-	// FindShortenedURL find shortened URL by short id
+	// StoreShortenedURLs store many shortened URL with multi-row insert in one transaction
+	// short id already exist in the workspace is skipped, stored is the keys which are inserted
+	StoreShortenedURLs(
+		ctx context.Context,
+		shortenedURLs []*entity.ShortenedURL,
+	) (stored []string, err error)
+
+	// FindShortenedURL find shortened URL by workspace and short id
 	FindShortenedURL(
 		ctx context.Context,
+		workspaceID string,
 		short string,
 	) (shortenedURL *entity.ShortenedURL, err error)
+
+	// FindDuplicateShortenedURL find the newest shortened URL of owner in workspace with the same canonical original URL
+	// which is neither expired, disabled, click limited nor protected at now, ErrResourceNotFound when there is none
+	FindDuplicateShortenedURL(
+		ctx context.Context,
+		workspaceID string,
+		ownerID string,
+		originalURL string,
+		now time.Time,
+	) (shortenedURL *entity.ShortenedURL, err error)
+
+	// ListShortenedURLs list shortened URL of all workspaces order by workspace and short id
+	// only return key after the cursor, empty cursor means from the beginning
+	ListShortenedURLs(
+		ctx context.Context,
+		cursor string,
+		limit int,
+	) (shortenedURLs []*entity.ShortenedURL, err error)
+
+	// SearchShortenedURLs search shortened URL order by created time desc
+	// next is the cursor of the next page, empty means no more page
+	SearchShortenedURLs(
+		ctx context.Context,
+		filter *SearchFilter,
+	) (shortenedURLs []*entity.ShortenedURL, next string, err error)
+
+	// UpdateShortenedURL update title, tags, notes, interstitial, expire time and fallback url of shortened URL by workspace and short id
+	UpdateShortenedURL(
+		ctx context.Context,
+		shortenedURL *entity.ShortenedURL,
+	) (err error)
+
+	// DisableShortenedURL disable shortened URL by workspace and short id at disabledAt
+	// ErrResourceNotFound when it does not exist or is already disabled
+	DisableShortenedURL(
+		ctx context.Context,
+		workspaceID string,
+		short string,
+		disabledAt time.Time,
+	) (err error)
+
+	// UnflagShortenedURL turn off interstitial of flagged shortened URL by workspace and short id,
+	// interstitial chosen by its owner is kept
+	UnflagShortenedURL(
+		ctx context.Context,
+		workspaceID string,
+		short string,
+	) (err error)
+
+	// IncrementClicks count one click of click limited shortened URL by workspace and short id, clicks is the count after it.
+	// ErrShortenedURLExhausted when its clicks already reach max clicks or it is not click limited
+	IncrementClicks(
+		ctx context.Context,
+		workspaceID string,
+		short string,
+	) (clicks int64, err error)
+
+	// DeleteShortenedURL delete shortened URL by workspace and short id
+	DeleteShortenedURL(
+		ctx context.Context,
+		workspaceID string,
+		short string,
+	) (err error)
+
+	// ImportShortenedURL store shortened URL and handle short id conflict in the workspace by mode
+	// stored is false when the shortened URL is skipped
+	ImportShortenedURL(
+		ctx context.Context,
+		shortenedURL *entity.ShortenedURL,
+		mode ConflictMode,
+	) (stored bool, err error)
+}
+
+// ConflictMode define how to handle short id already exist when import
+type ConflictMode string
+
+const (
+	ConflictSkip      ConflictMode = "skip"      // ConflictSkip keep the existing one
+	ConflictOverwrite ConflictMode = "overwrite" // ConflictOverwrite replace the existing one
+	ConflictFail      ConflictMode = "fail"      // ConflictFail return ErrConflict
+)
+
+// KeyRotator define re-encrypt stored shortened URL with the primary key
+type KeyRotator interface {
+	// RotateKeys re-encrypt at most batchSize rows which not sealed by the primary key
+	// return how many rows are rotated, zero means all rows are up-to-date
+	RotateKeys(
+		ctx context.Context,
+		batchSize int,
+	) (rotated int, err error)
+}
+
+// CanonicalRehasher define recompute the dedupe hash of stored shortened URL
+type CanonicalRehasher interface {
+	// RehashCanonical recompute canonical hash of at most batchSize rows after the cursor,
+	// return cursor of the last row and how many hashes are changed, done when no row is after the cursor
+	RehashCanonical(
+		ctx context.Context,
+		cursor string,
+		batchSize int,
+	) (next string, rehashed int, done bool, err error)
 }
 
 // RepoImpl is implementation for Repository
@@ -33,99 +148,822 @@ type RepoImpl struct {
 	readDB  *gorm.DB
 	writeDB *gorm.DB
 	cache   *freecache.Cache
+	keyring *keyring.Keyring
+
+	// canonical return the canonical form of original url which is hashed for dedupe, nil keep url as is
+	canonical func(rawURL string) string
+}
+
+// An Option is passed to Repository constructor
+type Option interface {
+	apply(*RepoImpl)
+}
+
+type setKeyring struct{ keyring *keyring.Keyring }
+
+func (opt *setKeyring) apply(repo *RepoImpl) { repo.keyring = opt.keyring }
+
+// WithKeyring enable encryption at rest for original URL
+func WithKeyring(k *keyring.Keyring) Option {
+	return &setKeyring{keyring: k}
+}
+
+type setCanonical struct{ canonical func(rawURL string) string }
+
+func (opt *setCanonical) apply(repo *RepoImpl) { repo.canonical = opt.canonical }
+
+// WithCanonical hash the canonical form of original URL for dedupe, the stored URL is kept as is
+func WithCanonical(canonical func(rawURL string) string) Option {
+	return &setCanonical{canonical: canonical}
 }
 
 // New Repository constructor
-func New(conn db.Connection) Repository {
+func New(conn db.Connection, opts ...Option) Repository {
+	return NewRepoImpl(conn, opts...)
+}
+
+// NewRepoImpl RepoImpl constructor
+func NewRepoImpl(conn db.Connection, opts ...Option) *RepoImpl {
 	// In bytes, where 1024 * 1024 represents a single Megabyte, and 100 * 1024*1024 represents 100 Megabytes.
 	cacheSize := 100 * 1024 * 1024
 
-	return &RepoImpl{
+	repo := &RepoImpl{
 		readDB:  conn.ReadDB(),
 		writeDB: conn.WriteDB(),
 		cache:   freecache.NewCache(cacheSize),
 	}
+	for _, opt := range opts {
+		opt.apply(repo)
+	}
+
+	return repo
+}
+
+const (
+	// cacheKeyPrefix is local cache key prefix of shortened URL
+	cacheKeyPrefix = "ShortenedURL:"
+
+	// batchInsertSize is the max rows of one multi-row insert
+	batchInsertSize = 500
+
+	// shortenedURLInsert is insert statement of shortenedURLRow, follow by shortenedURLValues
+	shortenedURLInsert = `INSERT INTO "shortened_urls" ("short","original_url","key_id","created_at","expired_at","original_host","title","tags","notes","owner_id","workspace_id","interstitial","disabled_at","canonical_hash","max_clicks","clicks","password_hash","activate_at","schedule","fallback_url") VALUES `
+
+	// shortenedURLValues is placeholder of shortenedURLRow.values
+	shortenedURLValues = `(?,?,?,?,?,?,?,?::jsonb,?,?,?,?,?,?,?,?,?,?,?,?)`
+
+	// shortenedURLColumns is select columns of shortenedURLRow
+	shortenedURLColumns = `short,
+    				original_url,
+    				key_id,
+    				to_timestamp(created_at/1000) as created_at,
+    				to_timestamp(expired_at/1000) as expired_at,
+    				original_host,
+    				title,
+    				tags::text as tags,
+    				notes,
+    				owner_id,
+    				workspace_id,
+    				interstitial,
+    				disabled_at,
+    				canonical_hash,
+    				max_clicks,
+    				clicks,
+    				password_hash,
+    				activate_at,
+    				schedule,
+    				fallback_url`
+)
+
+// shortenedURLRow is shortened_urls table row
+// OriginalURL is ciphertext when KeyID is not empty
+type shortenedURLRow struct {
+	Short       string    `gorm:"column:short"`
+	OriginalURL string    `gorm:"column:original_url"`
+	KeyID       string    `gorm:"column:key_id"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	ExpiredAt   time.Time `gorm:"column:expired_at"`
+
+	OriginalHost string `gorm:"column:original_host"`
+	Title        string `gorm:"column:title"`
+	Tags         string `gorm:"column:tags"` // Tags is json array
+	Notes        string `gorm:"column:notes"`
+	OwnerID      string `gorm:"column:owner_id"`
+	WorkspaceID  string `gorm:"column:workspace_id"`
+	Interstitial string `gorm:"column:interstitial"`
+	DisabledAt   int64  `gorm:"column:disabled_at"` // DisabledAt zero means enabled
+
+	// CanonicalHash is hex hmac-sha256 keyed by keyring of the canonical plaintext original URL, plain sha256 without keyring
+	CanonicalHash string `gorm:"column:canonical_hash"`
+
+	MaxClicks int64 `gorm:"column:max_clicks"` // MaxClicks zero means unlimited
+	Clicks    int64 `gorm:"column:clicks"`
+
+	PasswordHash string `gorm:"column:password_hash"` // PasswordHash is bcrypt hash, empty means not protected
+
+	ActivateAt int64 `gorm:"column:activate_at"` // ActivateAt zero means active at once
+
+	// Schedule is json array of destinations, it is ciphertext sealed by KeyID like OriginalURL when KeyID is not empty
+	Schedule string `gorm:"column:schedule"`
+
+	// FallbackURL is plaintext like OriginalHost, it is a public landing page rather than the destination
+	FallbackURL string `gorm:"column:fallback_url"` // FallbackURL empty means no fallback
+}
+
+// values return insert values in the order of shortenedURLInsert columns
+func (row *shortenedURLRow) values() []interface{} {
+	return []interface{}{
+		row.Short,
+		row.OriginalURL,
+		row.KeyID,
+		row.CreatedAt.UnixMilli(),
+		row.ExpiredAt.UnixMilli(),
+		row.OriginalHost,
+		row.Title,
+		row.Tags,
+		row.Notes,
+		row.OwnerID,
+		row.WorkspaceID,
+		row.Interstitial,
+		row.DisabledAt,
+		row.CanonicalHash,
+		row.MaxClicks,
+		row.Clicks,
+		row.PasswordHash,
+		row.ActivateAt,
+		row.Schedule,
+		row.FallbackURL,
+	}
+}
+
+// key return unique key of row across workspaces, it is also additional data of encryption
+func (row *shortenedURLRow) key() string {
+	return entity.JoinKey(row.WorkspaceID, row.Short)
+}
+
+// scheduleKey return additional data of schedule encryption, so schedule and original URL can not be swapped
+func (row *shortenedURLRow) scheduleKey() string {
+	return row.key() + "#schedule"
 }
 
 // StoreShortenedURL method is implementation for Repository
 func (repo *RepoImpl) StoreShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL) (err error) {
 	const (
-		sql = `INSERT INTO "shortened_urls" ("short","original_url","created_at","expired_at") VALUES (?,?,?,?)`
+		sql = shortenedURLInsert + shortenedURLValues
 	)
 
-	err = repo.writeDB.WithContext(ctx).Exec(
-		sql,
-		shortenedURL.Short,
-		shortenedURL.OriginalURL,
-		shortenedURL.CreatedAt.UnixMilli(),
-		shortenedURL.ExpiredAt.UnixMilli(),
-	).Error
+	row, err := repo.toRow(shortenedURL)
+	if err != nil {
+		return err
+	}
+
+	err = repo.writeDB.WithContext(ctx).Exec(sql, row.values()...).Error
 	if err != nil {
 		return errors.Wrapf(
 			errors.ErrInternal,
-			"failed to store shortenedURL %v err =%v", shortenedURL, err,
+			"failed to store shortenedURL short = %v err =%v", shortenedURL.Short, err,
 		)
 	}
 
 	return nil
 }
 
+// StoreShortenedURLs method is implementation for Repository
+func (repo *RepoImpl) StoreShortenedURLs(ctx context.Context, shortenedURLs []*entity.ShortenedURL) (stored []string, err error) {
+	rows := make([]*shortenedURLRow, 0, len(shortenedURLs))
+	for _, shortenedURL := range shortenedURLs {
+		row, err := repo.toRow(shortenedURL)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	stored = make([]string, 0, len(rows))
+	err = repo.writeDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for begin := 0; begin < len(rows); begin += batchInsertSize {
+			end := begin + batchInsertSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+
+			placeholders := make([]string, 0, end-begin)
+			values := make([]interface{}, 0, (end-begin)*20)
+			for _, row := range rows[begin:end] {
+				placeholders = append(placeholders, shortenedURLValues)
+				values = append(values, row.values()...)
+			}
+
+			sql := shortenedURLInsert + strings.Join(placeholders, ",") +
+				` ON CONFLICT ("workspace_id","short") DO NOTHING RETURNING "workspace_id","short"`
+
+			inserted := make([]*shortenedURLRow, 0, end-begin)
+			if err := tx.Raw(sql, values...).Scan(&inserted).Error; err != nil {
+				return errors.Wrapf(
+					errors.ErrInternal,
+					"failed to store shortenedURLs from short = %v err = %v", rows[begin].Short, err,
+				)
+			}
+			for _, row := range inserted {
+				stored = append(stored, row.key())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
 // FindShortenedURL method is implementation for Repository
-func (repo *RepoImpl) FindShortenedURL(ctx context.Context, short string) (shortenedURL *entity.ShortenedURL, err error) {
-	keyPrefix := "ShortenedURL:"
-	shortenedURL = &entity.ShortenedURL{}
+func (repo *RepoImpl) FindShortenedURL(ctx context.Context, workspaceID string, short string) (shortenedURL *entity.ShortenedURL, err error) {
+	row := &shortenedURLRow{}
 	expireSeconds := 600
+	key := entity.JoinKey(workspaceID, short)
 
 	logger := log.Ctx(ctx)
 
 	// try to get data from local cache
-	data, err := repo.cache.Get([]byte(keyPrefix + short))
+	// cache keep the row as stored, so the original URL is still encrypted in memory
+	data, err := repo.cache.Get([]byte(cacheKeyPrefix + key))
 	if err != nil {
 		// not found in cache
 		// load data from datastore
 		if errors.Is(err, freecache.ErrNotFound) {
 			const (
-				sql = `SELECT 
-    				short,
-    				original_url,
-    				to_timestamp(created_at/1000) as created_at,
-    				to_timestamp(expired_at/1000) as expired_at
-       		   FROM shortened_urls 
-       		   WHERE short = ? LIMIT 1`
+				sql = `SELECT ` + shortenedURLColumns + `
+       		   FROM shortened_urls
+       		   WHERE workspace_id = ? AND short = ? LIMIT 1`
 			)
 
-			rtn := make([]*entity.ShortenedURL, 0)
+			rtn := make([]*shortenedURLRow, 0)
 			err = repo.readDB.
 				WithContext(ctx).
-				Raw(sql, short).
+				Raw(sql, workspaceID, short).
 				Scan(&rtn).
 				Error
 			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found", short)
+					return nil, errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found", key)
 				}
 
-				return nil, errors.Wrapf(errors.ErrInternal, "short = %v not found, err = %v", short, err)
+				return nil, errors.Wrapf(errors.ErrInternal, "short = %v not found, err = %v", key, err)
 			}
 
 			if len(rtn) == 0 {
-				return nil, errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found", short)
+				return nil, errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found", key)
 			}
 
-			// write entity into cache
+			// write row into cache
 			data, _ = json.Marshal(rtn[0])
-			err = repo.cache.Set([]byte(keyPrefix+short), data, expireSeconds)
+			err = repo.cache.Set([]byte(cacheKeyPrefix+key), data, expireSeconds)
 			if err != nil {
-				logger.Warn().Err(err).Msgf("fail to write short=%v into cache", short)
+				logger.Warn().Err(err).Msgf("fail to write short=%v into cache", key)
 			}
 
-			return rtn[0], nil
+			return repo.toEntity(rtn[0])
 		}
 
 		return nil, errors.Wrapf(errors.ErrInternal, "failed to get data from cache %v", err)
 	}
 
-	_ = json.Unmarshal(data, shortenedURL)
+	_ = json.Unmarshal(data, row)
+
+	return repo.toEntity(row)
+}
+
+// FindDuplicateShortenedURL method is implementation for Repository
+// rows are matched by hash, the canonical original URL is compared again in case of collision
+func (repo *RepoImpl) FindDuplicateShortenedURL(
+	ctx context.Context,
+	workspaceID string,
+	ownerID string,
+	originalURL string,
+	now time.Time,
+) (shortenedURL *entity.ShortenedURL, err error) {
+	const (
+		sql = `SELECT ` + shortenedURLColumns + `
+			FROM shortened_urls
+			WHERE workspace_id = ? AND owner_id = ? AND canonical_hash = ? AND expired_at > ? AND disabled_at = 0 AND max_clicks = 0 AND password_hash = ''
+				AND activate_at <= ? AND schedule = '' AND fallback_url = ''
+			ORDER BY created_at DESC
+			LIMIT 5`
+	)
+
+	rows := make([]*shortenedURLRow, 0)
+	err = repo.readDB.WithContext(ctx).
+		Raw(sql, workspaceID, ownerID, repo.canonicalHash(originalURL), now.UnixMilli(), now.UnixMilli()).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to find duplicate of url in workspace = %v err = %v", workspaceID, err)
+	}
+
+	for _, row := range rows {
+		shortenedURL, err := repo.toEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		if repo.canonicalURL(shortenedURL.OriginalURL) == repo.canonicalURL(originalURL) {
+			return shortenedURL, nil
+		}
+	}
+
+	return nil, errors.Wrapf(errors.ErrResourceNotFound, "duplicate of url in workspace = %v not found", workspaceID)
+}
+
+// ListShortenedURLs method is implementation for Repository
+func (repo *RepoImpl) ListShortenedURLs(ctx context.Context, cursor string, limit int) (shortenedURLs []*entity.ShortenedURL, err error) {
+	const (
+		sql = `SELECT ` + shortenedURLColumns + `
+       		   FROM shortened_urls
+       		   WHERE (workspace_id, short) > (?, ?)
+       		   ORDER BY workspace_id, short
+       		   LIMIT ?`
+	)
+
+	workspaceID, short := entity.SplitKey(cursor)
+
+	rows := make([]*shortenedURLRow, 0, limit)
+	err = repo.readDB.
+		WithContext(ctx).
+		Raw(sql, workspaceID, short, limit).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list shortened url after cursor = %v err = %v", cursor, err)
+	}
+
+	shortenedURLs = make([]*entity.ShortenedURL, 0, len(rows))
+	for _, row := range rows {
+		shortenedURL, err := repo.toEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		shortenedURLs = append(shortenedURLs, shortenedURL)
+	}
+
+	return shortenedURLs, nil
+}
+
+// UpdateShortenedURL method is implementation for Repository
+// other instances may serve the stale row from local cache until it expires
+func (repo *RepoImpl) UpdateShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL) (err error) {
+	const (
+		sql = `UPDATE shortened_urls
+				SET title = ?, tags = ?::jsonb, notes = ?, interstitial = ?, expired_at = ?, fallback_url = ?
+				WHERE workspace_id = ? AND short = ?`
+	)
+
+	row, err := repo.toRow(shortenedURL)
+	if err != nil {
+		return err
+	}
+
+	result := repo.writeDB.WithContext(ctx).Exec(
+		sql,
+		row.Title,
+		row.Tags,
+		row.Notes,
+		row.Interstitial,
+		row.ExpiredAt.UnixMilli(),
+		row.FallbackURL,
+		row.WorkspaceID,
+		row.Short,
+	)
+	if result.Error != nil {
+		return errors.Wrapf(
+			errors.ErrInternal,
+			"failed to update shortenedURL short = %v err = %v", shortenedURL.Short, result.Error,
+		)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found", shortenedURL.Key())
+	}
+
+	repo.cache.Del([]byte(cacheKeyPrefix + shortenedURL.Key()))
+
+	return nil
+}
+
+// DisableShortenedURL method is implementation for Repository
+// only disabled_at is written, so it never race with owner update of other fields
+func (repo *RepoImpl) DisableShortenedURL(
+	ctx context.Context,
+	workspaceID string,
+	short string,
+	disabledAt time.Time,
+) (err error) {
+	const (
+		sql = `UPDATE shortened_urls SET disabled_at = ? WHERE workspace_id = ? AND short = ? AND disabled_at = 0`
+	)
+
+	key := entity.JoinKey(workspaceID, short)
+
+	result := repo.writeDB.WithContext(ctx).Exec(sql, disabledAt.UnixMilli(), workspaceID, short)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to disable shortenedURL short = %v err = %v", key, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found or already disabled", key)
+	}
+
+	repo.cache.Del([]byte(cacheKeyPrefix + key))
+
+	return nil
+}
+
+// UnflagShortenedURL method is implementation for Repository
+// only a flagged interstitial is written, so it never race with owner update of other fields
+func (repo *RepoImpl) UnflagShortenedURL(ctx context.Context, workspaceID string, short string) (err error) {
+	const (
+		sql = `UPDATE shortened_urls SET interstitial = ? WHERE workspace_id = ? AND short = ? AND interstitial = ?`
+	)
+
+	key := entity.JoinKey(workspaceID, short)
+
+	result := repo.writeDB.WithContext(ctx).Exec(sql, entity.InterstitialOff, workspaceID, short, entity.InterstitialFlagged)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to unflag shortenedURL short = %v err = %v", key, result.Error)
+	}
+
+	repo.cache.Del([]byte(cacheKeyPrefix + key))
+
+	return nil
+}
+
+// IncrementClicks method is implementation for Repository
+// the check and increment is one statement, so concurrent clicks never exceed max clicks
+func (repo *RepoImpl) IncrementClicks(ctx context.Context, workspaceID string, short string) (clicks int64, err error) {
+	const (
+		sql = `UPDATE shortened_urls
+				SET clicks = clicks + 1
+				WHERE workspace_id = ? AND short = ? AND clicks < max_clicks
+				RETURNING clicks`
+	)
+
+	key := entity.JoinKey(workspaceID, short)
+
+	rows := make([]*shortenedURLRow, 0, 1)
+	if err := repo.writeDB.WithContext(ctx).Raw(sql, workspaceID, short).Scan(&rows).Error; err != nil {
+		return 0, errors.Wrapf(errors.ErrInternal, "failed to count click of short = %v err = %v", key, err)
+	}
+
+	// the cached row keep the clicks when it is loaded, drop it so this instance show the new clicks
+	repo.cache.Del([]byte(cacheKeyPrefix + key))
+
+	if len(rows) == 0 {
+		return 0, errors.Wrapf(errors.ErrShortenedURLExhausted, "short = %v has no click left", key)
+	}
+
+	return rows[0].Clicks, nil
+}
+
+// DeleteShortenedURL method is implementation for Repository
+// other instances may serve the stale row from local cache until it expires
+func (repo *RepoImpl) DeleteShortenedURL(ctx context.Context, workspaceID string, short string) (err error) {
+	const (
+		sql = `DELETE FROM shortened_urls WHERE workspace_id = ? AND short = ?`
+	)
+
+	key := entity.JoinKey(workspaceID, short)
+
+	result := repo.writeDB.WithContext(ctx).Exec(sql, workspaceID, short)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to delete shortenedURL short = %v err = %v", key, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrResourceNotFound, "short = %v not found", key)
+	}
+
+	repo.cache.Del([]byte(cacheKeyPrefix + key))
+
+	return nil
+}
+
+// ImportShortenedURL method is implementation for Repository
+func (repo *RepoImpl) ImportShortenedURL(ctx context.Context, shortenedURL *entity.ShortenedURL, mode ConflictMode) (stored bool, err error) {
+	const (
+		insertSQL = shortenedURLInsert + shortenedURLValues
+
+		doNothing = ` ON CONFLICT ("workspace_id","short") DO NOTHING`
+		doUpdate  = ` ON CONFLICT ("workspace_id","short") DO UPDATE SET
+				"original_url" = EXCLUDED."original_url",
+				"key_id" = EXCLUDED."key_id",
+				"created_at" = EXCLUDED."created_at",
+				"expired_at" = EXCLUDED."expired_at",
+				"original_host" = EXCLUDED."original_host",
+				"title" = EXCLUDED."title",
+				"tags" = EXCLUDED."tags",
+				"notes" = EXCLUDED."notes",
+				"owner_id" = EXCLUDED."owner_id",
+				"interstitial" = EXCLUDED."interstitial",
+				"disabled_at" = EXCLUDED."disabled_at",
+				"canonical_hash" = EXCLUDED."canonical_hash",
+				"max_clicks" = EXCLUDED."max_clicks",
+				"clicks" = EXCLUDED."clicks",
+				"password_hash" = EXCLUDED."password_hash",
+				"activate_at" = EXCLUDED."activate_at",
+				"schedule" = EXCLUDED."schedule",
+				"fallback_url" = EXCLUDED."fallback_url"`
+	)
+
+	var sql string
+	switch mode {
+	case ConflictSkip, ConflictFail:
+		sql = insertSQL + doNothing
+	case ConflictOverwrite:
+		sql = insertSQL + doUpdate
+	default:
+		return false, errors.Wrapf(errors.ErrInvalidInput, "unknown conflict mode %v", mode)
+	}
+
+	row, err := repo.toRow(shortenedURL)
+	if err != nil {
+		return false, err
+	}
+
+	result := repo.writeDB.WithContext(ctx).Exec(sql, row.values()...)
+	if result.Error != nil {
+		return false, errors.Wrapf(
+			errors.ErrInternal,
+			"failed to import shortenedURL short = %v err =%v", shortenedURL.Short, result.Error,
+		)
+	}
+
+	if result.RowsAffected == 0 {
+		if mode == ConflictFail {
+			return false, errors.Wrapf(errors.ErrConflict, "short = %v already exist", shortenedURL.Key())
+		}
+		return false, nil
+	}
+
+	// drop stale cache when overwrite
+	repo.cache.Del([]byte(cacheKeyPrefix + shortenedURL.Key()))
+
+	return true, nil
+}
+
+// RotateKeys method is implementation for KeyRotator
+func (repo *RepoImpl) RotateKeys(ctx context.Context, batchSize int) (rotated int, err error) {
+	if repo.keyring == nil {
+		return 0, errors.Wrap(errors.ErrInternal, "keyring is not configured")
+	}
+
+	primary := repo.keyring.PrimaryKeyID()
+
+	err = repo.writeDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		const (
+			selectSQL = `SELECT workspace_id, short, original_url, key_id, schedule
+				FROM shortened_urls
+				WHERE key_id <> ?
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED`
+			updateSQL = `UPDATE shortened_urls
+				SET original_url = ?, key_id = ?, schedule = ?, canonical_hash = COALESCE(NULLIF(?, ''), canonical_hash)
+				WHERE workspace_id = ? AND short = ?`
+		)
+
+		rows := make([]*shortenedURLRow, 0, batchSize)
+		if err := tx.Raw(selectSQL, primary, batchSize).Scan(&rows).Error; err != nil {
+			return errors.Wrapf(errors.ErrInternal, "failed to select rows to rotate err = %v", err)
+		}
+
+		for _, row := range rows {
+			var (
+				keyID      string
+				ciphertext string
+				hash       string
+				err        error
+			)
+
+			if row.KeyID == "" {
+				// plaintext row stored before encryption enabled, its plain sha256 is replaced by keyed hash
+				keyID, ciphertext, err = repo.keyring.Seal([]byte(row.OriginalURL), []byte(row.key()))
+				hash = repo.canonicalHash(row.OriginalURL)
+			} else {
+				keyID, ciphertext, err = repo.keyring.Rewrap(row.KeyID, row.OriginalURL, []byte(row.key()))
+			}
+			if err != nil {
+				return errors.Wrapf(err, "failed to rotate short = %v", row.key())
+			}
+
+			schedule := row.Schedule
+			if schedule != "" {
+				if row.KeyID == "" {
+					_, schedule, err = repo.keyring.Seal([]byte(row.Schedule), []byte(row.scheduleKey()))
+				} else {
+					_, schedule, err = repo.keyring.Rewrap(row.KeyID, row.Schedule, []byte(row.scheduleKey()))
+				}
+				if err != nil {
+					return errors.Wrapf(err, "failed to rotate schedule of short = %v", row.key())
+				}
+			}
+
+			if err := tx.Exec(updateSQL, ciphertext, keyID, schedule, hash, row.WorkspaceID, row.Short).Error; err != nil {
+				return errors.Wrapf(errors.ErrInternal, "failed to update short = %v err = %v", row.key(), err)
+			}
+		}
+
+		rotated = len(rows)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rotated, nil
+}
+
+// RehashCanonical method is implementation for CanonicalRehasher
+// encrypted rows are opened by keyring, so every row is hashed by its canonical plaintext URL
+func (repo *RepoImpl) RehashCanonical(ctx context.Context, cursor string, batchSize int) (next string, rehashed int, done bool, err error) {
+	const (
+		sql = `UPDATE shortened_urls
+				SET canonical_hash = ?
+				WHERE workspace_id = ? AND short = ? AND canonical_hash <> ?`
+	)
+
+	shortenedURLs, err := repo.ListShortenedURLs(ctx, cursor, batchSize)
+	if err != nil {
+		return cursor, 0, false, err
+	}
+
+	if len(shortenedURLs) == 0 {
+		return cursor, 0, true, nil
+	}
+
+	for _, shortenedURL := range shortenedURLs {
+		hash := repo.canonicalHash(shortenedURL.OriginalURL)
+
+		result := repo.writeDB.WithContext(ctx).Exec(sql, hash, shortenedURL.WorkspaceID, shortenedURL.Short, hash)
+		if result.Error != nil {
+			return cursor, rehashed, false, errors.Wrapf(errors.ErrInternal, "failed to rehash short = %v err = %v", shortenedURL.Key(), result.Error)
+		}
+
+		rehashed += int(result.RowsAffected)
+		cursor = shortenedURL.Key()
+	}
+
+	return cursor, rehashed, false, nil
+}
+
+// toRow convert entity to table row, seal original URL when keyring is configured
+func (repo *RepoImpl) toRow(shortenedURL *entity.ShortenedURL) (*shortenedURLRow, error) {
+	tags := shortenedURL.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to encode tags of short = %v err = %v", shortenedURL.Short, err)
+	}
+
+	row := &shortenedURLRow{
+		Short:         shortenedURL.Short,
+		OriginalURL:   shortenedURL.OriginalURL,
+		CreatedAt:     shortenedURL.CreatedAt,
+		ExpiredAt:     shortenedURL.ExpiredAt,
+		OriginalHost:  originalHost(shortenedURL.OriginalURL),
+		Title:         shortenedURL.Title,
+		Tags:          string(data),
+		Notes:         shortenedURL.Notes,
+		OwnerID:       shortenedURL.OwnerID,
+		WorkspaceID:   shortenedURL.WorkspaceID,
+		Interstitial:  string(shortenedURL.Interstitial),
+		CanonicalHash: repo.canonicalHash(shortenedURL.OriginalURL),
+		MaxClicks:     shortenedURL.MaxClicks,
+		Clicks:        shortenedURL.Clicks,
+		PasswordHash:  shortenedURL.PasswordHash,
+		FallbackURL:   shortenedURL.FallbackURL,
+	}
+	if !shortenedURL.DisabledAt.IsZero() {
+		row.DisabledAt = shortenedURL.DisabledAt.UnixMilli()
+	}
+	if !shortenedURL.ActivateAt.IsZero() {
+		row.ActivateAt = shortenedURL.ActivateAt.UnixMilli()
+	}
+	if len(shortenedURL.Schedule) != 0 {
+		data, err := json.Marshal(shortenedURL.Schedule)
+		if err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "failed to encode schedule of short = %v err = %v", shortenedURL.Short, err)
+		}
+		row.Schedule = string(data)
+	}
+
+	// key is short id in the default workspace, so rows sealed before workspaces still open
+	if repo.keyring != nil {
+		keyID, ciphertext, err := repo.keyring.Seal([]byte(shortenedURL.OriginalURL), []byte(row.key()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encrypt short = %v", row.key())
+		}
+
+		row.OriginalURL = ciphertext
+		row.KeyID = keyID
+
+		// sealed after the original URL, so both are sealed by the same primary key
+		if row.Schedule != "" {
+			_, row.Schedule, err = repo.keyring.Seal([]byte(row.Schedule), []byte(row.scheduleKey()))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to encrypt schedule of short = %v", row.key())
+			}
+		}
+	}
+
+	return row, nil
+}
+
+// toEntity convert table row to entity, open original URL when it is encrypted
+func (repo *RepoImpl) toEntity(row *shortenedURLRow) (*entity.ShortenedURL, error) {
+	shortenedURL := &entity.ShortenedURL{
+		Short:        row.Short,
+		OriginalURL:  row.OriginalURL,
+		CreatedAt:    row.CreatedAt,
+		ExpiredAt:    row.ExpiredAt,
+		Title:        row.Title,
+		Notes:        row.Notes,
+		OwnerID:      row.OwnerID,
+		WorkspaceID:  row.WorkspaceID,
+		Interstitial: entity.Interstitial(row.Interstitial),
+		MaxClicks:    row.MaxClicks,
+		Clicks:       row.Clicks,
+		PasswordHash: row.PasswordHash,
+		FallbackURL:  row.FallbackURL,
+	}
+	if row.DisabledAt != 0 {
+		shortenedURL.DisabledAt = time.UnixMilli(row.DisabledAt).UTC()
+	}
+	if row.ActivateAt != 0 {
+		shortenedURL.ActivateAt = time.UnixMilli(row.ActivateAt).UTC()
+	}
+
+	if row.Tags != "" {
+		if err := json.Unmarshal([]byte(row.Tags), &shortenedURL.Tags); err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "failed to decode tags of short = %v err = %v", row.Short, err)
+		}
+	}
+
+	if row.KeyID != "" {
+		if repo.keyring == nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "short = %v is encrypted but keyring is not configured", row.Short)
+		}
+
+		plaintext, err := repo.keyring.Open(row.KeyID, row.OriginalURL, []byte(row.key()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt short = %v", row.key())
+		}
+		shortenedURL.OriginalURL = string(plaintext)
+	}
+
+	if row.Schedule != "" {
+		schedule := []byte(row.Schedule)
+		if row.KeyID != "" {
+			plaintext, err := repo.keyring.Open(row.KeyID, row.Schedule, []byte(row.scheduleKey()))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decrypt schedule of short = %v", row.key())
+			}
+			schedule = plaintext
+		}
+		if err := json.Unmarshal(schedule, &shortenedURL.Schedule); err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "failed to decode schedule of short = %v err = %v", row.Short, err)
+		}
+	}
 
 	return shortenedURL, nil
 }
+
+// canonicalURL return the canonical form of original url, the url itself without canonical option
+func (repo *RepoImpl) canonicalURL(originalURL string) string {
+	if repo.canonical == nil {
+		return originalURL
+	}
+
+	return repo.canonical(originalURL)
+}
+
+// canonicalHash return hash of the canonical form of original url, the key of dedupe.
+// It is keyed by keyring when configured, so the hash does not reveal the encrypted url
+func (repo *RepoImpl) canonicalHash(originalURL string) string {
+	canonical := []byte(repo.canonicalURL(originalURL))
+	if repo.keyring != nil {
+		return repo.keyring.Hash(canonical)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// originalHost return lower case host of url, empty when url is invalid
+func originalHost(originalURL string) string {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
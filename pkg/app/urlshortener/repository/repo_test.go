@@ -179,7 +179,7 @@ func TestRepoImpl_FindShortenedURL(t *testing.T) {
 			tt.setup()
 			defer tt.teardown()
 
-			actual, err := repo.FindShortenedURL(ctx, tt.args.short)
+			actual, err := repo.FindShortenedURL(ctx, "", tt.args.short)
 			if err != nil && tt.err != nil {
 				assert.Truef(
 					t,
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/db"
+	"url-shortener/pkg/errors"
+)
+
+// ReportRepository define abuse reports and moderation audit trail repository layer
+type ReportRepository interface {
+	// CreateReport store open report and set its id
+	CreateReport(
+		ctx context.Context,
+		report *entity.Report,
+	) (err error)
+
+	// FindReport find report by id
+	FindReport(
+		ctx context.Context,
+		id int64,
+	) (report *entity.Report, err error)
+
+	// ListReports list reports of status oldest first
+	ListReports(
+		ctx context.Context,
+		status entity.ReportStatus,
+		limit int,
+	) (reports []*entity.Report, err error)
+
+	// ModerateReport set status and resolution of report which is not resolved and append the action
+	// to the audit trail in one transaction, ErrConflict when the report is already resolved
+	ModerateReport(
+		ctx context.Context,
+		report *entity.Report,
+		action *entity.ModerationAction,
+	) (err error)
+
+	// ListActions list audit trail of report oldest first
+	ListActions(
+		ctx context.Context,
+		reportID int64,
+	) (actions []*entity.ModerationAction, err error)
+}
+
+// reportRow is abuse_reports table row
+type reportRow struct {
+	ID          int64  `gorm:"column:id"`
+	WorkspaceID string `gorm:"column:workspace_id"`
+	Short       string `gorm:"column:short"`
+	Reason      string `gorm:"column:reason"`
+	Contact     string `gorm:"column:contact"`
+	Status      string `gorm:"column:status"`
+	Resolution  string `gorm:"column:resolution"`
+	CreatedAt   int64  `gorm:"column:created_at"`
+	UpdatedAt   int64  `gorm:"column:updated_at"`
+}
+
+func (row *reportRow) toEntity() *entity.Report {
+	return &entity.Report{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Short:       row.Short,
+		Reason:      row.Reason,
+		Contact:     row.Contact,
+		Status:      entity.ReportStatus(row.Status),
+		Resolution:  entity.Resolution(row.Resolution),
+		CreatedAt:   time.UnixMilli(row.CreatedAt).UTC(),
+		UpdatedAt:   time.UnixMilli(row.UpdatedAt).UTC(),
+	}
+}
+
+// actionRow is moderation_actions table row
+type actionRow struct {
+	ID        int64  `gorm:"column:id"`
+	ReportID  int64  `gorm:"column:report_id"`
+	ActorID   string `gorm:"column:actor_id"`
+	Action    string `gorm:"column:action"`
+	Note      string `gorm:"column:note"`
+	CreatedAt int64  `gorm:"column:created_at"`
+}
+
+const reportColumns = `id, workspace_id, short, reason, contact, status, resolution, created_at, updated_at`
+
+var _ ReportRepository = &ReportRepoImpl{}
+
+// ReportRepoImpl is implementation for ReportRepository
+type ReportRepoImpl struct {
+	readDB  *gorm.DB
+	writeDB *gorm.DB
+}
+
+// NewReportRepository ReportRepository constructor
+func NewReportRepository(conn db.Connection) *ReportRepoImpl {
+	return &ReportRepoImpl{
+		readDB:  conn.ReadDB(),
+		writeDB: conn.WriteDB(),
+	}
+}
+
+// CreateReport method is implementation for ReportRepository
+func (repo *ReportRepoImpl) CreateReport(ctx context.Context, report *entity.Report) (err error) {
+	const (
+		sql = `INSERT INTO "abuse_reports" ("workspace_id","short","reason","contact","status","resolution","created_at","updated_at")
+			VALUES (?,?,?,?,?,?,?,?) RETURNING "id"`
+	)
+
+	rows := make([]*reportRow, 0, 1)
+	err = repo.writeDB.WithContext(ctx).Raw(
+		sql,
+		report.WorkspaceID,
+		report.Short,
+		report.Reason,
+		report.Contact,
+		string(report.Status),
+		string(report.Resolution),
+		report.CreatedAt.UnixMilli(),
+		report.UpdatedAt.UnixMilli(),
+	).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return errors.Wrapf(errors.ErrInternal, "failed to store report of short = %v err = %v", report.Key(), err)
+	}
+
+	report.ID = rows[0].ID
+	return nil
+}
+
+// FindReport method is implementation for ReportRepository
+func (repo *ReportRepoImpl) FindReport(ctx context.Context, id int64) (report *entity.Report, err error) {
+	const (
+		sql = `SELECT ` + reportColumns + ` FROM abuse_reports WHERE id = ? LIMIT 1`
+	)
+
+	rows := make([]*reportRow, 0, 1)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, id).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to find report = %v err = %v", id, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.Wrapf(errors.ErrResourceNotFound, "report = %v not found", id)
+	}
+
+	return rows[0].toEntity(), nil
+}
+
+// ListReports method is implementation for ReportRepository
+func (repo *ReportRepoImpl) ListReports(ctx context.Context, status entity.ReportStatus, limit int) (reports []*entity.Report, err error) {
+	const (
+		sql = `SELECT ` + reportColumns + `
+			FROM abuse_reports
+			WHERE status = ?
+			ORDER BY created_at, id
+			LIMIT ?`
+	)
+
+	rows := make([]*reportRow, 0, limit)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, string(status), limit).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list reports of status = %v err = %v", status, err)
+	}
+
+	reports = make([]*entity.Report, 0, len(rows))
+	for _, row := range rows {
+		reports = append(reports, row.toEntity())
+	}
+
+	return reports, nil
+}
+
+// ModerateReport method is implementation for ReportRepository
+func (repo *ReportRepoImpl) ModerateReport(ctx context.Context, report *entity.Report, action *entity.ModerationAction) (err error) {
+	const (
+		updateSQL = `UPDATE abuse_reports SET status = ?, resolution = ?, updated_at = ?
+			WHERE id = ? AND status <> ?`
+		insertSQL = `INSERT INTO "moderation_actions" ("report_id","actor_id","action","note","created_at")
+			VALUES (?,?,?,?,?)`
+	)
+
+	return repo.writeDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(
+			updateSQL,
+			string(report.Status),
+			string(report.Resolution),
+			report.UpdatedAt.UnixMilli(),
+			report.ID,
+			string(entity.ReportResolved),
+		)
+		if result.Error != nil {
+			return errors.Wrapf(errors.ErrInternal, "failed to update report = %v err = %v", report.ID, result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.Wrapf(errors.ErrConflict, "report = %v is resolved", report.ID)
+		}
+
+		err := tx.Exec(
+			insertSQL,
+			report.ID,
+			action.ActorID,
+			string(action.Action),
+			action.Note,
+			action.CreatedAt.UnixMilli(),
+		).Error
+		if err != nil {
+			return errors.Wrapf(errors.ErrInternal, "failed to store action of report = %v err = %v", report.ID, err)
+		}
+
+		return nil
+	})
+}
+
+// ListActions method is implementation for ReportRepository
+func (repo *ReportRepoImpl) ListActions(ctx context.Context, reportID int64) (actions []*entity.ModerationAction, err error) {
+	const (
+		sql = `SELECT id, report_id, actor_id, action, note, created_at
+			FROM moderation_actions
+			WHERE report_id = ?
+			ORDER BY id`
+	)
+
+	rows := make([]*actionRow, 0)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, reportID).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list actions of report = %v err = %v", reportID, err)
+	}
+
+	actions = make([]*entity.ModerationAction, 0, len(rows))
+	for _, row := range rows {
+		actions = append(actions, &entity.ModerationAction{
+			ID:        row.ID,
+			ReportID:  row.ReportID,
+			ActorID:   row.ActorID,
+			Action:    entity.ModerationActionType(row.Action),
+			Note:      row.Note,
+			CreatedAt: time.UnixMilli(row.CreatedAt).UTC(),
+		})
+	}
+
+	return actions, nil
+}
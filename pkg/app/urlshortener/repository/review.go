@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/db"
+	"url-shortener/pkg/errors"
+)
+
+// ReviewRepository define review queue of flagged urls repository layer
+type ReviewRepository interface {
+	// CreateReview store pending review, a resolved review of a deleted url with the same short id is replaced
+	CreateReview(
+		ctx context.Context,
+		review *entity.Review,
+	) (err error)
+
+	// FindReview find review by workspace and short id
+	FindReview(
+		ctx context.Context,
+		workspaceID string,
+		short string,
+	) (review *entity.Review, err error)
+
+	// ListReviews list reviews of status oldest first
+	ListReviews(
+		ctx context.Context,
+		status entity.ReviewStatus,
+		limit int,
+	) (reviews []*entity.Review, err error)
+
+	// ResolveReview set status of pending review, ErrConflict when it is already resolved
+	ResolveReview(
+		ctx context.Context,
+		workspaceID string,
+		short string,
+		status entity.ReviewStatus,
+		reviewerID string,
+		reviewedAt time.Time,
+	) (err error)
+}
+
+// reviewRow is link_reviews table row
+type reviewRow struct {
+	WorkspaceID string `gorm:"column:workspace_id"`
+	Short       string `gorm:"column:short"`
+	OwnerID     string `gorm:"column:owner_id"`
+	Score       int    `gorm:"column:score"`
+	Signals     string `gorm:"column:signals"` // Signals is json array
+	Status      string `gorm:"column:status"`
+	CreatedAt   int64  `gorm:"column:created_at"`
+	ReviewedAt  int64  `gorm:"column:reviewed_at"` // ReviewedAt zero means pending
+	ReviewerID  string `gorm:"column:reviewer_id"`
+}
+
+func (row *reviewRow) toEntity() (*entity.Review, error) {
+	review := &entity.Review{
+		WorkspaceID: row.WorkspaceID,
+		Short:       row.Short,
+		OwnerID:     row.OwnerID,
+		Score:       row.Score,
+		Status:      entity.ReviewStatus(row.Status),
+		CreatedAt:   time.UnixMilli(row.CreatedAt).UTC(),
+		ReviewerID:  row.ReviewerID,
+	}
+
+	if err := json.Unmarshal([]byte(row.Signals), &review.Signals); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to decode signals of review = %v err = %v", review.Key(), err)
+	}
+
+	if row.ReviewedAt != 0 {
+		t := time.UnixMilli(row.ReviewedAt).UTC()
+		review.ReviewedAt = &t
+	}
+
+	return review, nil
+}
+
+const reviewColumns = `workspace_id, short, owner_id, score, signals, status, created_at, reviewed_at, reviewer_id`
+
+var _ ReviewRepository = &ReviewRepoImpl{}
+
+// ReviewRepoImpl is implementation for ReviewRepository
+type ReviewRepoImpl struct {
+	readDB  *gorm.DB
+	writeDB *gorm.DB
+}
+
+// NewReviewRepository ReviewRepository constructor
+func NewReviewRepository(conn db.Connection) *ReviewRepoImpl {
+	return &ReviewRepoImpl{
+		readDB:  conn.ReadDB(),
+		writeDB: conn.WriteDB(),
+	}
+}
+
+// CreateReview method is implementation for ReviewRepository
+func (repo *ReviewRepoImpl) CreateReview(ctx context.Context, review *entity.Review) (err error) {
+	const (
+		sql = `INSERT INTO "link_reviews" ("workspace_id","short","owner_id","score","signals","status","created_at")
+			VALUES (?,?,?,?,?,?,?)
+			ON CONFLICT ("workspace_id","short") DO UPDATE SET
+				"owner_id" = EXCLUDED."owner_id",
+				"score" = EXCLUDED."score",
+				"signals" = EXCLUDED."signals",
+				"status" = EXCLUDED."status",
+				"created_at" = EXCLUDED."created_at",
+				"reviewed_at" = 0,
+				"reviewer_id" = ''`
+	)
+
+	signals := review.Signals
+	if signals == nil {
+		signals = []entity.Signal{}
+	}
+	data, err := json.Marshal(signals)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to encode signals of review = %v err = %v", review.Key(), err)
+	}
+
+	result := repo.writeDB.WithContext(ctx).Exec(
+		sql,
+		review.WorkspaceID,
+		review.Short,
+		review.OwnerID,
+		review.Score,
+		string(data),
+		string(entity.ReviewPending),
+		review.CreatedAt.UnixMilli(),
+	)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to store review = %v err = %v", review.Key(), result.Error)
+	}
+
+	return nil
+}
+
+// FindReview method is implementation for ReviewRepository
+func (repo *ReviewRepoImpl) FindReview(ctx context.Context, workspaceID string, short string) (review *entity.Review, err error) {
+	const (
+		sql = `SELECT ` + reviewColumns + ` FROM link_reviews WHERE workspace_id = ? AND short = ? LIMIT 1`
+	)
+
+	rows := make([]*reviewRow, 0, 1)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, workspaceID, short).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to find review = %v err = %v", entity.JoinKey(workspaceID, short), err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.Wrapf(errors.ErrResourceNotFound, "review = %v not found", entity.JoinKey(workspaceID, short))
+	}
+
+	return rows[0].toEntity()
+}
+
+// ListReviews method is implementation for ReviewRepository
+func (repo *ReviewRepoImpl) ListReviews(ctx context.Context, status entity.ReviewStatus, limit int) (reviews []*entity.Review, err error) {
+	const (
+		sql = `SELECT ` + reviewColumns + `
+			FROM link_reviews
+			WHERE status = ?
+			ORDER BY created_at, workspace_id, short
+			LIMIT ?`
+	)
+
+	rows := make([]*reviewRow, 0, limit)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, string(status), limit).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list reviews of status = %v err = %v", status, err)
+	}
+
+	reviews = make([]*entity.Review, 0, len(rows))
+	for _, row := range rows {
+		review, err := row.toEntity()
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// ResolveReview method is implementation for ReviewRepository
+func (repo *ReviewRepoImpl) ResolveReview(
+	ctx context.Context,
+	workspaceID string,
+	short string,
+	status entity.ReviewStatus,
+	reviewerID string,
+	reviewedAt time.Time,
+) (err error) {
+	const (
+		sql = `UPDATE link_reviews SET status = ?, reviewer_id = ?, reviewed_at = ?
+			WHERE workspace_id = ? AND short = ? AND status = ?`
+	)
+
+	result := repo.writeDB.WithContext(ctx).Exec(
+		sql,
+		string(status),
+		reviewerID,
+		reviewedAt.UnixMilli(),
+		workspaceID,
+		short,
+		string(entity.ReviewPending),
+	)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to resolve review = %v err = %v", entity.JoinKey(workspaceID, short), result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrConflict, "review = %v is not pending", entity.JoinKey(workspaceID, short))
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+// ExpiryState filter shortened URL by expire time
+type ExpiryState string
+
+const (
+	ExpiryStateAll     ExpiryState = ""        // ExpiryStateAll not filter by expire time
+	ExpiryStateActive  ExpiryState = "active"  // ExpiryStateActive not expired yet
+	ExpiryStateExpired ExpiryState = "expired" // ExpiryStateExpired already expired
+)
+
+// SearchMode define how SearchFilter.Query match title and destination
+type SearchMode string
+
+const (
+	SearchModePrefix   SearchMode = "prefix"   // SearchModePrefix title or destination start with query
+	SearchModeFullText SearchMode = "fulltext" // SearchModeFullText title or destination contain all words of query
+)
+
+// SearchFilter define filter of SearchShortenedURLs, zero value field is not filtered
+// destination is only searchable when it is not encrypted, title is always searchable
+type SearchFilter struct {
+	Tag         string
+	CreatedFrom *time.Time // CreatedFrom is inclusive
+	CreatedTo   *time.Time // CreatedTo is exclusive
+	State       ExpiryState
+	Host        string
+	Query       string
+	Mode        SearchMode
+
+	// OwnerID only return urls of the owner, empty means all owners
+	OwnerID string
+
+	// WorkspaceID only return urls of the workspace, empty means the default workspace
+	WorkspaceID string
+
+	// Cursor is the next cursor of previous page, empty means the first page
+	Cursor string
+	Limit  int
+}
+
+// searchRow is shortenedURLRow with the exact created_at for cursor
+type searchRow struct {
+	shortenedURLRow
+	CursorCreatedAt int64 `gorm:"column:cursor_created_at"`
+}
+
+// searchCursor is position of the last row in a page, rows are order by created_at and short desc
+type searchCursor struct {
+	CreatedAt int64
+	Short     string
+}
+
+func (c *searchCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CreatedAt, 10) + ":" + c.Short))
+}
+
+func decodeSearchCursor(cursor string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "cursor = %v is invalid", cursor)
+	}
+
+	createdAt, short, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "cursor = %v is invalid", cursor)
+	}
+
+	c := &searchCursor{Short: short}
+	if c.CreatedAt, err = strconv.ParseInt(createdAt, 10, 64); err != nil {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "cursor = %v is invalid", cursor)
+	}
+
+	return c, nil
+}
+
+// SearchShortenedURLs method is implementation for Repository
+func (repo *RepoImpl) SearchShortenedURLs(ctx context.Context, filter *SearchFilter) (shortenedURLs []*entity.ShortenedURL, next string, err error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	conditions = append(conditions, `workspace_id = ?`)
+	args = append(args, filter.WorkspaceID)
+
+	if filter.Cursor != "" {
+		c, err := decodeSearchCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, `(created_at, short) < (?, ?)`)
+		args = append(args, c.CreatedAt, c.Short)
+	}
+
+	if filter.OwnerID != "" {
+		conditions = append(conditions, `owner_id = ?`)
+		args = append(args, filter.OwnerID)
+	}
+
+	if filter.Tag != "" {
+		tag, _ := json.Marshal([]string{filter.Tag})
+		conditions = append(conditions, `tags @> ?::jsonb`)
+		args = append(args, string(tag))
+	}
+
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, filter.CreatedFrom.UnixMilli())
+	}
+
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, filter.CreatedTo.UnixMilli())
+	}
+
+	switch filter.State {
+	case ExpiryStateActive:
+		conditions = append(conditions, `expired_at > ?`)
+		args = append(args, time.Now().UnixMilli())
+	case ExpiryStateExpired:
+		conditions = append(conditions, `expired_at <= ?`)
+		args = append(args, time.Now().UnixMilli())
+	}
+
+	if filter.Host != "" {
+		conditions = append(conditions, `original_host = ?`)
+		args = append(args, strings.ToLower(filter.Host))
+	}
+
+	if filter.Query != "" {
+		switch filter.Mode {
+		case SearchModeFullText:
+			conditions = append(conditions, `search_vector @@ plainto_tsquery('simple', ?)`)
+			args = append(args, filter.Query)
+		default:
+			prefix := escapeLike(filter.Query) + "%"
+			conditions = append(conditions, `((key_id = '' AND original_url LIKE ?) OR lower(title) LIKE lower(?))`)
+			args = append(args, prefix, prefix)
+		}
+	}
+
+	sql := `SELECT ` + shortenedURLColumns + `, created_at as cursor_created_at
+		FROM shortened_urls
+		WHERE ` + strings.Join(conditions, ` AND `) + `
+		ORDER BY created_at DESC, short DESC
+		LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows := make([]*searchRow, 0, filter.Limit)
+	err = repo.readDB.
+		WithContext(ctx).
+		Raw(sql, args...).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, "", errors.Wrapf(errors.ErrInternal, "failed to search shortened url err = %v", err)
+	}
+
+	shortenedURLs = make([]*entity.ShortenedURL, 0, len(rows))
+	for _, row := range rows {
+		shortenedURL, err := repo.toEntity(&row.shortenedURLRow)
+		if err != nil {
+			return nil, "", err
+		}
+		shortenedURLs = append(shortenedURLs, shortenedURL)
+	}
+
+	// a full page means there may be more rows
+	if len(rows) != 0 && len(rows) == filter.Limit {
+		last := rows[len(rows)-1]
+		next = (&searchCursor{CreatedAt: last.CursorCreatedAt, Short: last.Short}).encode()
+	}
+
+	return shortenedURLs, next, nil
+}
+
+// escapeLike escape LIKE wildcard of user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/db"
+	"url-shortener/pkg/errors"
+)
+
+// WorkspaceRepository define workspace and custom domain repository layer
+type WorkspaceRepository interface {
+	// CreateWorkspace store workspace, ErrConflict when id already exist
+	CreateWorkspace(
+		ctx context.Context,
+		workspace *entity.Workspace,
+	) (err error)
+
+	// FindWorkspace find workspace with its domains by id
+	FindWorkspace(
+		ctx context.Context,
+		id string,
+	) (workspace *entity.Workspace, err error)
+
+	// ListWorkspaces list workspaces with their domains of the owner, empty owner means all owners
+	ListWorkspaces(
+		ctx context.Context,
+		ownerID string,
+	) (workspaces []*entity.Workspace, err error)
+
+	// SetFallbackURL update fallback url of workspace, empty means none
+	SetFallbackURL(
+		ctx context.Context,
+		id string,
+		fallbackURL string,
+	) (err error)
+
+	// AddDomain store domain of workspace, ErrConflict when host already belong to a workspace
+	AddDomain(
+		ctx context.Context,
+		domain *entity.Domain,
+	) (err error)
+
+	// FindDomain find domain by host
+	FindDomain(
+		ctx context.Context,
+		host string,
+	) (domain *entity.Domain, err error)
+
+	// VerifyDomain mark domain ownership is verified
+	VerifyDomain(
+		ctx context.Context,
+		host string,
+		verifiedAt time.Time,
+	) (err error)
+}
+
+const (
+	// workspaceCacheKeyPrefix is local cache key prefix of workspace
+	workspaceCacheKeyPrefix = "Workspace:"
+
+	// domainCacheKeyPrefix is local cache key prefix of domain
+	domainCacheKeyPrefix = "Domain:"
+
+	// workspaceCacheExpireSeconds is short, so verified domain takes effect on other instances soon
+	workspaceCacheExpireSeconds = 60
+)
+
+// workspaceRow is workspaces table row
+type workspaceRow struct {
+	ID          string `gorm:"column:id"`
+	Name        string `gorm:"column:name"`
+	OwnerID     string `gorm:"column:owner_id"`
+	CreatedAt   int64  `gorm:"column:created_at"`
+	FallbackURL string `gorm:"column:fallback_url"`
+}
+
+// domainRow is workspace_domains table row
+type domainRow struct {
+	Host        string `gorm:"column:host"`
+	WorkspaceID string `gorm:"column:workspace_id"`
+	Token       string `gorm:"column:token"`
+	CreatedAt   int64  `gorm:"column:created_at"`
+	VerifiedAt  int64  `gorm:"column:verified_at"` // VerifiedAt zero means not verified
+}
+
+func (row *domainRow) toEntity() *entity.Domain {
+	domain := &entity.Domain{
+		Host:        row.Host,
+		WorkspaceID: row.WorkspaceID,
+		Token:       row.Token,
+		CreatedAt:   time.UnixMilli(row.CreatedAt).UTC(),
+	}
+
+	if row.VerifiedAt != 0 {
+		t := time.UnixMilli(row.VerifiedAt).UTC()
+		domain.VerifiedAt = &t
+	}
+
+	return domain
+}
+
+var _ WorkspaceRepository = &WorkspaceRepoImpl{}
+
+// WorkspaceRepoImpl is implementation for WorkspaceRepository
+type WorkspaceRepoImpl struct {
+	readDB  *gorm.DB
+	writeDB *gorm.DB
+	cache   *freecache.Cache
+}
+
+// NewWorkspaceRepository WorkspaceRepository constructor
+func NewWorkspaceRepository(conn db.Connection) *WorkspaceRepoImpl {
+	// In bytes, workspaces and domains are few and small
+	cacheSize := 10 * 1024 * 1024
+
+	return &WorkspaceRepoImpl{
+		readDB:  conn.ReadDB(),
+		writeDB: conn.WriteDB(),
+		cache:   freecache.NewCache(cacheSize),
+	}
+}
+
+// CreateWorkspace method is implementation for WorkspaceRepository
+func (repo *WorkspaceRepoImpl) CreateWorkspace(ctx context.Context, workspace *entity.Workspace) (err error) {
+	const (
+		sql = `INSERT INTO "workspaces" ("id","name","owner_id","created_at") VALUES (?,?,?,?) ON CONFLICT ("id") DO NOTHING`
+	)
+
+	result := repo.writeDB.WithContext(ctx).Exec(
+		sql,
+		workspace.ID,
+		workspace.Name,
+		workspace.OwnerID,
+		workspace.CreatedAt.UnixMilli(),
+	)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to store workspace id = %v err = %v", workspace.ID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrConflict, "workspace id = %v already exist", workspace.ID)
+	}
+
+	return nil
+}
+
+// FindWorkspace method is implementation for WorkspaceRepository
+// the workspace is cached in local cache for a short while, it is read on every request of its domains
+func (repo *WorkspaceRepoImpl) FindWorkspace(ctx context.Context, id string) (workspace *entity.Workspace, err error) {
+	const (
+		sql = `SELECT id, name, owner_id, created_at, fallback_url FROM workspaces WHERE id = ? LIMIT 1`
+	)
+
+	cacheKey := []byte(workspaceCacheKeyPrefix + id)
+	if data, err := repo.cache.Get(cacheKey); err == nil {
+		workspace = &entity.Workspace{}
+		if err := json.Unmarshal(data, workspace); err == nil {
+			return workspace, nil
+		}
+	}
+
+	rows := make([]*workspaceRow, 0, 1)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, id).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to find workspace id = %v err = %v", id, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.Wrapf(errors.ErrResourceNotFound, "workspace id = %v not found", id)
+	}
+
+	workspaces, err := repo.withDomains(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	workspace = workspaces[0]
+
+	data, _ := json.Marshal(workspace)
+	if err := repo.cache.Set(cacheKey, data, workspaceCacheExpireSeconds); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("fail to write workspace=%v into cache", id)
+	}
+
+	return workspace, nil
+}
+
+// ListWorkspaces method is implementation for WorkspaceRepository
+func (repo *WorkspaceRepoImpl) ListWorkspaces(ctx context.Context, ownerID string) (workspaces []*entity.Workspace, err error) {
+	const (
+		sql = `SELECT id, name, owner_id, created_at, fallback_url
+			FROM workspaces
+			WHERE ? = '' OR owner_id = ?
+			ORDER BY id`
+	)
+
+	rows := make([]*workspaceRow, 0)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, ownerID, ownerID).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list workspaces of owner = %v err = %v", ownerID, err)
+	}
+
+	return repo.withDomains(ctx, rows)
+}
+
+// SetFallbackURL method is implementation for WorkspaceRepository
+// other instances may redirect to the former fallback url until their local cache expires
+func (repo *WorkspaceRepoImpl) SetFallbackURL(ctx context.Context, id string, fallbackURL string) (err error) {
+	const (
+		sql = `UPDATE workspaces SET fallback_url = ? WHERE id = ?`
+	)
+
+	result := repo.writeDB.WithContext(ctx).Exec(sql, fallbackURL, id)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to set fallback url of workspace id = %v err = %v", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrResourceNotFound, "workspace id = %v not found", id)
+	}
+
+	repo.cache.Del([]byte(workspaceCacheKeyPrefix + id))
+
+	return nil
+}
+
+// AddDomain method is implementation for WorkspaceRepository
+func (repo *WorkspaceRepoImpl) AddDomain(ctx context.Context, domain *entity.Domain) (err error) {
+	const (
+		sql = `INSERT INTO "workspace_domains" ("host","workspace_id","token","created_at") VALUES (?,?,?,?) ON CONFLICT ("host") DO NOTHING`
+	)
+
+	result := repo.writeDB.WithContext(ctx).Exec(
+		sql,
+		domain.Host,
+		domain.WorkspaceID,
+		domain.Token,
+		domain.CreatedAt.UnixMilli(),
+	)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to store domain host = %v err = %v", domain.Host, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrConflict, "domain host = %v already exist", domain.Host)
+	}
+
+	repo.cache.Del([]byte(workspaceCacheKeyPrefix + domain.WorkspaceID))
+	repo.cache.Del([]byte(domainCacheKeyPrefix + domain.Host))
+
+	return nil
+}
+
+// FindDomain method is implementation for WorkspaceRepository
+// unknown host is also cached, so requests of the main host do not hit database
+func (repo *WorkspaceRepoImpl) FindDomain(ctx context.Context, host string) (domain *entity.Domain, err error) {
+	const (
+		sql = `SELECT host, workspace_id, token, created_at, verified_at FROM workspace_domains WHERE host = ? LIMIT 1`
+	)
+
+	row := &domainRow{}
+
+	cacheKey := []byte(domainCacheKeyPrefix + host)
+	data, err := repo.cache.Get(cacheKey)
+	if err == nil {
+		_ = json.Unmarshal(data, row)
+	} else {
+		rows := make([]*domainRow, 0, 1)
+		if err := repo.readDB.WithContext(ctx).Raw(sql, host).Scan(&rows).Error; err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "failed to find domain host = %v err = %v", host, err)
+		}
+
+		// empty row means host not found
+		if len(rows) != 0 {
+			row = rows[0]
+		}
+
+		data, _ = json.Marshal(row)
+		if err := repo.cache.Set(cacheKey, data, workspaceCacheExpireSeconds); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("fail to write domain=%v into cache", host)
+		}
+	}
+
+	if row.Host == "" {
+		return nil, errors.Wrapf(errors.ErrResourceNotFound, "domain host = %v not found", host)
+	}
+
+	return row.toEntity(), nil
+}
+
+// VerifyDomain method is implementation for WorkspaceRepository
+// other instances may serve the unverified domain from local cache until it expires
+func (repo *WorkspaceRepoImpl) VerifyDomain(ctx context.Context, host string, verifiedAt time.Time) (err error) {
+	const (
+		sql = `UPDATE workspace_domains SET verified_at = ? WHERE host = ? RETURNING workspace_id`
+	)
+
+	workspaceIDs := make([]string, 0, 1)
+	if err := repo.writeDB.WithContext(ctx).Raw(sql, verifiedAt.UnixMilli(), host).Scan(&workspaceIDs).Error; err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to verify domain host = %v err = %v", host, err)
+	}
+
+	if len(workspaceIDs) == 0 {
+		return errors.Wrapf(errors.ErrResourceNotFound, "domain host = %v not found", host)
+	}
+
+	repo.cache.Del([]byte(workspaceCacheKeyPrefix + workspaceIDs[0]))
+	repo.cache.Del([]byte(domainCacheKeyPrefix + host))
+
+	return nil
+}
+
+// withDomains convert rows to entities and load their domains in one query
+func (repo *WorkspaceRepoImpl) withDomains(ctx context.Context, rows []*workspaceRow) ([]*entity.Workspace, error) {
+	const (
+		sql = `SELECT host, workspace_id, token, created_at, verified_at
+			FROM workspace_domains
+			WHERE workspace_id IN ?
+			ORDER BY created_at, host`
+	)
+
+	workspaces := make([]*entity.Workspace, 0, len(rows))
+	byID := make(map[string]*entity.Workspace, len(rows))
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		workspace := &entity.Workspace{
+			ID:          row.ID,
+			Name:        row.Name,
+			OwnerID:     row.OwnerID,
+			CreatedAt:   time.UnixMilli(row.CreatedAt).UTC(),
+			FallbackURL: row.FallbackURL,
+		}
+		workspaces = append(workspaces, workspace)
+		byID[row.ID] = workspace
+		ids = append(ids, row.ID)
+	}
+
+	if len(ids) == 0 {
+		return workspaces, nil
+	}
+
+	domains := make([]*domainRow, 0)
+	if err := repo.readDB.WithContext(ctx).Raw(sql, ids).Scan(&domains).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list domains of workspaces err = %v", err)
+	}
+
+	for _, row := range domains {
+		if workspace, ok := byID[row.WorkspaceID]; ok {
+			workspace.Domains = append(workspace.Domains, row.toEntity())
+		}
+	}
+
+	return workspaces, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// defaultPorts is the port which is stripped from the host of scheme
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// CanonicalURL return the canonical form of url, so the same destination is checked and deduplicated as one url.
+// The host is lower case punycode without the default port and the trailing dot, an empty path is "/",
+// query params are sorted by key and params match trackingParams are removed.
+// A tracking param ending with "*" match by prefix, e.g. "utm_*". Url which can not be parsed is returned as is
+func CanonicalURL(rawURL string, trackingParams []string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return rawURL
+	}
+
+	host, port := u.Hostname(), u.Port()
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) == nil {
+		if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+			host = ascii
+		}
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" && port != defaultPorts[u.Scheme] {
+		host = host + ":" + port
+	}
+	u.Host = host
+
+	if u.Path == "" && u.RawPath == "" {
+		u.Path = "/"
+	}
+
+	u.RawQuery = canonicalQuery(u.RawQuery, trackingParams)
+	u.ForceQuery = false
+
+	return u.String()
+}
+
+// canonicalQuery sort params by key and remove tracking params, the encoding of every param is kept
+func canonicalQuery(rawQuery string, trackingParams []string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	type param struct {
+		key string
+		raw string
+	}
+
+	params := make([]param, 0, strings.Count(rawQuery, "&")+1)
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+
+		key := raw
+		if i := strings.IndexByte(raw, '='); i >= 0 {
+			key = raw[:i]
+		}
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+
+		if isTrackingParam(key, trackingParams) {
+			continue
+		}
+		params = append(params, param{key: key, raw: raw})
+	}
+
+	// values of the same key keep their order, some servers read them as a list
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].key < params[j].key
+	})
+
+	raws := make([]string, 0, len(params))
+	for _, p := range params {
+		raws = append(raws, p.raw)
+	}
+
+	return strings.Join(raws, "&")
+}
+
+// isTrackingParam check key match any tracking param case-insensitively
+func isTrackingParam(key string, trackingParams []string) bool {
+	key = strings.ToLower(key)
+	for _, p := range trackingParams {
+		p = strings.ToLower(p)
+		if prefix := strings.TrimSuffix(p, "*"); prefix != p {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+			continue
+		}
+		if key == p {
+			return true
+		}
+	}
+
+	return false
+}
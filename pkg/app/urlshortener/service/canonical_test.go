@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tracking := []string{"utm_*", "fbclid"}
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "Canonical",
+			url:  "https://www.dcard.tw/f?a=1",
+			want: "https://www.dcard.tw/f?a=1",
+		},
+		{
+			name: "LowerHost",
+			url:  "HTTPS://WWW.Dcard.TW./f/Path",
+			want: "https://www.dcard.tw/f/Path",
+		},
+		{
+			name: "DefaultPort",
+			url:  "http://www.dcard.tw:80/f",
+			want: "http://www.dcard.tw/f",
+		},
+		{
+			name: "OtherPort",
+			url:  "https://www.dcard.tw:8443/f",
+			want: "https://www.dcard.tw:8443/f",
+		},
+		{
+			name: "EmptyPath",
+			url:  "https://www.dcard.tw?b=2",
+			want: "https://www.dcard.tw/?b=2",
+		},
+		{
+			name: "IDN",
+			url:  "https://bücher.example/katalog",
+			want: "https://xn--bcher-kva.example/katalog",
+		},
+		{
+			name: "IPv6",
+			url:  "http://[2001:DB8::1]:80/",
+			want: "http://[2001:db8::1]/",
+		},
+		{
+			name: "SortQuery",
+			url:  "https://www.dcard.tw/search?q=a%20b&forum=tech&q=c&empty",
+			want: "https://www.dcard.tw/search?empty&forum=tech&q=a%20b&q=c",
+		},
+		{
+			name: "TrackingParams",
+			url:  "https://www.dcard.tw/f?UTM_source=mail&fbclid=x&page=2&utm_campaign=summer",
+			want: "https://www.dcard.tw/f?page=2",
+		},
+		{
+			name: "OnlyTrackingParams",
+			url:  "https://www.dcard.tw/f?utm_source=mail#top",
+			want: "https://www.dcard.tw/f#top",
+		},
+		{
+			name: "NotAbsolute",
+			url:  "www.dcard.tw/f",
+			want: "www.dcard.tw/f",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CanonicalURL(tt.url, tracking))
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLDedupe(t *testing.T) {
+	const (
+		originalURL = "https://WWW.dcard.tw:443/f?utm_source=mail&page=2"
+		canonical   = "https://www.dcard.tw/f?page=2"
+	)
+	principal := &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeCreate}}
+	existing := &entity.ShortenedURL{
+		Short:       "6Xme5Xwp",
+		OriginalURL: canonical,
+		OwnerID:     "alice",
+		ExpiredAt:   time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name      string
+		opts      *ShortURLOption
+		duplicate *entity.ShortenedURL
+		err       error
+		stored    bool
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "Duplicate",
+			opts:      &ShortURLOption{Dedupe: true},
+			duplicate: existing,
+			want:      "6Xme5Xwp",
+		},
+		{
+			name:   "NoDuplicate",
+			opts:   &ShortURLOption{Dedupe: true},
+			err:    errors.ErrResourceNotFound,
+			stored: true,
+		},
+		{
+			name:    "FindFailed",
+			opts:    &ShortURLOption{Dedupe: true},
+			err:     errors.ErrInternal,
+			wantErr: errors.ErrInternal,
+		},
+		{
+			name:   "DedupeOff",
+			stored: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.opts != nil && tt.opts.Dedupe {
+				repo.EXPECT().FindDuplicateShortenedURL(mock.Anything, "", "alice", originalURL, mock.Anything).Return(tt.duplicate, tt.err)
+			}
+			if tt.stored {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+					return shortenedURL.OriginalURL == originalURL
+				})).Return(nil)
+			}
+
+			ctx := auth.NewContext(context.Background(), principal)
+			srv := New(repo, bf, WithTrackingParams("utm_*"))
+			shortenedURL, err := srv.ShortURL(ctx, originalURL, tt.opts)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ShortURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				if tt.want != "" {
+					assert.Equal(t, tt.want, shortenedURL.Short)
+				} else {
+					assert.Equal(t, originalURL, shortenedURL.OriginalURL)
+				}
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLsDedupe(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindDuplicateShortenedURL(mock.Anything, "", "", "https://www.dcard.tw/f", mock.Anything).
+		Return(&entity.ShortenedURL{Short: "6Xme5Xwp", OriginalURL: "https://www.dcard.tw/f"}, nil)
+	repo.EXPECT().StoreShortenedURLs(mock.Anything, mock.MatchedBy(func(shortenedURLs []*entity.ShortenedURL) bool {
+		return len(shortenedURLs) == 1 && shortenedURLs[0].OriginalURL == "https://www.dcard.tw"
+	})).RunAndReturn(func(ctx context.Context, shortenedURLs []*entity.ShortenedURL) ([]string, error) {
+		return []string{shortenedURLs[0].Key()}, nil
+	})
+	bf := bm.NewFilter(t)
+	bf.EXPECT().AddMany(mock.Anything, mock.Anything)
+
+	results := New(repo, bf).ShortURLs(context.Background(), []*ShortURLItem{
+		{URL: "https://www.dcard.tw/f", Opts: &ShortURLOption{Dedupe: true}},
+		{URL: "https://www.dcard.tw"},
+	})
+
+	if assert.NoError(t, results[0].Err) {
+		assert.Equal(t, "6Xme5Xwp", results[0].ShortenedURL.Short)
+	}
+	if assert.NoError(t, results[1].Err) {
+		assert.Equal(t, "https://www.dcard.tw", results[1].ShortenedURL.OriginalURL)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+// takeClick count one click of click limited url, ErrShortenedURLExhausted when it has no click left.
+// The counter reject clicks of exhausted url without touching the datastore, every click it allows is
+// persisted by a conditional increment, so clicks never exceed max clicks even when the counter is down or reset
+func (srv *shortenedURLServiceImpl) takeClick(ctx context.Context, shortenedURL *entity.ShortenedURL, now time.Time) error {
+	key := shortenedURL.Key()
+	ttl := shortenedURL.ExpiredAt.Sub(now)
+
+	if srv.clicks != nil {
+		ok, err := srv.clicks.Take(ctx, key, shortenedURL.MaxClicks-shortenedURL.Clicks, ttl)
+		switch {
+		case err != nil:
+			log.Ctx(ctx).Warn().Err(err).Msgf("failed to take click of short = %v, count it in datastore only", key)
+		case !ok:
+			return exhausted(shortenedURL)
+		}
+	}
+
+	clicks, err := srv.repo.IncrementClicks(ctx, shortenedURL.WorkspaceID, shortenedURL.Short)
+	if err != nil {
+		// the counter is behind the datastore, e.g. it was seeded by a stale row
+		if errors.Is(err, errors.ErrShortenedURLExhausted) && srv.clicks != nil {
+			if err := srv.clicks.Exhaust(ctx, key, ttl); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msgf("failed to exhaust clicks of short = %v", key)
+			}
+		}
+		return err
+	}
+
+	shortenedURL.Clicks = clicks
+	return nil
+}
+
+// exhausted return ErrShortenedURLExhausted of click limited url
+func exhausted(shortenedURL *entity.ShortenedURL) error {
+	return errors.Wrapf(
+		errors.ErrShortenedURLExhausted,
+		"the short = %v has used up its %v clicks",
+		shortenedURL.Key(),
+		shortenedURL.MaxClicks,
+	)
+}
@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	bm "url-shortener/pkg/bloom/mocks"
+	cm "url-shortener/pkg/clicklimit/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func Test_shortenedURLServiceImpl_RetrieveShortenedURLWithMaxClicks(t *testing.T) {
+	tests := []struct {
+		name      string
+		clicks    int64
+		take      bool
+		taken     bool
+		takeErr   error
+		increment bool
+		incrErr   error
+		exhaust   bool
+		want      int64
+		err       error
+	}{
+		{
+			name:      "Taken",
+			clicks:    1,
+			take:      true,
+			taken:     true,
+			increment: true,
+			want:      2,
+		},
+		{
+			name:   "CounterExhausted",
+			clicks: 1,
+			take:   true,
+			err:    errors.ErrShortenedURLExhausted,
+		},
+		{
+			name:      "CounterBehind",
+			clicks:    1,
+			take:      true,
+			taken:     true,
+			increment: true,
+			incrErr:   errors.ErrShortenedURLExhausted,
+			exhaust:   true,
+			err:       errors.ErrShortenedURLExhausted,
+		},
+		{
+			name:      "CounterDown",
+			clicks:    1,
+			take:      true,
+			takeErr:   errors.ErrInternal,
+			increment: true,
+			want:      2,
+		},
+		{
+			name:   "PersistedExhausted",
+			clicks: 3,
+			err:    errors.ErrShortenedURLExhausted,
+		},
+		{
+			name:      "PersistFailed",
+			clicks:    1,
+			take:      true,
+			taken:     true,
+			increment: true,
+			incrErr:   errors.ErrInternal,
+			err:       errors.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+				Short:       "6Xme5Xwp",
+				OriginalURL: "https://www.dcard.tw/reset?token=x",
+				ExpiredAt:   time.Now().Add(time.Hour),
+				MaxClicks:   3,
+				Clicks:      tt.clicks,
+			}, nil)
+			if tt.increment {
+				repo.EXPECT().IncrementClicks(mock.Anything, "", "6Xme5Xwp").Return(tt.want, tt.incrErr)
+			}
+
+			counter := cm.NewCounter(t)
+			if tt.take {
+				counter.EXPECT().Take(mock.Anything, "6Xme5Xwp", 3-tt.clicks, mock.Anything).Return(tt.taken, tt.takeErr)
+			}
+			if tt.exhaust {
+				counter.EXPECT().Exhaust(mock.Anything, "6Xme5Xwp", mock.Anything).Return(nil)
+			}
+
+			srv := New(repo, bf, WithClickCounter(counter))
+			shortenedURL, err := srv.RetrieveShortenedURL(context.Background(), "6Xme5Xwp")
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "RetrieveShortenedURL() error = %v, expected %v", err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, shortenedURL.Clicks)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_RetrieveShortenedURLUnlimited(t *testing.T) {
+	bf := bm.NewFilter(t)
+	bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").
+		Return(&entity.ShortenedURL{Short: "6Xme5Xwp", ExpiredAt: time.Now().Add(time.Hour)}, nil)
+
+	// neither the counter nor the datastore count clicks of unlimited url
+	srv := New(repo, bf, WithClickCounter(cm.NewCounter(t)))
+	_, err := srv.RetrieveShortenedURL(context.Background(), "6Xme5Xwp")
+	assert.NoError(t, err)
+}
+
+func Test_shortenedURLServiceImpl_ShortURLWithMaxClicks(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *ShortURLOption
+		stored  bool
+		wantErr error
+	}{
+		{
+			name:   "SingleUse",
+			opts:   &ShortURLOption{MaxClicks: 1},
+			stored: true,
+		},
+		{
+			name:   "DedupeIgnored",
+			opts:   &ShortURLOption{MaxClicks: 1, Dedupe: true},
+			stored: true,
+		},
+		{
+			name:    "Negative",
+			opts:    &ShortURLOption{MaxClicks: -1},
+			wantErr: errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.stored {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+					return shortenedURL.MaxClicks == tt.opts.MaxClicks && shortenedURL.Clicks == 0
+				})).Return(nil)
+			}
+
+			_, err := New(repo, bf).ShortURL(context.Background(), "https://www.dcard.tw/reset?token=x", tt.opts)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ShortURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
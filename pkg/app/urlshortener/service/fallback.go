@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"url-shortener/pkg/abuse"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+// ReasonExpired is errors.Detail reason of expired url which has a fallback url
+const ReasonExpired = "EXPIRED"
+
+// checkFallback return fallback url of option, it is checked like the original url.
+// Empty means the url has no fallback
+func (srv *shortenedURLServiceImpl) checkFallback(ctx context.Context, opts *ShortURLOption) (string, error) {
+	if opts == nil || opts.FallbackURL == "" {
+		return "", nil
+	}
+
+	if err := srv.checkOriginalURL(ctx, opts.FallbackURL); err != nil {
+		return "", err
+	}
+
+	return opts.FallbackURL, nil
+}
+
+// scoreFallback score fallback url like a scheduled destination, the verdict of the higher score is kept
+func (srv *shortenedURLServiceImpl) scoreFallback(ctx context.Context, fallback string, verdict abuse.Verdict) (abuse.Verdict, error) {
+	if fallback == "" {
+		return verdict, nil
+	}
+
+	return srv.scoreSchedule(ctx, []entity.Destination{{URL: fallback}}, verdict)
+}
+
+// expired return ErrShortenedURLExpire, the fallback url of the url or else of the workspace of context
+// is in detail metadata
+func expired(ctx context.Context, shortenedURL *entity.ShortenedURL, now time.Time) error {
+	e := errors.ErrShortenedURLExpire
+
+	fallback := shortenedURL.FallbackURL
+	if workspace, ok := WorkspaceFromContext(ctx); ok && fallback == "" {
+		fallback = workspace.FallbackURL
+	}
+	if fallback != "" {
+		e = e.WithDetails(errors.Detail{
+			Reason: ReasonExpired,
+			Domain: Domain,
+			Metadata: map[string]interface{}{
+				"fallbackUrl": fallback,
+			},
+		})
+	}
+
+	return errors.Wrapf(
+		e,
+		"the short = %v is expire, now=%v expireAt=%v",
+		shortenedURL.Key(),
+		now.Format(time.RFC3339),
+		shortenedURL.ExpiredAt.UTC().Format(time.RFC3339),
+	)
+}
+
+// FallbackOf return the fallback url of ErrShortenedURLExpire, false when the url and its workspace have none
+func FallbackOf(err error) (string, bool) {
+	e := errors.TryConvert(err)
+	if e == nil {
+		return "", false
+	}
+
+	for _, detail := range e.Details {
+		if detail.Reason != ReasonExpired || detail.Domain != Domain {
+			continue
+		}
+		if fallback, ok := detail.Metadata["fallbackUrl"].(string); ok && fallback != "" {
+			return fallback, true
+		}
+	}
+
+	return "", false
+}
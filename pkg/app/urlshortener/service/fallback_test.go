@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func Test_shortenedURLServiceImpl_RetrieveShortenedURLFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallback  string
+		workspace *entity.Workspace
+		want      string
+	}{
+		{
+			name:     "URL",
+			fallback: "https://www.dcard.tw/f/expired",
+			want:     "https://www.dcard.tw/f/expired",
+		},
+		{
+			name:      "URLOverWorkspace",
+			fallback:  "https://www.dcard.tw/f/expired",
+			workspace: &entity.Workspace{ID: "team-a", FallbackURL: "https://team-a.example.com"},
+			want:      "https://www.dcard.tw/f/expired",
+		},
+		{
+			name:      "Workspace",
+			workspace: &entity.Workspace{ID: "team-a", FallbackURL: "https://team-a.example.com"},
+			want:      "https://team-a.example.com",
+		},
+		{
+			name: "None",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			workspaceID := ""
+			if tt.workspace != nil {
+				ctx = NewWorkspaceContext(ctx, tt.workspace)
+				workspaceID = tt.workspace.ID
+			}
+
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, entity.JoinKey(workspaceID, "6Xme5Xwp")).Return(true)
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, workspaceID, "6Xme5Xwp").Return(&entity.ShortenedURL{
+				Short:       "6Xme5Xwp",
+				OriginalURL: "https://www.dcard.tw/f/sale",
+				ExpiredAt:   time.Now().Add(-time.Hour),
+				WorkspaceID: workspaceID,
+				FallbackURL: tt.fallback,
+			}, nil)
+
+			_, err := New(repo, bf).RetrieveShortenedURL(ctx, "6Xme5Xwp")
+			assert.Truef(t, errors.Is(err, errors.ErrShortenedURLExpire), "RetrieveShortenedURL() error = %v", err)
+
+			fallback, ok := FallbackOf(err)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, fallback)
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLWithFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *ShortURLOption
+		want    string
+		wantErr error
+	}{
+		{
+			name: "KeptAsIs",
+			opts: &ShortURLOption{FallbackURL: "HTTPS://www.Dcard.tw/f/expired?utm_source=mail"},
+			want: "HTTPS://www.Dcard.tw/f/expired?utm_source=mail",
+		},
+		{
+			name: "DedupeIgnored",
+			opts: &ShortURLOption{FallbackURL: "https://www.dcard.tw/f/expired", Dedupe: true},
+			want: "https://www.dcard.tw/f/expired",
+		},
+		{
+			name:    "NotHTTP",
+			opts:    &ShortURLOption{FallbackURL: "javascript:alert(1)"},
+			wantErr: errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.wantErr == nil {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+					return shortenedURL.FallbackURL == tt.want
+				})).Return(nil)
+			}
+
+			srv := New(repo, bf, WithTrackingParams("utm_*"))
+			_, err := srv.ShortURL(context.Background(), "https://www.dcard.tw/f/sale", tt.opts)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ShortURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_UpdateShortenedURLFallback(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "team-a", Scopes: []auth.Scope{auth.ScopeManage}})
+	fallback, empty := "https://www.dcard.tw/f/expired", ""
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{
+		Short:       "K2MY8LEp",
+		OwnerID:     "team-a",
+		FallbackURL: "https://www.dcard.tw/f/old",
+	}, nil)
+	repo.EXPECT().UpdateShortenedURL(mock.Anything, mock.Anything).Return(nil)
+
+	srv := New(repo, bm.NewFilter(t))
+
+	shortenedURL, err := srv.UpdateShortenedURL(ctx, "K2MY8LEp", &UpdateOption{FallbackURL: &fallback})
+	if assert.NoError(t, err) {
+		assert.Equal(t, fallback, shortenedURL.FallbackURL)
+	}
+
+	shortenedURL, err = srv.UpdateShortenedURL(ctx, "K2MY8LEp", &UpdateOption{FallbackURL: &empty})
+	if assert.NoError(t, err) {
+		assert.Empty(t, shortenedURL.FallbackURL)
+	}
+
+	invalid := "ftp://www.dcard.tw"
+	_, err = srv.UpdateShortenedURL(ctx, "K2MY8LEp", &UpdateOption{FallbackURL: &invalid})
+	assert.True(t, errors.Is(err, errors.ErrInvalidInput))
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+)
+
+// maxBlockDuration is the max duration of a manual block
+const maxBlockDuration = 30 * 24 * time.Hour
+
+// ListBlockedClients is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) ListBlockedClients(ctx context.Context) (blocks []*guard.Block, err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if srv.guard == nil {
+		return []*guard.Block{}, nil
+	}
+
+	return srv.guard.ListBlocks(ctx)
+}
+
+// BlockClient is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) BlockClient(ctx context.Context, client string, duration time.Duration) (block *guard.Block, err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if srv.guard == nil {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "enumeration guard is disabled")
+	}
+
+	if client == "" {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "client is empty")
+	}
+
+	if duration <= 0 || duration > maxBlockDuration {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "block duration = %v must be positive and at most %v", duration, maxBlockDuration)
+	}
+
+	client = guard.ClientOf(client)
+	if err := srv.guard.Block(ctx, client, guard.ReasonManual, duration); err != nil {
+		return nil, err
+	}
+
+	return &guard.Block{
+		Client:    client,
+		Reason:    guard.ReasonManual,
+		ExpiredAt: time.Now().Add(duration).UTC(),
+	}, nil
+}
+
+// UnblockClient is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) UnblockClient(ctx context.Context, client string) (err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	if srv.guard == nil {
+		return errors.Wrap(errors.ErrInvalidInput, "enumeration guard is disabled")
+	}
+
+	return srv.guard.Unblock(ctx, guard.ClientOf(client))
+}
+
+// recordMiss count a 404 or expired lookup against the client of context, a failed guard is only logged
+func (srv *shortenedURLServiceImpl) recordMiss(ctx context.Context) {
+	if srv.guard == nil {
+		return
+	}
+
+	client, ok := guard.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if err := srv.guard.RecordMiss(ctx, client); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to record miss of client %v", client)
+	}
+}
+
+// checkAdmin deny anonymous context and principal without admin scope, admin is never anonymous even when auth is disabled
+func checkAdmin(ctx context.Context) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return errors.Wrap(errors.ErrUnauthorized, "admin credential is required")
+	}
+	if !p.IsAdmin() {
+		return errors.Wrapf(errors.ErrForbidden, "principal = %v is not admin", p.ID)
+	}
+	return nil
+}
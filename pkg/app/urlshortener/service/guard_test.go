@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+	gm "url-shortener/pkg/guard/mocks"
+)
+
+func Test_shortenedURLServiceImpl_RetrieveShortenedURLWithGuard(t *testing.T) {
+	const client = "203.0.113.7"
+
+	tests := []struct {
+		name    string
+		exist   bool
+		found   *entity.ShortenedURL
+		findErr error
+		client  string
+		miss    bool
+		err     error
+	}{
+		{
+			name:   "Found",
+			exist:  true,
+			found:  &entity.ShortenedURL{Short: "6Xme5Xwp", ExpiredAt: time.Now().Add(time.Hour)},
+			client: client,
+		},
+		{
+			name:   "NotInBloom",
+			client: client,
+			miss:   true,
+			err:    errors.ErrPageNotFound,
+		},
+		{
+			name:   "NotFound",
+			exist:  true,
+			client: client,
+			miss:   true,
+			err:    errors.ErrPageNotFound,
+		},
+		{
+			name:   "Expired",
+			exist:  true,
+			found:  &entity.ShortenedURL{Short: "6Xme5Xwp", ExpiredAt: time.Now().Add(-time.Hour)},
+			client: client,
+			miss:   true,
+			err:    errors.ErrShortenedURLExpire,
+		},
+		{
+			name:   "Exhausted",
+			exist:  true,
+			found:  &entity.ShortenedURL{Short: "6Xme5Xwp", ExpiredAt: time.Now().Add(time.Hour), MaxClicks: 1, Clicks: 1},
+			client: client,
+			err:    errors.ErrShortenedURLExhausted,
+		},
+		{
+			name:    "RepositoryFailure",
+			exist:   true,
+			findErr: errors.ErrInternal,
+			client:  client,
+			err:     errors.ErrInternal,
+		},
+		{
+			name: "NoClient",
+			err:  errors.ErrPageNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(tt.exist)
+
+			repo := mocks.NewRepository(t)
+			switch {
+			case tt.found != nil:
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(tt.found, nil)
+			case tt.findErr != nil:
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(nil, tt.findErr)
+			case tt.exist:
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(nil, errors.ErrResourceNotFound)
+			}
+
+			g := gm.NewGuard(t)
+			if tt.miss {
+				g.EXPECT().RecordMiss(mock.Anything, client).Return(nil)
+			}
+
+			ctx := context.Background()
+			if tt.client != "" {
+				ctx = guard.NewContext(ctx, tt.client)
+			}
+
+			_, err := New(repo, bf, WithGuard(g)).RetrieveShortenedURL(ctx, "6Xme5Xwp")
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Truef(t, errors.Is(err, tt.err), "RetrieveShortenedURL() error = %v, expected %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_BlockClient(t *testing.T) {
+	admin := &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	alice := &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeManage}}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		client    string
+		duration  time.Duration
+		blocked   string
+		err       error
+	}{
+		{
+			name:      "Success",
+			principal: admin,
+			client:    "203.0.113.7",
+			duration:  time.Hour,
+			blocked:   "203.0.113.7",
+		},
+		{
+			name:      "IPv6",
+			principal: admin,
+			client:    "2001:db8:1:2::abcd",
+			duration:  time.Hour,
+			blocked:   "2001:db8:1:2::/64",
+		},
+		{
+			name:      "NotAdmin",
+			principal: alice,
+			client:    "203.0.113.7",
+			duration:  time.Hour,
+			err:       errors.ErrForbidden,
+		},
+		{
+			name:     "Anonymous",
+			client:   "203.0.113.7",
+			duration: time.Hour,
+			err:      errors.ErrUnauthorized,
+		},
+		{
+			name:      "InvalidDuration",
+			principal: admin,
+			client:    "203.0.113.7",
+			duration:  365 * 24 * time.Hour,
+			err:       errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gm.NewGuard(t)
+			if tt.blocked != "" {
+				g.EXPECT().Block(mock.Anything, tt.blocked, guard.ReasonManual, tt.duration).Return(nil)
+			}
+
+			ctx := auth.NewContext(context.Background(), tt.principal)
+			block, err := New(mocks.NewRepository(t), bm.NewFilter(t), WithGuard(g)).BlockClient(ctx, tt.client, tt.duration)
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "BlockClient() error = %v, expected %v", err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.blocked, block.Client)
+				assert.Equal(t, guard.ReasonManual, block.Reason)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ListBlockedClients(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}})
+
+	// disabled guard has no blocks
+	blocks, err := New(mocks.NewRepository(t), bm.NewFilter(t)).ListBlockedClients(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	expected := []*guard.Block{{Client: "203.0.113.7", Reason: guard.ReasonAuto, ExpiredAt: time.Now().Add(time.Hour)}}
+	g := gm.NewGuard(t)
+	g.EXPECT().ListBlocks(mock.Anything).Return(expected, nil)
+
+	blocks, err = New(mocks.NewRepository(t), bm.NewFilter(t), WithGuard(g)).ListBlockedClients(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, blocks)
+}
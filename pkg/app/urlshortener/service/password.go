@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+// UnlockShortenedURL is implement ShortenedURLService method
+// the url is looked up even it is expired, so its redirect answers why it can not be used after unlocking
+func (srv *shortenedURLServiceImpl) UnlockShortenedURL(ctx context.Context, short, password, client string) (err error) {
+	shortenedURL, err := srv.lookupShortenedURL(ctx, short)
+	if err != nil {
+		return err
+	}
+
+	if !shortenedURL.Protected() {
+		return nil
+	}
+
+	// the attempt is counted before the password is compared, so concurrent guesses never pass the limit
+	key := shortenedURL.Key()
+	attempted := false
+	if srv.lockout != nil {
+		err := srv.lockout.Attempt(ctx, key, client)
+		switch {
+		case errors.Is(err, errors.ErrTooManyRequests):
+			return err
+		case err != nil:
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to count attempt of short = %v", key)
+		default:
+			attempted = true
+		}
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(shortenedURL.PasswordHash), []byte(password)); err != nil {
+		return errors.Wrapf(errors.ErrPasswordRequired, "wrong password of short = %v", key)
+	}
+
+	if attempted {
+		if err := srv.lockout.Succeed(ctx, key, client); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to take back attempt of short = %v", key)
+		}
+	}
+
+	return nil
+}
+
+type unlockedKey struct{}
+
+// NewUnlockedContext return context whose redirect of protected url is unlocked,
+// the transport put it after it verifies the proof of UnlockShortenedURL, e.g. a signed cookie
+func NewUnlockedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unlockedKey{}, true)
+}
+
+// isUnlocked check redirect of protected url is unlocked in context
+func isUnlocked(ctx context.Context) bool {
+	unlocked, _ := ctx.Value(unlockedKey{}).(bool)
+	return unlocked
+}
+
+// hashPassword return bcrypt hash of option password, empty when there is no password
+func hashPassword(opts *ShortURLOption) (string, error) {
+	if opts == nil || opts.Password == "" {
+		return "", nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.Wrapf(errors.ErrInternal, "failed to hash password err = %v", err)
+	}
+
+	return string(hash), nil
+}
+
+// redact return copy of protected url without original url and scheduled urls, so lookup never leak the destination,
+// the password hash is kept to tell the url is protected and never leave the service
+func redact(shortenedURL *entity.ShortenedURL) *entity.ShortenedURL {
+	if shortenedURL == nil || !shortenedURL.Protected() {
+		return shortenedURL
+	}
+
+	redacted := *shortenedURL
+	redacted.OriginalURL = ""
+	if len(shortenedURL.Schedule) != 0 {
+		redacted.Schedule = make([]entity.Destination, len(shortenedURL.Schedule))
+		for i, d := range shortenedURL.Schedule {
+			redacted.Schedule[i] = entity.Destination{At: d.At}
+		}
+	}
+
+	return &redacted
+}
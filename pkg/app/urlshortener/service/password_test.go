@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/lockout"
+	lm "url-shortener/pkg/lockout/mocks"
+)
+
+func newProtectedURL(t *testing.T, password string) *entity.ShortenedURL {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &entity.ShortenedURL{
+		Short:        "6Xme5Xwp",
+		OriginalURL:  "https://www.dcard.tw/secret",
+		ExpiredAt:    time.Now().Add(time.Hour),
+		PasswordHash: string(hash),
+	}
+}
+
+func Test_shortenedURLServiceImpl_UnlockShortenedURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		protected  bool
+		attemptErr error
+		succeed    bool
+		err        error
+	}{
+		{
+			name:      "Unlocked",
+			password:  "open sesame",
+			protected: true,
+			succeed:   true,
+		},
+		{
+			name:      "WrongPassword",
+			password:  "guess",
+			protected: true,
+			err:       errors.ErrPasswordRequired,
+		},
+		{
+			name:       "LockedOut",
+			password:   "open sesame",
+			protected:  true,
+			attemptErr: lockout.LockedOut(lockout.KindLink, time.Minute),
+			err:        errors.ErrTooManyRequests,
+		},
+		{
+			name:       "LockoutDown",
+			password:   "open sesame",
+			protected:  true,
+			attemptErr: errors.ErrInternal,
+		},
+		{
+			name:     "NotProtected",
+			password: "anything",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortenedURL := newProtectedURL(t, "open sesame")
+			if !tt.protected {
+				shortenedURL.PasswordHash = ""
+			}
+
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(shortenedURL, nil)
+
+			l := lm.NewLockout(t)
+			if tt.protected {
+				l.EXPECT().Attempt(mock.Anything, "6Xme5Xwp", "203.0.113.7").Return(tt.attemptErr)
+			}
+			if tt.succeed {
+				l.EXPECT().Succeed(mock.Anything, "6Xme5Xwp", "203.0.113.7").Return(nil)
+			}
+
+			srv := New(repo, bf, WithLockout(l))
+			err := srv.UnlockShortenedURL(context.Background(), "6Xme5Xwp", tt.password, "203.0.113.7")
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "UnlockShortenedURL() error = %v, expected %v", err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_RetrieveShortenedURLProtected(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+	}{
+		{
+			name: "Locked",
+			ctx:  context.Background(),
+			err:  errors.ErrPasswordRequired,
+		},
+		{
+			name: "Unlocked",
+			ctx:  NewUnlockedContext(context.Background()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(newProtectedURL(t, "open sesame"), nil)
+
+			shortenedURL, err := New(repo, bf).RetrieveShortenedURL(tt.ctx, "6Xme5Xwp")
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "RetrieveShortenedURL() error = %v, expected %v", err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, "https://www.dcard.tw/secret", shortenedURL.OriginalURL)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_LookupShortenedURLProtected(t *testing.T) {
+	bf := bm.NewFilter(t)
+	bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(newProtectedURL(t, "open sesame"), nil)
+
+	shortenedURL, err := New(repo, bf).LookupShortenedURL(context.Background(), "6Xme5Xwp")
+	if assert.NoError(t, err) {
+		assert.Empty(t, shortenedURL.OriginalURL)
+		assert.True(t, shortenedURL.Protected())
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLWithPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *ShortURLOption
+		stored  bool
+		wantErr error
+	}{
+		{
+			name:   "Protected",
+			opts:   &ShortURLOption{Password: "open sesame"},
+			stored: true,
+		},
+		{
+			name:   "DedupeIgnored",
+			opts:   &ShortURLOption{Password: "open sesame", Dedupe: true},
+			stored: true,
+		},
+		{
+			name:    "TooLong",
+			opts:    &ShortURLOption{Password: strings.Repeat("p", 73)},
+			wantErr: errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.stored {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+					return bcrypt.CompareHashAndPassword([]byte(shortenedURL.PasswordHash), []byte(tt.opts.Password)) == nil
+				})).Return(nil)
+			}
+
+			shortenedURL, err := New(repo, bf).ShortURL(context.Background(), "https://www.dcard.tw/secret", tt.opts)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ShortURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.True(t, shortenedURL.Protected())
+			}
+		})
+	}
+}
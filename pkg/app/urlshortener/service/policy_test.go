@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/policy"
+	pm "url-shortener/pkg/policy/mocks"
+)
+
+func Test_shortenedURLServiceImpl_ShortURLWithPolicy(t *testing.T) {
+	rejected := errors.ErrInvalidInput.WithDetails(errors.Detail{Reason: policy.ReasonSelfLoop, Domain: policy.Domain})
+
+	tests := []struct {
+		name   string
+		url    string
+		policy func() *pm.Policy
+		err    error
+	}{
+		{
+			name: "NoPolicyJavascript",
+			url:  "javascript:alert(1)",
+			err:  errors.ErrInvalidInput,
+		},
+		{
+			name: "NoPolicyBareString",
+			url:  "www.dcard.tw",
+			err:  errors.ErrInvalidInput,
+		},
+		{
+			name: "Rejected",
+			url:  "https://sho.rt/K2MY8LEp",
+			policy: func() *pm.Policy {
+				p := pm.NewPolicy(t)
+				p.EXPECT().Check(mock.Anything, "https://sho.rt/K2MY8LEp").Return(rejected)
+				return p
+			},
+			err: errors.ErrInvalidInput,
+		},
+		{
+			name: "Allowed",
+			url:  "https://www.dcard.tw/f",
+			policy: func() *pm.Policy {
+				p := pm.NewPolicy(t)
+				p.EXPECT().Check(mock.Anything, "https://www.dcard.tw/f").Return(nil)
+				return p
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.err == nil {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.Anything).Return(nil)
+			}
+
+			var opts []Option
+			if tt.policy != nil {
+				opts = append(opts, WithPolicy(tt.policy()))
+			}
+			srv := New(repo, bf, opts...)
+
+			_, err := srv.ShortURL(context.Background(), tt.url, nil)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Truef(t, errors.Is(err, tt.err), "ShortURL(%v) error = %v, expected %v", tt.url, err, tt.err)
+
+			// batch reject the same url by its own result
+			results := srv.ShortURLs(context.Background(), []*ShortURLItem{{URL: tt.url}})
+			assert.Truef(t, errors.Is(results[0].Err, tt.err), "ShortURLs(%v) error = %v, expected %v", tt.url, results[0].Err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/quota"
+)
+
+// QuotaUsage is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) QuotaUsage(ctx context.Context) (usages []*quota.Usage, err error) {
+	usages = make([]*quota.Usage, 0, 2)
+	if srv.quota == nil {
+		return usages, nil
+	}
+
+	for _, subject := range quotaSubjects(ctx) {
+		usage, err := srv.quota.Usage(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
+
+// consumeQuota charge shortened urls to the quota of credential and workspace of context
+func (srv *shortenedURLServiceImpl) consumeQuota(ctx context.Context, shortenedURLs []*entity.ShortenedURL) error {
+	if srv.quota == nil {
+		return nil
+	}
+
+	links := make([]quota.Link, 0, len(shortenedURLs))
+	for _, shortenedURL := range shortenedURLs {
+		links = append(links, quota.Link{Key: shortenedURL.Key(), ExpiredAt: shortenedURL.ExpiredAt})
+	}
+
+	return srv.quota.Consume(ctx, quotaSubjects(ctx), links)
+}
+
+// releaseQuota remove urls from active urls of quota, refund when urls are failed to store
+func (srv *shortenedURLServiceImpl) releaseQuota(ctx context.Context, keys []string, refund bool) {
+	if srv.quota == nil || len(keys) == 0 {
+		return
+	}
+
+	srv.quota.Release(ctx, keys, refund)
+}
+
+// quotaSubjects return who urls created in context are charged to,
+// api key is charged by key id, other credentials by owner, and the workspace if any
+func quotaSubjects(ctx context.Context) []quota.Subject {
+	subjects := make([]quota.Subject, 0, 2)
+
+	if subject, ok := credentialSubject(ctx); ok {
+		subjects = append(subjects, subject)
+	}
+
+	if id := workspaceID(ctx); id != "" {
+		subjects = append(subjects, quota.Subject{Kind: quota.KindWorkspace, ID: id})
+	}
+
+	return subjects
+}
+
+// credentialSubject return api key id of api key credential, otherwise principal id
+func credentialSubject(ctx context.Context) (quota.Subject, bool) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return quota.Subject{}, false
+	}
+
+	if p.Method == auth.MethodAPIKey && p.KeyID != "" {
+		return quota.Subject{Kind: quota.KindAPIKey, ID: p.KeyID}, true
+	}
+	return quota.Subject{Kind: quota.KindOwner, ID: p.ID}, true
+}
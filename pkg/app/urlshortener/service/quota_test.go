@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/quota"
+	qm "url-shortener/pkg/quota/mocks"
+)
+
+func Test_shortenedURLServiceImpl_ShortURLWithQuota(t *testing.T) {
+	principal := &auth.Principal{ID: "alice", KeyID: "Xa8LmQ2pRt0z", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeCreate}}
+	subjects := []quota.Subject{{Kind: quota.KindAPIKey, ID: "Xa8LmQ2pRt0z"}}
+
+	tests := []struct {
+		name  string
+		repo  func() *mocks.Repository
+		quota func() *qm.Quota
+		err   error
+	}{
+		{
+			name: "Success",
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.Anything).Return(nil)
+				return repo
+			},
+			quota: func() *qm.Quota {
+				q := qm.NewQuota(t)
+				q.EXPECT().Consume(mock.Anything, subjects, mock.MatchedBy(func(links []quota.Link) bool {
+					return len(links) == 1 && !links[0].ExpiredAt.IsZero()
+				})).Return(nil)
+				return q
+			},
+		},
+		{
+			name: "Exceeded",
+			repo: func() *mocks.Repository {
+				return mocks.NewRepository(t)
+			},
+			quota: func() *qm.Quota {
+				q := qm.NewQuota(t)
+				q.EXPECT().Consume(mock.Anything, subjects, mock.Anything).Return(errors.ErrTooManyRequests)
+				return q
+			},
+			err: errors.ErrTooManyRequests,
+		},
+		{
+			name: "StoreFailRefund",
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.Anything).Return(errors.ErrInternal)
+				return repo
+			},
+			quota: func() *qm.Quota {
+				q := qm.NewQuota(t)
+				q.EXPECT().Consume(mock.Anything, subjects, mock.Anything).Return(nil)
+				q.EXPECT().Release(mock.Anything, mock.Anything, true)
+				return q
+			},
+			err: errors.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+			if tt.err == nil {
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+			}
+
+			ctx := auth.NewContext(context.Background(), principal)
+			_, err := New(tt.repo(), bf, WithQuota(tt.quota())).ShortURL(ctx, "https://www.dcard.tw", nil)
+			if err != nil || tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "ShortURL() error = %v, expected error %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLsWithQuota(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "alice", Method: "jwt", Scopes: []auth.Scope{auth.ScopeCreate}})
+
+	var conflict string
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().StoreShortenedURLs(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, shortenedURLs []*entity.ShortenedURL) ([]string, error) {
+			// the first url conflict in the first round
+			if conflict == "" {
+				conflict = shortenedURLs[0].Key()
+				return []string{shortenedURLs[1].Key()}, nil
+			}
+			return []string{shortenedURLs[0].Key()}, nil
+		})
+
+	q := qm.NewQuota(t)
+	owner := []quota.Subject{{Kind: quota.KindOwner, ID: "alice"}}
+	q.EXPECT().Consume(mock.Anything, owner, mock.MatchedBy(func(links []quota.Link) bool { return len(links) == 2 })).Return(nil).Once()
+	q.EXPECT().Consume(mock.Anything, owner, mock.MatchedBy(func(links []quota.Link) bool { return len(links) == 1 })).Return(nil).Once()
+	q.EXPECT().Release(mock.Anything, mock.MatchedBy(func(keys []string) bool {
+		return len(keys) == 1 && keys[0] == conflict
+	}), true).Once()
+
+	bf := bm.NewFilter(t)
+	bf.EXPECT().AddMany(mock.Anything, mock.Anything)
+
+	results := New(repo, bf, WithQuota(q)).ShortURLs(ctx, []*ShortURLItem{
+		{URL: "https://www.dcard.tw/a"},
+		{URL: "https://www.dcard.tw/b"},
+	})
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+}
+
+func Test_shortenedURLServiceImpl_DeleteShortenedURLWithQuota(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeManage}})
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{Short: "K2MY8LEp", OwnerID: "alice"}, nil)
+	repo.EXPECT().DeleteShortenedURL(mock.Anything, "", "K2MY8LEp").Return(nil)
+
+	q := qm.NewQuota(t)
+	q.EXPECT().Release(mock.Anything, []string{"K2MY8LEp"}, false)
+
+	assert.NoError(t, New(repo, bm.NewFilter(t), WithQuota(q)).DeleteShortenedURL(ctx, "K2MY8LEp"))
+}
+
+func Test_shortenedURLServiceImpl_QuotaUsage(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "alice", KeyID: "Xa8LmQ2pRt0z", Method: auth.MethodAPIKey})
+	ctx = NewWorkspaceContext(ctx, &entity.Workspace{ID: "team-a", OwnerID: "alice"})
+
+	q := qm.NewQuota(t)
+	q.EXPECT().Usage(mock.Anything, quota.Subject{Kind: quota.KindAPIKey, ID: "Xa8LmQ2pRt0z"}).
+		Return(&quota.Usage{Subject: "apikey:Xa8LmQ2pRt0z"}, nil)
+	q.EXPECT().Usage(mock.Anything, quota.Subject{Kind: quota.KindWorkspace, ID: "team-a"}).
+		Return(&quota.Usage{Subject: "workspace:team-a"}, nil)
+
+	usages, err := New(mocks.NewRepository(t), bm.NewFilter(t), WithQuota(q)).QuotaUsage(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, usages, 2)
+
+	usages, err = New(mocks.NewRepository(t), bm.NewFilter(t)).QuotaUsage(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, usages)
+}
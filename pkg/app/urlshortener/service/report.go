@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+const (
+	maxReportReasonLength   = 1000
+	maxReportContactLength  = 200
+	maxModerationNoteLength = 1000
+)
+
+// ReportShortenedURL is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) ReportShortenedURL(ctx context.Context, short, reason, contact string) (report *entity.Report, err error) {
+	if srv.reports == nil {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "abuse reporting is disabled")
+	}
+
+	reason, contact = strings.TrimSpace(reason), strings.TrimSpace(contact)
+	if reason == "" {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "report reason is empty")
+	}
+	if utf8.RuneCountInString(reason) > maxReportReasonLength {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "report reason is longer than %v", maxReportReasonLength)
+	}
+	if utf8.RuneCountInString(contact) > maxReportContactLength {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "report contact is longer than %v", maxReportContactLength)
+	}
+
+	// expired and disabled urls can be reported, unknown urls can not. Everyone can report urls of any workspace
+	shortenedURL, err := srv.lookupShortenedURL(ctx, short)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	report = &entity.Report{
+		WorkspaceID: shortenedURL.WorkspaceID,
+		Short:       shortenedURL.Short,
+		Reason:      reason,
+		Contact:     contact,
+		Status:      entity.ReportOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := srv.reports.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ListReports is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) ListReports(ctx context.Context, status entity.ReportStatus, limit int) (reports []*entity.Report, err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if srv.reports == nil {
+		return []*entity.Report{}, nil
+	}
+
+	switch status {
+	case "":
+		status = entity.ReportOpen
+	case entity.ReportOpen, entity.ReportTriaged, entity.ReportResolved:
+	default:
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "unknown report status %v", status)
+	}
+
+	switch {
+	case limit <= 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	return srv.reports.ListReports(ctx, status, limit)
+}
+
+// GetReport is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) GetReport(ctx context.Context, id int64) (report *entity.Report, actions []*entity.ModerationAction, err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	if srv.reports == nil {
+		return nil, nil, errors.Wrap(errors.ErrInvalidInput, "abuse reporting is disabled")
+	}
+
+	report, err = srv.reports.FindReport(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actions, err = srv.reports.ListActions(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return report, actions, nil
+}
+
+// TriageReport is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) TriageReport(ctx context.Context, id int64, note string) (report *entity.Report, err error) {
+	report, err = srv.findModeratedReport(ctx, id, note)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Status != entity.ReportOpen {
+		return nil, errors.Wrapf(errors.ErrConflict, "report = %v is %v", id, report.Status)
+	}
+
+	if err := srv.moderateReport(ctx, report, entity.ReportTriaged, "", entity.ActionTriage, note); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ResolveReport is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) ResolveReport(ctx context.Context, id int64, resolution entity.Resolution, note string) (report *entity.Report, err error) {
+	var action entity.ModerationActionType
+	switch resolution {
+	case entity.ResolutionDisabled:
+		action = entity.ActionDisable
+	case entity.ResolutionDismissed:
+		action = entity.ActionDismiss
+	default:
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "resolution = %v must be disabled or dismissed", resolution)
+	}
+
+	report, err = srv.findModeratedReport(ctx, id, note)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Status == entity.ReportResolved {
+		return nil, errors.Wrapf(errors.ErrConflict, "report = %v is resolved", id)
+	}
+
+	// disabling is idempotent so it goes first, a failed audit trail can be retried
+	if resolution == entity.ResolutionDisabled {
+		if err := srv.disableURL(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := srv.moderateReport(ctx, report, entity.ReportResolved, resolution, action, note); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// findModeratedReport check the moderator and the note then find the report
+func (srv *shortenedURLServiceImpl) findModeratedReport(ctx context.Context, id int64, note string) (*entity.Report, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if srv.reports == nil {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "abuse reporting is disabled")
+	}
+
+	if utf8.RuneCountInString(note) > maxModerationNoteLength {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "note is longer than %v", maxModerationNoteLength)
+	}
+
+	return srv.reports.FindReport(ctx, id)
+}
+
+// moderateReport move report to status and record the action of moderator in context
+func (srv *shortenedURLServiceImpl) moderateReport(
+	ctx context.Context,
+	report *entity.Report,
+	status entity.ReportStatus,
+	resolution entity.Resolution,
+	action entity.ModerationActionType,
+	note string,
+) error {
+	now := time.Now().UTC()
+	report.Status = status
+	report.Resolution = resolution
+	report.UpdatedAt = now
+
+	return srv.reports.ModerateReport(ctx, report, &entity.ModerationAction{
+		ReportID:  report.ID,
+		ActorID:   ownerID(ctx),
+		Action:    action,
+		Note:      strings.TrimSpace(note),
+		CreatedAt: now,
+	})
+}
+
+// disableURL disable the reported url, an already deleted or disabled url has nothing to disable
+func (srv *shortenedURLServiceImpl) disableURL(ctx context.Context, report *entity.Report) error {
+	err := srv.repo.DisableShortenedURL(ctx, report.WorkspaceID, report.Short, time.Now().UTC())
+	if errors.Is(err, errors.ErrResourceNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func Test_shortenedURLServiceImpl_ReportShortenedURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  string
+		contact string
+		lookup  bool
+		exist   bool
+		wantErr error
+	}{
+		{
+			name:    "Report",
+			reason:  " phishing page of a bank ",
+			contact: "abuse@example.com",
+			lookup:  true,
+			exist:   true,
+		},
+		{
+			name:    "EmptyReason",
+			reason:  "   ",
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name:    "LongReason",
+			reason:  strings.Repeat("詐", 1001),
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name:    "LongContact",
+			reason:  "phishing",
+			contact: strings.Repeat("a", 201),
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name:    "NotFound",
+			reason:  "phishing",
+			lookup:  true,
+			wantErr: errors.ErrPageNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			reports := mocks.NewReportRepository(t)
+			if tt.lookup {
+				bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(tt.exist)
+			}
+			if tt.exist {
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+					Short:       "6Xme5Xwp",
+					OriginalURL: "https://203.0.113.7/",
+				}, nil)
+				reports.EXPECT().CreateReport(mock.Anything, mock.MatchedBy(func(report *entity.Report) bool {
+					return report.Short == "6Xme5Xwp" && report.Status == entity.ReportOpen
+				})).RunAndReturn(func(ctx context.Context, report *entity.Report) error {
+					report.ID = 7
+					return nil
+				})
+			}
+
+			report, err := New(repo, bf, WithReports(reports)).ReportShortenedURL(context.Background(), "6Xme5Xwp", tt.reason, tt.contact)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ReportShortenedURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, int64(7), report.ID)
+				assert.Equal(t, "phishing page of a bank", report.Reason)
+				assert.Equal(t, tt.contact, report.Contact)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ResolveReport(t *testing.T) {
+	admin := &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	alice := &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeManage}}
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		resolution entity.Resolution
+		status     entity.ReportStatus
+		action     entity.ModerationActionType
+		disabled   bool
+		disableErr error
+		err        error
+	}{
+		{
+			name:       "Disable",
+			principal:  admin,
+			resolution: entity.ResolutionDisabled,
+			status:     entity.ReportTriaged,
+			action:     entity.ActionDisable,
+			disabled:   true,
+		},
+		{
+			name:       "AlreadyDisabled",
+			principal:  admin,
+			resolution: entity.ResolutionDisabled,
+			status:     entity.ReportTriaged,
+			action:     entity.ActionDisable,
+			disabled:   true,
+			disableErr: errors.ErrResourceNotFound,
+		},
+		{
+			name:       "Dismiss",
+			principal:  admin,
+			resolution: entity.ResolutionDismissed,
+			status:     entity.ReportOpen,
+			action:     entity.ActionDismiss,
+		},
+		{
+			name:       "Resolved",
+			principal:  admin,
+			resolution: entity.ResolutionDismissed,
+			status:     entity.ReportResolved,
+			err:        errors.ErrConflict,
+		},
+		{
+			name:       "Anonymous",
+			resolution: entity.ResolutionDisabled,
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "NotAdmin",
+			principal:  alice,
+			resolution: entity.ResolutionDisabled,
+			err:        errors.ErrForbidden,
+		},
+		{
+			name:       "InvalidResolution",
+			principal:  admin,
+			resolution: "deleted",
+			err:        errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			reports := mocks.NewReportRepository(t)
+			if tt.status != "" {
+				reports.EXPECT().FindReport(mock.Anything, int64(7)).Return(&entity.Report{
+					ID:        7,
+					Short:     "6Xme5Xwp",
+					Reason:    "phishing",
+					Status:    tt.status,
+					CreatedAt: time.Now(),
+				}, nil)
+			}
+			if tt.action != "" {
+				reports.EXPECT().ModerateReport(mock.Anything, mock.Anything, mock.MatchedBy(func(action *entity.ModerationAction) bool {
+					return action.ReportID == 7 && action.ActorID == "ops" && action.Action == tt.action && action.Note == "confirmed"
+				})).Return(nil)
+			}
+			if tt.disabled {
+				repo.EXPECT().DisableShortenedURL(mock.Anything, "", "6Xme5Xwp", mock.MatchedBy(func(disabledAt time.Time) bool {
+					return !disabledAt.IsZero()
+				})).Return(tt.disableErr)
+			}
+
+			ctx := auth.NewContext(context.Background(), tt.principal)
+			report, err := New(repo, bm.NewFilter(t), WithReports(reports)).ResolveReport(ctx, 7, tt.resolution, " confirmed ")
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "ResolveReport() error = %v, expected %v", err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, entity.ReportResolved, report.Status)
+				assert.Equal(t, tt.resolution, report.Resolution)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_TriageReport(t *testing.T) {
+	admin := &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}
+
+	reports := mocks.NewReportRepository(t)
+	reports.EXPECT().FindReport(mock.Anything, int64(7)).Return(&entity.Report{ID: 7, Short: "6Xme5Xwp", Status: entity.ReportOpen}, nil).Once()
+	reports.EXPECT().ModerateReport(mock.Anything, mock.Anything, mock.MatchedBy(func(action *entity.ModerationAction) bool {
+		return action.Action == entity.ActionTriage && action.ActorID == "ops"
+	})).Return(nil)
+	reports.EXPECT().FindReport(mock.Anything, int64(8)).Return(&entity.Report{ID: 8, Short: "6Xme5Xwp", Status: entity.ReportTriaged}, nil).Once()
+
+	srv := New(mocks.NewRepository(t), bm.NewFilter(t), WithReports(reports))
+	ctx := auth.NewContext(context.Background(), admin)
+
+	report, err := srv.TriageReport(ctx, 7, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.ReportTriaged, report.Status)
+	}
+
+	_, err = srv.TriageReport(ctx, 8, "")
+	assert.Truef(t, errors.Is(err, errors.ErrConflict), "TriageReport() error = %v, expected %v", err, errors.ErrConflict)
+}
+
+func Test_shortenedURLServiceImpl_RetrieveDisabledShortenedURL(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+		Short:       "6Xme5Xwp",
+		OriginalURL: "https://203.0.113.7/",
+		ExpiredAt:   time.Now().Add(-time.Hour),
+		DisabledAt:  time.Now().Add(-time.Minute),
+	}, nil)
+	bf := bm.NewFilter(t)
+	bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+
+	_, err := New(repo, bf).RetrieveShortenedURL(context.Background(), "6Xme5Xwp")
+	assert.Truef(t, errors.Is(err, errors.ErrShortenedURLDisabled), "RetrieveShortenedURL() error = %v", err)
+}
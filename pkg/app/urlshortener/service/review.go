@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/abuse"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+// ListReviews is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) ListReviews(ctx context.Context, status entity.ReviewStatus, limit int) (reviews []*entity.Review, err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if srv.reviews == nil {
+		return []*entity.Review{}, nil
+	}
+
+	switch status {
+	case "":
+		status = entity.ReviewPending
+	case entity.ReviewPending, entity.ReviewApproved, entity.ReviewRejected:
+	default:
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "unknown review status %v", status)
+	}
+
+	switch {
+	case limit <= 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	reviews, err = srv.reviews.ListReviews(ctx, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// the original url is only kept by the url, which is encrypted at rest when keyring is set
+	for _, review := range reviews {
+		if shortenedURL, err := srv.repo.FindShortenedURL(ctx, review.WorkspaceID, review.Short); err == nil {
+			review.OriginalURL = shortenedURL.OriginalURL
+		}
+	}
+
+	return reviews, nil
+}
+
+// ResolveReview is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) ResolveReview(ctx context.Context, short string, status entity.ReviewStatus) (review *entity.Review, err error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if srv.reviews == nil {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "abuse scoring is disabled")
+	}
+
+	if status != entity.ReviewApproved && status != entity.ReviewRejected {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "review status = %v must be approved or rejected", status)
+	}
+
+	workspace := workspaceID(ctx)
+	review, err = srv.reviews.FindReview(ctx, workspace, short)
+	if err != nil {
+		return nil, err
+	}
+
+	// the url may be already deleted by its owner
+	shortenedURL, err := srv.repo.FindShortenedURL(ctx, workspace, short)
+	switch {
+	case err == nil:
+		review.OriginalURL = shortenedURL.OriginalURL
+	case !errors.Is(err, errors.ErrResourceNotFound):
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := srv.reviews.ResolveReview(ctx, workspace, short, status, ownerID(ctx), now); err != nil {
+		return nil, err
+	}
+	review.Status = status
+	review.ReviewedAt = &now
+	review.ReviewerID = ownerID(ctx)
+
+	// rejected url is removed at once, its short id is kept in the bloom filter and get 404,
+	// approved url redirect at once unless the owner ask for the interstitial page
+	switch {
+	case shortenedURL == nil:
+	case status == entity.ReviewRejected:
+		if err := srv.repo.DeleteShortenedURL(ctx, workspace, short); err != nil {
+			return nil, err
+		}
+		srv.releaseQuota(ctx, []string{review.Key()}, false)
+	case shortenedURL.Interstitial == entity.InterstitialFlagged:
+		if err := srv.repo.UnflagShortenedURL(ctx, workspace, short); err != nil {
+			return nil, err
+		}
+	}
+
+	return review, nil
+}
+
+// scoreURL return abuse verdict of url created in context, rejected url return error.
+// A failed scorer allows the url
+func (srv *shortenedURLServiceImpl) scoreURL(ctx context.Context, originalURL string) (abuse.Verdict, error) {
+	if srv.scorer == nil {
+		return abuse.Verdict{Action: abuse.ActionAllow}, nil
+	}
+
+	var subject string
+	if s, ok := credentialSubject(ctx); ok {
+		subject = s.String()
+	}
+
+	verdict, err := srv.scorer.Score(ctx, CanonicalURL(originalURL, srv.trackingParams), subject)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to score url")
+		return abuse.Verdict{Action: abuse.ActionAllow}, nil
+	}
+
+	if verdict.Action == abuse.ActionReject {
+		return verdict, abuse.Rejected(verdict)
+	}
+
+	return verdict, nil
+}
+
+// setFlagged show interstitial page of flagged url until its review is approved
+func setFlagged(shortenedURL *entity.ShortenedURL, verdict abuse.Verdict) {
+	if verdict.Action == abuse.ActionFlag && shortenedURL.Interstitial == entity.InterstitialOff {
+		shortenedURL.Interstitial = entity.InterstitialFlagged
+	}
+}
+
+// flagURL queue flagged url for review, the url is already stored so a failed queue is only logged
+func (srv *shortenedURLServiceImpl) flagURL(ctx context.Context, shortenedURL *entity.ShortenedURL, verdict abuse.Verdict) {
+	if srv.reviews == nil || verdict.Action != abuse.ActionFlag {
+		return
+	}
+
+	review := &entity.Review{
+		WorkspaceID: shortenedURL.WorkspaceID,
+		Short:       shortenedURL.Short,
+		OwnerID:     shortenedURL.OwnerID,
+		Score:       verdict.Score,
+		Signals:     make([]entity.Signal, 0, len(verdict.Signals)),
+		Status:      entity.ReviewPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	for _, signal := range verdict.Signals {
+		review.Signals = append(review.Signals, entity.Signal{Rule: signal.Rule, Score: signal.Score, Reason: signal.Reason})
+	}
+
+	if err := srv.reviews.CreateReview(ctx, review); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to queue flagged url %v for review", review.Key())
+	}
+}
@@ -0,0 +1,229 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/abuse"
+	am "url-shortener/pkg/abuse/mocks"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func Test_shortenedURLServiceImpl_ShortURLWithAbuse(t *testing.T) {
+	const original = "https://paypal.com.evil.example/login"
+	principal := &auth.Principal{ID: "alice", KeyID: "Xa8LmQ2pRt0z", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeCreate}}
+	signals := []abuse.Signal{{Rule: abuse.RuleCredential, Score: 30, Reason: `path or query contain "login"`}}
+
+	tests := []struct {
+		name         string
+		opts         *ShortURLOption
+		verdict      abuse.Verdict
+		err          error
+		stored       bool
+		flagged      bool
+		interstitial entity.Interstitial
+		wantErr      error
+	}{
+		{
+			name:    "Allow",
+			verdict: abuse.Verdict{Action: abuse.ActionAllow},
+			stored:  true,
+		},
+		{
+			name:         "Flag",
+			verdict:      abuse.Verdict{Action: abuse.ActionFlag, Score: 45, Signals: signals},
+			stored:       true,
+			flagged:      true,
+			interstitial: entity.InterstitialFlagged,
+		},
+		{
+			name:         "FlagInterstitial",
+			opts:         &ShortURLOption{Interstitial: true},
+			verdict:      abuse.Verdict{Action: abuse.ActionFlag, Score: 45, Signals: signals},
+			stored:       true,
+			flagged:      true,
+			interstitial: entity.InterstitialAlways,
+		},
+		{
+			name:    "Reject",
+			verdict: abuse.Verdict{Action: abuse.ActionReject, Score: 90, Signals: signals},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name:   "ScorerFailed",
+			err:    errors.ErrInternal,
+			stored: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer := am.NewScorer(t)
+			scorer.EXPECT().Score(mock.Anything, original, "apikey:Xa8LmQ2pRt0z").Return(tt.verdict, tt.err)
+
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.stored {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.Anything).Return(nil)
+			}
+
+			reviews := mocks.NewReviewRepository(t)
+			if tt.flagged {
+				reviews.EXPECT().CreateReview(mock.Anything, mock.MatchedBy(func(review *entity.Review) bool {
+					return review.OwnerID == "alice" &&
+						review.Score == 45 &&
+						review.Status == entity.ReviewPending &&
+						len(review.Signals) == 1 && review.Signals[0].Rule == abuse.RuleCredential
+				})).Return(nil)
+			}
+
+			ctx := auth.NewContext(context.Background(), principal)
+			shortenedURL, err := New(repo, bf, WithAbuse(scorer, reviews)).ShortURL(ctx, original, tt.opts)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ShortURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, original, shortenedURL.OriginalURL)
+				assert.Equal(t, tt.interstitial, shortenedURL.Interstitial)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLsWithAbuse(t *testing.T) {
+	scorer := am.NewScorer(t)
+	scorer.EXPECT().Score(mock.Anything, "https://www.dcard.tw/f", "").Return(abuse.Verdict{Action: abuse.ActionAllow}, nil)
+	scorer.EXPECT().Score(mock.Anything, "https://203.0.113.7/", "").Return(abuse.Verdict{Action: abuse.ActionFlag, Score: 40}, nil)
+	scorer.EXPECT().Score(mock.Anything, "https://203.0.113.7/login", "").Return(abuse.Verdict{Action: abuse.ActionReject, Score: 80}, nil)
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().StoreShortenedURLs(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx context.Context, shortenedURLs []*entity.ShortenedURL) ([]string, error) {
+			keys := make([]string, 0, len(shortenedURLs))
+			for _, shortenedURL := range shortenedURLs {
+				keys = append(keys, shortenedURL.Key())
+			}
+			return keys, nil
+		})
+	bf := bm.NewFilter(t)
+	bf.EXPECT().AddMany(mock.Anything, mock.Anything)
+
+	reviews := mocks.NewReviewRepository(t)
+	reviews.EXPECT().CreateReview(mock.Anything, mock.MatchedBy(func(review *entity.Review) bool {
+		return review.Score == 40
+	})).Return(nil).Once()
+
+	results := New(repo, bf, WithAbuse(scorer, reviews)).ShortURLs(context.Background(), []*ShortURLItem{
+		{URL: "https://www.dcard.tw/f"},
+		{URL: "https://203.0.113.7/"},
+		{URL: "https://203.0.113.7/login"},
+	})
+
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	assert.Truef(t, errors.Is(results[2].Err, errors.ErrInvalidInput), "ShortURLs() error = %v", results[2].Err)
+}
+
+func Test_shortenedURLServiceImpl_ResolveReview(t *testing.T) {
+	admin := &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	alice := &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeManage}}
+
+	tests := []struct {
+		name         string
+		principal    *auth.Principal
+		status       entity.ReviewStatus
+		interstitial entity.Interstitial
+		deleted      bool
+		unflagged    bool
+		err          error
+	}{
+		{
+			name:         "Approve",
+			principal:    admin,
+			status:       entity.ReviewApproved,
+			interstitial: entity.InterstitialFlagged,
+			unflagged:    true,
+		},
+		{
+			name:         "ApproveInterstitial",
+			principal:    admin,
+			status:       entity.ReviewApproved,
+			interstitial: entity.InterstitialAlways,
+		},
+		{
+			name:         "Reject",
+			principal:    admin,
+			status:       entity.ReviewRejected,
+			interstitial: entity.InterstitialFlagged,
+			deleted:      true,
+		},
+		{
+			name:   "Anonymous",
+			status: entity.ReviewRejected,
+			err:    errors.ErrUnauthorized,
+		},
+		{
+			name:      "NotAdmin",
+			principal: alice,
+			status:    entity.ReviewRejected,
+			err:       errors.ErrForbidden,
+		},
+		{
+			name:      "InvalidStatus",
+			principal: admin,
+			status:    entity.ReviewPending,
+			err:       errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			reviews := mocks.NewReviewRepository(t)
+			if tt.err == nil {
+				reviews.EXPECT().FindReview(mock.Anything, "", "6Xme5Xwp").Return(&entity.Review{
+					Short:     "6Xme5Xwp",
+					OwnerID:   "alice",
+					Score:     45,
+					Status:    entity.ReviewPending,
+					CreatedAt: time.Now(),
+				}, nil)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+					Short:        "6Xme5Xwp",
+					OriginalURL:  "https://203.0.113.7/",
+					Interstitial: tt.interstitial,
+				}, nil)
+				reviews.EXPECT().ResolveReview(mock.Anything, "", "6Xme5Xwp", tt.status, "ops", mock.Anything).Return(nil)
+			}
+			if tt.deleted {
+				repo.EXPECT().DeleteShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(nil)
+			}
+			if tt.unflagged {
+				repo.EXPECT().UnflagShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(nil)
+			}
+
+			ctx := auth.NewContext(context.Background(), tt.principal)
+			review, err := New(repo, bm.NewFilter(t), WithAbuse(am.NewScorer(t), reviews)).ResolveReview(ctx, "6Xme5Xwp", tt.status)
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "ResolveReview() error = %v, expected %v", err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.status, review.Status)
+				assert.Equal(t, "ops", review.ReviewerID)
+				assert.Equal(t, "https://203.0.113.7/", review.OriginalURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"url-shortener/pkg/abuse"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/errors"
+)
+
+const (
+	maxScheduleLength = 20 // maxScheduleLength is the max destination switches of a url
+
+	// ReasonNotActive is errors.Detail reason of url which is not active yet
+	ReasonNotActive = "NOT_ACTIVE"
+
+	// Domain is errors.Detail domain of url shortener
+	Domain = "urlshortener"
+)
+
+// checkSchedule check activate time and schedule of option is between now and the expire time,
+// return destinations order by time, every destination is checked like the original url
+func (srv *shortenedURLServiceImpl) checkSchedule(ctx context.Context, opts *ShortURLOption, now time.Time) ([]entity.Destination, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	expiredAt := newExpiredAt(now, opts)
+	start := now
+	if opts.ActivateAt != nil {
+		if !opts.ActivateAt.Before(expiredAt) {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "activate time = %v must before expire time", opts.ActivateAt.Format(time.RFC3339))
+		}
+		if opts.ActivateAt.After(start) {
+			start = *opts.ActivateAt
+		}
+	}
+
+	if len(opts.Schedule) == 0 {
+		return nil, nil
+	}
+
+	if len(opts.Schedule) > maxScheduleLength {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "schedule is more than %v", maxScheduleLength)
+	}
+
+	schedule := make([]entity.Destination, 0, len(opts.Schedule))
+	for _, d := range opts.Schedule {
+		if !d.At.After(start) || !d.At.Before(expiredAt) {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "schedule time = %v must between activate and expire time", d.At.Format(time.RFC3339))
+		}
+
+		if err := srv.checkOriginalURL(ctx, d.URL); err != nil {
+			return nil, err
+		}
+
+		schedule = append(schedule, entity.Destination{At: d.At.UTC(), URL: d.URL})
+	}
+
+	sort.Slice(schedule, func(i, j int) bool { return schedule[i].At.Before(schedule[j].At) })
+	for i := 1; i < len(schedule); i++ {
+		if schedule[i].At.Unix() == schedule[i-1].At.Unix() {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "schedule time = %v is duplicated", schedule[i].At.Format(time.RFC3339))
+		}
+	}
+
+	return schedule, nil
+}
+
+// scoreSchedule score every destination of schedule, the verdict of the highest score is kept,
+// so a url is flagged or rejected when any destination is
+func (srv *shortenedURLServiceImpl) scoreSchedule(ctx context.Context, schedule []entity.Destination, verdict abuse.Verdict) (abuse.Verdict, error) {
+	for _, d := range schedule {
+		v, err := srv.scoreURL(ctx, d.URL)
+		if err != nil {
+			return v, err
+		}
+		if v.Score > verdict.Score {
+			verdict = v
+		}
+	}
+	return verdict, nil
+}
+
+// notActive return ErrShortenedURLNotActive with its activate time and seconds to it in detail metadata
+func notActive(shortenedURL *entity.ShortenedURL, now time.Time) error {
+	return errors.Wrapf(
+		errors.ErrShortenedURLNotActive.WithDetails(errors.Detail{
+			Reason: ReasonNotActive,
+			Domain: Domain,
+			Metadata: map[string]interface{}{
+				"activateAt": shortenedURL.ActivateAt.UTC().Format(time.RFC3339),
+				"retryAfter": int64(math.Ceil(shortenedURL.ActivateAt.Sub(now).Seconds())),
+			},
+		}),
+		"the short = %v is not active until %v", shortenedURL.Key(), shortenedURL.ActivateAt.UTC().Format(time.RFC3339),
+	)
+}
+
+// RetryAfterOf return seconds to the activate time of ErrShortenedURLNotActive
+func RetryAfterOf(err error) (int64, bool) {
+	e := errors.TryConvert(err)
+	if e == nil {
+		return 0, false
+	}
+
+	for _, detail := range e.Details {
+		if detail.Reason != ReasonNotActive || detail.Domain != Domain {
+			continue
+		}
+		if retryAfter, ok := detail.Metadata["retryAfter"].(int64); ok {
+			return retryAfter, true
+		}
+	}
+
+	return 0, false
+}
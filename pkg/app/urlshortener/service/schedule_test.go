@@ -0,0 +1,202 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func Test_shortenedURLServiceImpl_RetrieveShortenedURLScheduled(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name       string
+		activateAt time.Time
+		schedule   []entity.Destination
+		want       string
+		err        error
+	}{
+		{
+			name:       "NotActive",
+			activateAt: now.Add(time.Hour),
+			err:        errors.ErrShortenedURLNotActive,
+		},
+		{
+			name:       "Activated",
+			activateAt: now.Add(-time.Hour),
+			want:       "https://www.dcard.tw/f/teaser",
+		},
+		{
+			name: "Switched",
+			schedule: []entity.Destination{
+				{At: now.Add(-2 * time.Hour), URL: "https://www.dcard.tw/f/sale"},
+				{At: now.Add(-time.Hour), URL: "https://www.dcard.tw/f/last-call"},
+				{At: now.Add(time.Hour), URL: "https://www.dcard.tw/f/ended"},
+			},
+			want: "https://www.dcard.tw/f/last-call",
+		},
+		{
+			name: "NotSwitchedYet",
+			schedule: []entity.Destination{
+				{At: now.Add(time.Hour), URL: "https://www.dcard.tw/f/sale"},
+			},
+			want: "https://www.dcard.tw/f/teaser",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+				Short:       "6Xme5Xwp",
+				OriginalURL: "https://www.dcard.tw/f/teaser",
+				ExpiredAt:   now.Add(24 * time.Hour),
+				ActivateAt:  tt.activateAt,
+				Schedule:    tt.schedule,
+			}, nil)
+
+			shortenedURL, err := New(repo, bf).RetrieveShortenedURL(context.Background(), "6Xme5Xwp")
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "RetrieveShortenedURL() error = %v, expected %v", err, tt.err)
+				retryAfter, ok := RetryAfterOf(err)
+				assert.True(t, ok)
+				assert.InDelta(t, 3600, retryAfter, 2)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, shortenedURL.OriginalURL)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLWithSchedule(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	activateAt := now.Add(time.Hour)
+	expiredAt := now.Add(48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		opts    *ShortURLOption
+		want    []entity.Destination
+		wantErr error
+	}{
+		{
+			name: "Scheduled",
+			opts: &ShortURLOption{
+				ExpiredAt:  &expiredAt,
+				ActivateAt: &activateAt,
+				Schedule: []entity.Destination{
+					{At: now.Add(3 * time.Hour), URL: "https://WWW.DCARD.TW/f/ended?utm_source=mail"},
+					{At: now.Add(2 * time.Hour), URL: "https://www.dcard.tw/f/sale"},
+				},
+			},
+			want: []entity.Destination{
+				{At: now.Add(2 * time.Hour), URL: "https://www.dcard.tw/f/sale"},
+				{At: now.Add(3 * time.Hour), URL: "https://WWW.DCARD.TW/f/ended?utm_source=mail"},
+			},
+		},
+		{
+			name: "DedupeIgnored",
+			opts: &ShortURLOption{ActivateAt: &activateAt, Dedupe: true},
+		},
+		{
+			name:    "ActivateAfterExpire",
+			opts:    &ShortURLOption{ExpiredAt: &activateAt, ActivateAt: &expiredAt},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name: "SwitchBeforeActivate",
+			opts: &ShortURLOption{
+				ActivateAt: &activateAt,
+				Schedule:   []entity.Destination{{At: now.Add(time.Minute), URL: "https://www.dcard.tw/f/sale"}},
+			},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name: "SwitchAfterExpire",
+			opts: &ShortURLOption{
+				ExpiredAt: &expiredAt,
+				Schedule:  []entity.Destination{{At: expiredAt.Add(time.Minute), URL: "https://www.dcard.tw/f/sale"}},
+			},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name: "Duplicated",
+			opts: &ShortURLOption{
+				Schedule: []entity.Destination{
+					{At: now.Add(time.Hour), URL: "https://www.dcard.tw/f/sale"},
+					{At: now.Add(time.Hour), URL: "https://www.dcard.tw/f/ended"},
+				},
+			},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name: "TooMany",
+			opts: &ShortURLOption{
+				Schedule: func() []entity.Destination {
+					schedule := make([]entity.Destination, 21)
+					for i := range schedule {
+						schedule[i] = entity.Destination{At: now.Add(time.Duration(i+1) * time.Minute), URL: "https://www.dcard.tw/f/sale"}
+					}
+					return schedule
+				}(),
+			},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name: "InvalidDestination",
+			opts: &ShortURLOption{
+				Schedule: []entity.Destination{{At: now.Add(time.Hour), URL: "javascript:alert(1)"}},
+			},
+			wantErr: errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.wantErr == nil {
+				bf.EXPECT().Exist(mock.Anything, mock.Anything).Return(false)
+				bf.EXPECT().Add(mock.Anything, mock.Anything)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+					return shortenedURL.ActivateAt.Equal(activateAt) && assert.ObjectsAreEqual(tt.want, shortenedURL.Schedule)
+				})).Return(nil)
+			}
+
+			_, err := New(repo, bf, WithTrackingParams("utm_*")).ShortURL(context.Background(), "https://www.dcard.tw/f/teaser", tt.opts)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "ShortURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_LookupShortenedURLProtectedSchedule(t *testing.T) {
+	shortenedURL := newProtectedURL(t, "open sesame")
+	shortenedURL.Schedule = []entity.Destination{{At: time.Now().Add(time.Hour), URL: "https://www.dcard.tw/secret/next"}}
+
+	bf := bm.NewFilter(t)
+	bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(shortenedURL, nil)
+
+	redacted, err := New(repo, bf).LookupShortenedURL(context.Background(), "6Xme5Xwp")
+	if assert.NoError(t, err) && assert.Len(t, redacted.Schedule, 1) {
+		assert.Empty(t, redacted.Schedule[0].URL)
+		assert.Equal(t, "https://www.dcard.tw/secret/next", shortenedURL.Schedule[0].URL)
+	}
+}
@@ -3,14 +3,23 @@ package service
 import (
 	"context"
 	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/rs/zerolog/log"
 
+	"url-shortener/pkg/abuse"
 	"url-shortener/pkg/app/urlshortener/entity"
 	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/auth"
 	"url-shortener/pkg/bloom"
+	"url-shortener/pkg/clicklimit"
 	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+	"url-shortener/pkg/lockout"
+	"url-shortener/pkg/policy"
+	"url-shortener/pkg/quota"
 	"url-shortener/pkg/utils"
 )
 
@@ -20,28 +29,260 @@ var _ ShortenedURLService = &shortenedURLServiceImpl{}
 type ShortURLOption struct {
 	// ExpiredAt is optional expire time at
 	ExpiredAt *time.Time
+
+	// Title, Tags and Notes are optional metadata
+	Title string
+	Tags  []string
+	Notes string
+
+	// Interstitial show a warning page before redirect
+	Interstitial bool
+
+	// Dedupe return the unexpired url of the same owner and canonical url instead of creating a new one,
+	// it is ignored by click limited, protected, scheduled and fallback url
+	Dedupe bool
+
+	// MaxClicks is how many redirects the url serves, zero means unlimited
+	MaxClicks int64
+
+	// Password protect the redirect, it is only stored as bcrypt hash
+	Password string
+
+	// ActivateAt the url start to redirect at, nil means at once
+	ActivateAt *time.Time
+
+	// Schedule switch the destination at the time of every item, it is checked like the original url
+	Schedule []entity.Destination
+
+	// FallbackURL the url redirect to after it is expired, it is checked like the original url
+	FallbackURL string
+}
+
+// UpdateOption UpdateShortenedURL option, nil field keep the current value
+type UpdateOption struct {
+	ExpiredAt *time.Time
+	Title     *string
+	Tags      *[]string
+	Notes     *string
+
+	// Interstitial turn on or off the warning page which the owner ask for,
+	// the page of flagged url is only removed by approving its review
+	Interstitial *bool
+
+	// FallbackURL set the url redirect to after it is expired, empty string remove it
+	FallbackURL *string
 }
 
+const (
+	maxTitleLength = 200  // maxTitleLength is the max runes of title
+	maxTags        = 20   // maxTags is the max number of tags
+	maxTagLength   = 50   // maxTagLength is the max runes of a tag
+	maxNotesLength = 2000 // maxNotesLength is the max runes of notes
+
+	maxPasswordLength = 72 // maxPasswordLength is the max bytes of password, bcrypt ignore the rest
+
+	defaultSearchLimit = 20  // defaultSearchLimit is page size when limit is not set
+	maxSearchLimit     = 100 // maxSearchLimit is the max page size
+)
+
 // ShortenedURLService define shortened URL service
 type ShortenedURLService interface {
 	// ShortURL create short key and store to datastore
 	ShortURL(ctx context.Context, url string, opts *ShortURLOption) (shortenedURL *entity.ShortenedURL, err error)
 
-	// RetrieveShortenedURL retrieve short url
+	// ShortURLs create many short keys and store to datastore in bulk, every url has its own result
+	// urls are stored in the workspace of context, see NewWorkspaceContext
+	ShortURLs(ctx context.Context, items []*ShortURLItem) (results []*ShortURLResult)
+
+	// SearchShortenedURLs list short urls match the filter, next is the cursor of the next page
+	SearchShortenedURLs(ctx context.Context, filter *repository.SearchFilter) (shortenedURLs []*entity.ShortenedURL, next string, err error)
+
+	// RetrieveShortenedURL retrieve short url to redirect, a click of click limited url is counted
 	RetrieveShortenedURL(ctx context.Context, short string) (shortenedURL *entity.ShortenedURL, err error)
+
+	// LookupShortenedURL retrieve short url include expired one, original url of protected url is removed,
+	// only the owner of the workspace or admin can read urls out of the default workspace
+	LookupShortenedURL(ctx context.Context, short string) (shortenedURL *entity.ShortenedURL, err error)
+
+	// LookupShortenedURLs retrieve many short urls include expired one, every short has its own result,
+	// original url of protected url is removed
+	LookupShortenedURLs(ctx context.Context, shorts []string) (results []*LookupResult)
+
+	// UpdateShortenedURL update metadata and expire time of short url, only the owner or admin can update
+	UpdateShortenedURL(ctx context.Context, short string, opts *UpdateOption) (shortenedURL *entity.ShortenedURL, err error)
+
+	// DeleteShortenedURL delete short url, only the owner or admin can delete
+	DeleteShortenedURL(ctx context.Context, short string) (err error)
+
+	// UnlockShortenedURL check password of protected url, failed attempts are limited per url and client,
+	// redirect of the url is unlocked by NewUnlockedContext after it
+	UnlockShortenedURL(ctx context.Context, short, password, client string) (err error)
+
+	// QuotaUsage return quota consumption of the credential and workspace of context, empty when quota is disabled
+	QuotaUsage(ctx context.Context) (usages []*quota.Usage, err error)
+
+	// ListBlockedClients list clients blocked by the enumeration guard, only admin can list
+	ListBlockedClients(ctx context.Context) (blocks []*guard.Block, err error)
+
+	// BlockClient block client ip or IPv6 /64 for duration, only admin can block
+	BlockClient(ctx context.Context, client string, duration time.Duration) (block *guard.Block, err error)
+
+	// UnblockClient remove block and misses of client, only admin can unblock
+	UnblockClient(ctx context.Context, client string) (err error)
+
+	// ListReviews list flagged urls of review status, pending when status is empty, only admin can list
+	ListReviews(ctx context.Context, status entity.ReviewStatus, limit int) (reviews []*entity.Review, err error)
+
+	// ResolveReview approve or reject flagged url, rejected url is deleted, only admin can resolve
+	ResolveReview(ctx context.Context, short string, status entity.ReviewStatus) (review *entity.Review, err error)
+
+	// ReportShortenedURL open abuse report of short url in the workspace of context, anyone can report
+	ReportShortenedURL(ctx context.Context, short, reason, contact string) (report *entity.Report, err error)
+
+	// ListReports list abuse reports of status, open when status is empty, only admin can list
+	ListReports(ctx context.Context, status entity.ReportStatus, limit int) (reports []*entity.Report, err error)
+
+	// GetReport retrieve abuse report with its moderation actions, only admin can get
+	GetReport(ctx context.Context, id int64) (report *entity.Report, actions []*entity.ModerationAction, err error)
+
+	// TriageReport mark open report as triaged, only admin can triage
+	TriageReport(ctx context.Context, id int64, note string) (report *entity.Report, err error)
+
+	// ResolveReport disable the reported url or dismiss the report, only admin can resolve
+	ResolveReport(ctx context.Context, id int64, resolution entity.Resolution, note string) (report *entity.Report, err error)
+}
+
+// ShortURLItem is one url of ShortURLs
+type ShortURLItem struct {
+	URL  string
+	Opts *ShortURLOption
+}
+
+// ShortURLResult is result of one url in ShortURLs, either ShortenedURL or Err is set
+type ShortURLResult struct {
+	ShortenedURL *entity.ShortenedURL
+	Err          error
+}
+
+// LookupResult is result of one short in LookupShortenedURLs
+type LookupResult struct {
+	Short        string
+	ShortenedURL *entity.ShortenedURL
+	Err          error
 }
 
 // shortenedURLServiceImpl implement ShortenedURLService
 type shortenedURLServiceImpl struct {
 	repo        repository.Repository
 	bloomFilter bloom.Filter
+	quota       quota.Quota
+	guard       guard.Guard
+	policy      policy.Policy
+	scorer      abuse.Scorer
+	reviews     repository.ReviewRepository
+	reports     repository.ReportRepository
+	clicks      clicklimit.Counter
+	lockout     lockout.Lockout
+
+	// trackingParams is query params removed from the canonical form of original url, see CanonicalURL
+	trackingParams []string
+}
+
+// An Option is passed to ShortenedURLService constructor
+type Option interface {
+	apply(*shortenedURLServiceImpl)
+}
+
+type setQuota struct{ quota quota.Quota }
+
+func (opt *setQuota) apply(srv *shortenedURLServiceImpl) { srv.quota = opt.quota }
+
+// WithQuota charge created urls to the quota of credential and workspace
+func WithQuota(q quota.Quota) Option {
+	return &setQuota{quota: q}
+}
+
+type setGuard struct{ guard guard.Guard }
+
+func (opt *setGuard) apply(srv *shortenedURLServiceImpl) { srv.guard = opt.guard }
+
+type setPolicy struct{ policy policy.Policy }
+
+func (opt *setPolicy) apply(srv *shortenedURLServiceImpl) { srv.policy = opt.policy }
+
+// WithPolicy check original url by the destination url policy instead of only http url
+func WithPolicy(p policy.Policy) Option {
+	return &setPolicy{policy: p}
+}
+
+type setAbuse struct {
+	scorer  abuse.Scorer
+	reviews repository.ReviewRepository
+}
+
+func (opt *setAbuse) apply(srv *shortenedURLServiceImpl) {
+	srv.scorer = opt.scorer
+	srv.reviews = opt.reviews
+}
+
+// WithAbuse score created urls, rejected url is not stored and flagged url is queued for review
+func WithAbuse(scorer abuse.Scorer, reviews repository.ReviewRepository) Option {
+	return &setAbuse{scorer: scorer, reviews: reviews}
 }
 
-func New(repo repository.Repository, bloomFilter bloom.Filter) ShortenedURLService {
-	return &shortenedURLServiceImpl{
+type setReports struct{ reports repository.ReportRepository }
+
+func (opt *setReports) apply(srv *shortenedURLServiceImpl) { srv.reports = opt.reports }
+
+// WithReports accept abuse reports of urls and keep the moderation audit trail
+func WithReports(reports repository.ReportRepository) Option {
+	return &setReports{reports: reports}
+}
+
+type setTrackingParams struct{ params []string }
+
+func (opt *setTrackingParams) apply(srv *shortenedURLServiceImpl) { srv.trackingParams = opt.params }
+
+// WithTrackingParams ignore tracking query params when urls are checked, e.g. "utm_*" and "fbclid"
+func WithTrackingParams(params ...string) Option {
+	return &setTrackingParams{params: params}
+}
+
+type setClickCounter struct{ counter clicklimit.Counter }
+
+func (opt *setClickCounter) apply(srv *shortenedURLServiceImpl) { srv.clicks = opt.counter }
+
+// WithClickCounter count clicks of click limited urls in counter before they are persisted,
+// so redirects of exhausted urls do not write the datastore
+func WithClickCounter(counter clicklimit.Counter) Option {
+	return &setClickCounter{counter: counter}
+}
+
+type setLockout struct{ lockout lockout.Lockout }
+
+func (opt *setLockout) apply(srv *shortenedURLServiceImpl) { srv.lockout = opt.lockout }
+
+// WithLockout limit failed password attempts of protected urls per url and client
+func WithLockout(l lockout.Lockout) Option {
+	return &setLockout{lockout: l}
+}
+
+// WithGuard count 404 and expired redirects against the client of context, see guard.NewContext
+func WithGuard(g guard.Guard) Option {
+	return &setGuard{guard: g}
+}
+
+func New(repo repository.Repository, bloomFilter bloom.Filter, opts ...Option) ShortenedURLService {
+	srv := &shortenedURLServiceImpl{
 		repo:        repo,
 		bloomFilter: bloomFilter,
 	}
+
+	for _, opt := range opts {
+		opt.apply(srv)
+	}
+
+	return srv
 }
 
 // ShortURL is implement ShortenedURLService method
@@ -53,28 +294,62 @@ func (srv *shortenedURLServiceImpl) ShortURL(ctx context.Context, originalURL st
 	)
 
 	logger := log.Ctx(ctx)
+	now := time.Now().UTC()
 
-	if originalURL == "" {
-		return nil, errors.Wrap(errors.ErrInvalidInput, "input originalURL is empty")
+	if err := srv.checkOriginalURL(ctx, originalURL); err != nil {
+		return nil, err
+	}
+
+	if err := validateMetadata(opts); err != nil {
+		return nil, err
 	}
 
-	_, err = url.Parse(originalURL)
+	schedule, err := srv.checkSchedule(ctx, opts, now)
 	if err != nil {
-		return nil, errors.Wrapf(errors.ErrInvalidInput, "input originalURL is %v err = %v", originalURL, err)
+		return nil, err
 	}
 
-	now := time.Now().UTC()
-	if opts.ExpiredAt == nil {
-		expiredAt = now.Add(entity.DefaultShortenedURLExpireDur)
-	} else {
-		expiredAt = *opts.ExpiredAt
+	fallback, err := srv.checkFallback(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWorkspaceWriter(ctx); err != nil {
+		return nil, err
+	}
+	workspace := workspaceID(ctx)
+
+	if dedupe(opts) {
+		if shortenedURL, err = srv.findDuplicate(ctx, originalURL); shortenedURL != nil || err != nil {
+			return shortenedURL, err
+		}
+	}
+
+	verdict, err := srv.scoreURL(ctx, originalURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if verdict, err = srv.scoreSchedule(ctx, schedule, verdict); err != nil {
+		return nil, err
+	}
+
+	if verdict, err = srv.scoreFallback(ctx, fallback, verdict); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := hashPassword(opts)
+	if err != nil {
+		return nil, err
 	}
 
+	expiredAt = newExpiredAt(now, opts)
+
 	// using bloom filter prevent direct to hit database
 	// accept missing rate then reduce direct access datastore
 	for true {
 		short = srv.NewShortID(ctx)
-		if !srv.IsInBloomFilter(ctx, short) {
+		if !srv.IsInBloomFilter(ctx, entity.JoinKey(workspace, short)) {
 			break
 		}
 
@@ -86,47 +361,583 @@ func (srv *shortenedURLServiceImpl) ShortURL(ctx context.Context, originalURL st
 		originalURL,
 		expiredAt,
 	)
+	shortenedURL.OwnerID = ownerID(ctx)
+	shortenedURL.WorkspaceID = workspace
+	shortenedURL.PasswordHash = passwordHash
+	shortenedURL.Schedule = schedule
+	shortenedURL.FallbackURL = fallback
+	setMetadata(shortenedURL, opts)
+	setFlagged(shortenedURL, verdict)
+
+	if err := srv.consumeQuota(ctx, []*entity.ShortenedURL{shortenedURL}); err != nil {
+		return nil, err
+	}
 
 	if err := srv.repo.StoreShortenedURL(ctx, shortenedURL); err != nil {
+		srv.releaseQuota(ctx, []string{shortenedURL.Key()}, true)
 		return nil, err
 	}
 
 	// Add to bloom filter
-	srv.AddToBloomFilter(ctx, short)
+	srv.AddToBloomFilter(ctx, shortenedURL.Key())
+
+	srv.flagURL(ctx, shortenedURL, verdict)
 
 	return
 }
 
+// maxShortIDRetry is max rounds of ShortURLs regenerate short id for the conflict one
+const maxShortIDRetry = 3
+
+// ShortURLs is implement ShortenedURLService method
+// short ids are generated in bulk and conflict one is regenerated in the next round,
+// so the bloom filter is only written after the rows are stored
+func (srv *shortenedURLServiceImpl) ShortURLs(ctx context.Context, items []*ShortURLItem) (results []*ShortURLResult) {
+	logger := log.Ctx(ctx)
+	now := time.Now().UTC()
+	owner := ownerID(ctx)
+	workspace := workspaceID(ctx)
+
+	results = make([]*ShortURLResult, len(items))
+	if err := checkWorkspaceWriter(ctx); err != nil {
+		for i := range items {
+			results[i] = &ShortURLResult{Err: err}
+		}
+		return results
+	}
+
+	pending := make([]int, 0, len(items))
+	urls := make([]string, len(items))
+	verdicts := make([]abuse.Verdict, len(items))
+	passwordHashes := make([]string, len(items))
+	schedules := make([][]entity.Destination, len(items))
+	fallbacks := make([]string, len(items))
+	for i, item := range items {
+		urls[i] = item.URL
+		if err := srv.checkOriginalURL(ctx, urls[i]); err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		if err := validateMetadata(item.Opts); err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		schedule, err := srv.checkSchedule(ctx, item.Opts, now)
+		if err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		schedules[i] = schedule
+		if fallbacks[i], err = srv.checkFallback(ctx, item.Opts); err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		if dedupe(item.Opts) {
+			shortenedURL, err := srv.findDuplicate(ctx, urls[i])
+			if shortenedURL != nil || err != nil {
+				results[i] = &ShortURLResult{ShortenedURL: shortenedURL, Err: err}
+				continue
+			}
+		}
+		verdict, err := srv.scoreURL(ctx, urls[i])
+		if err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		if verdict, err = srv.scoreSchedule(ctx, schedule, verdict); err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		if verdict, err = srv.scoreFallback(ctx, fallbacks[i], verdict); err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		verdicts[i] = verdict
+		if passwordHashes[i], err = hashPassword(item.Opts); err != nil {
+			results[i] = &ShortURLResult{Err: err}
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	for round := 0; round < maxShortIDRetry && len(pending) != 0; round++ {
+		generated := make(map[string]bool, len(pending))
+		shortenedURLs := make([]*entity.ShortenedURL, 0, len(pending))
+		for _, i := range pending {
+			short := srv.NewShortID(ctx)
+			for generated[short] {
+				short = srv.NewShortID(ctx)
+			}
+			generated[short] = true
+
+			shortenedURL := entity.NewShortenedURL(
+				short,
+				urls[i],
+				newExpiredAt(now, items[i].Opts),
+			)
+			shortenedURL.OwnerID = owner
+			shortenedURL.WorkspaceID = workspace
+			shortenedURL.PasswordHash = passwordHashes[i]
+			shortenedURL.Schedule = schedules[i]
+			shortenedURL.FallbackURL = fallbacks[i]
+			setMetadata(shortenedURL, items[i].Opts)
+			setFlagged(shortenedURL, verdicts[i])
+			shortenedURLs = append(shortenedURLs, shortenedURL)
+		}
+
+		// the whole round is charged at once, conflict one is refunded and charged again with its new id
+		if err := srv.consumeQuota(ctx, shortenedURLs); err != nil {
+			for _, i := range pending {
+				results[i] = &ShortURLResult{Err: err}
+			}
+			return results
+		}
+
+		stored, err := srv.repo.StoreShortenedURLs(ctx, shortenedURLs)
+		if err != nil {
+			keys := make([]string, 0, len(shortenedURLs))
+			for _, i := range pending {
+				results[i] = &ShortURLResult{Err: err}
+			}
+			for _, shortenedURL := range shortenedURLs {
+				keys = append(keys, shortenedURL.Key())
+			}
+			srv.releaseQuota(ctx, keys, true)
+			return results
+		}
+
+		inserted := make(map[string]bool, len(stored))
+		for _, key := range stored {
+			inserted[key] = true
+		}
+
+		conflicts := pending[:0]
+		var refunds []string
+		for j, i := range pending {
+			if !inserted[shortenedURLs[j].Key()] {
+				conflicts = append(conflicts, i)
+				refunds = append(refunds, shortenedURLs[j].Key())
+				continue
+			}
+			results[i] = &ShortURLResult{ShortenedURL: shortenedURLs[j]}
+		}
+		srv.releaseQuota(ctx, refunds, true)
+
+		if len(stored) != 0 {
+			srv.bloomFilter.AddMany(ctx, stored)
+		}
+
+		logger.Trace().Msgf("round %v stored %v shortened urls, %v short id conflict", round, len(stored), len(conflicts))
+		pending = conflicts
+	}
+
+	for _, i := range pending {
+		results[i] = &ShortURLResult{
+			Err: errors.Wrapf(errors.ErrInternal, "failed to generate unique short id for %v", urls[i]),
+		}
+	}
+
+	for i, result := range results {
+		if result.ShortenedURL != nil {
+			srv.flagURL(ctx, result.ShortenedURL, verdicts[i])
+		}
+	}
+
+	return results
+}
+
+// SearchShortenedURLs is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) SearchShortenedURLs(ctx context.Context, filter *repository.SearchFilter) (shortenedURLs []*entity.ShortenedURL, next string, err error) {
+	f := *filter
+	f.WorkspaceID = workspaceID(ctx)
+
+	// non admin principal only see its own urls
+	if p, ok := auth.FromContext(ctx); ok && !p.IsAdmin() {
+		f.OwnerID = p.ID
+	}
+
+	f.Tag = strings.ToLower(strings.TrimSpace(f.Tag))
+	f.Host = strings.ToLower(strings.TrimSpace(f.Host))
+	f.Query = strings.TrimSpace(f.Query)
+
+	switch {
+	case f.Limit <= 0:
+		f.Limit = defaultSearchLimit
+	case f.Limit > maxSearchLimit:
+		f.Limit = maxSearchLimit
+	}
+
+	switch f.State {
+	case repository.ExpiryStateAll, repository.ExpiryStateActive, repository.ExpiryStateExpired:
+	default:
+		return nil, "", errors.Wrapf(errors.ErrInvalidInput, "unknown expiry state %v", f.State)
+	}
+
+	switch f.Mode {
+	case "":
+		f.Mode = repository.SearchModePrefix
+	case repository.SearchModePrefix, repository.SearchModeFullText:
+	default:
+		return nil, "", errors.Wrapf(errors.ErrInvalidInput, "unknown search mode %v", f.Mode)
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return nil, "", errors.Wrap(errors.ErrInvalidInput, "created from must before created to")
+	}
+
+	shortenedURLs, next, err = srv.repo.SearchShortenedURLs(ctx, &f)
+	for i, shortenedURL := range shortenedURLs {
+		shortenedURLs[i] = redact(shortenedURL)
+	}
+
+	return shortenedURLs, next, err
+}
+
 // RetrieveShortenedURL is implement ShortenedURLService method
 func (srv *shortenedURLServiceImpl) RetrieveShortenedURL(ctx context.Context, short string) (shortenedURL *entity.ShortenedURL, err error) {
 	now := time.Now().UTC()
 
+	shortenedURL, err = srv.lookupShortenedURL(ctx, short)
+	if err != nil {
+		if errors.Is(err, errors.ErrPageNotFound) {
+			srv.recordMiss(ctx)
+		}
+		return nil, err
+	}
+
+	// disabled and exhausted url are answered by their own error, they are not a guess of the client
+	switch shortenedURL.StatusAt(now) {
+	case entity.StatusDisabled:
+		return nil, errors.Wrapf(errors.ErrShortenedURLDisabled, "the short = %v is disabled for abuse", short)
+	case entity.StatusExpired:
+		srv.recordMiss(ctx)
+		return nil, expired(ctx, shortenedURL, now)
+	case entity.StatusExhausted:
+		return nil, exhausted(shortenedURL)
+	case entity.StatusScheduled:
+		return nil, notActive(shortenedURL, now)
+	}
+
+	// the password form is not a click, so it is checked before counting
+	if shortenedURL.Protected() && !isUnlocked(ctx) {
+		return nil, errors.Wrapf(errors.ErrPasswordRequired, "the short = %v is protected by password", short)
+	}
+
+	if shortenedURL.MaxClicks > 0 {
+		if err := srv.takeClick(ctx, shortenedURL, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// the entity is built for every lookup, so its original url is replaced by the destination of now
+	shortenedURL.OriginalURL = shortenedURL.DestinationAt(now)
+
+	return
+}
+
+// LookupShortenedURL is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) LookupShortenedURL(ctx context.Context, short string) (shortenedURL *entity.ShortenedURL, err error) {
+	if err := checkWorkspaceReader(ctx); err != nil {
+		return nil, err
+	}
+
+	shortenedURL, err = srv.lookupShortenedURL(ctx, short)
+	if err != nil {
+		return nil, err
+	}
+
+	return redact(shortenedURL), nil
+}
+
+// lookupShortenedURL find short url in the workspace of context include expired one, bloom filter is checked first
+func (srv *shortenedURLServiceImpl) lookupShortenedURL(ctx context.Context, short string) (shortenedURL *entity.ShortenedURL, err error) {
 	if short == "" {
 		return nil, errors.Wrap(errors.ErrInvalidInput, "input shot url is empty")
 	}
 
-	if !srv.IsInBloomFilter(ctx, short) {
+	workspace := workspaceID(ctx)
+	if !srv.IsInBloomFilter(ctx, entity.JoinKey(workspace, short)) {
 		return nil, errors.Wrap(errors.ErrPageNotFound, "shortened url not in bloom filter")
 	}
 
-	shortenedURL, err = srv.repo.FindShortenedURL(ctx, short)
-	if err != nil {
+	// only a missing url is a page not found, a failure of the repository is not a guess of the client
+	shortenedURL, err = srv.repo.FindShortenedURL(ctx, workspace, short)
+	if errors.Is(err, errors.ErrResourceNotFound) {
 		return nil, errors.Wrapf(errors.ErrPageNotFound, "shortened =%v url not found ", short)
 	}
-
-	if shortenedURL.ExpiredAt.UnixMilli() <= now.UnixMilli() {
-		return nil, errors.Wrapf(
-			errors.ErrShortenedURLExpire,
-			"the short = %v is expire, now=%v expireAt=%v",
-			short,
-			now.Format(time.RFC3339),
-			shortenedURL.ExpiredAt.UTC().Format(time.RFC3339),
-		)
+	if err != nil {
+		return nil, err
 	}
 
 	return
 }
 
+// LookupShortenedURLs is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) LookupShortenedURLs(ctx context.Context, shorts []string) (results []*LookupResult) {
+	results = make([]*LookupResult, 0, len(shorts))
+
+	for _, short := range shorts {
+		shortenedURL, err := srv.LookupShortenedURL(ctx, short)
+		results = append(results, &LookupResult{
+			Short:        short,
+			ShortenedURL: shortenedURL,
+			Err:          err,
+		})
+	}
+
+	return results
+}
+
+// UpdateShortenedURL is implement ShortenedURLService method
+func (srv *shortenedURLServiceImpl) UpdateShortenedURL(ctx context.Context, short string, opts *UpdateOption) (shortenedURL *entity.ShortenedURL, err error) {
+	if opts == nil {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "nothing to update")
+	}
+
+	metadata := &ShortURLOption{}
+	if opts.Title != nil {
+		metadata.Title = *opts.Title
+	}
+	if opts.Tags != nil {
+		metadata.Tags = *opts.Tags
+	}
+	if opts.Notes != nil {
+		metadata.Notes = *opts.Notes
+	}
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+
+	var (
+		fallback string
+		verdict  abuse.Verdict
+	)
+	if opts.FallbackURL != nil {
+		if fallback, err = srv.checkFallback(ctx, &ShortURLOption{FallbackURL: *opts.FallbackURL}); err != nil {
+			return nil, err
+		}
+		if verdict, err = srv.scoreFallback(ctx, fallback, verdict); err != nil {
+			return nil, err
+		}
+	}
+
+	shortenedURL, err = srv.findOwnedShortenedURL(ctx, short)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ExpiredAt != nil {
+		shortenedURL.ExpiredAt = *opts.ExpiredAt
+	}
+	if opts.Title != nil {
+		shortenedURL.Title = strings.TrimSpace(metadata.Title)
+	}
+	if opts.Tags != nil {
+		shortenedURL.Tags = normalizeTags(metadata.Tags)
+	}
+	if opts.Notes != nil {
+		shortenedURL.Notes = metadata.Notes
+	}
+	switch {
+	case opts.Interstitial == nil:
+	case *opts.Interstitial:
+		shortenedURL.Interstitial = entity.InterstitialAlways
+	case shortenedURL.Interstitial == entity.InterstitialAlways:
+		shortenedURL.Interstitial = entity.InterstitialOff
+	}
+	if opts.FallbackURL != nil {
+		shortenedURL.FallbackURL = fallback
+		setFlagged(shortenedURL, verdict)
+	}
+
+	if err := srv.repo.UpdateShortenedURL(ctx, shortenedURL); err != nil {
+		return nil, err
+	}
+
+	srv.flagURL(ctx, shortenedURL, verdict)
+
+	return redact(shortenedURL), nil
+}
+
+// DeleteShortenedURL is implement ShortenedURLService method
+// the short id stay in bloom filter, later lookup fall through to datastore and not found
+func (srv *shortenedURLServiceImpl) DeleteShortenedURL(ctx context.Context, short string) (err error) {
+	if _, err := srv.findOwnedShortenedURL(ctx, short); err != nil {
+		return err
+	}
+
+	if err := srv.repo.DeleteShortenedURL(ctx, workspaceID(ctx), short); err != nil {
+		return err
+	}
+
+	srv.releaseQuota(ctx, []string{entity.JoinKey(workspaceID(ctx), short)}, false)
+	return nil
+}
+
+// findOwnedShortenedURL find short url which the principal of context owns, admin owns all urls
+func (srv *shortenedURLServiceImpl) findOwnedShortenedURL(ctx context.Context, short string) (*entity.ShortenedURL, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, errors.Wrap(errors.ErrUnauthorized, "credential is required")
+	}
+
+	if short == "" {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "input shot url is empty")
+	}
+
+	shortenedURL, err := srv.repo.FindShortenedURL(ctx, workspaceID(ctx), short)
+	if err != nil {
+		return nil, err
+	}
+
+	if shortenedURL.OwnerID != p.ID && !p.IsAdmin() {
+		return nil, errors.Wrapf(errors.ErrForbidden, "principal = %v is not owner of short = %v", p.ID, short)
+	}
+
+	return shortenedURL, nil
+}
+
+// ownerID return the principal id of context, empty means anonymous
+func ownerID(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.ID
+	}
+	return ""
+}
+
+// newExpiredAt return expire time from option, default expire after DefaultShortenedURLExpireDur
+func newExpiredAt(now time.Time, opts *ShortURLOption) time.Time {
+	if opts == nil || opts.ExpiredAt == nil {
+		return now.Add(entity.DefaultShortenedURLExpireDur)
+	}
+	return *opts.ExpiredAt
+}
+
+// validateMetadata check title, tags and notes length, max clicks and password
+func validateMetadata(opts *ShortURLOption) error {
+	if opts == nil {
+		return nil
+	}
+
+	if utf8.RuneCountInString(opts.Title) > maxTitleLength {
+		return errors.Wrapf(errors.ErrInvalidInput, "title is longer than %v", maxTitleLength)
+	}
+
+	if strings.ContainsAny(opts.Title, "\r\n") {
+		return errors.Wrap(errors.ErrInvalidInput, "title must be single line")
+	}
+
+	if utf8.RuneCountInString(opts.Notes) > maxNotesLength {
+		return errors.Wrapf(errors.ErrInvalidInput, "notes is longer than %v", maxNotesLength)
+	}
+
+	if len(opts.Tags) > maxTags {
+		return errors.Wrapf(errors.ErrInvalidInput, "tags is more than %v", maxTags)
+	}
+
+	for _, tag := range opts.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return errors.Wrapf(errors.ErrInvalidInput, "tag = %v must be 1 ~ %v characters", tag, maxTagLength)
+		}
+	}
+
+	if opts.MaxClicks < 0 {
+		return errors.Wrapf(errors.ErrInvalidInput, "max clicks = %v must not be negative", opts.MaxClicks)
+	}
+
+	if len(opts.Password) > maxPasswordLength {
+		return errors.Wrapf(errors.ErrInvalidInput, "password is longer than %v bytes", maxPasswordLength)
+	}
+
+	return nil
+}
+
+// setMetadata set title, notes, normalized tags, interstitial, max clicks and activate time from option
+func setMetadata(shortenedURL *entity.ShortenedURL, opts *ShortURLOption) {
+	if opts == nil {
+		return
+	}
+
+	shortenedURL.Title = strings.TrimSpace(opts.Title)
+	shortenedURL.Notes = opts.Notes
+	shortenedURL.Tags = normalizeTags(opts.Tags)
+	if opts.Interstitial {
+		shortenedURL.Interstitial = entity.InterstitialAlways
+	}
+	shortenedURL.MaxClicks = opts.MaxClicks
+	if opts.ActivateAt != nil {
+		shortenedURL.ActivateAt = opts.ActivateAt.UTC()
+	}
+}
+
+// normalizeTags return lower case and unique tags, keep the first appear order
+func normalizeTags(tags []string) []string {
+	var normalized []string
+
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// checkOriginalURL check canonical form of original url by the policy,
+// or only check it is an absolute http url without policy
+func (srv *shortenedURLServiceImpl) checkOriginalURL(ctx context.Context, originalURL string) error {
+	if originalURL == "" {
+		return errors.Wrap(errors.ErrInvalidInput, "input originalURL is empty")
+	}
+	originalURL = CanonicalURL(originalURL, srv.trackingParams)
+
+	if srv.policy != nil {
+		return srv.policy.Check(ctx, originalURL)
+	}
+
+	return validateOriginalURL(originalURL)
+}
+
+// dedupe check option ask for dedupe, click limited, protected, scheduled and fallback url are always created
+func dedupe(opts *ShortURLOption) bool {
+	return opts != nil && opts.Dedupe && opts.MaxClicks == 0 && opts.Password == "" &&
+		opts.ActivateAt == nil && len(opts.Schedule) == 0 && opts.FallbackURL == ""
+}
+
+// findDuplicate return the unexpired url of the owner and workspace of context with the same canonical url,
+// nil when there is none
+func (srv *shortenedURLServiceImpl) findDuplicate(ctx context.Context, originalURL string) (*entity.ShortenedURL, error) {
+	shortenedURL, err := srv.repo.FindDuplicateShortenedURL(ctx, workspaceID(ctx), ownerID(ctx), originalURL, time.Now().UTC())
+	if errors.Is(err, errors.ErrResourceNotFound) {
+		return nil, nil
+	}
+
+	return shortenedURL, err
+}
+
+// validateOriginalURL check original url is an absolute http url
+func validateOriginalURL(originalURL string) error {
+	if originalURL == "" {
+		return errors.Wrap(errors.ErrInvalidInput, "input originalURL is empty")
+	}
+
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "input originalURL is %v err = %v", originalURL, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrapf(errors.ErrInvalidInput, "input originalURL is %v not http url", originalURL)
+	}
+
+	return nil
+}
+
 // AddToBloomFilter wrap bloomFilter add method
 func (srv *shortenedURLServiceImpl) AddToBloomFilter(ctx context.Context, item string) {
 	srv.bloomFilter.Add(ctx, item)
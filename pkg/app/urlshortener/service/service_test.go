@@ -12,6 +12,7 @@ import (
 	"url-shortener/pkg/app/urlshortener/mocks"
 	"url-shortener/pkg/app/urlshortener/repository"
 	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
 	"url-shortener/pkg/bloom"
 	bm "url-shortener/pkg/bloom/mocks"
 	"url-shortener/pkg/errors"
@@ -180,7 +181,7 @@ func Test_shortenedURLServiceImpl_RetrieveShortenedURL(t *testing.T) {
 			repo: func() repository.Repository {
 				repo := mocks.NewRepository(t)
 				repo.EXPECT().
-					FindShortenedURL(mock.Anything, mock.Anything).
+					FindShortenedURL(mock.Anything, "", mock.Anything).
 					Return(&entity.ShortenedURL{
 						Short:       "6Xme5Xwp",
 						OriginalURL: "https://www.dcard.tw/f",
@@ -225,7 +226,7 @@ func Test_shortenedURLServiceImpl_RetrieveShortenedURL(t *testing.T) {
 			repo: func() repository.Repository {
 				repo := mocks.NewRepository(t)
 				repo.EXPECT().
-					FindShortenedURL(mock.Anything, mock.Anything).
+					FindShortenedURL(mock.Anything, "", mock.Anything).
 					Return(nil, errors.ErrResourceNotFound)
 				return repo
 			}(),
@@ -245,7 +246,7 @@ func Test_shortenedURLServiceImpl_RetrieveShortenedURL(t *testing.T) {
 			repo: func() repository.Repository {
 				repo := mocks.NewRepository(t)
 				repo.EXPECT().
-					FindShortenedURL(mock.Anything, mock.Anything).
+					FindShortenedURL(mock.Anything, "", mock.Anything).
 					Return(&entity.ShortenedURL{
 						Short:       "6Xme5Xwp",
 						OriginalURL: "https://www.dcard.tw/f",
@@ -304,3 +305,362 @@ func Test_shortenedURLServiceImpl_RetrieveShortenedURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_shortenedURLServiceImpl_LookupShortenedURLs(t *testing.T) {
+	expired := &entity.ShortenedURL{
+		Short:       "6Xme5Xwp",
+		OriginalURL: "https://www.dcard.tw/f",
+		CreatedAt:   time.Now().Add(-2 * entity.DefaultShortenedURLExpireDur),
+		ExpiredAt:   time.Now().Add(-entity.DefaultShortenedURLExpireDur),
+	}
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(expired, nil)
+
+	bf := bm.NewFilter(t)
+	bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+	bf.EXPECT().Exist(mock.Anything, "K2MY8LEp").Return(false)
+
+	logger := logging.SetupWithOption(
+		logging.WithDebug(true),
+		logging.WithLevel(logging.TraceLevel),
+	)
+	ctx := logger.WithContext(context.Background())
+
+	results := New(repo, bf).LookupShortenedURLs(ctx, []string{"6Xme5Xwp", "K2MY8LEp", ""})
+	assert.Len(t, results, 3)
+
+	// expired url is still returned, the caller decide by its status
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, expired, results[0].ShortenedURL)
+	assert.Equal(t, entity.StatusExpired, results[0].ShortenedURL.StatusAt(time.Now()))
+
+	assert.Equal(t, "K2MY8LEp", results[1].Short)
+	assert.True(t, errors.Is(results[1].Err, errors.ErrPageNotFound))
+
+	assert.True(t, errors.Is(results[2].Err, errors.ErrInvalidInput))
+}
+
+func Test_shortenedURLServiceImpl_ShortURLs(t *testing.T) {
+	items := []*ShortURLItem{
+		{URL: "https://www.dcard.tw/f"},
+		{URL: "ftp://www.dcard.tw/f"},
+		{URL: "https://www.dcard.tw/"},
+	}
+
+	tests := []struct {
+		name        string
+		repo        func() repository.Repository
+		bloomFilter func() bloom.Filter
+		errs        []error
+	}{
+		{
+			name: "Success",
+			repo: func() repository.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().
+					StoreShortenedURLs(mock.Anything, mock.Anything).
+					RunAndReturn(func(ctx context.Context, shortenedURLs []*entity.ShortenedURL) ([]string, error) {
+						assert.Len(t, shortenedURLs, 2)
+						return []string{shortenedURLs[0].Key(), shortenedURLs[1].Key()}, nil
+					})
+				return repo
+			},
+			bloomFilter: func() bloom.Filter {
+				bf := bm.NewFilter(t)
+				bf.EXPECT().AddMany(mock.Anything, mock.Anything)
+				return bf
+			},
+			errs: []error{nil, errors.ErrInvalidInput, nil},
+		},
+		{
+			name: "RetryConflictShortID",
+			repo: func() repository.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().
+					StoreShortenedURLs(mock.Anything, mock.Anything).
+					RunAndReturn(func(ctx context.Context, shortenedURLs []*entity.ShortenedURL) ([]string, error) {
+						// the first one is always conflict at the first round
+						if len(shortenedURLs) == 2 {
+							return []string{shortenedURLs[1].Key()}, nil
+						}
+						return []string{shortenedURLs[0].Key()}, nil
+					}).
+					Twice()
+				return repo
+			},
+			bloomFilter: func() bloom.Filter {
+				bf := bm.NewFilter(t)
+				bf.EXPECT().AddMany(mock.Anything, mock.Anything).Twice()
+				return bf
+			},
+			errs: []error{nil, errors.ErrInvalidInput, nil},
+		},
+		{
+			name: "StoreFail",
+			repo: func() repository.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().
+					StoreShortenedURLs(mock.Anything, mock.Anything).
+					Return(nil, errors.ErrInternal)
+				return repo
+			},
+			bloomFilter: func() bloom.Filter {
+				return bm.NewFilter(t)
+			},
+			errs: []error{errors.ErrInternal, errors.ErrInvalidInput, errors.ErrInternal},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logging.SetupWithOption(
+				logging.WithDebug(true),
+				logging.WithLevel(logging.TraceLevel),
+			)
+			ctx := logger.WithContext(context.Background())
+
+			results := New(tt.repo(), tt.bloomFilter()).ShortURLs(ctx, items)
+			assert.Len(t, results, len(items))
+
+			for i, r := range results {
+				if tt.errs[i] != nil {
+					assert.Truef(t, errors.Is(r.Err, tt.errs[i]), "ShortURLs()[%v] error = %v, expected error %v", i, r.Err, tt.errs[i])
+					continue
+				}
+
+				assert.NoError(t, r.Err)
+				assert.Equal(t, items[i].URL, r.ShortenedURL.OriginalURL)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_SearchShortenedURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		filter    *repository.SearchFilter
+		expected  *repository.SearchFilter
+		err       error
+	}{
+		{
+			name:   "Default",
+			filter: &repository.SearchFilter{},
+			expected: &repository.SearchFilter{
+				Mode:  repository.SearchModePrefix,
+				Limit: 20,
+			},
+		},
+		{
+			name: "Normalize",
+			filter: &repository.SearchFilter{
+				Tag:   " Campaign ",
+				Host:  "WWW.Dcard.TW",
+				Query: " dcard ",
+				Mode:  repository.SearchModeFullText,
+				State: repository.ExpiryStateActive,
+				Limit: 1000,
+			},
+			expected: &repository.SearchFilter{
+				Tag:   "campaign",
+				Host:  "www.dcard.tw",
+				Query: "dcard",
+				Mode:  repository.SearchModeFullText,
+				State: repository.ExpiryStateActive,
+				Limit: 100,
+			},
+		},
+		{
+			name: "InvalidRange",
+			filter: &repository.SearchFilter{
+				CreatedFrom: func() *time.Time { t := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC); return &t }(),
+				CreatedTo:   func() *time.Time { t := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC); return &t }(),
+			},
+			err: errors.ErrInvalidInput,
+		},
+		{
+			name:   "UnknownState",
+			filter: &repository.SearchFilter{State: "deleted"},
+			err:    errors.ErrInvalidInput,
+		},
+		{
+			name:      "OwnerOnly",
+			principal: &auth.Principal{ID: "team-a", Scopes: []auth.Scope{auth.ScopeRead}},
+			filter:    &repository.SearchFilter{OwnerID: "team-b"},
+			expected: &repository.SearchFilter{
+				OwnerID: "team-a",
+				Mode:    repository.SearchModePrefix,
+				Limit:   20,
+			},
+		},
+		{
+			name:      "AdminAllOwners",
+			principal: &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}},
+			filter:    &repository.SearchFilter{},
+			expected: &repository.SearchFilter{
+				Mode:  repository.SearchModePrefix,
+				Limit: 20,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			if tt.expected != nil {
+				repo.EXPECT().SearchShortenedURLs(mock.Anything, tt.expected).Return(nil, "", nil)
+			}
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.NewContext(ctx, tt.principal)
+			}
+
+			_, _, err := New(repo, bm.NewFilter(t)).SearchShortenedURLs(ctx, tt.filter)
+			if err != nil || tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "SearchShortenedURLs() error = %v, expected error %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_UpdateShortenedURL(t *testing.T) {
+	owner := &auth.Principal{ID: "team-a", Scopes: []auth.Scope{auth.ScopeManage}}
+	other := &auth.Principal{ID: "team-b", Scopes: []auth.Scope{auth.ScopeManage}}
+	admin := &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}
+
+	title := "Dcard forum"
+	tags := []string{"Forum", "forum"}
+	longTitle := string(make([]rune, 201))
+	on, off := true, false
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		opts      *UpdateOption
+		repo      func() *mocks.Repository
+		err       error
+	}{
+		{
+			name:      "Owner",
+			principal: owner,
+			opts:      &UpdateOption{Title: &title, Tags: &tags},
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{
+					Short:   "K2MY8LEp",
+					Title:   "Dcard",
+					Notes:   "keep",
+					OwnerID: "team-a",
+				}, nil)
+				repo.EXPECT().
+					UpdateShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+						return shortenedURL.Title == title &&
+							assert.ObjectsAreEqual([]string{"forum"}, shortenedURL.Tags) &&
+							shortenedURL.Notes == "keep"
+					})).
+					Return(nil)
+				return repo
+			},
+		},
+		{
+			name:      "Admin",
+			principal: admin,
+			opts:      &UpdateOption{Title: &title},
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{Short: "K2MY8LEp", OwnerID: "team-a"}, nil)
+				repo.EXPECT().UpdateShortenedURL(mock.Anything, mock.Anything).Return(nil)
+				return repo
+			},
+		},
+		{
+			name:      "InterstitialOn",
+			principal: owner,
+			opts:      &UpdateOption{Interstitial: &on},
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{Short: "K2MY8LEp", OwnerID: "team-a"}, nil)
+				repo.EXPECT().
+					UpdateShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+						return shortenedURL.Interstitial == entity.InterstitialAlways
+					})).
+					Return(nil)
+				return repo
+			},
+		},
+		{
+			// the owner can not skip the page of flagged url
+			name:      "InterstitialOffFlagged",
+			principal: owner,
+			opts:      &UpdateOption{Interstitial: &off},
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{
+					Short:        "K2MY8LEp",
+					OwnerID:      "team-a",
+					Interstitial: entity.InterstitialFlagged,
+				}, nil)
+				repo.EXPECT().
+					UpdateShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+						return shortenedURL.Interstitial == entity.InterstitialFlagged
+					})).
+					Return(nil)
+				return repo
+			},
+		},
+		{
+			name:      "NotOwner",
+			principal: other,
+			opts:      &UpdateOption{Title: &title},
+			repo: func() *mocks.Repository {
+				repo := mocks.NewRepository(t)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{Short: "K2MY8LEp", OwnerID: "team-a"}, nil)
+				return repo
+			},
+			err: errors.ErrForbidden,
+		},
+		{
+			name: "Anonymous",
+			opts: &UpdateOption{Title: &title},
+			repo: func() *mocks.Repository {
+				return mocks.NewRepository(t)
+			},
+			err: errors.ErrUnauthorized,
+		},
+		{
+			name:      "InvalidTitle",
+			principal: owner,
+			opts:      &UpdateOption{Title: &longTitle},
+			repo: func() *mocks.Repository {
+				return mocks.NewRepository(t)
+			},
+			err: errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.NewContext(ctx, tt.principal)
+			}
+
+			_, err := New(tt.repo(), bm.NewFilter(t)).UpdateShortenedURL(ctx, "K2MY8LEp", tt.opts)
+			if err != nil || tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "UpdateShortenedURL() error = %v, expected error %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_DeleteShortenedURL(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Principal{ID: "team-a", Scopes: []auth.Scope{auth.ScopeManage}})
+
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "K2MY8LEp").Return(&entity.ShortenedURL{Short: "K2MY8LEp", OwnerID: "team-a"}, nil)
+	repo.EXPECT().DeleteShortenedURL(mock.Anything, "", "K2MY8LEp").Return(nil)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{Short: "6Xme5Xwp", OwnerID: "team-b"}, nil)
+
+	srv := New(repo, bm.NewFilter(t))
+	assert.NoError(t, srv.DeleteShortenedURL(ctx, "K2MY8LEp"))
+	assert.True(t, errors.Is(srv.DeleteShortenedURL(ctx, "6Xme5Xwp"), errors.ErrForbidden))
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"url-shortener/pkg/abuse"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+)
+
+const (
+	// VerificationRecordPrefix is prefix of the DNS TXT record name which prove domain ownership
+	VerificationRecordPrefix = "_urlshortener."
+
+	// VerificationValuePrefix is prefix of the DNS TXT record value, follow by domain token
+	VerificationValuePrefix = "urlshortener-verification="
+)
+
+var (
+	// workspaceIDPattern is url safe slug, so it never contain the key separator
+	workspaceIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,31}$`)
+
+	// hostPattern is lower case host name with at least two labels
+	hostPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// TXTResolver lookup DNS TXT records of name, *net.Resolver is the default implementation
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var _ TXTResolver = net.DefaultResolver
+
+// WorkspaceService define workspace and custom domain service
+type WorkspaceService interface {
+	// CreateWorkspace create workspace which the owner can short urls in
+	CreateWorkspace(ctx context.Context, id string, name string, ownerID string) (workspace *entity.Workspace, err error)
+
+	// ListWorkspaces list workspaces of the owner, empty owner means all owners
+	ListWorkspaces(ctx context.Context, ownerID string) (workspaces []*entity.Workspace, err error)
+
+	// SetFallbackURL set the url which expired urls of workspace redirect to when they have no fallback,
+	// empty fallback url remove it
+	SetFallbackURL(ctx context.Context, id string, fallbackURL string) (workspace *entity.Workspace, err error)
+
+	// AddDomain add unverified domain to workspace, the domain token must be published in DNS TXT record
+	AddDomain(ctx context.Context, workspaceID string, host string) (domain *entity.Domain, err error)
+
+	// VerifyDomain check DNS TXT record of the domain contain its token
+	VerifyDomain(ctx context.Context, host string) (domain *entity.Domain, err error)
+
+	// FindWorkspace find workspace by id
+	FindWorkspace(ctx context.Context, id string) (workspace *entity.Workspace, err error)
+
+	// ResolveHost find workspace of the request host, nil means the default workspace
+	ResolveHost(ctx context.Context, host string) (workspace *entity.Workspace, err error)
+}
+
+// workspaceServiceImpl implement WorkspaceService
+type workspaceServiceImpl struct {
+	repo     repository.WorkspaceRepository
+	resolver TXTResolver
+
+	// urls check fallback url like the original url of short urls, nil only validate it is an absolute http url
+	urls *shortenedURLServiceImpl
+}
+
+// A WorkspaceOption is passed to WorkspaceService constructor
+type WorkspaceOption interface {
+	applyWorkspace(*workspaceServiceImpl)
+}
+
+type setURLCheck struct{ opts []Option }
+
+func (opt *setURLCheck) applyWorkspace(srv *workspaceServiceImpl) {
+	srv.urls = &shortenedURLServiceImpl{}
+	for _, o := range opt.opts {
+		o.apply(srv.urls)
+	}
+}
+
+// WithURLCheck check fallback urls by the policy, abuse scorer and tracking params of the ShortenedURLService options,
+// the same way as the original url of short urls
+func WithURLCheck(opts ...Option) WorkspaceOption {
+	return &setURLCheck{opts: opts}
+}
+
+// NewWorkspaceService WorkspaceService constructor, resolver lookup DNS TXT record when verify domain
+func NewWorkspaceService(repo repository.WorkspaceRepository, resolver TXTResolver, opts ...WorkspaceOption) WorkspaceService {
+	srv := &workspaceServiceImpl{
+		repo:     repo,
+		resolver: resolver,
+	}
+
+	for _, opt := range opts {
+		opt.applyWorkspace(srv)
+	}
+
+	return srv
+}
+
+// CreateWorkspace is implement WorkspaceService method
+func (srv *workspaceServiceImpl) CreateWorkspace(ctx context.Context, id string, name string, ownerID string) (workspace *entity.Workspace, err error) {
+	if !workspaceIDPattern.MatchString(id) {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "workspace id = %v must be 2 ~ 32 lower case letters, digits or hyphens", id)
+	}
+
+	if ownerID == "" {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "workspace owner is required")
+	}
+
+	workspace = &entity.Workspace{
+		ID:        id,
+		Name:      strings.TrimSpace(name),
+		OwnerID:   ownerID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := srv.repo.CreateWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// ListWorkspaces is implement WorkspaceService method
+func (srv *workspaceServiceImpl) ListWorkspaces(ctx context.Context, ownerID string) (workspaces []*entity.Workspace, err error) {
+	return srv.repo.ListWorkspaces(ctx, ownerID)
+}
+
+// SetFallbackURL is implement WorkspaceService method
+func (srv *workspaceServiceImpl) SetFallbackURL(ctx context.Context, id string, fallbackURL string) (workspace *entity.Workspace, err error) {
+	fallbackURL = strings.TrimSpace(fallbackURL)
+	if fallbackURL != "" {
+		if err := srv.checkFallbackURL(ctx, fallbackURL); err != nil {
+			return nil, err
+		}
+	}
+
+	workspace, err = srv.repo.FindWorkspace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := srv.repo.SetFallbackURL(ctx, id, fallbackURL); err != nil {
+		return nil, err
+	}
+	workspace.FallbackURL = fallbackURL
+
+	return workspace, nil
+}
+
+// checkFallbackURL check fallback url by the policy and abuse score like the original url of short urls,
+// a flagged url is rejected because the fallback url is redirected to without interstitial page
+func (srv *workspaceServiceImpl) checkFallbackURL(ctx context.Context, fallbackURL string) error {
+	if srv.urls == nil {
+		return validateOriginalURL(fallbackURL)
+	}
+
+	if err := srv.urls.checkOriginalURL(ctx, fallbackURL); err != nil {
+		return err
+	}
+
+	verdict, err := srv.urls.scoreURL(ctx, fallbackURL)
+	if err != nil {
+		return err
+	}
+	if verdict.Action == abuse.ActionFlag {
+		return abuse.Rejected(verdict)
+	}
+
+	return nil
+}
+
+// AddDomain is implement WorkspaceService method
+func (srv *workspaceServiceImpl) AddDomain(ctx context.Context, workspaceID string, host string) (domain *entity.Domain, err error) {
+	host = normalizeHost(host)
+	if !hostPattern.MatchString(host) {
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "domain = %v is not a valid host name", host)
+	}
+
+	if _, err := srv.repo.FindWorkspace(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to generate domain token err = %v", err)
+	}
+
+	domain = &entity.Domain{
+		Host:        host,
+		WorkspaceID: workspaceID,
+		Token:       hex.EncodeToString(token),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if err := srv.repo.AddDomain(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// VerifyDomain is implement WorkspaceService method
+func (srv *workspaceServiceImpl) VerifyDomain(ctx context.Context, host string) (domain *entity.Domain, err error) {
+	host = normalizeHost(host)
+
+	domain, err = srv.repo.FindDomain(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.Verified() {
+		return domain, nil
+	}
+
+	name := VerificationRecordPrefix + host
+	records, err := srv.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrForbidden, "failed to lookup TXT record of %v err = %v", name, err)
+	}
+
+	expected := VerificationValuePrefix + domain.Token
+	found := false
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.Wrapf(errors.ErrForbidden, "TXT record of %v does not contain %v", name, expected)
+	}
+
+	now := time.Now().UTC()
+	if err := srv.repo.VerifyDomain(ctx, host, now); err != nil {
+		return nil, err
+	}
+	domain.VerifiedAt = &now
+
+	return domain, nil
+}
+
+// FindWorkspace is implement WorkspaceService method
+func (srv *workspaceServiceImpl) FindWorkspace(ctx context.Context, id string) (workspace *entity.Workspace, err error) {
+	if !workspaceIDPattern.MatchString(id) {
+		return nil, errors.Wrapf(errors.ErrResourceNotFound, "workspace id = %v not found", id)
+	}
+
+	return srv.repo.FindWorkspace(ctx, id)
+}
+
+// ResolveHost is implement WorkspaceService method
+// host not added or not verified yet is served by the default workspace
+func (srv *workspaceServiceImpl) ResolveHost(ctx context.Context, host string) (workspace *entity.Workspace, err error) {
+	host = normalizeHost(host)
+	if host == "" {
+		return nil, nil
+	}
+
+	domain, err := srv.repo.FindDomain(ctx, host)
+	if err != nil {
+		if errors.Is(err, errors.ErrResourceNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !domain.Verified() {
+		return nil, nil
+	}
+
+	return srv.repo.FindWorkspace(ctx, domain.WorkspaceID)
+}
+
+// normalizeHost return lower case host name without port and trailing dot
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+type workspaceKey struct{}
+
+// NewWorkspaceContext return context carry the workspace of request
+func NewWorkspaceContext(ctx context.Context, workspace *entity.Workspace) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// WorkspaceFromContext return the workspace of request, false means the default workspace
+func WorkspaceFromContext(ctx context.Context) (*entity.Workspace, bool) {
+	workspace, ok := ctx.Value(workspaceKey{}).(*entity.Workspace)
+	return workspace, ok && workspace != nil
+}
+
+// workspaceID return the workspace id of context, empty means the default workspace
+func workspaceID(ctx context.Context) string {
+	if workspace, ok := WorkspaceFromContext(ctx); ok {
+		return workspace.ID
+	}
+	return ""
+}
+
+// checkWorkspaceWriter check the principal of context can short urls in the workspace of context,
+// everyone who can short urls can write the default workspace
+func checkWorkspaceWriter(ctx context.Context) error {
+	return checkWorkspaceMember(ctx, "short url")
+}
+
+// checkWorkspaceReader check the principal of context can read urls in the workspace of context,
+// everyone who can read urls can read the default workspace
+func checkWorkspaceReader(ctx context.Context) error {
+	return checkWorkspaceMember(ctx, "read url")
+}
+
+// checkWorkspaceMember check the principal of context is owner of the workspace of context or admin
+func checkWorkspaceMember(ctx context.Context, action string) error {
+	workspace, ok := WorkspaceFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return errors.Wrapf(errors.ErrUnauthorized, "credential is required to %v in workspace = %v", action, workspace.ID)
+	}
+
+	if p.ID != workspace.OwnerID && !p.IsAdmin() {
+		return errors.Wrapf(errors.ErrForbidden, "principal = %v is not member of workspace = %v", p.ID, workspace.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,355 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/abuse"
+	am "url-shortener/pkg/abuse/mocks"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	. "url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+	pm "url-shortener/pkg/policy/mocks"
+)
+
+// fakeResolver answer TXT lookup from map, missing name is not found
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func Test_workspaceServiceImpl_CreateWorkspace(t *testing.T) {
+	repo := mocks.NewWorkspaceRepository(t)
+	repo.EXPECT().
+		CreateWorkspace(mock.Anything, mock.MatchedBy(func(workspace *entity.Workspace) bool {
+			return workspace.ID == "team-a" && workspace.Name == "Team A" && workspace.OwnerID == "alice"
+		})).
+		Return(nil)
+
+	srv := NewWorkspaceService(repo, fakeResolver{})
+
+	workspace, err := srv.CreateWorkspace(context.Background(), "team-a", " Team A ", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", workspace.ID)
+
+	for _, id := range []string{"", "a", "Team-A", "team/a", "-team", strings.Repeat("a", 33)} {
+		_, err := srv.CreateWorkspace(context.Background(), id, "", "alice")
+		assert.Truef(t, errors.Is(err, errors.ErrInvalidInput), "CreateWorkspace(%q) error = %v", id, err)
+	}
+
+	_, err = srv.CreateWorkspace(context.Background(), "team-b", "", "")
+	assert.True(t, errors.Is(err, errors.ErrInvalidInput))
+}
+
+func Test_workspaceServiceImpl_SetFallbackURL(t *testing.T) {
+	repo := mocks.NewWorkspaceRepository(t)
+	repo.EXPECT().FindWorkspace(mock.Anything, "team-a").Return(&entity.Workspace{ID: "team-a"}, nil)
+	repo.EXPECT().SetFallbackURL(mock.Anything, "team-a", "https://team-a.example.com/expired").Return(nil)
+
+	srv := NewWorkspaceService(repo, fakeResolver{})
+
+	workspace, err := srv.SetFallbackURL(context.Background(), "team-a", " https://team-a.example.com/expired ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://team-a.example.com/expired", workspace.FallbackURL)
+	}
+
+	for _, fallback := range []string{"team-a.example.com", "mailto:ops@example.com"} {
+		_, err := srv.SetFallbackURL(context.Background(), "team-a", fallback)
+		assert.Truef(t, errors.Is(err, errors.ErrInvalidInput), "SetFallbackURL(%q) error = %v", fallback, err)
+	}
+}
+
+func Test_workspaceServiceImpl_SetFallbackURLWithURLCheck(t *testing.T) {
+	const fallback = "https://team-a.example.com/expired?utm_source=mail"
+
+	tests := []struct {
+		name      string
+		policyErr error
+		verdict   abuse.Verdict
+		stored    bool
+		wantErr   error
+	}{
+		{
+			name:    "Allowed",
+			verdict: abuse.Verdict{Action: abuse.ActionAllow},
+			stored:  true,
+		},
+		{
+			name:      "PolicyRejected",
+			policyErr: errors.ErrInvalidInput,
+			wantErr:   errors.ErrInvalidInput,
+		},
+		{
+			name:    "Flagged",
+			verdict: abuse.Verdict{Action: abuse.ActionFlag, Score: 45},
+			wantErr: errors.ErrInvalidInput,
+		},
+		{
+			name:    "Rejected",
+			verdict: abuse.Verdict{Action: abuse.ActionReject, Score: 90},
+			wantErr: errors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pm.NewPolicy(t)
+			p.EXPECT().Check(mock.Anything, "https://team-a.example.com/expired").Return(tt.policyErr)
+
+			scorer := am.NewScorer(t)
+			if tt.policyErr == nil {
+				scorer.EXPECT().Score(mock.Anything, "https://team-a.example.com/expired", "").Return(tt.verdict, nil)
+			}
+
+			repo := mocks.NewWorkspaceRepository(t)
+			if tt.stored {
+				repo.EXPECT().FindWorkspace(mock.Anything, "team-a").Return(&entity.Workspace{ID: "team-a"}, nil)
+				repo.EXPECT().SetFallbackURL(mock.Anything, "team-a", fallback).Return(nil)
+			}
+
+			srv := NewWorkspaceService(repo, fakeResolver{}, WithURLCheck(
+				WithPolicy(p),
+				WithAbuse(scorer, mocks.NewReviewRepository(t)),
+				WithTrackingParams("utm_*"),
+			))
+
+			workspace, err := srv.SetFallbackURL(context.Background(), "team-a", fallback)
+			if tt.wantErr != nil {
+				assert.Truef(t, errors.Is(err, tt.wantErr), "SetFallbackURL() error = %v, expected %v", err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, fallback, workspace.FallbackURL)
+			}
+		})
+	}
+}
+
+func Test_workspaceServiceImpl_AddDomain(t *testing.T) {
+	repo := mocks.NewWorkspaceRepository(t)
+	repo.EXPECT().FindWorkspace(mock.Anything, "team-a").Return(&entity.Workspace{ID: "team-a"}, nil)
+	repo.EXPECT().AddDomain(mock.Anything, mock.Anything).Return(nil)
+
+	srv := NewWorkspaceService(repo, fakeResolver{})
+
+	domain, err := srv.AddDomain(context.Background(), "team-a", "Go.Example.COM.")
+	assert.NoError(t, err)
+	assert.Equal(t, "go.example.com", domain.Host)
+	assert.Len(t, domain.Token, 32)
+	assert.False(t, domain.Verified())
+
+	for _, host := range []string{"", "localhost", "exa mple.com", "-a.com", "a..com"} {
+		_, err := srv.AddDomain(context.Background(), "team-a", host)
+		assert.Truef(t, errors.Is(err, errors.ErrInvalidInput), "AddDomain(%q) error = %v", host, err)
+	}
+}
+
+func Test_workspaceServiceImpl_VerifyDomain(t *testing.T) {
+	verifiedAt := time.Date(2023, 7, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		domain   *entity.Domain
+		resolver fakeResolver
+		verify   bool
+		err      error
+	}{
+		{
+			name:   "Success",
+			domain: &entity.Domain{Host: "go.example.com", WorkspaceID: "team-a", Token: "abc"},
+			resolver: fakeResolver{
+				"_urlshortener.go.example.com": {"other", "urlshortener-verification=abc"},
+			},
+			verify: true,
+		},
+		{
+			name:   "AlreadyVerified",
+			domain: &entity.Domain{Host: "go.example.com", WorkspaceID: "team-a", Token: "abc", VerifiedAt: &verifiedAt},
+		},
+		{
+			name:   "TokenMismatch",
+			domain: &entity.Domain{Host: "go.example.com", WorkspaceID: "team-a", Token: "abc"},
+			resolver: fakeResolver{
+				"_urlshortener.go.example.com": {"urlshortener-verification=xyz"},
+			},
+			err: errors.ErrForbidden,
+		},
+		{
+			name:   "RecordNotFound",
+			domain: &entity.Domain{Host: "go.example.com", WorkspaceID: "team-a", Token: "abc"},
+			err:    errors.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewWorkspaceRepository(t)
+			repo.EXPECT().FindDomain(mock.Anything, "go.example.com").Return(tt.domain, nil)
+			if tt.verify {
+				repo.EXPECT().VerifyDomain(mock.Anything, "go.example.com", mock.Anything).Return(nil)
+			}
+
+			domain, err := NewWorkspaceService(repo, tt.resolver).VerifyDomain(context.Background(), "go.example.com")
+			if err != nil || tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "VerifyDomain() error = %v, expected error %v", err, tt.err)
+				return
+			}
+			assert.True(t, domain.Verified())
+		})
+	}
+}
+
+func Test_workspaceServiceImpl_ResolveHost(t *testing.T) {
+	verifiedAt := time.Date(2023, 7, 17, 0, 0, 0, 0, time.UTC)
+	workspace := &entity.Workspace{ID: "team-a", OwnerID: "alice"}
+
+	repo := mocks.NewWorkspaceRepository(t)
+	repo.EXPECT().FindDomain(mock.Anything, "go.example.com").
+		Return(&entity.Domain{Host: "go.example.com", WorkspaceID: "team-a", VerifiedAt: &verifiedAt}, nil)
+	repo.EXPECT().FindDomain(mock.Anything, "new.example.com").
+		Return(&entity.Domain{Host: "new.example.com", WorkspaceID: "team-a"}, nil)
+	repo.EXPECT().FindDomain(mock.Anything, "sho.rt").
+		Return(nil, errors.Wrap(errors.ErrResourceNotFound, "not found"))
+	repo.EXPECT().FindWorkspace(mock.Anything, "team-a").Return(workspace, nil)
+
+	srv := NewWorkspaceService(repo, fakeResolver{})
+
+	tests := []struct {
+		host     string
+		expected *entity.Workspace
+	}{
+		{host: "GO.example.com:443", expected: workspace},
+		{host: "new.example.com"},
+		{host: "sho.rt"},
+		{host: ""},
+	}
+	for _, tt := range tests {
+		actual, err := srv.ResolveHost(context.Background(), tt.host)
+		assert.NoError(t, err)
+		assert.Equalf(t, tt.expected, actual, "ResolveHost(%q)", tt.host)
+	}
+}
+
+func Test_shortenedURLServiceImpl_ShortURLInWorkspace(t *testing.T) {
+	workspace := &entity.Workspace{ID: "team-a", OwnerID: "alice"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		err       error
+	}{
+		{
+			name:      "Owner",
+			principal: &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeCreate}},
+		},
+		{
+			name:      "Admin",
+			principal: &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}},
+		},
+		{
+			name:      "NotMember",
+			principal: &auth.Principal{ID: "bob", Scopes: []auth.Scope{auth.ScopeCreate}},
+			err:       errors.ErrForbidden,
+		},
+		{
+			name: "Anonymous",
+			err:  errors.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewWorkspaceContext(context.Background(), workspace)
+			if tt.principal != nil {
+				ctx = auth.NewContext(ctx, tt.principal)
+			}
+
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.err == nil {
+				isWorkspaceKey := mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, "team-a/") })
+				bf.EXPECT().Exist(mock.Anything, isWorkspaceKey).Return(false)
+				bf.EXPECT().Add(mock.Anything, isWorkspaceKey)
+				repo.EXPECT().StoreShortenedURL(mock.Anything, mock.MatchedBy(func(shortenedURL *entity.ShortenedURL) bool {
+					return shortenedURL.WorkspaceID == "team-a"
+				})).Return(nil)
+			}
+
+			actual, err := New(repo, bf).ShortURL(ctx, "https://www.dcard.tw", nil)
+			if err != nil || tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "ShortURL() error = %v, expected error %v", err, tt.err)
+				return
+			}
+			assert.Equal(t, "team-a", actual.WorkspaceID)
+		})
+	}
+}
+
+func Test_shortenedURLServiceImpl_LookupShortenedURLInWorkspace(t *testing.T) {
+	workspace := &entity.Workspace{ID: "team-a", OwnerID: "alice"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		err       error
+	}{
+		{
+			name:      "Owner",
+			principal: &auth.Principal{ID: "alice", Scopes: []auth.Scope{auth.ScopeRead}},
+		},
+		{
+			name:      "Admin",
+			principal: &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}},
+		},
+		{
+			name:      "NotMember",
+			principal: &auth.Principal{ID: "bob", Scopes: []auth.Scope{auth.ScopeRead}},
+			err:       errors.ErrForbidden,
+		},
+		{
+			name: "Anonymous",
+			err:  errors.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewWorkspaceContext(context.Background(), workspace)
+			if tt.principal != nil {
+				ctx = auth.NewContext(ctx, tt.principal)
+			}
+
+			repo := mocks.NewRepository(t)
+			bf := bm.NewFilter(t)
+			if tt.err == nil {
+				bf.EXPECT().Exist(mock.Anything, "team-a/6Xme5Xwp").Return(true).Times(2)
+				repo.EXPECT().FindShortenedURL(mock.Anything, "team-a", "6Xme5Xwp").
+					Return(&entity.ShortenedURL{Short: "6Xme5Xwp", WorkspaceID: "team-a", OriginalURL: "https://www.dcard.tw"}, nil).
+					Times(2)
+			}
+
+			srv := New(repo, bf)
+			actual, err := srv.LookupShortenedURL(ctx, "6Xme5Xwp")
+			results := srv.LookupShortenedURLs(ctx, []string{"6Xme5Xwp"})
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "LookupShortenedURL() error = %v, expected error %v", err, tt.err)
+				assert.Truef(t, errors.Is(results[0].Err, tt.err), "LookupShortenedURLs() error = %v, expected error %v", results[0].Err, tt.err)
+				return
+			}
+			if assert.NoError(t, err) && assert.NoError(t, results[0].Err) {
+				assert.Equal(t, "https://www.dcard.tw", actual.OriginalURL)
+				assert.Equal(t, "https://www.dcard.tw", results[0].ShortenedURL.OriginalURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"url-shortener/pkg/app/urlshortener/endpoints"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/transports/grpc/pb"
+	"url-shortener/pkg/errors"
+)
+
+var _ pb.URLShortenerServer = &Server{}
+
+// Server is wrap all endpoints
+type Server struct {
+	pb.UnimplementedURLShortenerServer
+
+	shortURL  kitgrpc.Handler
+	resolve   kitgrpc.Handler
+	updateURL kitgrpc.Handler
+	deleteURL kitgrpc.Handler
+}
+
+// NewServer new grpc server
+func NewServer(e endpoints.Endpoints) *Server {
+	v := validator.New()
+
+	return &Server{
+		shortURL: kitgrpc.NewServer(
+			e.ShortURLEndpoint,
+			decodeShortURLRequest(v),
+			encodeShortURLResponse,
+		),
+		resolve: kitgrpc.NewServer(
+			e.RedirectURLEndpoint,
+			decodeResolveRequest(v),
+			encodeResolveResponse,
+		),
+		updateURL: kitgrpc.NewServer(
+			e.UpdateShortenedURLEndpoint,
+			decodeUpdateURLRequest(v),
+			encodeUpdateURLResponse,
+		),
+		deleteURL: kitgrpc.NewServer(
+			e.DeleteShortenedURLEndpoint,
+			decodeDeleteURLRequest(v),
+			encodeDeleteURLResponse,
+		),
+	}
+}
+
+// ShortURL short URL grpc handler
+func (s *Server) ShortURL(ctx context.Context, req *pb.ShortURLRequest) (*pb.ShortURLResponse, error) {
+	_, resp, err := s.shortURL.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ShortURLResponse), nil
+}
+
+// Resolve is resolve short URL grpc handler
+func (s *Server) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	_, resp, err := s.resolve.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ResolveResponse), nil
+}
+
+// UpdateURL is update shortened URL grpc handler
+func (s *Server) UpdateURL(ctx context.Context, req *pb.UpdateURLRequest) (*pb.UpdateURLResponse, error) {
+	_, resp, err := s.updateURL.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateURLResponse), nil
+}
+
+// DeleteURL is delete shortened URL grpc handler
+func (s *Server) DeleteURL(ctx context.Context, req *pb.DeleteURLRequest) (*emptypb.Empty, error) {
+	_, resp, err := s.deleteURL.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*emptypb.Empty), nil
+}
+
+func decodeShortURLRequest(v *validator.Validate) kitgrpc.DecodeRequestFunc {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r := request.(*pb.ShortURLRequest)
+
+		req := &endpoints.ShortURLRequest{
+			URL: r.GetUrl(),
+		}
+		if r.GetExpireAt() != nil {
+			expireAt := r.GetExpireAt().AsTime().UTC().Format(`2006-01-02T15:04:05Z`)
+			req.ExpiredAt = &expireAt
+		}
+
+		if err := v.Struct(req); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidInput, "validate short url request is fail")
+		}
+
+		return req, nil
+	}
+}
+
+func encodeShortURLResponse(ctx context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*endpoints.ShortURLResponse)
+
+	return &pb.ShortURLResponse{
+		Id:       resp.ID,
+		ShortUrl: resp.ShortURL,
+	}, nil
+}
+
+func decodeResolveRequest(v *validator.Validate) kitgrpc.DecodeRequestFunc {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r := request.(*pb.ResolveRequest)
+
+		req := &endpoints.RedirectURLRequest{
+			ShortURL: r.GetId(),
+		}
+
+		if err := v.Struct(req); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidInput, "validate resolve request is fail")
+		}
+
+		return req, nil
+	}
+}
+
+func encodeResolveResponse(ctx context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*endpoints.RedirectURLResponse)
+
+	// the http redirect shows the interstitial page, a grpc client must show its own warning
+	return &pb.ResolveResponse{
+		Id:           resp.ShortenedURL.Short,
+		OriginalUrl:  resp.ShortenedURL.OriginalURL,
+		CreatedAt:    timestamppb.New(resp.ShortenedURL.CreatedAt),
+		ExpiredAt:    timestamppb.New(resp.ShortenedURL.ExpiredAt),
+		Interstitial: resp.ShortenedURL.Interstitial != entity.InterstitialOff,
+	}, nil
+}
+
+func decodeUpdateURLRequest(v *validator.Validate) kitgrpc.DecodeRequestFunc {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r := request.(*pb.UpdateURLRequest)
+
+		req := &endpoints.UpdateShortenedURLRequest{
+			ID:           r.GetId(),
+			Title:        r.Title,
+			Notes:        r.Notes,
+			Interstitial: r.Interstitial,
+			FallbackURL:  r.FallbackUrl,
+		}
+		if r.GetExpireAt() != nil {
+			expireAt := r.GetExpireAt().AsTime().UTC().Format(`2006-01-02T15:04:05Z`)
+			req.ExpiredAt = &expireAt
+		}
+		if r.GetTags() != nil {
+			tags := r.GetTags().GetValues()
+			if tags == nil {
+				tags = []string{}
+			}
+			req.Tags = &tags
+		}
+
+		if err := v.Struct(req); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidInput, "validate update url request is fail")
+		}
+
+		return req, nil
+	}
+}
+
+func encodeUpdateURLResponse(ctx context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*endpoints.ShortenedURLResponse)
+
+	return &pb.UpdateURLResponse{
+		Id:           resp.ID,
+		ShortUrl:     resp.ShortURL,
+		OriginalUrl:  resp.OriginalURL,
+		CreatedAt:    timestamppb.New(resp.CreatedAt),
+		ExpiredAt:    timestamppb.New(resp.ExpiredAt),
+		Title:        resp.Title,
+		Tags:         resp.Tags,
+		Notes:        resp.Notes,
+		Interstitial: resp.Interstitial,
+		FallbackUrl:  resp.FallbackURL,
+	}, nil
+}
+
+func decodeDeleteURLRequest(v *validator.Validate) kitgrpc.DecodeRequestFunc {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r := request.(*pb.DeleteURLRequest)
+
+		req := &endpoints.DeleteShortenedURLRequest{
+			ID: r.GetId(),
+		}
+
+		if err := v.Struct(req); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidInput, "validate delete url request is fail")
+		}
+
+		return req, nil
+	}
+}
+
+func encodeDeleteURLResponse(ctx context.Context, response interface{}) (interface{}, error) {
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,221 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.3
+// source: urlshortener.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	URLShortener_ShortURL_FullMethodName  = "/urlshortener.v1.URLShortener/ShortURL"
+	URLShortener_Resolve_FullMethodName   = "/urlshortener.v1.URLShortener/Resolve"
+	URLShortener_UpdateURL_FullMethodName = "/urlshortener.v1.URLShortener/UpdateURL"
+	URLShortener_DeleteURL_FullMethodName = "/urlshortener.v1.URLShortener/DeleteURL"
+)
+
+// URLShortenerClient is the client API for URLShortener service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type URLShortenerClient interface {
+	ShortURL(ctx context.Context, in *ShortURLRequest, opts ...grpc.CallOption) (*ShortURLResponse, error)
+	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
+	UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*UpdateURLResponse, error)
+	DeleteURL(ctx context.Context, in *DeleteURLRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type uRLShortenerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewURLShortenerClient(cc grpc.ClientConnInterface) URLShortenerClient {
+	return &uRLShortenerClient{cc}
+}
+
+func (c *uRLShortenerClient) ShortURL(ctx context.Context, in *ShortURLRequest, opts ...grpc.CallOption) (*ShortURLResponse, error) {
+	out := new(ShortURLResponse)
+	err := c.cc.Invoke(ctx, URLShortener_ShortURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, URLShortener_Resolve_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*UpdateURLResponse, error) {
+	out := new(UpdateURLResponse)
+	err := c.cc.Invoke(ctx, URLShortener_UpdateURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) DeleteURL(ctx context.Context, in *DeleteURLRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, URLShortener_DeleteURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// URLShortenerServer is the server API for URLShortener service.
+// All implementations must embed UnimplementedURLShortenerServer
+// for forward compatibility
+type URLShortenerServer interface {
+	ShortURL(context.Context, *ShortURLRequest) (*ShortURLResponse, error)
+	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	UpdateURL(context.Context, *UpdateURLRequest) (*UpdateURLResponse, error)
+	DeleteURL(context.Context, *DeleteURLRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedURLShortenerServer()
+}
+
+// UnimplementedURLShortenerServer must be embedded to have forward compatible implementations.
+type UnimplementedURLShortenerServer struct {
+}
+
+func (UnimplementedURLShortenerServer) ShortURL(context.Context, *ShortURLRequest) (*ShortURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShortURL not implemented")
+}
+func (UnimplementedURLShortenerServer) Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedURLShortenerServer) UpdateURL(context.Context, *UpdateURLRequest) (*UpdateURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateURL not implemented")
+}
+func (UnimplementedURLShortenerServer) DeleteURL(context.Context, *DeleteURLRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteURL not implemented")
+}
+func (UnimplementedURLShortenerServer) mustEmbedUnimplementedURLShortenerServer() {}
+
+// UnsafeURLShortenerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to URLShortenerServer will
+// result in compilation errors.
+type UnsafeURLShortenerServer interface {
+	mustEmbedUnimplementedURLShortenerServer()
+}
+
+func RegisterURLShortenerServer(s grpc.ServiceRegistrar, srv URLShortenerServer) {
+	s.RegisterService(&URLShortener_ServiceDesc, srv)
+}
+
+func _URLShortener_ShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).ShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_ShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).ShortURL(ctx, req.(*ShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_Resolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).Resolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_Resolve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).Resolve(ctx, req.(*ResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_UpdateURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).UpdateURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_UpdateURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).UpdateURL(ctx, req.(*UpdateURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_DeleteURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).DeleteURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_DeleteURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).DeleteURL(ctx, req.(*DeleteURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// URLShortener_ServiceDesc is the grpc.ServiceDesc for URLShortener service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var URLShortener_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "urlshortener.v1.URLShortener",
+	HandlerType: (*URLShortenerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ShortURL",
+			Handler:    _URLShortener_ShortURL_Handler,
+		},
+		{
+			MethodName: "Resolve",
+			Handler:    _URLShortener_Resolve_Handler,
+		},
+		{
+			MethodName: "UpdateURL",
+			Handler:    _URLShortener_UpdateURL_Handler,
+		},
+		{
+			MethodName: "DeleteURL",
+			Handler:    _URLShortener_DeleteURL_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "urlshortener.proto",
+}
@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/app/urlshortener/transports/grpc/pb"
+)
+
+// MetadataWorkspaceID select workspace of request which is not sent to the workspace domain, same as HTTP X-Workspace-ID
+const MetadataWorkspaceID = "x-workspace-id"
+
+// UnaryWorkspaceInterceptor put workspace of request into request context,
+// Resolve is only resolved by :authority like the redirect, other methods also accept MetadataWorkspaceID
+func UnaryWorkspaceInterceptor(workspaces service.WorkspaceService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		var (
+			workspace *entity.Workspace
+			err       error
+		)
+		if id := first(md, MetadataWorkspaceID); info.FullMethod != pb.URLShortener_Resolve_FullMethodName && id != "" {
+			workspace, err = workspaces.FindWorkspace(ctx, id)
+		} else {
+			workspace, err = workspaces.ResolveHost(ctx, first(md, ":authority"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if workspace == nil {
+			return handler(ctx, req)
+		}
+
+		logger := log.Ctx(ctx).With().Str("workspace", workspace.ID).Logger()
+		return handler(logger.WithContext(service.NewWorkspaceContext(ctx, workspace)), req)
+	}
+}
+
+// first return the first value of key in metadata, empty when it is missing
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) != 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
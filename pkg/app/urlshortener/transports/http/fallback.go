@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/app/urlshortener/service"
+)
+
+type setFallbackURL struct{ fallbackURL string }
+
+func (opt *setFallbackURL) apply(h *Handler) { h.fallbackURL = opt.fallbackURL }
+
+// WithFallbackURL redirect expired urls to fallbackURL when neither the url nor its workspace has a fallback,
+// empty keep the 404 response
+func WithFallbackURL(fallbackURL string) Option {
+	return &setFallbackURL{fallbackURL: fallbackURL}
+}
+
+// fallback redirect expired url to the fallback url of the url, its workspace or the server,
+// false when there is none. The hit is logged with postExpiry, so analytics can tell it from a redirect.
+// It is not cached, so extending the expire time take effect at once
+func (h *Handler) fallback(c echo.Context, short string, err error) (bool, error) {
+	fallbackURL, ok := service.FallbackOf(err)
+	if !ok {
+		fallbackURL = h.fallbackURL
+	}
+	if fallbackURL == "" {
+		return false, nil
+	}
+
+	log.Ctx(c.Request().Context()).Info().
+		Str("short", short).
+		Str("fallback", fallbackURL).
+		Bool("postExpiry", true).
+		Msg("redirect expired url to fallback")
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return true, c.Redirect(http.StatusFound, fallbackURL)
+}
@@ -1,22 +1,120 @@
 package http
 
 import (
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"url-shortener/pkg/app/urlshortener/endpoints"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/service"
+	"url-shortener/pkg/auth"
 	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+	"url-shortener/pkg/idempotency"
+	"url-shortener/pkg/ratelimit"
 )
 
 // Handler is wrap all endpoints
 type Handler struct {
 	e endpoints.Endpoints
+
+	authenticators []auth.Authenticator
+	workspaces     service.WorkspaceService
+	limiter        ratelimit.Limiter
+	policies       map[string]ratelimit.Policy
+	guard          guard.Guard
+
+	idempotency     idempotency.Store
+	idempotencyWait time.Duration
+
+	interstitialDelay time.Duration
+
+	unlockSecret []byte
+	unlockTTL    time.Duration
+
+	fallbackURL string
+}
+
+// An Option is passed to Handler constructor
+type Option interface {
+	apply(*Handler)
+}
+
+type setAuthenticators struct{ authenticators []auth.Authenticator }
+
+func (opt *setAuthenticators) apply(h *Handler) { h.authenticators = opt.authenticators }
+
+// WithAuthenticators require credential on routes with scope
+func WithAuthenticators(authenticators ...auth.Authenticator) Option {
+	return &setAuthenticators{authenticators: authenticators}
+}
+
+type setWorkspaces struct{ workspaces service.WorkspaceService }
+
+func (opt *setWorkspaces) apply(h *Handler) { h.workspaces = opt.workspaces }
+
+// WithWorkspaces resolve workspace of every request by Host, api routes also accept HeaderWorkspaceID
+func WithWorkspaces(workspaces service.WorkspaceService) Option {
+	return &setWorkspaces{workspaces: workspaces}
+}
+
+type setRateLimit struct {
+	limiter  ratelimit.Limiter
+	policies map[string]ratelimit.Policy
+}
+
+func (opt *setRateLimit) apply(h *Handler) {
+	h.limiter = opt.limiter
+	h.policies = opt.policies
+}
+
+// WithRateLimit limit requests of routes by policy name, e.g. RateLimitCreate and RateLimitRedirect
+func WithRateLimit(limiter ratelimit.Limiter, policies map[string]ratelimit.Policy) Option {
+	return &setRateLimit{limiter: limiter, policies: policies}
+}
+
+type setGuard struct{ guard guard.Guard }
+
+func (opt *setGuard) apply(h *Handler) { h.guard = opt.guard }
+
+// WithGuard tarpit or block clients of guarded routes by their misses, e.g. redirect
+func WithGuard(g guard.Guard) Option {
+	return &setGuard{guard: g}
+}
+
+type setIdempotency struct {
+	store idempotency.Store
+	wait  time.Duration
+}
+
+func (opt *setIdempotency) apply(h *Handler) {
+	h.idempotency = opt.store
+	h.idempotencyWait = opt.wait
+}
+
+// WithIdempotency replay the first response of idempotent routes with the same Idempotency-Key header,
+// a duplicate of a running request wait for it at most wait
+func WithIdempotency(store idempotency.Store, wait time.Duration) Option {
+	return &setIdempotency{store: store, wait: wait}
 }
 
 // NewHandler new handler
-func NewHandler(e endpoints.Endpoints) *Handler {
-	return &Handler{e: e}
+func NewHandler(e endpoints.Endpoints, opts ...Option) *Handler {
+	h := &Handler{e: e, interstitialDelay: DefaultInterstitialDelay, unlockTTL: DefaultUnlockTTL}
+	for _, opt := range opts {
+		opt.apply(h)
+	}
+
+	if len(h.unlockSecret) == 0 {
+		h.unlockSecret = newUnlockSecret()
+	}
+
+	return h
 }
 
 // ShortURL short URL http handler
@@ -43,6 +141,30 @@ func (h *Handler) ShortURL(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// BatchShortURL is batch short URL http handler
+func (h *Handler) BatchShortURL(c echo.Context) error {
+	var (
+		req = new(endpoints.BatchShortURLRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to bind batch short url request %v", err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "validate batch short url request is fail, urls must be 1 ~ %v", endpoints.MaxBatchShortURLs)
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.BatchShortURLEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // RedirectURL is redirectURL http handler
 func (h *Handler) RedirectURL(c echo.Context) error {
 	var (
@@ -57,14 +179,417 @@ func (h *Handler) RedirectURL(c echo.Context) error {
 		return errors.Wrap(errors.ErrInvalidInput, "validate redirect url request is fail")
 	}
 
+	now := time.Now()
 	ctx := c.Request().Context()
+	if h.unlocked(c, req.ShortURL, now) {
+		ctx = service.NewUnlockedContext(ctx)
+	}
 
 	resp, err := h.e.RedirectURLEndpoint(ctx, req)
 	if err != nil {
+		switch {
+		case errors.Is(err, errors.ErrPasswordRequired):
+			return h.protected(c, http.StatusOK, req.ShortURL, "")
+		case errors.Is(err, errors.ErrShortenedURLNotActive):
+			if retryAfter, ok := service.RetryAfterOf(err); ok {
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+			}
+		case errors.Is(err, errors.ErrShortenedURLExpire):
+			if ok, err := h.fallback(c, req.ShortURL, err); ok {
+				return err
+			}
+		}
 		return err
 	}
 
 	r := resp.(*endpoints.RedirectURLResponse)
+	if r.ShortenedURL.Interstitial != entity.InterstitialOff {
+		return h.interstitial(c, r.ShortenedURL)
+	}
+
+	// the redirect of protected url is only for the unlocked browser, no cache may replay it without the password
+	if r.ShortenedURL.Protected() {
+		c.Response().Header().Set("Cache-Control", "no-store, private")
+		return c.Redirect(http.StatusFound, r.ShortenedURL.OriginalURL)
+	}
+
+	// browsers cache permanent redirect, every click of click limited url must reach us to be counted
+	if r.ShortenedURL.MaxClicks > 0 {
+		c.Response().Header().Set("Cache-Control", "no-store")
+		return c.Redirect(http.StatusFound, r.ShortenedURL.OriginalURL)
+	}
+
+	// scheduled url switch its destination later, so the redirect is only cached until then
+	if next := r.ShortenedURL.NextTransitionAt(now); !next.IsZero() {
+		maxAge := int64(math.Ceil(next.Sub(now).Seconds()))
+		c.Response().Header().Set("Cache-Control", "max-age="+strconv.FormatInt(maxAge, 10))
+		return c.Redirect(http.StatusFound, r.ShortenedURL.OriginalURL)
+	}
 
 	return c.Redirect(http.StatusMovedPermanently, r.ShortenedURL.OriginalURL)
 }
+
+// GetShortenedURL is get shortened url metadata http handler
+func (h *Handler) GetShortenedURL(c echo.Context) error {
+	var (
+		req = new(endpoints.GetShortenedURLRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind get shortened url request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate get shortened url request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.GetShortenedURLEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListShortenedURLs is list shortened urls http handler
+func (h *Handler) ListShortenedURLs(c echo.Context) error {
+	var (
+		req = new(endpoints.ListShortenedURLsRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to bind list shortened urls request %v", err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "validate list shortened urls request is fail %v", err)
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ListShortenedURLsEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ResolveURLs is resolve many short urls http handler
+func (h *Handler) ResolveURLs(c echo.Context) error {
+	var (
+		req = new(endpoints.ResolveURLsRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to bind resolve urls request %v", err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "validate resolve urls request is fail, ids must be 1 ~ %v", endpoints.MaxResolveURLs)
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ResolveURLsEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateShortenedURL is update shortened url http handler
+func (h *Handler) UpdateShortenedURL(c echo.Context) error {
+	var (
+		req = new(endpoints.UpdateShortenedURLRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to bind update shortened url request %v", err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate update shortened url request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.UpdateShortenedURLEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteShortenedURL is delete shortened url http handler
+func (h *Handler) DeleteShortenedURL(c echo.Context) error {
+	var (
+		req = new(endpoints.DeleteShortenedURLRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind delete shortened url request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate delete shortened url request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	if _, err := h.e.DeleteShortenedURLEndpoint(ctx, req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// QuotaUsage is quota usage http handler
+func (h *Handler) QuotaUsage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	resp, err := h.e.QuotaUsageEndpoint(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListBlocks is list blocked clients http handler
+func (h *Handler) ListBlocks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ListBlocksEndpoint(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// BlockClient is block client http handler
+func (h *Handler) BlockClient(c echo.Context) error {
+	var (
+		req = new(endpoints.BlockClientRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind block client request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate block client request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.BlockClientEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UnblockClient is unblock client http handler
+func (h *Handler) UnblockClient(c echo.Context) error {
+	var (
+		req = new(endpoints.UnblockClientRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind unblock client request")
+	}
+
+	// IPv6 /64 client contain slash, so it is path escaped
+	client, err := url.PathUnescape(req.Client)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to unescape client = %v", req.Client)
+	}
+	req.Client = client
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate unblock client request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	if _, err := h.e.UnblockClientEndpoint(ctx, req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListReviews is list reviews http handler
+func (h *Handler) ListReviews(c echo.Context) error {
+	var (
+		req = new(endpoints.ListReviewsRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "failed to bind list reviews request %v", err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrapf(errors.ErrInvalidInput, "validate list reviews request is fail %v", err)
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ListReviewsEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ResolveReview is resolve review http handler
+func (h *Handler) ResolveReview(c echo.Context) error {
+	var (
+		req = new(endpoints.ResolveReviewRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind resolve review request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate resolve review request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ResolveReviewEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ReportURL is report abuse url http handler
+func (h *Handler) ReportURL(c echo.Context) error {
+	var (
+		req = new(endpoints.ReportURLRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind report url request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate report url request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ReportURLEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListReports is list reports http handler
+func (h *Handler) ListReports(c echo.Context) error {
+	var (
+		req = new(endpoints.ListReportsRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind list reports request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate list reports request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ListReportsEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetReport is get report http handler
+func (h *Handler) GetReport(c echo.Context) error {
+	var (
+		req = new(endpoints.GetReportRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind get report request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate get report request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.GetReportEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// TriageReport is triage report http handler
+func (h *Handler) TriageReport(c echo.Context) error {
+	var (
+		req = new(endpoints.TriageReportRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind triage report request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate triage report request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.TriageReportEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ResolveReport is resolve report http handler
+func (h *Handler) ResolveReport(c echo.Context) error {
+	var (
+		req = new(endpoints.ResolveReportRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind resolve report request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate resolve report request is fail")
+	}
+
+	ctx := c.Request().Context()
+
+	resp, err := h.e.ResolveReportEndpoint(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
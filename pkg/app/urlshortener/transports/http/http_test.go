@@ -0,0 +1,1154 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/pkg/app/urlshortener/endpoints"
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/mocks"
+	"url-shortener/pkg/app/urlshortener/repository"
+	"url-shortener/pkg/app/urlshortener/service"
+	th "url-shortener/pkg/app/urlshortener/transports/http"
+	"url-shortener/pkg/auth"
+	authmocks "url-shortener/pkg/auth/mocks"
+	bm "url-shortener/pkg/bloom/mocks"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+	gm "url-shortener/pkg/guard/mocks"
+	ph "url-shortener/pkg/http"
+	"url-shortener/pkg/http/openapi"
+	"url-shortener/pkg/quota"
+	"url-shortener/pkg/ratelimit"
+)
+
+// adminKey is the credential of adminAuthenticator
+const adminKey = "usk_admin"
+
+// adminAuthenticator accept adminKey as admin, so contract tests reach admin routes which are only served with authenticators
+type adminAuthenticator struct{}
+
+func (adminAuthenticator) Authenticate(_ context.Context, credential string) (*auth.Principal, error) {
+	if credential != adminKey {
+		return nil, nil
+	}
+	return &auth.Principal{ID: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}}, nil
+}
+
+// TestRoutes_MatchOpenAPI fail when a registered route is missing in the document or vice versa
+func TestRoutes_MatchOpenAPI(t *testing.T) {
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(mocks.NewShortenedURLService(t)))
+	h.MakeRouter(e)
+
+	doc := h.OpenAPI()
+
+	registered := make(map[string]bool)
+	for _, r := range e.Routes() {
+		if r.Path == th.OpenAPIPath || r.Path == th.SwaggerUIPath {
+			continue
+		}
+		registered[r.Method+" "+openapi.Path(r.Path)] = true
+		assert.NotNilf(t, doc.Find(r.Method, r.Path), "route %v %v is not in OpenAPI document", r.Method, r.Path)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range *item {
+			assert.Truef(t, registered[strings.ToUpper(method)+" "+path], "OpenAPI operation %v %v is not registered", method, path)
+		}
+	}
+}
+
+// TestHandler_Contract call every handler and check request and response match the OpenAPI document
+func TestHandler_Contract(t *testing.T) {
+	shortenedURL := &entity.ShortenedURL{
+		Short:       "K2MY8LEp",
+		OriginalURL: "https://www.dcard.tw/",
+		CreatedAt:   time.Now(),
+		ExpiredAt:   time.Now().Add(time.Hour),
+		Title:       "Dcard",
+		Tags:        []string{"campaign"},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		target string
+		body   string
+		svc    func() *mocks.ShortenedURLService
+		status int
+	}{
+		{
+			name:   "ShortURL",
+			method: http.MethodPost,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls",
+			body:   `{"url":"https://www.dcard.tw/","expireAt":"2099-07-03T13:08:00Z"}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ShortURL(mock.Anything, "https://www.dcard.tw/", mock.Anything).Return(shortenedURL, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ShortURLDedupe",
+			method: http.MethodPost,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls",
+			body:   `{"url":"https://www.dcard.tw/","dedupe":true}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ShortURL(mock.Anything, "https://www.dcard.tw/", mock.MatchedBy(func(opts *service.ShortURLOption) bool {
+					return opts.Dedupe
+				})).Return(shortenedURL, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ShortURLSingleUse",
+			method: http.MethodPost,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls",
+			body:   `{"url":"https://www.dcard.tw/","maxClicks":1}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ShortURL(mock.Anything, "https://www.dcard.tw/", mock.MatchedBy(func(opts *service.ShortURLOption) bool {
+					return opts.MaxClicks == 1
+				})).Return(shortenedURL, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ShortURLInvalidInput",
+			method: http.MethodPost,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls",
+			body:   `{"url":"https://www.dcard.tw/","expireAt":"2006-01-02"}`,
+			svc: func() *mocks.ShortenedURLService {
+				return mocks.NewShortenedURLService(t)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "BatchShortURL",
+			method: http.MethodPost,
+			path:   "/api/v1/urls\\:batch",
+			target: "/api/v1/urls:batch",
+			body:   `{"urls":[{"url":"https://www.dcard.tw/"},{"url":"https://www.dcard.tw/","expireAt":"2006-01-02"}]}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ShortURLs(mock.Anything, mock.Anything).Return([]*service.ShortURLResult{
+					{ShortenedURL: shortenedURL},
+				})
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "RedirectURL",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/K2MY8LEp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(shortenedURL, nil)
+				return svc
+			},
+			status: http.StatusMovedPermanently,
+		},
+		{
+			name:   "RedirectURLInterstitial",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/K2MY8LEp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(&entity.ShortenedURL{
+					Short:        "K2MY8LEp",
+					OriginalURL:  "https://www.dcard.tw/f",
+					Interstitial: entity.InterstitialAlways,
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "RedirectURLNotFound",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/K2MY8LEp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(nil, errors.ErrResourceNotFound)
+				return svc
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "RedirectURLClickLimited",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "6Xme5Xwp").Return(&entity.ShortenedURL{
+					Short:       "6Xme5Xwp",
+					OriginalURL: "https://www.dcard.tw/reset?token=x",
+					ExpiredAt:   time.Now().Add(time.Hour),
+					MaxClicks:   1,
+					Clicks:      1,
+				}, nil)
+				return svc
+			},
+			status: http.StatusFound,
+		},
+		{
+			name:   "RedirectURLScheduled",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "6Xme5Xwp").Return(&entity.ShortenedURL{
+					Short:       "6Xme5Xwp",
+					OriginalURL: "https://www.dcard.tw/f/teaser",
+					ExpiredAt:   time.Now().Add(24 * time.Hour),
+					Schedule:    []entity.Destination{{At: time.Now().Add(time.Hour), URL: "https://www.dcard.tw/f/sale"}},
+				}, nil)
+				return svc
+			},
+			status: http.StatusFound,
+		},
+		{
+			name:   "RedirectURLNotActive",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "6Xme5Xwp").Return(nil, errors.ErrShortenedURLNotActive)
+				return svc
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "RedirectURLExpiredFallback",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "6Xme5Xwp").Return(nil, errors.ErrShortenedURLExpire.WithDetails(errors.Detail{
+					Reason:   service.ReasonExpired,
+					Domain:   service.Domain,
+					Metadata: map[string]interface{}{"fallbackUrl": "https://www.dcard.tw/f/expired"},
+				}))
+				return svc
+			},
+			status: http.StatusFound,
+		},
+		{
+			name:   "RedirectURLExhausted",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/K2MY8LEp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(nil, errors.ErrShortenedURLExhausted)
+				return svc
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ListShortenedURLs",
+			method: http.MethodGet,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls?tag=campaign&state=active&createdFrom=2023-06-30T00:00:00Z&limit=1",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().
+					SearchShortenedURLs(mock.Anything, mock.MatchedBy(func(filter *repository.SearchFilter) bool {
+						return filter.Tag == "campaign" &&
+							filter.State == repository.ExpiryStateActive &&
+							filter.CreatedFrom.Equal(time.Date(2023, time.June, 30, 0, 0, 0, 0, time.UTC)) &&
+							filter.Limit == 1
+					})).
+					Return([]*entity.ShortenedURL{shortenedURL}, "MTY4ODEyOTIwMDAwMDpLMk1ZOExFcA", nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ListShortenedURLsInvalidState",
+			method: http.MethodGet,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls?state=deleted",
+			svc: func() *mocks.ShortenedURLService {
+				return mocks.NewShortenedURLService(t)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "GetShortenedURL",
+			method: http.MethodGet,
+			path:   "/api/v1/urls/:id",
+			target: "/api/v1/urls/K2MY8LEp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().LookupShortenedURL(mock.Anything, "K2MY8LEp").Return(shortenedURL, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "UpdateShortenedURL",
+			method: http.MethodPatch,
+			path:   "/api/v1/urls/:id",
+			target: "/api/v1/urls/K2MY8LEp",
+			body:   `{"title":"Dcard forum","tags":["forum"]}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().
+					UpdateShortenedURL(mock.Anything, "K2MY8LEp", mock.MatchedBy(func(opts *service.UpdateOption) bool {
+						return *opts.Title == "Dcard forum" && opts.Notes == nil && opts.ExpiredAt == nil
+					})).
+					Return(shortenedURL, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "UpdateShortenedURLForbidden",
+			method: http.MethodPatch,
+			path:   "/api/v1/urls/:id",
+			target: "/api/v1/urls/K2MY8LEp",
+			body:   `{"title":"Dcard forum"}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().UpdateShortenedURL(mock.Anything, "K2MY8LEp", mock.Anything).Return(nil, errors.ErrForbidden)
+				return svc
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "DeleteShortenedURL",
+			method: http.MethodDelete,
+			path:   "/api/v1/urls/:id",
+			target: "/api/v1/urls/K2MY8LEp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().DeleteShortenedURL(mock.Anything, "K2MY8LEp").Return(nil)
+				return svc
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "ResolveURLs",
+			method: http.MethodPost,
+			path:   "/api/v1/urls\\:resolve",
+			target: "/api/v1/urls:resolve",
+			body:   `{"ids":["K2MY8LEp","6Xme5Xwp"]}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().LookupShortenedURLs(mock.Anything, []string{"K2MY8LEp", "6Xme5Xwp"}).Return([]*service.LookupResult{
+					{Short: "K2MY8LEp", ShortenedURL: shortenedURL},
+					{Short: "6Xme5Xwp", Err: errors.Wrap(errors.ErrPageNotFound, "shortened url not in bloom filter")},
+				})
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "QuotaUsage",
+			method: http.MethodGet,
+			path:   "/api/v1/usage",
+			target: "/api/v1/usage",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().QuotaUsage(mock.Anything).Return([]*quota.Usage{
+					{
+						Subject: "apikey:Xa8LmQ2pRt0z",
+						Day:     quota.Counter{Used: 3, Limit: 100, ResetAt: time.Now().Add(time.Hour)},
+						Month:   quota.Counter{Used: 42, Limit: 1000, ResetAt: time.Now().Add(time.Hour)},
+						Active:  quota.Counter{Used: 40},
+					},
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "QuotaExceeded",
+			method: http.MethodPost,
+			path:   "/api/v1/urls",
+			target: "/api/v1/urls",
+			body:   `{"url":"https://www.dcard.tw/"}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ShortURL(mock.Anything, "https://www.dcard.tw/", mock.Anything).Return(nil, errors.ErrTooManyRequests.WithDetails(errors.Detail{
+					Reason:   quota.ReasonExceeded,
+					Domain:   quota.Domain,
+					Metadata: map[string]interface{}{"window": "day", "resetAt": "2023-07-18T00:00:00Z"},
+				}))
+				return svc
+			},
+			status: http.StatusTooManyRequests,
+		},
+		{
+			name:   "ListBlocks",
+			method: http.MethodGet,
+			path:   "/api/v1/admin/blocks",
+			target: "/api/v1/admin/blocks",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ListBlockedClients(mock.Anything).Return([]*guard.Block{
+					{Client: "203.0.113.7", Reason: guard.ReasonAuto, ExpiredAt: time.Now().Add(time.Hour)},
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "BlockClient",
+			method: http.MethodPost,
+			path:   "/api/v1/admin/blocks",
+			target: "/api/v1/admin/blocks",
+			body:   `{"client":"203.0.113.7","duration":"24h"}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().BlockClient(mock.Anything, "203.0.113.7", 24*time.Hour).Return(&guard.Block{
+					Client: "203.0.113.7", Reason: guard.ReasonManual, ExpiredAt: time.Now().Add(24 * time.Hour),
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "BlockClientInvalidDuration",
+			method: http.MethodPost,
+			path:   "/api/v1/admin/blocks",
+			target: "/api/v1/admin/blocks",
+			body:   `{"client":"203.0.113.7","duration":"a day"}`,
+			svc: func() *mocks.ShortenedURLService {
+				return mocks.NewShortenedURLService(t)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "UnblockClient",
+			method: http.MethodDelete,
+			path:   "/api/v1/admin/blocks/:client",
+			target: "/api/v1/admin/blocks/2001:db8:1:2::%2F64",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().UnblockClient(mock.Anything, "2001:db8:1:2::/64").Return(nil)
+				return svc
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "ListReviews",
+			method: http.MethodGet,
+			path:   "/api/v1/admin/reviews",
+			target: "/api/v1/admin/reviews?status=pending&limit=10",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ListReviews(mock.Anything, entity.ReviewPending, 10).Return([]*entity.Review{
+					{
+						Short:       "6Xme5Xwp",
+						OriginalURL: "https://203.0.113.7/",
+						Score:       40,
+						Signals:     []entity.Signal{{Rule: "ipHost", Score: 40, Reason: "host 203.0.113.7 is an ip"}},
+						Status:      entity.ReviewPending,
+						CreatedAt:   time.Now(),
+					},
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ListReviewsInvalidStatus",
+			method: http.MethodGet,
+			path:   "/api/v1/admin/reviews",
+			target: "/api/v1/admin/reviews?status=unknown",
+			svc: func() *mocks.ShortenedURLService {
+				return mocks.NewShortenedURLService(t)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "ResolveReview",
+			method: http.MethodPost,
+			path:   "/api/v1/admin/reviews/:id",
+			target: "/api/v1/admin/reviews/6Xme5Xwp",
+			body:   `{"status":"rejected"}`,
+			svc: func() *mocks.ShortenedURLService {
+				reviewedAt := time.Now()
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ResolveReview(mock.Anything, "6Xme5Xwp", entity.ReviewRejected).Return(&entity.Review{
+					Short:      "6Xme5Xwp",
+					Score:      40,
+					Status:     entity.ReviewRejected,
+					CreatedAt:  time.Now(),
+					ReviewedAt: &reviewedAt,
+					ReviewerID: "ops",
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ReportURL",
+			method: http.MethodPost,
+			path:   "/api/v1/reports",
+			target: "/api/v1/reports",
+			body:   `{"code":"https://s.example/6Xme5Xwp","reason":"phishing page of a bank","contact":"abuse@example.com"}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ReportShortenedURL(mock.Anything, "6Xme5Xwp", "phishing page of a bank", "abuse@example.com").
+					Return(&entity.Report{ID: 7, Short: "6Xme5Xwp", Status: entity.ReportOpen}, nil)
+				return svc
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name:   "ReportURLWithoutReason",
+			method: http.MethodPost,
+			path:   "/api/v1/reports",
+			target: "/api/v1/reports",
+			body:   `{"code":"6Xme5Xwp"}`,
+			svc: func() *mocks.ShortenedURLService {
+				return mocks.NewShortenedURLService(t)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "GetReport",
+			method: http.MethodGet,
+			path:   "/api/v1/admin/reports/:id",
+			target: "/api/v1/admin/reports/7",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().GetReport(mock.Anything, int64(7)).Return(
+					&entity.Report{ID: 7, Short: "6Xme5Xwp", Reason: "phishing", Status: entity.ReportTriaged, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+					[]*entity.ModerationAction{{ID: 1, ReportID: 7, ActorID: "ops", Action: entity.ActionTriage, CreatedAt: time.Now()}},
+					nil,
+				)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "ResolveReport",
+			method: http.MethodPost,
+			path:   "/api/v1/admin/reports/:id/resolve",
+			target: "/api/v1/admin/reports/7/resolve",
+			body:   `{"resolution":"disabled","note":"confirmed phishing"}`,
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().ResolveReport(mock.Anything, int64(7), entity.ResolutionDisabled, "confirmed phishing").Return(&entity.Report{
+					ID:         7,
+					Short:      "6Xme5Xwp",
+					Reason:     "phishing",
+					Status:     entity.ReportResolved,
+					Resolution: entity.ResolutionDisabled,
+					CreatedAt:  time.Now(),
+					UpdatedAt:  time.Now(),
+				}, nil)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "RedirectURLDisabled",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "6Xme5Xwp").Return(nil, errors.ErrShortenedURLDisabled)
+				return svc
+			},
+			status: http.StatusGone,
+		},
+		{
+			name:   "RedirectURLProtected",
+			method: http.MethodGet,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().RetrieveShortenedURL(mock.Anything, "6Xme5Xwp").Return(nil, errors.ErrPasswordRequired)
+				return svc
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "UnlockURL",
+			method: http.MethodPost,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().UnlockShortenedURL(mock.Anything, "6Xme5Xwp", "", "192.0.2.1").Return(nil)
+				return svc
+			},
+			status: http.StatusSeeOther,
+		},
+		{
+			name:   "UnlockURLWrongPassword",
+			method: http.MethodPost,
+			path:   "/:url",
+			target: "/6Xme5Xwp",
+			svc: func() *mocks.ShortenedURLService {
+				svc := mocks.NewShortenedURLService(t)
+				svc.EXPECT().UnlockShortenedURL(mock.Anything, "6Xme5Xwp", "", "192.0.2.1").Return(errors.ErrPasswordRequired)
+				return svc
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "ResolveURLsTooMany",
+			method: http.MethodPost,
+			path:   "/api/v1/urls\\:resolve",
+			target: "/api/v1/urls:resolve",
+			body:   `{"ids":[` + strings.Repeat(`"K2MY8LEp",`, endpoints.MaxResolveURLs) + `"6Xme5Xwp"]}`,
+			svc: func() *mocks.ShortenedURLService {
+				return mocks.NewShortenedURLService(t)
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			h := th.NewHandler(endpoints.New(tt.svc()), th.WithAuthenticators(adminAuthenticator{}))
+			h.MakeRouter(e)
+
+			doc := h.OpenAPI()
+			op := doc.Find(tt.method, tt.path)
+			if !assert.NotNil(t, op) {
+				return
+			}
+
+			if tt.body != "" && tt.status < http.StatusBadRequest && assert.NotNil(t, op.RequestBody) {
+				assert.NoError(t, doc.Validate(op.RequestBody.Content[echo.MIMEApplicationJSON].Schema, decode(t, tt.body)))
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(auth.HeaderAPIKey, adminKey)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+
+			resp, ok := op.Responses[strconv.Itoa(rec.Code)]
+			if !ok {
+				resp, ok = op.Responses["default"]
+			}
+			if !assert.Truef(t, ok, "status %v is not in OpenAPI document", rec.Code) {
+				return
+			}
+
+			for header := range resp.Headers {
+				assert.NotEmptyf(t, rec.Header().Get(header), "header %v is missing", header)
+			}
+
+			if content, ok := resp.Content[echo.MIMEApplicationJSON]; ok {
+				assert.NoError(t, doc.Validate(content.Schema, decode(t, rec.Body.String())))
+			} else {
+				assert.NotContains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+			}
+		})
+	}
+}
+
+// TestHandler_Auth check route with scope require credential only when handler has authenticators
+func TestHandler_Auth(t *testing.T) {
+	svc := mocks.NewShortenedURLService(t)
+	svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(nil, errors.ErrResourceNotFound)
+
+	a := authmocks.NewAuthenticator(t)
+	a.EXPECT().Authenticate(mock.Anything, "usk_read").Return(&auth.Principal{ID: "team-a", Scopes: []auth.Scope{auth.ScopeRead}}, nil)
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(svc), th.WithAuthenticators(a))
+	h.MakeRouter(e)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		key    string
+		status int
+	}{
+		{name: "Anonymous", method: http.MethodPost, target: "/api/v1/urls", status: http.StatusUnauthorized},
+		{name: "MissingScope", method: http.MethodPost, target: "/api/v1/urls", key: "usk_read", status: http.StatusForbidden},
+		{name: "PublicRedirect", method: http.MethodGet, target: "/K2MY8LEp", status: http.StatusNotFound},
+		{name: "AnonymousAdmin", method: http.MethodPost, target: "/api/v1/admin/blocks", status: http.StatusUnauthorized},
+		{name: "NotAdmin", method: http.MethodPost, target: "/api/v1/admin/blocks", key: "usk_read", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(`{"url":"https://www.dcard.tw/"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.key != "" {
+				req.Header.Set(auth.HeaderAPIKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	op := h.OpenAPI().Find(http.MethodPost, "/api/v1/urls")
+	if assert.NotNil(t, op) {
+		assert.Contains(t, op.Security, openapi.SecurityRequirement{"apiKey": {"create"}})
+	}
+}
+
+// TestHandler_AdminWithoutAuth check admin routes are not served when the handler has no authenticators,
+// the path falls through to redirect which does not find it
+func TestHandler_AdminWithoutAuth(t *testing.T) {
+	svc := mocks.NewShortenedURLService(t)
+	svc.EXPECT().RetrieveShortenedURL(mock.Anything, mock.Anything).Return(nil, errors.ErrPageNotFound)
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(svc))
+	h.MakeRouter(e)
+
+	for _, target := range []string{"/api/v1/admin/blocks", "/api/v1/admin/reviews", "/api/v1/admin/reports"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equalf(t, http.StatusNotFound, rec.Code, "GET %v", target)
+		assert.Nilf(t, h.OpenAPI().Find(http.MethodGet, target), "GET %v is in OpenAPI document", target)
+	}
+}
+
+// TestHandler_Validation check request which does not match the OpenAPI document never reach the service
+func TestHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		field  string
+	}{
+		{
+			name:   "BodyType",
+			method: http.MethodPost,
+			target: "/api/v1/urls",
+			body:   `{"url":1}`,
+			field:  "url",
+		},
+		{
+			name:   "BodyUnknownField",
+			method: http.MethodPost,
+			target: "/api/v1/urls",
+			body:   `{"url":"https://www.dcard.tw/f","expire":"2023-07-01T00:00:00Z"}`,
+			field:  "expire",
+		},
+		{
+			name:   "QueryType",
+			method: http.MethodGet,
+			target: "/api/v1/urls?limit=ten",
+			field:  "limit",
+		},
+		{
+			name:   "QueryEnum",
+			method: http.MethodGet,
+			target: "/api/v1/urls?state=deleted",
+			field:  "state",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			th.NewHandler(endpoints.New(mocks.NewShortenedURLService(t))).MakeRouter(e)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"`+tt.field+`"`)
+		})
+	}
+}
+
+func TestHandler_ServeOpenAPI(t *testing.T) {
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(mocks.NewShortenedURLService(t)))
+	h.MakeRouter(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, th.OpenAPIPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	doc := new(openapi.Document)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Components.Schemas, "ShortURLResponse")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, th.SwaggerUIPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), th.OpenAPIPath)
+}
+
+func decode(t *testing.T, body string) interface{} {
+	var v interface{}
+	assert.NoError(t, json.Unmarshal([]byte(body), &v))
+	return v
+}
+
+// TestHandler_Workspace check workspace is resolved by host on redirect and by header on api
+func TestHandler_Workspace(t *testing.T) {
+	verifiedAt := time.Now()
+	workspace := &entity.Workspace{
+		ID:      "team-a",
+		OwnerID: "alice",
+		Domains: []*entity.Domain{{Host: "go.example.com", WorkspaceID: "team-a", VerifiedAt: &verifiedAt}},
+	}
+	inWorkspace := mock.MatchedBy(func(ctx context.Context) bool {
+		w, ok := service.WorkspaceFromContext(ctx)
+		return ok && w.ID == "team-a"
+	})
+
+	svc := mocks.NewShortenedURLService(t)
+	svc.EXPECT().RetrieveShortenedURL(inWorkspace, "K2MY8LEp").Return(&entity.ShortenedURL{
+		Short:       "K2MY8LEp",
+		WorkspaceID: "team-a",
+		OriginalURL: "https://www.dcard.tw/",
+		ExpiredAt:   time.Now().Add(time.Hour),
+	}, nil)
+	svc.EXPECT().ShortURL(inWorkspace, "https://www.dcard.tw/", mock.Anything).Return(&entity.ShortenedURL{
+		Short:       "6Xme5Xwp",
+		WorkspaceID: "team-a",
+		OriginalURL: "https://www.dcard.tw/",
+	}, nil)
+
+	workspaces := mocks.NewWorkspaceService(t)
+	workspaces.EXPECT().ResolveHost(mock.Anything, "go.example.com").Return(workspace, nil)
+	workspaces.EXPECT().FindWorkspace(mock.Anything, "team-a").Return(workspace, nil)
+	workspaces.EXPECT().FindWorkspace(mock.Anything, "team-z").Return(nil, errors.ErrResourceNotFound)
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(svc), th.WithWorkspaces(workspaces))
+	h.MakeRouter(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/K2MY8LEp", nil)
+	req.Host = "go.example.com"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://www.dcard.tw/"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(th.HeaderWorkspaceID, "team-a")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if assert.Equal(t, http.StatusOK, rec.Code) {
+		assert.Contains(t, rec.Body.String(), `"shortUrl":"https://go.example.com/6Xme5Xwp"`)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://www.dcard.tw/"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(th.HeaderWorkspaceID, "team-z")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestHandler_RateLimit check routes with policy are limited and document the 429 response
+func TestHandler_RateLimit(t *testing.T) {
+	svc := mocks.NewShortenedURLService(t)
+	svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(nil, errors.ErrResourceNotFound).Once()
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(svc), th.WithRateLimit(ratelimit.NewMemoryLimiter(), map[string]ratelimit.Policy{
+		th.RateLimitRedirect: {Rate: 1, Period: time.Minute},
+	}))
+	h.MakeRouter(e)
+
+	statuses := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/K2MY8LEp", nil))
+		statuses = append(statuses, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusNotFound, http.StatusTooManyRequests}, statuses)
+
+	doc := h.OpenAPI()
+	assert.Contains(t, doc.Find(http.MethodGet, "/:url").Responses, "429")
+	assert.NotContains(t, doc.Find(http.MethodPost, "/api/v1/urls").Responses, "429")
+}
+
+// TestHandler_Guard check blocked client is rejected on redirect and the client is passed to the service
+func TestHandler_Guard(t *testing.T) {
+	svc := mocks.NewShortenedURLService(t)
+	svc.EXPECT().RetrieveShortenedURL(mock.MatchedBy(func(ctx context.Context) bool {
+		client, ok := guard.FromContext(ctx)
+		return ok && client == "192.0.2.1"
+	}), "K2MY8LEp").Return(nil, errors.ErrPageNotFound).Once()
+
+	g := gm.NewGuard(t)
+	g.EXPECT().Check(mock.Anything, "192.0.2.1").Return(guard.Verdict{}, nil).Once()
+	g.EXPECT().Check(mock.Anything, "192.0.2.1").Return(guard.Verdict{Blocked: true, BlockedUntil: time.Now().Add(time.Hour)}, nil).Once()
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	h := th.NewHandler(endpoints.New(svc), th.WithGuard(g))
+	h.MakeRouter(e)
+
+	statuses := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/K2MY8LEp", nil))
+		statuses = append(statuses, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusNotFound, http.StatusTooManyRequests}, statuses)
+
+	doc := h.OpenAPI()
+	assert.Contains(t, doc.Find(http.MethodGet, "/:url").Responses, "429")
+	assert.NotContains(t, doc.Find(http.MethodGet, "/api/v1/urls").Responses, "429")
+}
+
+// TestHandler_Interstitial check interstitial url render warning page with the destination instead of redirect
+func TestHandler_Interstitial(t *testing.T) {
+	tests := []struct {
+		name         string
+		interstitial entity.Interstitial
+		flagged      bool
+	}{
+		{name: "Always", interstitial: entity.InterstitialAlways},
+		{name: "Flagged", interstitial: entity.InterstitialFlagged, flagged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewShortenedURLService(t)
+			svc.EXPECT().RetrieveShortenedURL(mock.Anything, "K2MY8LEp").Return(&entity.ShortenedURL{
+				Short:        "K2MY8LEp",
+				OriginalURL:  "https://www.dcard.tw/f?a=1&b=<2>",
+				Interstitial: tt.interstitial,
+			}, nil)
+
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			th.NewHandler(endpoints.New(svc), th.WithInterstitialDelay(3*time.Second)).MakeRouter(e)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/K2MY8LEp", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get(echo.HeaderLocation))
+			assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			assert.Contains(t, rec.Body.String(), "You are leaving to www.dcard.tw")
+			assert.Contains(t, rec.Body.String(), `href="https://www.dcard.tw/f?a=1&amp;b=%3c2%3e"`)
+			assert.Contains(t, rec.Body.String(), "let remaining =  3 ;")
+			assert.Equal(t, tt.flagged, strings.Contains(rec.Body.String(), "flagged as possibly unsafe"))
+		})
+	}
+}
+
+// TestHandler_Unlock check protected url render password form until the right password set its unlock cookie
+func TestHandler_Unlock(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bf := bm.NewFilter(t)
+	bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+	repo := mocks.NewRepository(t)
+	repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+		Short:        "6Xme5Xwp",
+		OriginalURL:  "https://www.dcard.tw/secret",
+		ExpiredAt:    time.Now().Add(time.Hour),
+		PasswordHash: string(hash),
+	}, nil)
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	th.NewHandler(endpoints.New(service.New(repo, bf)), th.WithUnlockCookie([]byte("secret"), time.Minute)).MakeRouter(e)
+
+	redirect := func(cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/6Xme5Xwp", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	unlock := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/6Xme5Xwp", strings.NewReader("password="+password))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := redirect()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `action="/6Xme5Xwp"`)
+	assert.NotContains(t, rec.Body.String(), "dcard")
+
+	rec = unlock("guess")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "The password is wrong")
+	assert.Empty(t, rec.Result().Cookies())
+
+	rec = unlock("open+sesame")
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/6Xme5Xwp", rec.Header().Get(echo.HeaderLocation))
+	cookies := rec.Result().Cookies()
+	if !assert.Len(t, cookies, 1) {
+		return
+	}
+	assert.Equal(t, "/6Xme5Xwp", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, 60, cookies[0].MaxAge)
+
+	rec = redirect(cookies[0])
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "no-store, private", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "https://www.dcard.tw/secret", rec.Header().Get(echo.HeaderLocation))
+
+	// the signature cover the expire time, so the cookie can not be extended
+	exp, mac, _ := strings.Cut(cookies[0].Value, ".")
+	forged := *cookies[0]
+	forged.Value = exp + "0." + mac
+	assert.Equal(t, http.StatusOK, redirect(&forged).Code)
+}
+
+// TestHandler_Scheduled check scheduled url is cached until its next transition and not active url tell when to retry
+func TestHandler_Fallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback string
+		server   string
+		status   int
+		location string
+	}{
+		{
+			name:     "URL",
+			fallback: "https://www.dcard.tw/f/expired",
+			server:   "https://www.dcard.tw",
+			status:   http.StatusFound,
+			location: "https://www.dcard.tw/f/expired",
+		},
+		{
+			name:     "Server",
+			server:   "https://www.dcard.tw",
+			status:   http.StatusFound,
+			location: "https://www.dcard.tw",
+		},
+		{
+			name:   "None",
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+				Short:       "6Xme5Xwp",
+				OriginalURL: "https://www.dcard.tw/f/sale",
+				ExpiredAt:   time.Now().Add(-time.Hour),
+				FallbackURL: tt.fallback,
+			}, nil)
+
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			th.NewHandler(endpoints.New(service.New(repo, bf)), th.WithFallbackURL(tt.server)).MakeRouter(e)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/6Xme5Xwp", nil))
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.location, rec.Header().Get(echo.HeaderLocation))
+			if tt.location != "" {
+				assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestHandler_Scheduled(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		activateAt   time.Time
+		schedule     []entity.Destination
+		status       int
+		location     string
+		cacheControl string
+		retryAfter   string
+	}{
+		{
+			name:       "NotActive",
+			activateAt: now.Add(10 * time.Minute),
+			status:     http.StatusNotFound,
+			retryAfter: "600",
+		},
+		{
+			name:         "Switched",
+			activateAt:   now.Add(-time.Hour),
+			schedule:     []entity.Destination{{At: now.Add(-time.Minute), URL: "https://www.dcard.tw/f/sale"}, {At: now.Add(time.Hour), URL: "https://www.dcard.tw/f/ended"}},
+			status:       http.StatusFound,
+			location:     "https://www.dcard.tw/f/sale",
+			cacheControl: "max-age=3600",
+		},
+		{
+			name:     "LastSwitched",
+			schedule: []entity.Destination{{At: now.Add(-time.Minute), URL: "https://www.dcard.tw/f/ended"}},
+			status:   http.StatusMovedPermanently,
+			location: "https://www.dcard.tw/f/ended",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := bm.NewFilter(t)
+			bf.EXPECT().Exist(mock.Anything, "6Xme5Xwp").Return(true)
+			repo := mocks.NewRepository(t)
+			repo.EXPECT().FindShortenedURL(mock.Anything, "", "6Xme5Xwp").Return(&entity.ShortenedURL{
+				Short:       "6Xme5Xwp",
+				OriginalURL: "https://www.dcard.tw/f/teaser",
+				ExpiredAt:   now.Add(24 * time.Hour),
+				ActivateAt:  tt.activateAt,
+				Schedule:    tt.schedule,
+			}, nil)
+
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			th.NewHandler(endpoints.New(service.New(repo, bf))).MakeRouter(e)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/6Xme5Xwp", nil))
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.location, rec.Header().Get(echo.HeaderLocation))
+			assert.Equal(t, tt.cacheControl, rec.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.retryAfter, rec.Header().Get(echo.HeaderRetryAfter))
+		})
+	}
+}
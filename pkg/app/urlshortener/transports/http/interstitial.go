@@ -0,0 +1,54 @@
+package http
+
+import (
+	_ "embed"
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+)
+
+//go:embed interstitial.html
+var interstitialHTML string
+
+var interstitialTemplate = template.Must(template.New("interstitial").Parse(interstitialHTML))
+
+// DefaultInterstitialDelay is how long the continue button of interstitial page is disabled, see WithInterstitialDelay
+const DefaultInterstitialDelay = 5 * time.Second
+
+type setInterstitialDelay struct{ delay time.Duration }
+
+func (opt *setInterstitialDelay) apply(h *Handler) { h.interstitialDelay = opt.delay }
+
+// WithInterstitialDelay disable the continue button of interstitial page for delay, zero enable it at once
+func WithInterstitialDelay(delay time.Duration) Option {
+	return &setInterstitialDelay{delay: delay}
+}
+
+// interstitial render warning page of the original url instead of redirect,
+// the page is not cached so turning the interstitial off take effect at once
+func (h *Handler) interstitial(c echo.Context, shortenedURL *entity.ShortenedURL) error {
+	host := shortenedURL.OriginalURL
+	if u, err := url.Parse(shortenedURL.OriginalURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	header.Set("Cache-Control", "no-store")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("X-Robots-Tag", "noindex, nofollow")
+	c.Response().WriteHeader(http.StatusOK)
+
+	return interstitialTemplate.Execute(c.Response(), map[string]interface{}{
+		"Host":        host,
+		"Destination": shortenedURL.OriginalURL,
+		"Flagged":     shortenedURL.Interstitial == entity.InterstitialFlagged,
+		"Delay":       int(math.Ceil(h.interstitialDelay.Seconds())),
+	})
+}
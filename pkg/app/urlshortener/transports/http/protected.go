@@ -0,0 +1,149 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"url-shortener/pkg/app/urlshortener/endpoints"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+)
+
+//go:embed protected.html
+var protectedHTML string
+
+var protectedTemplate = template.Must(template.New("protected").Parse(protectedHTML))
+
+const (
+	// DefaultUnlockTTL is how long a protected url stays unlocked after the password is entered, see WithUnlockCookie
+	DefaultUnlockTTL = 10 * time.Minute
+
+	// unlockCookieName is the cookie which prove the password of a protected url is entered
+	unlockCookieName = "unlock"
+)
+
+type setUnlockCookie struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func (opt *setUnlockCookie) apply(h *Handler) {
+	h.unlockSecret = opt.secret
+	h.unlockTTL = opt.ttl
+}
+
+// WithUnlockCookie sign unlock cookie of protected url by secret, the cookie is valid for ttl,
+// instances behind the same domain must share the secret
+func WithUnlockCookie(secret []byte, ttl time.Duration) Option {
+	return &setUnlockCookie{secret: secret, ttl: ttl}
+}
+
+// newUnlockSecret return random secret, unlock cookies are invalid after restart with it
+func newUnlockSecret() []byte {
+	secret := make([]byte, sha256.Size)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+// unlockMAC sign the host, short and expire time, so the cookie can not be moved to another url or extended
+func (h *Handler) unlockMAC(host, short string, exp int64) string {
+	mac := hmac.New(sha256.New, h.unlockSecret)
+	mac.Write([]byte(host + "/" + short + "\n" + strconv.FormatInt(exp, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// setUnlockCookie set signed cookie which unlock the short url until the ttl
+func (h *Handler) setUnlockCookie(c echo.Context, short string, now time.Time) {
+	exp := now.Add(h.unlockTTL).Unix()
+
+	c.SetCookie(&http.Cookie{
+		Name:     unlockCookieName,
+		Value:    strconv.FormatInt(exp, 10) + "." + h.unlockMAC(c.Request().Host, short, exp),
+		Path:     "/" + short,
+		MaxAge:   int(h.unlockTTL.Seconds()),
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// unlocked check request carry valid unlock cookie of the short url
+func (h *Handler) unlocked(c echo.Context, short string, now time.Time) bool {
+	cookie, err := c.Cookie(unlockCookieName)
+	if err != nil {
+		return false
+	}
+
+	value, mac, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return false
+	}
+
+	exp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || now.Unix() >= exp {
+		return false
+	}
+
+	return hmac.Equal([]byte(mac), []byte(h.unlockMAC(c.Request().Host, short, exp)))
+}
+
+// protected render password form of protected url, the form post to UnlockURL
+func (h *Handler) protected(c echo.Context, status int, short string, message string) error {
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	header.Set("Cache-Control", "no-store")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("X-Robots-Tag", "noindex, nofollow")
+	c.Response().WriteHeader(status)
+
+	return protectedTemplate.Execute(c.Response(), map[string]interface{}{
+		"Short": short,
+		"Error": message,
+	})
+}
+
+// UnlockURL is unlock protected url http handler, the right password set unlock cookie and redirect back,
+// the wrong one render the form again
+func (h *Handler) UnlockURL(c echo.Context) error {
+	var (
+		req = new(endpoints.UnlockURLRequest)
+	)
+
+	if err := c.Bind(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "failed to bind unlock url request")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return errors.Wrap(errors.ErrInvalidInput, "validate unlock url request is fail")
+	}
+
+	req.Client = guard.ClientOf(c.RealIP())
+	ctx := c.Request().Context()
+
+	if _, err := h.e.UnlockURLEndpoint(ctx, req); err != nil {
+		switch {
+		case errors.Is(err, errors.ErrPasswordRequired):
+			return h.protected(c, http.StatusUnauthorized, req.ShortURL, "The password is wrong, please try again.")
+		case errors.Is(err, errors.ErrTooManyRequests):
+			return h.protected(c, http.StatusTooManyRequests, req.ShortURL, "Too many wrong passwords, please try again later.")
+		}
+		return err
+	}
+
+	h.setUnlockCookie(c, req.ShortURL, time.Now())
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.Redirect(http.StatusSeeOther, "/"+req.ShortURL)
+}
@@ -0,0 +1,547 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+
+	"url-shortener/pkg/app/urlshortener/endpoints"
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/http/middleware"
+	"url-shortener/pkg/http/openapi"
+	"url-shortener/pkg/idempotency"
+	"url-shortener/pkg/ratelimit"
+)
+
+const (
+	// OpenAPIPath is the path which serve OpenAPI document
+	OpenAPIPath = "/openapi.json"
+
+	// SwaggerUIPath is the path which serve Swagger UI
+	SwaggerUIPath = "/docs"
+
+	// RateLimitCreate is rate limit policy name of creating short urls
+	RateLimitCreate = "create"
+
+	// RateLimitRedirect is rate limit policy name of redirect
+	RateLimitRedirect = "redirect"
+
+	// RateLimitReport is rate limit policy name of abuse reports
+	RateLimitReport = "report"
+
+	securityAPIKey = "apiKey"
+	securityBearer = "bearer"
+)
+
+// Route is http route and its OpenAPI spec
+type Route struct {
+	openapi.Spec
+	Handler echo.HandlerFunc
+
+	// Scope is required scope when auth is enabled, empty means public
+	Scope auth.Scope
+
+	// RateLimit is the rate limit policy name, empty means unlimited
+	RateLimit string
+
+	// Guard is whether suspicious clients of the route are tarpitted or blocked by their misses
+	Guard bool
+
+	// Idempotent is whether the first response of the route is replayed for the same Idempotency-Key header
+	Idempotent bool
+}
+
+// Routes return all served routes of handler, the OpenAPI document is generated from it
+func (h *Handler) Routes() []Route {
+	return h.served([]Route{
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/urls",
+				OperationID: "shortURL",
+				Summary:     "Upload a URL with its expired date and response shorten url",
+				Tags:        []string{"urls"},
+				Request:     endpoints.ShortURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ShortURLResponse{}},
+				},
+			},
+			Handler:    h.ShortURL,
+			Scope:      auth.ScopeCreate,
+			RateLimit:  RateLimitCreate,
+			Idempotent: true,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/urls\\:batch",
+				OperationID: "batchShortURL",
+				Summary:     "Upload many URLs at once, every url has its own result",
+				Tags:        []string{"urls"},
+				Request:     endpoints.BatchShortURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.BatchShortURLResponse{}},
+				},
+			},
+			Handler:    h.BatchShortURL,
+			Scope:      auth.ScopeCreate,
+			RateLimit:  RateLimitCreate,
+			Idempotent: true,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/urls",
+				OperationID: "listShortenedURLs",
+				Summary:     "List shortened urls newest first, filter by tag, created time, expiry state, host or search text",
+				Tags:        []string{"urls"},
+				Request:     endpoints.ListShortenedURLsRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ListShortenedURLsResponse{}},
+				},
+			},
+			Handler: h.ListShortenedURLs,
+			Scope:   auth.ScopeRead,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/urls/:id",
+				OperationID: "getShortenedURL",
+				Summary:     "Get shortened url metadata without redirect",
+				Tags:        []string{"urls"},
+				Request:     endpoints.GetShortenedURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ShortenedURLResponse{}},
+				},
+			},
+			Handler: h.GetShortenedURL,
+			Scope:   auth.ScopeRead,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPatch,
+				Path:        "/api/v1/urls/:id",
+				OperationID: "updateShortenedURL",
+				Summary:     "Update title, tags, notes or expire time of an owned shortened url",
+				Tags:        []string{"urls"},
+				Request:     endpoints.UpdateShortenedURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ShortenedURLResponse{}},
+				},
+			},
+			Handler: h.UpdateShortenedURL,
+			Scope:   auth.ScopeManage,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodDelete,
+				Path:        "/api/v1/urls/:id",
+				OperationID: "deleteShortenedURL",
+				Summary:     "Delete an owned shortened url",
+				Tags:        []string{"urls"},
+				Request:     endpoints.DeleteShortenedURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusNoContent: {Description: "Deleted"},
+				},
+			},
+			Handler: h.DeleteShortenedURL,
+			Scope:   auth.ScopeManage,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/urls\\:resolve",
+				OperationID: "resolveURLs",
+				Summary:     "Resolve many short ids, every id has its own url or error",
+				Tags:        []string{"urls"},
+				Request:     endpoints.ResolveURLsRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ResolveURLsResponse{}},
+				},
+			},
+			Handler: h.ResolveURLs,
+			Scope:   auth.ScopeRead,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/usage",
+				OperationID: "quotaUsage",
+				Summary:     "Show quota consumption of the credential and workspace",
+				Tags:        []string{"quota"},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.QuotaUsageResponse{}},
+				},
+			},
+			Handler: h.QuotaUsage,
+			Scope:   auth.ScopeRead,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/admin/blocks",
+				OperationID: "listBlocks",
+				Summary:     "List clients blocked by short id enumeration protection",
+				Tags:        []string{"admin"},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ListBlocksResponse{}},
+				},
+			},
+			Handler: h.ListBlocks,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/admin/blocks",
+				OperationID: "blockClient",
+				Summary:     "Block a client ip or IPv6 /64 from redirect for a duration",
+				Tags:        []string{"admin"},
+				Request:     endpoints.BlockClientRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.Block{}},
+				},
+			},
+			Handler: h.BlockClient,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodDelete,
+				Path:        "/api/v1/admin/blocks/:client",
+				OperationID: "unblockClient",
+				Summary:     "Unblock a client and reset its misses",
+				Tags:        []string{"admin"},
+				Request:     endpoints.UnblockClientRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusNoContent: {Description: "Unblocked"},
+				},
+			},
+			Handler: h.UnblockClient,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/admin/reviews",
+				OperationID: "listReviews",
+				Summary:     "List urls flagged by abuse scoring",
+				Tags:        []string{"admin"},
+				Request:     endpoints.ListReviewsRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ListReviewsResponse{}},
+				},
+			},
+			Handler: h.ListReviews,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/admin/reviews/:id",
+				OperationID: "resolveReview",
+				Summary:     "Approve or reject a flagged url, rejected url is deleted",
+				Tags:        []string{"admin"},
+				Request:     endpoints.ResolveReviewRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.Review{}},
+				},
+			},
+			Handler: h.ResolveReview,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/reports",
+				OperationID: "reportURL",
+				Summary:     "Report a short url for abuse, anyone can report",
+				Tags:        []string{"reports"},
+				Request:     endpoints.ReportURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusCreated: {Body: endpoints.ReportURLResponse{}},
+				},
+			},
+			Handler:   h.ReportURL,
+			RateLimit: RateLimitReport,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/admin/reports",
+				OperationID: "listReports",
+				Summary:     "List abuse reports",
+				Tags:        []string{"admin"},
+				Request:     endpoints.ListReportsRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.ListReportsResponse{}},
+				},
+			},
+			Handler: h.ListReports,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/api/v1/admin/reports/:id",
+				OperationID: "getReport",
+				Summary:     "Get an abuse report with its moderation audit trail",
+				Tags:        []string{"admin"},
+				Request:     endpoints.GetReportRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.GetReportResponse{}},
+				},
+			},
+			Handler: h.GetReport,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/admin/reports/:id/triage",
+				OperationID: "triageReport",
+				Summary:     "Mark an open abuse report as triaged",
+				Tags:        []string{"admin"},
+				Request:     endpoints.TriageReportRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.Report{}},
+				},
+			},
+			Handler: h.TriageReport,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/api/v1/admin/reports/:id/resolve",
+				OperationID: "resolveReport",
+				Summary:     "Disable the reported url or dismiss the abuse report",
+				Tags:        []string{"admin"},
+				Request:     endpoints.ResolveReportRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Body: endpoints.Report{}},
+				},
+			},
+			Handler: h.ResolveReport,
+			Scope:   auth.ScopeAdmin,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodGet,
+				Path:        "/:url",
+				OperationID: "redirectURL",
+				Summary:     "Redirect to original url",
+				Tags:        []string{"urls"},
+				Request:     endpoints.RedirectURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusOK: {Description: "Interstitial warning page of the original url, see interstitial, or password form of protected url"},
+					http.StatusMovedPermanently: {
+						Description: "Redirect to original url",
+						Headers:     []string{echo.HeaderLocation},
+					},
+					http.StatusFound: {
+						Description: "Redirect to original url of click limited url which is not cached, scheduled url which is cached until its next destination switch, or expired url to its fallback url which is not cached",
+						Headers:     []string{echo.HeaderLocation, echo.HeaderCacheControl},
+					},
+					http.StatusGone: {Description: "The short url is disabled for abuse", Body: errors.View{}},
+				},
+			},
+			Handler:   h.RedirectURL,
+			RateLimit: RateLimitRedirect,
+			Guard:     true,
+		},
+		{
+			Spec: openapi.Spec{
+				Method:      http.MethodPost,
+				Path:        "/:url",
+				OperationID: "unlockURL",
+				Summary:     "Unlock protected url by password form",
+				Tags:        []string{"urls"},
+				Request:     endpoints.UnlockURLRequest{},
+				Responses: map[int]openapi.ResponseSpec{
+					http.StatusSeeOther: {
+						Description: "Set unlock cookie and redirect back to the short url",
+						Headers:     []string{echo.HeaderLocation, echo.HeaderCacheControl},
+					},
+					http.StatusUnauthorized:    {Description: "Password form again, the password is wrong"},
+					http.StatusTooManyRequests: {Description: "Password form again, the url or client is locked out for too many wrong passwords"},
+				},
+			},
+			Handler: h.UnlockURL,
+			Guard:   true,
+		},
+	})
+}
+
+// served remove admin routes when the handler has no authenticators,
+// admin must be authenticated and an anonymous request is never admin
+func (h *Handler) served(routes []Route) []Route {
+	if len(h.authenticators) != 0 {
+		return routes
+	}
+
+	served := routes[:0]
+	for _, r := range routes {
+		if r.Scope != auth.ScopeAdmin {
+			served = append(served, r)
+		}
+	}
+	return served
+}
+
+// OpenAPI generate OpenAPI document of all routes
+func (h *Handler) OpenAPI() *openapi.Document {
+	routes := h.Routes()
+
+	specs := make([]openapi.Spec, 0, len(routes))
+	for _, r := range routes {
+		if r.Scope != "" {
+			r.Spec.Security = []openapi.SecurityRequirement{
+				{securityAPIKey: {string(r.Scope)}},
+				{securityBearer: {string(r.Scope)}},
+			}
+		}
+		if _, ok := h.rateLimitPolicy(r); ok {
+			r.Spec.Responses[http.StatusTooManyRequests] = openapi.ResponseSpec{
+				Description: "Rate limit is exceeded",
+				Body:        errors.View{},
+				Headers: []string{
+					middleware.HeaderRateLimitLimit,
+					middleware.HeaderRateLimitRemaining,
+					middleware.HeaderRateLimitReset,
+					middleware.HeaderRateLimitPolicy,
+					echo.HeaderRetryAfter,
+				},
+			}
+		}
+		if _, ok := r.Spec.Responses[http.StatusTooManyRequests]; !ok && h.guarded(r) {
+			r.Spec.Responses[http.StatusTooManyRequests] = openapi.ResponseSpec{
+				Description: "Client is blocked for too many missing or expired short ids",
+				Body:        errors.View{},
+				Headers:     []string{echo.HeaderRetryAfter},
+			}
+		}
+		if h.idempotent(r) {
+			r.Spec.Responses[http.StatusConflict] = openapi.ResponseSpec{
+				Description: "Idempotency key is used by a different request or its first request is in flight",
+				Body:        errors.View{},
+			}
+		}
+		specs = append(specs, r.Spec)
+	}
+
+	var servers []openapi.Server
+	if host := viper.GetString("serverHost"); host != "" {
+		servers = append(servers, openapi.Server{URL: host})
+	}
+
+	doc := openapi.New(
+		openapi.Info{
+			Title:   "URL Shortener",
+			Version: "v1",
+		},
+		servers,
+		errors.View{},
+		specs...,
+	)
+
+	// api routes are served in the workspace of HeaderWorkspaceID or Host
+	for _, r := range routes {
+		if op := doc.Find(r.Method, r.Path); op != nil && r.Scope != "" {
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name:   HeaderWorkspaceID,
+				In:     "header",
+				Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+		if op := doc.Find(r.Method, r.Path); op != nil && h.idempotent(r) {
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name:   idempotency.HeaderKey,
+				In:     "header",
+				Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+	}
+
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		securityAPIKey: {
+			Type:        "apiKey",
+			Description: "API key issued by the apikey command, only required when auth is enabled",
+			Name:        auth.HeaderAPIKey,
+			In:          "header",
+		},
+		securityBearer: {
+			Type:        "http",
+			Description: "API key as bearer token, only required when auth is enabled",
+			Scheme:      "bearer",
+		},
+	}
+
+	return doc
+}
+
+// MakeRouter register all routes, OpenAPI document and Swagger UI into echo
+// when the handler has authenticators, route with scope require a credential with the scope
+// when the handler has workspaces, route is served in the workspace of request
+// when the handler has rate limit, route with an enabled policy is limited
+// when the handler has guard, client of guarded route is tarpitted or blocked by its misses
+// parameters and body of every route are validated by its OpenAPI operation
+func (h *Handler) MakeRouter(e *echo.Echo) {
+	doc := h.OpenAPI()
+
+	e.GET(OpenAPIPath, openapi.Handler(doc))
+	e.GET(SwaggerUIPath, openapi.UIHandler(doc.Info.Title, OpenAPIPath))
+
+	for _, r := range h.Routes() {
+		var middlewares []echo.MiddlewareFunc
+		if len(h.authenticators) != 0 && r.Scope != "" {
+			middlewares = append(middlewares,
+				auth.NewMiddleware(h.authenticators...),
+				auth.RequireScope(r.Scope),
+			)
+		}
+		if policy, ok := h.rateLimitPolicy(r); ok {
+			// after auth, so authenticated request is limited by its credential
+			middlewares = append(middlewares, middleware.NewRateLimitMiddleware(h.limiter, r.RateLimit, policy))
+		}
+		if h.guarded(r) {
+			middlewares = append(middlewares, middleware.NewGuardMiddleware(h.guard))
+		}
+		if op := doc.Find(r.Method, r.Path); op != nil && (op.RequestBody != nil || len(op.Parameters) != 0) {
+			middlewares = append(middlewares, middleware.NewValidationMiddleware(doc, op))
+		}
+		if h.workspaces != nil {
+			// public route like redirect is only resolved by Host
+			middlewares = append(middlewares, h.workspaceMiddleware(r.Scope != ""))
+		}
+		if h.idempotent(r) {
+			// last, so rejected request is not stored and the key of a workspace is not replayed in another one
+			middlewares = append(middlewares, middleware.NewIdempotencyMiddleware(h.idempotency, h.idempotencyWait, HeaderWorkspaceID))
+		}
+
+		e.Add(r.Method, r.Path, r.Handler, middlewares...)
+	}
+}
+
+// rateLimitPolicy return the enabled rate limit policy of route
+func (h *Handler) rateLimitPolicy(r Route) (ratelimit.Policy, bool) {
+	if h.limiter == nil || r.RateLimit == "" {
+		return ratelimit.Policy{}, false
+	}
+
+	policy, ok := h.policies[r.RateLimit]
+	return policy, ok && policy.Enabled()
+}
+
+// guarded return whether client of route is checked by the guard
+func (h *Handler) guarded(r Route) bool {
+	return h.guard != nil && r.Guard
+}
+
+// idempotent return whether response of route is replayed by Idempotency-Key
+func (h *Handler) idempotent(r Route) bool {
+	return h.idempotency != nil && r.Idempotent
+}
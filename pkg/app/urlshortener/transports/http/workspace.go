@@ -0,0 +1,45 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/app/urlshortener/entity"
+	"url-shortener/pkg/app/urlshortener/service"
+)
+
+// HeaderWorkspaceID select workspace of api request which is not sent to the workspace domain
+const HeaderWorkspaceID = "X-Workspace-ID"
+
+// workspaceMiddleware put workspace of request into request context,
+// the workspace is found by HeaderWorkspaceID when header is true, otherwise by Host
+func (h *Handler) workspaceMiddleware(header bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			var (
+				workspace *entity.Workspace
+				err       error
+			)
+			if id := c.Request().Header.Get(HeaderWorkspaceID); header && id != "" {
+				workspace, err = h.workspaces.FindWorkspace(ctx, id)
+			} else {
+				workspace, err = h.workspaces.ResolveHost(ctx, c.Request().Host)
+			}
+			if err != nil {
+				return err
+			}
+
+			if workspace == nil {
+				return next(c)
+			}
+
+			logger := log.Ctx(ctx).With().Str("workspace", workspace.ID).Logger()
+			ctx = logger.WithContext(service.NewWorkspaceContext(ctx, workspace))
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"url-shortener/pkg/db"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/utils"
+)
+
+const (
+	// APIKeyPrefix is prefix of every api key, so it is easy to find in leaked text
+	APIKeyPrefix = "usk_"
+
+	// MethodAPIKey is Principal.Method of api key
+	MethodAPIKey = "apikey"
+
+	apiKeyIDLength    = 12
+	apiKeySecretBytes = 32
+)
+
+// APIKey is stored api key, only the hash of secret is kept
+type APIKey struct {
+	ID        string
+	OwnerID   string
+	Name      string
+	Hash      string
+	Scopes    []Scope
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// APIKeyStore define api key datastore
+//
+//go:generate mockery --name APIKeyStore --with-expecter
+type APIKeyStore interface {
+	// CreateAPIKey store api key
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+
+	// FindAPIKey find api key by id, revoked key is also returned
+	FindAPIKey(ctx context.Context, id string) (*APIKey, error)
+
+	// ListAPIKeys list api keys of owner, empty owner means all owners
+	ListAPIKeys(ctx context.Context, ownerID string) ([]*APIKey, error)
+
+	// RevokeAPIKey mark api key revoked, revoked key can not authenticate
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+// IssueAPIKey create a new api key and store its hash
+// the returned plaintext key is the only chance to see the secret
+func IssueAPIKey(ctx context.Context, store APIKeyStore, ownerID string, name string, scopes []Scope) (plaintext string, key *APIKey, err error) {
+	if ownerID == "" {
+		return "", nil, errors.Wrap(errors.ErrInvalidInput, "owner id is empty")
+	}
+
+	for _, scope := range scopes {
+		if !scope.valid() {
+			return "", nil, errors.Wrapf(errors.ErrInvalidInput, "unknown scope %v", scope)
+		}
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, errors.Wrapf(errors.ErrInternal, "failed to generate api key err = %v", err)
+	}
+
+	key = &APIKey{
+		ID:        utils.RandStringBytesMaskImprSrc(apiKeyIDLength),
+		OwnerID:   ownerID,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	plaintext = APIKeyPrefix + key.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	key.Hash = hashAPIKey(plaintext)
+
+	if err := store.CreateAPIKey(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, key, nil
+}
+
+// hashAPIKey hash whole api key, the secret is random enough so a fast hash is fine
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseAPIKeyID return key id of api key, false means credential is not an api key
+func parseAPIKeyID(credential string) (string, bool) {
+	if !strings.HasPrefix(credential, APIKeyPrefix) {
+		return "", false
+	}
+
+	id, _, ok := strings.Cut(strings.TrimPrefix(credential, APIKeyPrefix), "_")
+	if !ok || len(id) != apiKeyIDLength {
+		return "", false
+	}
+
+	return id, true
+}
+
+var _ Authenticator = &APIKeyAuthenticator{}
+
+// APIKeyAuthenticator authenticate api key
+type APIKeyAuthenticator struct {
+	store APIKeyStore
+}
+
+// NewAPIKeyAuthenticator APIKeyAuthenticator constructor
+func NewAPIKeyAuthenticator(store APIKeyStore) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{store: store}
+}
+
+// Authenticate method is implementation for Authenticator
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	id, ok := parseAPIKeyID(credential)
+	if !ok {
+		return nil, nil
+	}
+
+	key, err := a.store.FindAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, errors.ErrResourceNotFound) {
+			return nil, errors.Wrapf(errors.ErrUnauthorized, "api key id = %v not found", id)
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKey(credential))) != 1 {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "api key id = %v secret mismatch", id)
+	}
+
+	if key.RevokedAt != nil {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "api key id = %v is revoked", id)
+	}
+
+	return &Principal{
+		ID:     key.OwnerID,
+		KeyID:  key.ID,
+		Method: MethodAPIKey,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+// apiKeyRow is api_keys table row
+type apiKeyRow struct {
+	ID        string `gorm:"column:id"`
+	OwnerID   string `gorm:"column:owner_id"`
+	Name      string `gorm:"column:name"`
+	Hash      string `gorm:"column:hash"`
+	Scopes    string `gorm:"column:scopes"` // Scopes is comma separated
+	CreatedAt int64  `gorm:"column:created_at"`
+	RevokedAt int64  `gorm:"column:revoked_at"` // RevokedAt zero means not revoked
+}
+
+func (row *apiKeyRow) toAPIKey() *APIKey {
+	key := &APIKey{
+		ID:        row.ID,
+		OwnerID:   row.OwnerID,
+		Name:      row.Name,
+		Hash:      row.Hash,
+		CreatedAt: time.UnixMilli(row.CreatedAt).UTC(),
+	}
+
+	for _, scope := range strings.Split(row.Scopes, ",") {
+		if scope != "" {
+			key.Scopes = append(key.Scopes, Scope(scope))
+		}
+	}
+
+	if row.RevokedAt != 0 {
+		t := time.UnixMilli(row.RevokedAt).UTC()
+		key.RevokedAt = &t
+	}
+
+	return key
+}
+
+var _ APIKeyStore = &APIKeyStoreImpl{}
+
+// APIKeyStoreImpl is implementation for APIKeyStore
+type APIKeyStoreImpl struct {
+	readDB  *gorm.DB
+	writeDB *gorm.DB
+}
+
+// NewAPIKeyStore APIKeyStore constructor
+func NewAPIKeyStore(conn db.Connection) *APIKeyStoreImpl {
+	return &APIKeyStoreImpl{
+		readDB:  conn.ReadDB(),
+		writeDB: conn.WriteDB(),
+	}
+}
+
+// CreateAPIKey method is implementation for APIKeyStore
+func (store *APIKeyStoreImpl) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	const (
+		sql = `INSERT INTO "api_keys" ("id","owner_id","name","hash","scopes","created_at") VALUES (?,?,?,?,?,?)`
+	)
+
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	err := store.writeDB.WithContext(ctx).Exec(
+		sql,
+		key.ID,
+		key.OwnerID,
+		key.Name,
+		key.Hash,
+		strings.Join(scopes, ","),
+		key.CreatedAt.UnixMilli(),
+	).Error
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to store api key id = %v err = %v", key.ID, err)
+	}
+
+	return nil
+}
+
+// FindAPIKey method is implementation for APIKeyStore
+func (store *APIKeyStoreImpl) FindAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	const (
+		sql = `SELECT id, owner_id, name, hash, scopes, created_at, revoked_at FROM api_keys WHERE id = ? LIMIT 1`
+	)
+
+	rows := make([]*apiKeyRow, 0, 1)
+	if err := store.readDB.WithContext(ctx).Raw(sql, id).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to find api key id = %v err = %v", id, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.Wrapf(errors.ErrResourceNotFound, "api key id = %v not found", id)
+	}
+
+	return rows[0].toAPIKey(), nil
+}
+
+// ListAPIKeys method is implementation for APIKeyStore
+func (store *APIKeyStoreImpl) ListAPIKeys(ctx context.Context, ownerID string) ([]*APIKey, error) {
+	const (
+		sql = `SELECT id, owner_id, name, hash, scopes, created_at, revoked_at
+			FROM api_keys
+			WHERE ? = '' OR owner_id = ?
+			ORDER BY created_at`
+	)
+
+	rows := make([]*apiKeyRow, 0)
+	if err := store.readDB.WithContext(ctx).Raw(sql, ownerID, ownerID).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list api keys of owner = %v err = %v", ownerID, err)
+	}
+
+	keys := make([]*APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.toAPIKey())
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey method is implementation for APIKeyStore
+func (store *APIKeyStoreImpl) RevokeAPIKey(ctx context.Context, id string) error {
+	const (
+		sql = `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at = 0`
+	)
+
+	result := store.writeDB.WithContext(ctx).Exec(sql, time.Now().UnixMilli(), id)
+	if result.Error != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to revoke api key id = %v err = %v", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.Wrapf(errors.ErrResourceNotFound, "api key id = %v not found or already revoked", id)
+	}
+
+	return nil
+}
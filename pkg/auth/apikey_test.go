@@ -0,0 +1,132 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/auth/mocks"
+	"url-shortener/pkg/errors"
+)
+
+func TestIssueAPIKey(t *testing.T) {
+	var stored *auth.APIKey
+
+	store := mocks.NewAPIKeyStore(t)
+	store.EXPECT().
+		CreateAPIKey(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx context.Context, key *auth.APIKey) error {
+			stored = key
+			return nil
+		})
+
+	plaintext, key, err := auth.IssueAPIKey(context.Background(), store, "team-a", "ci", []auth.Scope{auth.ScopeCreate})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, auth.APIKeyPrefix+key.ID+"_"))
+	assert.Equal(t, key, stored)
+	assert.Equal(t, "team-a", key.OwnerID)
+	assert.NotContains(t, key.Hash, strings.TrimPrefix(plaintext, auth.APIKeyPrefix+key.ID+"_"))
+
+	_, _, err = auth.IssueAPIKey(context.Background(), store, "", "ci", []auth.Scope{auth.ScopeCreate})
+	assert.True(t, errors.Is(err, errors.ErrInvalidInput))
+}
+
+func TestAPIKeyAuthenticator_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	var key *auth.APIKey
+	issuer := mocks.NewAPIKeyStore(t)
+	issuer.EXPECT().
+		CreateAPIKey(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx context.Context, k *auth.APIKey) error {
+			key = k
+			return nil
+		})
+
+	plaintext, _, err := auth.IssueAPIKey(ctx, issuer, "team-a", "ci", []auth.Scope{auth.ScopeCreate, auth.ScopeRead})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	revoked := *key
+	revokedAt := time.Now()
+	revoked.RevokedAt = &revokedAt
+
+	tests := []struct {
+		name       string
+		credential string
+		store      func() *mocks.APIKeyStore
+		principal  *auth.Principal
+		err        error
+	}{
+		{
+			name:       "Success",
+			credential: plaintext,
+			store: func() *mocks.APIKeyStore {
+				store := mocks.NewAPIKeyStore(t)
+				store.EXPECT().FindAPIKey(mock.Anything, key.ID).Return(key, nil)
+				return store
+			},
+			principal: &auth.Principal{
+				ID:     "team-a",
+				KeyID:  key.ID,
+				Method: auth.MethodAPIKey,
+				Scopes: []auth.Scope{auth.ScopeCreate, auth.ScopeRead},
+			},
+		},
+		{
+			name:       "NotAPIKey",
+			credential: "eyJhbGciOiJSUzI1NiJ9.e30.sig",
+			store: func() *mocks.APIKeyStore {
+				return mocks.NewAPIKeyStore(t)
+			},
+		},
+		{
+			name:       "SecretMismatch",
+			credential: plaintext[:len(plaintext)-1] + "x",
+			store: func() *mocks.APIKeyStore {
+				store := mocks.NewAPIKeyStore(t)
+				store.EXPECT().FindAPIKey(mock.Anything, key.ID).Return(key, nil)
+				return store
+			},
+			err: errors.ErrUnauthorized,
+		},
+		{
+			name:       "Revoked",
+			credential: plaintext,
+			store: func() *mocks.APIKeyStore {
+				store := mocks.NewAPIKeyStore(t)
+				store.EXPECT().FindAPIKey(mock.Anything, key.ID).Return(&revoked, nil)
+				return store
+			},
+			err: errors.ErrUnauthorized,
+		},
+		{
+			name:       "NotFound",
+			credential: plaintext,
+			store: func() *mocks.APIKeyStore {
+				store := mocks.NewAPIKeyStore(t)
+				store.EXPECT().FindAPIKey(mock.Anything, key.ID).Return(nil, errors.ErrResourceNotFound)
+				return store
+			},
+			err: errors.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := auth.NewAPIKeyAuthenticator(tt.store()).Authenticate(ctx, tt.credential)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.principal, p)
+		})
+	}
+}
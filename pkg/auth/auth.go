@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/errors"
+)
+
+// Config auth config
+type Config struct {
+	// Enabled require credential on routes which need scope, disabled keep all routes anonymous
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// Scope define what a principal can do
+type Scope string
+
+const (
+	ScopeCreate Scope = "create" // ScopeCreate create short urls
+	ScopeRead   Scope = "read"   // ScopeRead read short url metadata and list owned urls
+	ScopeManage Scope = "manage" // ScopeManage update and delete owned urls
+	ScopeAdmin  Scope = "admin"  // ScopeAdmin all scopes on every url
+)
+
+// Scopes is all known scopes
+var Scopes = []Scope{ScopeCreate, ScopeRead, ScopeManage, ScopeAdmin}
+
+// ParseScopes parse comma separated scopes
+func ParseScopes(s string) ([]Scope, error) {
+	var scopes []Scope
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		scope := Scope(name)
+		if !scope.valid() {
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "unknown scope %v", name)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	if len(scopes) == 0 {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "scopes is empty")
+	}
+
+	return scopes, nil
+}
+
+func (s Scope) valid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is the authenticated caller
+type Principal struct {
+	// ID is the owner id of urls created by the principal
+	ID string
+
+	// KeyID is the credential id, e.g. api key id or token id
+	KeyID string
+
+	// Method is how the principal is authenticated, e.g. apikey
+	Method string
+
+	Scopes []Scope
+}
+
+// HasScope check principal has scope, admin has all scopes
+func (p *Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin check principal has admin scope
+func (p *Principal) IsAdmin() bool {
+	return p.HasScope(ScopeAdmin)
+}
+
+type principalKey struct{}
+
+// NewContext return context with principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext return principal of context, false means anonymous
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Authenticator verify credential
+//
+//go:generate mockery --name Authenticator --with-expecter
+type Authenticator interface {
+	// Authenticate return principal of credential
+	// nil principal and nil error means the credential is not this authenticator format
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// Authenticate try authenticators in order, the first one accept the credential win
+func Authenticate(ctx context.Context, authenticators []Authenticator, credential string) (*Principal, error) {
+	for _, a := range authenticators {
+		p, err := a.Authenticate(ctx, credential)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+	}
+
+	return nil, errors.Wrap(errors.ErrUnauthorized, "credential format is not supported")
+}
+
+const (
+	// HeaderAPIKey is header of api key, Authorization bearer is also accepted
+	HeaderAPIKey = "X-API-Key"
+
+	bearerPrefix = "Bearer "
+)
+
+// credential return the credential of request, empty means anonymous
+func credential(c echo.Context) string {
+	if key := c.Request().Header.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+
+	authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(authorization) > len(bearerPrefix) && strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(authorization[len(bearerPrefix):])
+	}
+
+	return ""
+}
+
+// NewMiddleware authenticate request credential and put principal into request context
+// request without credential is anonymous, RequireScope decide whether the route accept it
+func NewMiddleware(authenticators ...Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cred := credential(c)
+			if cred == "" {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+
+			p, err := Authenticate(ctx, authenticators, cred)
+			if err != nil {
+				return err
+			}
+
+			logger := log.Ctx(ctx).With().Str("principal", p.ID).Str("key_id", p.KeyID).Logger()
+			ctx = logger.WithContext(NewContext(ctx, p))
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// RequireScope reject anonymous request and principal without scope
+func RequireScope(scope Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := checkScope(c.Request().Context(), scope); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+func checkScope(ctx context.Context, scope Scope) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return errors.Wrap(errors.ErrUnauthorized, "credential is required")
+	}
+
+	if !p.HasScope(scope) {
+		return errors.Wrapf(errors.ErrForbidden, "principal = %v has no %v scope", p.ID, scope)
+	}
+
+	return nil
+}
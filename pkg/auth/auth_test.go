@@ -0,0 +1,175 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/auth/mocks"
+	"url-shortener/pkg/errors"
+	ph "url-shortener/pkg/http"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := auth.ParseScopes("create, read,,manage")
+	assert.NoError(t, err)
+	assert.Equal(t, []auth.Scope{auth.ScopeCreate, auth.ScopeRead, auth.ScopeManage}, scopes)
+
+	_, err = auth.ParseScopes("create,delete")
+	assert.True(t, errors.Is(err, errors.ErrInvalidInput))
+
+	_, err = auth.ParseScopes("")
+	assert.True(t, errors.Is(err, errors.ErrInvalidInput))
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	p := &auth.Principal{Scopes: []auth.Scope{auth.ScopeCreate}}
+	assert.True(t, p.HasScope(auth.ScopeCreate))
+	assert.False(t, p.HasScope(auth.ScopeManage))
+	assert.False(t, p.IsAdmin())
+
+	admin := &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}}
+	assert.True(t, admin.HasScope(auth.ScopeManage))
+	assert.True(t, admin.IsAdmin())
+}
+
+func TestMiddleware(t *testing.T) {
+	principal := &auth.Principal{ID: "team-a", KeyID: "Xa8LmQ2pRt0z", Scopes: []auth.Scope{auth.ScopeRead}}
+
+	tests := []struct {
+		name          string
+		header        string
+		value         string
+		authenticator func() *mocks.Authenticator
+		scope         auth.Scope
+		status        int
+	}{
+		{
+			name:   "Anonymous",
+			scope:  auth.ScopeRead,
+			status: http.StatusUnauthorized,
+			authenticator: func() *mocks.Authenticator {
+				return mocks.NewAuthenticator(t)
+			},
+		},
+		{
+			name:   "APIKeyHeader",
+			header: auth.HeaderAPIKey,
+			value:  "usk_key",
+			scope:  auth.ScopeRead,
+			status: http.StatusOK,
+			authenticator: func() *mocks.Authenticator {
+				a := mocks.NewAuthenticator(t)
+				a.EXPECT().Authenticate(mock.Anything, "usk_key").Return(principal, nil)
+				return a
+			},
+		},
+		{
+			name:   "Bearer",
+			header: echo.HeaderAuthorization,
+			value:  "bearer usk_key",
+			scope:  auth.ScopeRead,
+			status: http.StatusOK,
+			authenticator: func() *mocks.Authenticator {
+				a := mocks.NewAuthenticator(t)
+				a.EXPECT().Authenticate(mock.Anything, "usk_key").Return(principal, nil)
+				return a
+			},
+		},
+		{
+			name:   "MissingScope",
+			header: auth.HeaderAPIKey,
+			value:  "usk_key",
+			scope:  auth.ScopeManage,
+			status: http.StatusForbidden,
+			authenticator: func() *mocks.Authenticator {
+				a := mocks.NewAuthenticator(t)
+				a.EXPECT().Authenticate(mock.Anything, "usk_key").Return(principal, nil)
+				return a
+			},
+		},
+		{
+			name:   "UnsupportedCredential",
+			header: auth.HeaderAPIKey,
+			value:  "unknown",
+			scope:  auth.ScopeRead,
+			status: http.StatusUnauthorized,
+			authenticator: func() *mocks.Authenticator {
+				a := mocks.NewAuthenticator(t)
+				a.EXPECT().Authenticate(mock.Anything, "unknown").Return(nil, nil)
+				return a
+			},
+		},
+		{
+			name:   "RevokedKey",
+			header: auth.HeaderAPIKey,
+			value:  "usk_key",
+			scope:  auth.ScopeRead,
+			status: http.StatusUnauthorized,
+			authenticator: func() *mocks.Authenticator {
+				a := mocks.NewAuthenticator(t)
+				a.EXPECT().Authenticate(mock.Anything, "usk_key").Return(nil, errors.Wrap(errors.ErrUnauthorized, "api key is revoked"))
+				return a
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			e.GET("/", func(c echo.Context) error {
+				p, ok := auth.FromContext(c.Request().Context())
+				assert.True(t, ok)
+				assert.Equal(t, principal.ID, p.ID)
+				return c.NoContent(http.StatusOK)
+			}, auth.NewMiddleware(tt.authenticator()), auth.RequireScope(tt.scope))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/urlshortener.v1.URLShortener/ShortURL"
+
+	a := mocks.NewAuthenticator(t)
+	a.EXPECT().Authenticate(mock.Anything, "usk_key").Return(&auth.Principal{
+		ID:     "team-a",
+		Scopes: []auth.Scope{auth.ScopeCreate},
+	}, nil)
+
+	interceptor := auth.UnaryServerInterceptor(map[string]auth.Scope{method: auth.ScopeCreate}, a)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p, ok := auth.FromContext(ctx)
+		if ok {
+			return p.ID, nil
+		}
+		return "", nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "usk_key"))
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", resp)
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	assert.True(t, errors.Is(err, errors.ErrUnauthorized))
+
+	resp, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/urlshortener.v1.URLShortener/Resolve"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "", resp)
+}
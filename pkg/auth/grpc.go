@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcCredential return the credential of incoming metadata, empty means anonymous
+func grpcCredential(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if keys := md.Get(strings.ToLower(HeaderAPIKey)); len(keys) != 0 && keys[0] != "" {
+		return keys[0]
+	}
+
+	if values := md.Get("authorization"); len(values) != 0 {
+		authorization := values[0]
+		if len(authorization) > len(bearerPrefix) && strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(authorization[len(bearerPrefix):])
+		}
+	}
+
+	return ""
+}
+
+// UnaryServerInterceptor authenticate x-api-key or authorization metadata and put principal into context
+// methodScopes is the scope required by full method name, method not in it is public
+func UnaryServerInterceptor(methodScopes map[string]Scope, authenticators ...Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		scope, ok := methodScopes[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if cred := grpcCredential(ctx); cred != "" {
+			p, err := Authenticate(ctx, authenticators, cred)
+			if err != nil {
+				return nil, err
+			}
+
+			logger := log.Ctx(ctx).With().Str("principal", p.ID).Str("key_id", p.KeyID).Logger()
+			ctx = logger.WithContext(NewContext(ctx, p))
+		}
+
+		if err := checkScope(ctx, scope); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,234 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	auth "url-shortener/pkg/auth"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyStore is an autogenerated mock type for the APIKeyStore type
+type APIKeyStore struct {
+	mock.Mock
+}
+
+type APIKeyStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *APIKeyStore) EXPECT() *APIKeyStore_Expecter {
+	return &APIKeyStore_Expecter{mock: &_m.Mock}
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, key
+func (_m *APIKeyStore) CreateAPIKey(ctx context.Context, key *auth.APIKey) error {
+	ret := _m.Called(ctx, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *auth.APIKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyStore_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type APIKeyStore_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key *auth.APIKey
+func (_e *APIKeyStore_Expecter) CreateAPIKey(ctx interface{}, key interface{}) *APIKeyStore_CreateAPIKey_Call {
+	return &APIKeyStore_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, key)}
+}
+
+func (_c *APIKeyStore_CreateAPIKey_Call) Run(run func(ctx context.Context, key *auth.APIKey)) *APIKeyStore_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*auth.APIKey))
+	})
+	return _c
+}
+
+func (_c *APIKeyStore_CreateAPIKey_Call) Return(_a0 error) *APIKeyStore_CreateAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyStore_CreateAPIKey_Call) RunAndReturn(run func(context.Context, *auth.APIKey) error) *APIKeyStore_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindAPIKey provides a mock function with given fields: ctx, id
+func (_m *APIKeyStore) FindAPIKey(ctx context.Context, id string) (*auth.APIKey, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *auth.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*auth.APIKey, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *auth.APIKey); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyStore_FindAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAPIKey'
+type APIKeyStore_FindAPIKey_Call struct {
+	*mock.Call
+}
+
+// FindAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *APIKeyStore_Expecter) FindAPIKey(ctx interface{}, id interface{}) *APIKeyStore_FindAPIKey_Call {
+	return &APIKeyStore_FindAPIKey_Call{Call: _e.mock.On("FindAPIKey", ctx, id)}
+}
+
+func (_c *APIKeyStore_FindAPIKey_Call) Run(run func(ctx context.Context, id string)) *APIKeyStore_FindAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyStore_FindAPIKey_Call) Return(_a0 *auth.APIKey, _a1 error) *APIKeyStore_FindAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyStore_FindAPIKey_Call) RunAndReturn(run func(context.Context, string) (*auth.APIKey, error)) *APIKeyStore_FindAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx, ownerID
+func (_m *APIKeyStore) ListAPIKeys(ctx context.Context, ownerID string) ([]*auth.APIKey, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []*auth.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*auth.APIKey, error)); ok {
+		return rf(ctx, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*auth.APIKey); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*auth.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKeyStore_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type APIKeyStore_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ownerID string
+func (_e *APIKeyStore_Expecter) ListAPIKeys(ctx interface{}, ownerID interface{}) *APIKeyStore_ListAPIKeys_Call {
+	return &APIKeyStore_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx, ownerID)}
+}
+
+func (_c *APIKeyStore_ListAPIKeys_Call) Run(run func(ctx context.Context, ownerID string)) *APIKeyStore_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyStore_ListAPIKeys_Call) Return(_a0 []*auth.APIKey, _a1 error) *APIKeyStore_ListAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKeyStore_ListAPIKeys_Call) RunAndReturn(run func(context.Context, string) ([]*auth.APIKey, error)) *APIKeyStore_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id
+func (_m *APIKeyStore) RevokeAPIKey(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKeyStore_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type APIKeyStore_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *APIKeyStore_Expecter) RevokeAPIKey(ctx interface{}, id interface{}) *APIKeyStore_RevokeAPIKey_Call {
+	return &APIKeyStore_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id)}
+}
+
+func (_c *APIKeyStore_RevokeAPIKey_Call) Run(run func(ctx context.Context, id string)) *APIKeyStore_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKeyStore_RevokeAPIKey_Call) Return(_a0 error) *APIKeyStore_RevokeAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKeyStore_RevokeAPIKey_Call) RunAndReturn(run func(context.Context, string) error) *APIKeyStore_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewAPIKeyStore interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAPIKeyStore creates a new instance of APIKeyStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAPIKeyStore(t mockConstructorTestingTNewAPIKeyStore) *APIKeyStore {
+	mock := &APIKeyStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,93 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	auth "url-shortener/pkg/auth"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Authenticator is an autogenerated mock type for the Authenticator type
+type Authenticator struct {
+	mock.Mock
+}
+
+type Authenticator_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Authenticator) EXPECT() *Authenticator_Expecter {
+	return &Authenticator_Expecter{mock: &_m.Mock}
+}
+
+// Authenticate provides a mock function with given fields: ctx, credential
+func (_m *Authenticator) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	ret := _m.Called(ctx, credential)
+
+	var r0 *auth.Principal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*auth.Principal, error)); ok {
+		return rf(ctx, credential)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *auth.Principal); ok {
+		r0 = rf(ctx, credential)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Principal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, credential)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Authenticator_Authenticate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Authenticate'
+type Authenticator_Authenticate_Call struct {
+	*mock.Call
+}
+
+// Authenticate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - credential string
+func (_e *Authenticator_Expecter) Authenticate(ctx interface{}, credential interface{}) *Authenticator_Authenticate_Call {
+	return &Authenticator_Authenticate_Call{Call: _e.mock.On("Authenticate", ctx, credential)}
+}
+
+func (_c *Authenticator_Authenticate_Call) Run(run func(ctx context.Context, credential string)) *Authenticator_Authenticate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Authenticator_Authenticate_Call) Return(_a0 *auth.Principal, _a1 error) *Authenticator_Authenticate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Authenticator_Authenticate_Call) RunAndReturn(run func(context.Context, string) (*auth.Principal, error)) *Authenticator_Authenticate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewAuthenticator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAuthenticator creates a new instance of Authenticator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAuthenticator(t mockConstructorTestingTNewAuthenticator) *Authenticator {
+	mock := &Authenticator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
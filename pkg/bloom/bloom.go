@@ -8,5 +8,12 @@ import (
 type Filter interface {
 	GetFilterNamespace() string
 	Add(ctx context.Context, item interface{})
+	AddMany(ctx context.Context, items []string)
 	Exist(ctx context.Context, item interface{}) bool
 }
+
+// Rebuilder define replace filter with a new one
+type Rebuilder interface {
+	// Rebuild create a new filter from items, next return items batch by batch until empty
+	Rebuild(ctx context.Context, next func(ctx context.Context) ([]string, error)) error
+}
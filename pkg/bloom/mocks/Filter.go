@@ -55,6 +55,40 @@ func (_c *Filter_Add_Call) RunAndReturn(run func(context.Context, interface{}))
 	return _c
 }
 
+// AddMany provides a mock function with given fields: ctx, items
+func (_m *Filter) AddMany(ctx context.Context, items []string) {
+	_m.Called(ctx, items)
+}
+
+// Filter_AddMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddMany'
+type Filter_AddMany_Call struct {
+	*mock.Call
+}
+
+// AddMany is a helper method to define mock.On call
+//   - ctx context.Context
+//   - items []string
+func (_e *Filter_Expecter) AddMany(ctx interface{}, items interface{}) *Filter_AddMany_Call {
+	return &Filter_AddMany_Call{Call: _e.mock.On("AddMany", ctx, items)}
+}
+
+func (_c *Filter_AddMany_Call) Run(run func(ctx context.Context, items []string)) *Filter_AddMany_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *Filter_AddMany_Call) Return() *Filter_AddMany_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Filter_AddMany_Call) RunAndReturn(run func(context.Context, []string)) *Filter_AddMany_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Exist provides a mock function with given fields: ctx, item
 func (_m *Filter) Exist(ctx context.Context, item interface{}) bool {
 	ret := _m.Called(ctx, item)
@@ -2,6 +2,7 @@ package bloom
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
@@ -13,9 +14,17 @@ type Config struct {
 	DefaultCapacity int64
 }
 
+// legacyNamespace is the key which the filter was stored before it kept its namespace
+const legacyNamespace = ""
+
 type RedisImpl struct {
 	rds       *redis.Client
 	namespace string
+	config    *Config
+
+	// legacy is 1 while the legacy filter exists, it is read when namespace miss and written with namespace,
+	// so instances of both versions see all items until Rebuild fill namespace and remove the legacy filter
+	legacy int32
 }
 
 func NewRedisFilter(namespace string, rds *redis.Client) Filter {
@@ -50,9 +59,28 @@ func NewRedisFilter(namespace string, rds *redis.Client) Filter {
 			Msgf("check redis bloom info")
 	}
 
-	return &RedisImpl{
-		rds: rds,
+	r := &RedisImpl{
+		rds:       rds,
+		namespace: cfg.Namespace,
+		config:    cfg,
+	}
+
+	if cfg.Namespace != legacyNamespace {
+		exists, err := rds.Exists(ctx, legacyNamespace).Result()
+		if err != nil {
+			log.Panic().
+				Err(err).
+				Msgf("failed to check legacy redis bloom filter")
+		}
+
+		if exists == 1 {
+			log.Warn().
+				Msgf("legacy redis bloom filter is used with %v until rebuild-bloom", cfg.Namespace)
+			r.legacy = 1
+		}
 	}
+
+	return r
 }
 
 func (r *RedisImpl) Add(ctx context.Context, item interface{}) {
@@ -62,6 +90,50 @@ func (r *RedisImpl) Add(ctx context.Context, item interface{}) {
 			Err(err).
 			Msgf("failed to add %v into %v bloom filter", item, r.namespace)
 	}
+
+	if r.usingLegacy() {
+		r.checkLegacy(r.rds.Do(ctx, "BF.INSERT", legacyNamespace, "NOCREATE", "ITEMS", item).Err())
+	}
+}
+
+// maddBatchSize is the max items of one BF.MADD command
+const maddBatchSize = 1000
+
+// AddMany add items by BF.MADD, commands of every batch are sent in one pipeline
+func (r *RedisImpl) AddMany(ctx context.Context, items []string) {
+	if err := r.madd(ctx, r.namespace, "BF.MADD", items); err != nil {
+		log.Error().
+			Err(err).
+			Msgf("failed to add %v items into %v bloom filter", len(items), r.namespace)
+	}
+
+	if r.usingLegacy() {
+		r.checkLegacy(r.madd(ctx, legacyNamespace, "BF.INSERT", items))
+	}
+}
+
+// madd add items into filter of key by BF.MADD, or by BF.INSERT which never create the filter
+func (r *RedisImpl) madd(ctx context.Context, key string, command string, items []string) error {
+	_, err := r.rds.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for begin := 0; begin < len(items); begin += maddBatchSize {
+			end := begin + maddBatchSize
+			if end > len(items) {
+				end = len(items)
+			}
+
+			args := make([]interface{}, 0, end-begin+4)
+			args = append(args, command, key)
+			if command == "BF.INSERT" {
+				args = append(args, "NOCREATE", "ITEMS")
+			}
+			for _, item := range items[begin:end] {
+				args = append(args, item)
+			}
+			pipe.Do(ctx, args...)
+		}
+		return nil
+	})
+	return err
 }
 
 func (r *RedisImpl) Exist(ctx context.Context, item interface{}) bool {
@@ -74,9 +146,81 @@ func (r *RedisImpl) Exist(ctx context.Context, item interface{}) bool {
 		return false
 	}
 
+	if result == 1 || !r.usingLegacy() {
+		return result == 1
+	}
+
+	result, err = r.rds.Do(ctx, "BF.EXISTS", legacyNamespace, item).Int()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msgf("failed to check %v in legacy bloom filter", item)
+
+		return false
+	}
+
 	return result == 1
 }
 
+// usingLegacy return whether the legacy filter is read and written
+func (r *RedisImpl) usingLegacy() bool {
+	return atomic.LoadInt32(&r.legacy) == 1
+}
+
+// checkLegacy stop using the legacy filter when it is removed by Rebuild of another instance
+func (r *RedisImpl) checkLegacy(err error) {
+	if err != nil && err.Error() == "ERR not found" {
+		atomic.StoreInt32(&r.legacy, 0)
+	}
+}
+
 func (r *RedisImpl) GetFilterNamespace() string {
 	return r.namespace
 }
+
+// Rebuild fill items into a temporary filter then atomic replace the current one, the legacy filter is removed after it.
+// Items added by Add during rebuild are lost, so run it when traffic is low.
+func (r *RedisImpl) Rebuild(ctx context.Context, next func(ctx context.Context) ([]string, error)) error {
+	tmp := r.namespace + ":rebuild"
+
+	if err := r.rds.Del(ctx, tmp).Err(); err != nil {
+		return err
+	}
+
+	err := r.rds.Do(ctx, "BF.RESERVE", tmp, r.config.ErrorRate, r.config.DefaultCapacity).Err()
+	if err != nil {
+		return err
+	}
+
+	for {
+		items, err := next(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			break
+		}
+
+		args := make([]interface{}, 0, len(items)+2)
+		args = append(args, "BF.MADD", tmp)
+		for _, item := range items {
+			args = append(args, item)
+		}
+
+		if err := r.rds.Do(ctx, args...).Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.rds.Rename(ctx, tmp, r.namespace).Err(); err != nil {
+		return err
+	}
+
+	if r.namespace == legacyNamespace {
+		return nil
+	}
+
+	atomic.StoreInt32(&r.legacy, 0)
+	return r.rds.Del(ctx, legacyNamespace).Err()
+}
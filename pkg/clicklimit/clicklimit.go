@@ -0,0 +1,19 @@
+package clicklimit
+
+import (
+	"context"
+	"time"
+)
+
+// Counter count down the clicks left of click limited urls, it is a fast gate in front of the persisted clicks
+//
+//go:generate mockery --name Counter --with-expecter
+type Counter interface {
+	// Take consume one click of key, ok is false when key has no click left and nothing is consumed.
+	// left seed the counter when key is not counted yet, e.g. max clicks minus persisted clicks,
+	// the counter is dropped after ttl, e.g. when the url expires
+	Take(ctx context.Context, key string, left int64, ttl time.Duration) (ok bool, err error)
+
+	// Exhaust mark key has no click left, e.g. the persisted clicks reach max clicks before the counter
+	Exhaust(ctx context.Context, key string, ttl time.Duration) error
+}
@@ -0,0 +1,137 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Counter is an autogenerated mock type for the Counter type
+type Counter struct {
+	mock.Mock
+}
+
+type Counter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Counter) EXPECT() *Counter_Expecter {
+	return &Counter_Expecter{mock: &_m.Mock}
+}
+
+// Exhaust provides a mock function with given fields: ctx, key, ttl
+func (_m *Counter) Exhaust(ctx context.Context, key string, ttl time.Duration) error {
+	ret := _m.Called(ctx, key, ttl)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) error); ok {
+		r0 = rf(ctx, key, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Counter_Exhaust_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Exhaust'
+type Counter_Exhaust_Call struct {
+	*mock.Call
+}
+
+// Exhaust is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - ttl time.Duration
+func (_e *Counter_Expecter) Exhaust(ctx interface{}, key interface{}, ttl interface{}) *Counter_Exhaust_Call {
+	return &Counter_Exhaust_Call{Call: _e.mock.On("Exhaust", ctx, key, ttl)}
+}
+
+func (_c *Counter_Exhaust_Call) Run(run func(ctx context.Context, key string, ttl time.Duration)) *Counter_Exhaust_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *Counter_Exhaust_Call) Return(_a0 error) *Counter_Exhaust_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Counter_Exhaust_Call) RunAndReturn(run func(context.Context, string, time.Duration) error) *Counter_Exhaust_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Take provides a mock function with given fields: ctx, key, left, ttl
+func (_m *Counter) Take(ctx context.Context, key string, left int64, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, key, left, ttl)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, time.Duration) (bool, error)); ok {
+		return rf(ctx, key, left, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, time.Duration) bool); ok {
+		r0 = rf(ctx, key, left, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, time.Duration) error); ok {
+		r1 = rf(ctx, key, left, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Counter_Take_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Take'
+type Counter_Take_Call struct {
+	*mock.Call
+}
+
+// Take is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - left int64
+//   - ttl time.Duration
+func (_e *Counter_Expecter) Take(ctx interface{}, key interface{}, left interface{}, ttl interface{}) *Counter_Take_Call {
+	return &Counter_Take_Call{Call: _e.mock.On("Take", ctx, key, left, ttl)}
+}
+
+func (_c *Counter_Take_Call) Run(run func(ctx context.Context, key string, left int64, ttl time.Duration)) *Counter_Take_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *Counter_Take_Call) Return(ok bool, err error) *Counter_Take_Call {
+	_c.Call.Return(ok, err)
+	return _c
+}
+
+func (_c *Counter_Take_Call) RunAndReturn(run func(context.Context, string, int64, time.Duration) (bool, error)) *Counter_Take_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewCounter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewCounter creates a new instance of Counter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewCounter(t mockConstructorTestingTNewCounter) *Counter {
+	mock := &Counter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
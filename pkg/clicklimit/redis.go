@@ -0,0 +1,70 @@
+package clicklimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"url-shortener/pkg/errors"
+)
+
+// keyPrefix is prefix of every click counter redis key
+const keyPrefix = "clicks:"
+
+// minTTL is the shortest ttl of a counter, redis reject zero ttl
+const minTTL = time.Second
+
+// takeScript seed the counter of key when it is missing, then consume one click when any is left
+//
+// KEYS: counter key
+// ARGV: clicks left to seed, ttl in milliseconds
+var takeScript = redis.NewScript(`
+local left = tonumber(redis.call('GET', KEYS[1]) or ARGV[1])
+if left <= 0 then
+	redis.call('SET', KEYS[1], 0, 'PX', ARGV[2])
+	return 0
+end
+
+redis.call('SET', KEYS[1], left - 1, 'PX', ARGV[2])
+return 1
+`)
+
+var _ Counter = &RedisCounter{}
+
+// RedisCounter is Counter shared by all instances through redis, the script make check and take atomic
+type RedisCounter struct {
+	rds *redis.Client
+}
+
+// NewRedisCounter Counter constructor
+func NewRedisCounter(rds *redis.Client) *RedisCounter {
+	return &RedisCounter{rds: rds}
+}
+
+// Take is implementation for Counter
+func (c *RedisCounter) Take(ctx context.Context, key string, left int64, ttl time.Duration) (bool, error) {
+	taken, err := takeScript.Run(ctx, c.rds, []string{keyPrefix + key}, left, counterTTL(ttl).Milliseconds()).Int64()
+	if err != nil {
+		return false, errors.Wrapf(errors.ErrInternal, "failed to take click of %v err = %v", key, err)
+	}
+
+	return taken == 1, nil
+}
+
+// Exhaust is implementation for Counter
+func (c *RedisCounter) Exhaust(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.rds.Set(ctx, keyPrefix+key, 0, counterTTL(ttl)).Err(); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to exhaust clicks of %v err = %v", key, err)
+	}
+
+	return nil
+}
+
+// counterTTL return ttl which is not shorter than minTTL
+func counterTTL(ttl time.Duration) time.Duration {
+	if ttl < minTTL {
+		return minTTL
+	}
+	return ttl
+}
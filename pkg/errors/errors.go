@@ -7,14 +7,18 @@ import (
 )
 
 var (
-	ErrInvalidInput       = &Exception{Code: 400001, Message: "One of the request inputs is not valid.", Status: http.StatusBadRequest, GRPCCode: codes.InvalidArgument}
-	ErrInvalidHeaderValue = &Exception{Code: 400003, Message: "The value provided for one of the HTTP headers was not in the correct format.", Status: http.StatusBadRequest, GRPCCode: codes.InvalidArgument}
-	ErrUnauthorized       = &Exception{Code: 401001, Message: "The request unauthorized", Status: http.StatusUnauthorized, GRPCCode: codes.PermissionDenied}
-	ErrForbidden          = &Exception{Code: 403001, Message: "Forbidden.", Status: http.StatusForbidden, GRPCCode: codes.PermissionDenied}
-	ErrPageNotFound       = &Exception{Code: 404001, Message: "Page not found.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
-	ErrResourceNotFound   = &Exception{Code: 404002, Message: "The specified resource does not exist.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
-	ErrShortenedURLExpire = &Exception{Code: 404003, Message: "The shortened URL is expire.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
-	ErrConflict           = &Exception{Code: 409001, Message: "The request conflict.", Status: http.StatusConflict, GRPCCode: codes.AlreadyExists}
-	ErrTooManyRequests    = &Exception{Code: 429001, Message: "Too Many Requests", Status: http.StatusTooManyRequests, GRPCCode: codes.PermissionDenied}
-	ErrInternal           = &Exception{Code: 500001, Message: "Serve occur error.", Status: http.StatusInternalServerError, GRPCCode: codes.Internal}
+	ErrInvalidInput          = &Exception{Code: 400001, Message: "One of the request inputs is not valid.", Status: http.StatusBadRequest, GRPCCode: codes.InvalidArgument}
+	ErrInvalidHeaderValue    = &Exception{Code: 400003, Message: "The value provided for one of the HTTP headers was not in the correct format.", Status: http.StatusBadRequest, GRPCCode: codes.InvalidArgument}
+	ErrUnauthorized          = &Exception{Code: 401001, Message: "The request unauthorized", Status: http.StatusUnauthorized, GRPCCode: codes.PermissionDenied}
+	ErrPasswordRequired      = &Exception{Code: 401002, Message: "The shortened URL is protected by a password.", Status: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated}
+	ErrForbidden             = &Exception{Code: 403001, Message: "Forbidden.", Status: http.StatusForbidden, GRPCCode: codes.PermissionDenied}
+	ErrPageNotFound          = &Exception{Code: 404001, Message: "Page not found.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
+	ErrResourceNotFound      = &Exception{Code: 404002, Message: "The specified resource does not exist.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
+	ErrShortenedURLExpire    = &Exception{Code: 404003, Message: "The shortened URL is expire.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
+	ErrShortenedURLExhausted = &Exception{Code: 404004, Message: "The shortened URL has used up its clicks.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
+	ErrShortenedURLNotActive = &Exception{Code: 404005, Message: "The shortened URL is not active yet.", Status: http.StatusNotFound, GRPCCode: codes.NotFound}
+	ErrConflict              = &Exception{Code: 409001, Message: "The request conflict.", Status: http.StatusConflict, GRPCCode: codes.AlreadyExists}
+	ErrShortenedURLDisabled  = &Exception{Code: 410001, Message: "The shortened URL is disabled for abuse.", Status: http.StatusGone, GRPCCode: codes.NotFound}
+	ErrTooManyRequests       = &Exception{Code: 429001, Message: "Too Many Requests", Status: http.StatusTooManyRequests, GRPCCode: codes.PermissionDenied}
+	ErrInternal              = &Exception{Code: 500001, Message: "Serve occur error.", Status: http.StatusInternalServerError, GRPCCode: codes.Internal}
 )
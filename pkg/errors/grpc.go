@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+const (
+	// Domain is ErrorInfo domain of Exception, its reason is the exception code
+	Domain = "url-shortener"
+
+	// TypeBadRequest is Detail type which convert to google.rpc.BadRequest
+	// metadata key is the field and value is the violation description
+	TypeBadRequest = "type.googleapis.com/google.rpc.BadRequest"
+
+	// metadataStatus is ErrorInfo metadata key of http status
+	metadataStatus = "status"
+	// metadataType is ErrorInfo metadata key of Detail type
+	metadataType = "@type"
+)
+
+var (
+	// exceptions is all predefined exception by code
+	exceptions = map[int]*Exception{}
+
+	// grpcExceptions is fallback exception when status not come from Exception
+	grpcExceptions = map[codes.Code]*Exception{
+		codes.InvalidArgument:   ErrInvalidInput,
+		codes.NotFound:          ErrResourceNotFound,
+		codes.AlreadyExists:     ErrConflict,
+		codes.PermissionDenied:  ErrForbidden,
+		codes.Unauthenticated:   ErrUnauthorized,
+		codes.ResourceExhausted: ErrTooManyRequests,
+	}
+)
+
+func init() {
+	for _, e := range []*Exception{
+		ErrInvalidInput,
+		ErrInvalidHeaderValue,
+		ErrUnauthorized,
+		ErrPasswordRequired,
+		ErrForbidden,
+		ErrPageNotFound,
+		ErrResourceNotFound,
+		ErrShortenedURLExpire,
+		ErrShortenedURLExhausted,
+		ErrShortenedURLNotActive,
+		ErrConflict,
+		ErrShortenedURLDisabled,
+		ErrTooManyRequests,
+		ErrInternal,
+	} {
+		exceptions[e.Code] = e
+	}
+}
+
+// ToGRPCStatus convert error into grpc status
+// The first detail is ErrorInfo which reason is the exception code,
+// then every Exception.Details follow as ErrorInfo or BadRequest.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	e := TryConvert(err)
+	if e == nil {
+		if st, ok := status.FromError(Cause(err)); ok {
+			return st
+		}
+		e = ErrInternal
+	}
+
+	code := e.GRPCCode
+	if code == codes.OK {
+		code = codes.Internal
+	}
+
+	details := make([]protoiface.MessageV1, 0, len(e.Details)+1)
+	details = append(details, &errdetails.ErrorInfo{
+		Reason: strconv.Itoa(e.Code),
+		Domain: Domain,
+		Metadata: map[string]string{
+			metadataStatus: strconv.Itoa(e.Status),
+		},
+	})
+	for _, d := range e.Details {
+		details = append(details, toProtoDetail(d))
+	}
+
+	st := status.New(code, e.Message)
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// FromGRPCStatus convert grpc status into Exception
+// status which not create by ToGRPCStatus is mapped by grpc code
+func FromGRPCStatus(st *status.Status) *Exception {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	e := &Exception{
+		Code:     ErrInternal.Code,
+		Status:   ErrInternal.Status,
+		Message:  st.Message(),
+		GRPCCode: st.Code(),
+	}
+	if fallback, ok := grpcExceptions[st.Code()]; ok {
+		e.Code = fallback.Code
+		e.Status = fallback.Status
+	}
+
+	found := false
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if !found && d.GetDomain() == Domain {
+				found = true
+				if code, err := strconv.Atoi(d.GetReason()); err == nil {
+					e.Code = code
+				}
+				if s, err := strconv.Atoi(d.GetMetadata()[metadataStatus]); err == nil {
+					e.Status = s
+				} else if known, ok := exceptions[e.Code]; ok {
+					e.Status = known.Status
+				}
+				continue
+			}
+			e.Details = append(e.Details, fromErrorInfo(d))
+		case *errdetails.BadRequest:
+			e.Details = append(e.Details, fromBadRequest(d))
+		}
+	}
+
+	return e
+}
+
+// UnaryServerInterceptor convert handler error into grpc status error
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, ToGRPCStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor convert handler error into grpc status error
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			return ToGRPCStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+func toProtoDetail(d Detail) protoiface.MessageV1 {
+	if d.Type == TypeBadRequest {
+		fields := make([]string, 0, len(d.Metadata))
+		for field := range d.Metadata {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		br := &errdetails.BadRequest{}
+		for _, field := range fields {
+			description, ok := d.Metadata[field].(string)
+			if !ok {
+				data, _ := json.Marshal(d.Metadata[field])
+				description = string(data)
+			}
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: description,
+			})
+		}
+		return br
+	}
+
+	// ErrorInfo metadata only accept string, so value is encoded as json
+	metadata := make(map[string]string, len(d.Metadata)+1)
+	for k, v := range d.Metadata {
+		data, _ := json.Marshal(v)
+		metadata[k] = string(data)
+	}
+	if d.Type != "" {
+		metadata[metadataType] = d.Type
+	}
+
+	return &errdetails.ErrorInfo{
+		Reason:   d.Reason,
+		Domain:   d.Domain,
+		Metadata: metadata,
+	}
+}
+
+func fromErrorInfo(info *errdetails.ErrorInfo) Detail {
+	d := Detail{
+		Reason: info.GetReason(),
+		Domain: info.GetDomain(),
+	}
+
+	for k, v := range info.GetMetadata() {
+		if k == metadataType {
+			d.Type = v
+			continue
+		}
+
+		if d.Metadata == nil {
+			d.Metadata = make(map[string]interface{}, len(info.GetMetadata()))
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(v), &value); err != nil {
+			value = v
+		}
+		d.Metadata[k] = value
+	}
+
+	return d
+}
+
+func fromBadRequest(br *errdetails.BadRequest) Detail {
+	d := Detail{
+		Type:     TypeBadRequest,
+		Metadata: make(map[string]interface{}, len(br.GetFieldViolations())),
+	}
+	for _, v := range br.GetFieldViolations() {
+		d.Metadata[v.GetField()] = v.GetDescription()
+	}
+	return d
+}
@@ -0,0 +1,106 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"url-shortener/pkg/errors"
+)
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     codes.Code
+		expected *errors.Exception
+	}{
+		{
+			name:     "Exception",
+			err:      errors.Wrap(errors.ErrShortenedURLExpire, "the short is expire"),
+			code:     codes.NotFound,
+			expected: errors.ErrShortenedURLExpire,
+		},
+		{
+			name:     "Disabled",
+			err:      errors.Wrap(errors.ErrShortenedURLDisabled, "the short is disabled"),
+			code:     codes.NotFound,
+			expected: errors.ErrShortenedURLDisabled,
+		},
+		{
+			name: "ExceptionWithDetails",
+			err: errors.ErrInvalidInput.WithDetails(
+				errors.Detail{
+					Reason:   "URL_BLOCKED",
+					Domain:   "policy",
+					Metadata: map[string]interface{}{"host": "evil.example", "score": float64(3)},
+				},
+				errors.Detail{
+					Type:     errors.TypeBadRequest,
+					Metadata: map[string]interface{}{"url": "scheme is not allowed"},
+				},
+			),
+			code: codes.InvalidArgument,
+			expected: errors.ErrInvalidInput.WithDetails(
+				errors.Detail{
+					Reason:   "URL_BLOCKED",
+					Domain:   "policy",
+					Metadata: map[string]interface{}{"host": "evil.example", "score": float64(3)},
+				},
+				errors.Detail{
+					Type:     errors.TypeBadRequest,
+					Metadata: map[string]interface{}{"url": "scheme is not allowed"},
+				},
+			),
+		},
+		{
+			name:     "UnknownError",
+			err:      context.DeadlineExceeded,
+			code:     codes.Internal,
+			expected: errors.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := errors.ToGRPCStatus(tt.err)
+			assert.Equal(t, tt.code, st.Code())
+
+			actual := errors.FromGRPCStatus(st)
+			assert.Equal(t, tt.expected.Code, actual.Code)
+			assert.Equal(t, tt.expected.Status, actual.Status)
+			assert.Equal(t, tt.expected.Message, actual.Message)
+			assert.Equal(t, tt.expected.Details, actual.Details)
+			assert.True(t, errors.Is(actual, tt.expected))
+		})
+	}
+}
+
+func TestFromGRPCStatus_WithoutErrorInfo(t *testing.T) {
+	actual := errors.FromGRPCStatus(status.New(codes.NotFound, "not found"))
+	assert.Equal(t, errors.ErrResourceNotFound.Code, actual.Code)
+	assert.Equal(t, "not found", actual.Message)
+
+	assert.Nil(t, errors.FromGRPCStatus(status.New(codes.OK, "")))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := errors.UnaryServerInterceptor()
+
+	_, err := interceptor(
+		context.Background(),
+		nil,
+		&grpc.UnaryServerInfo{FullMethod: "/urlshortener.v1.URLShortener/Resolve"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.Wrap(errors.ErrPageNotFound, "shortened url not in bloom filter")
+		},
+	)
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, errors.ErrPageNotFound.Code, errors.FromGRPCStatus(st).Code)
+}
@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"url-shortener/pkg/errors"
+)
+
+type Config struct {
+	Port string `mapstructure:"port"`
+}
+
+// NewServer grpc server, every request context has logger
+// and handler error is converted into grpc status with exception code
+func NewServer(logger zerolog.Logger, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			UnaryLoggerInterceptor(logger),
+			UnaryLoggingInterceptor(),
+			UnaryRecoveryInterceptor(),
+			errors.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			errors.StreamServerInterceptor(),
+		),
+	}, opts...)
+
+	return grpc.NewServer(opts...)
+}
+
+// UnaryLoggerInterceptor make request context with logger
+// then all context can get logger from context
+func UnaryLoggerInterceptor(logger zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(logger.WithContext(ctx), req)
+	}
+}
+
+// UnaryLoggingInterceptor make grpc access log
+func UnaryLoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		stop := time.Now()
+
+		code := status.Code(err)
+
+		var logger *zerolog.Event
+		if code == codes.Internal || code == codes.Unknown {
+			logger = log.Ctx(ctx).Error().Err(err)
+		} else {
+			logger = log.Ctx(ctx).Info()
+		}
+
+		logger.
+			Str("method", info.FullMethod).
+			Str("latency_human", stop.Sub(start).String()).
+			Str("code", code.String()).
+			Msg("grpc access log.")
+
+		return resp, err
+	}
+}
+
+// UnaryRecoveryInterceptor handles panic error
+func UnaryRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				trace := make([]byte, 4096)
+				runtime.Stack(trace, true)
+
+				log.Ctx(ctx).
+					Error().
+					Str("method", info.FullMethod).
+					Str("stack_error", string(trace)).
+					Msgf("grpc: unknown error: %v", fmt.Sprint(r))
+
+				err = errors.ToGRPCStatus(errors.ErrInternal).Err()
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// PeerIP return ip of the peer address of request, empty when it is unknown.
+// It is the address of the proxy when the server is behind one
+func PeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,54 @@
+package guard
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+
+	pg "url-shortener/pkg/grpc"
+)
+
+// UnaryServerInterceptor reject blocked client and delay suspicious client of guarded full method names,
+// the client is put into request context so the service can record its misses.
+// Client is the peer ip, IPv6 is grouped by /64. A failed guard allows the request.
+func UnaryServerInterceptor(g Guard, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		guarded[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !guarded[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		client := ClientOf(pg.PeerIP(ctx))
+		if client == "" {
+			return handler(ctx, req)
+		}
+
+		verdict, err := g.Check(ctx, client)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to check client %v", client)
+			verdict = Verdict{}
+		}
+
+		if verdict.Blocked {
+			return nil, Blocked(client, verdict.BlockedUntil)
+		}
+
+		if verdict.Delay > 0 {
+			timer := time.NewTimer(verdict.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+
+		return handler(NewContext(ctx, client), req)
+	}
+}
@@ -0,0 +1,68 @@
+package guard_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/guard"
+	"url-shortener/pkg/guard/mocks"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/urlshortener.v1.URLShortener/Resolve"
+
+	tests := []struct {
+		name    string
+		method  string
+		verdict guard.Verdict
+		err     error
+	}{
+		{
+			name:   "Allowed",
+			method: method,
+		},
+		{
+			name:    "Blocked",
+			method:  method,
+			verdict: guard.Verdict{Blocked: true, BlockedUntil: time.Now().Add(time.Hour)},
+			err:     errors.ErrTooManyRequests,
+		},
+		{
+			name:   "NotGuarded",
+			method: "/urlshortener.v1.URLShortener/ShortURL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := mocks.NewGuard(t)
+			if tt.method == method {
+				g.EXPECT().Check(mock.Anything, "203.0.113.7").Return(tt.verdict, nil)
+			}
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 5555}})
+			interceptor := guard.UnaryServerInterceptor(g, method)
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				client, ok := guard.FromContext(ctx)
+				assert.Equal(t, tt.method == method, ok)
+				if ok {
+					assert.Equal(t, "203.0.113.7", client)
+				}
+				return nil, nil
+			})
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "interceptor() error = %v, expected %v", err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package guard
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	ReasonAuto   = "auto"   // ReasonAuto is block by too many misses
+	ReasonManual = "manual" // ReasonManual is block by admin
+
+	ReasonBlocked = "CLIENT_BLOCKED" // ReasonBlocked is error detail reason of request from blocked client
+	Domain        = "guard"          // Domain is error detail domain
+)
+
+// Config enumeration guard config, misses are 404 and expired lookups of a client
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Window is how long misses are counted, default 10 minutes
+	Window time.Duration `mapstructure:"window"`
+
+	// TarpitThreshold is misses in window before every request of the client is delayed, default 20
+	TarpitThreshold int64 `mapstructure:"tarpitThreshold"`
+
+	// TarpitDelay is delay of each miss above TarpitThreshold, default 200ms, at most MaxDelay, default 5s
+	TarpitDelay time.Duration `mapstructure:"tarpitDelay"`
+	MaxDelay    time.Duration `mapstructure:"maxDelay"`
+
+	// BlockThreshold is misses in window before the client is blocked for BlockDuration, default 100 and 1 hour
+	BlockThreshold int64         `mapstructure:"blockThreshold"`
+	BlockDuration  time.Duration `mapstructure:"blockDuration"`
+}
+
+// withDefault fill zero fields with default
+func (c Config) withDefault() Config {
+	if c.Window <= 0 {
+		c.Window = 10 * time.Minute
+	}
+	if c.TarpitThreshold <= 0 {
+		c.TarpitThreshold = 20
+	}
+	if c.TarpitDelay <= 0 {
+		c.TarpitDelay = 200 * time.Millisecond
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = 5 * time.Second
+	}
+	if c.BlockThreshold <= 0 {
+		c.BlockThreshold = 100
+	}
+	if c.BlockDuration <= 0 {
+		c.BlockDuration = time.Hour
+	}
+	return c
+}
+
+// delay return tarpit delay of misses
+func (c Config) delay(misses int64) time.Duration {
+	if misses < c.TarpitThreshold {
+		return 0
+	}
+
+	delay := c.TarpitDelay * time.Duration(misses-c.TarpitThreshold+1)
+	if delay > c.MaxDelay {
+		return c.MaxDelay
+	}
+	return delay
+}
+
+// Verdict is how to serve a client
+type Verdict struct {
+	// Blocked client is rejected until BlockedUntil
+	Blocked      bool
+	BlockedUntil time.Time
+
+	// Delay is tarpit delay before serving the client
+	Delay time.Duration
+}
+
+// Block is a blocked client
+type Block struct {
+	Client    string
+	Reason    string
+	ExpiredAt time.Time
+}
+
+// Guard track misses of clients and escalate suspicious ones to tarpit and block
+//
+//go:generate mockery --name Guard --with-expecter
+type Guard interface {
+	// Check return verdict of client
+	Check(ctx context.Context, client string) (Verdict, error)
+
+	// RecordMiss count a miss of client, the client is blocked when it reach the block threshold
+	RecordMiss(ctx context.Context, client string) error
+
+	// ListBlocks list blocked clients, expired blocks are removed
+	ListBlocks(ctx context.Context) ([]*Block, error)
+
+	// Block block client for duration
+	Block(ctx context.Context, client string, reason string, duration time.Duration) error
+
+	// Unblock remove block and misses of client
+	Unblock(ctx context.Context, client string) error
+}
+
+// ClientOf return client of ip, IPv6 is grouped by /64 which a single host usually owns
+func ClientOf(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.String()
+	}
+
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+type clientKey struct{}
+
+// NewContext return context carry the client of request
+func NewContext(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// FromContext return the client of request
+func FromContext(ctx context.Context) (string, bool) {
+	client, ok := ctx.Value(clientKey{}).(string)
+	return client, ok && client != ""
+}
+
+// Blocked return ErrTooManyRequests with the block expire time in detail metadata
+func Blocked(client string, until time.Time) error {
+	return errors.Wrapf(
+		errors.ErrTooManyRequests.WithDetails(errors.Detail{
+			Reason: ReasonBlocked,
+			Domain: Domain,
+			Metadata: map[string]interface{}{
+				"blockedUntil": until.UTC().Format(time.RFC3339),
+			},
+		}),
+		"client %v is blocked until %v", client, until.UTC().Format(time.RFC3339),
+	)
+}
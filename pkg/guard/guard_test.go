@@ -0,0 +1,58 @@
+package guard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"url-shortener/pkg/errors"
+)
+
+func TestConfig_delay(t *testing.T) {
+	config := Config{TarpitThreshold: 20}.withDefault()
+
+	tests := []struct {
+		misses   int64
+		expected time.Duration
+	}{
+		{misses: 0, expected: 0},
+		{misses: 19, expected: 0},
+		{misses: 20, expected: 200 * time.Millisecond},
+		{misses: 24, expected: time.Second},
+		{misses: 99, expected: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equalf(t, tt.expected, config.delay(tt.misses), "delay(%v)", tt.misses)
+	}
+}
+
+func TestClientOf(t *testing.T) {
+	assert.Equal(t, "203.0.113.7", ClientOf("203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", ClientOf("::ffff:203.0.113.7"))
+	assert.Equal(t, "2001:db8:1:2::/64", ClientOf("2001:db8:1:2:aaaa:bbbb:cccc:dddd"))
+	assert.Equal(t, "2001:db8:1:2::/64", ClientOf("2001:db8:1:2::/64"))
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	client, ok := FromContext(NewContext(context.Background(), "203.0.113.7"))
+	assert.True(t, ok)
+	assert.Equal(t, "203.0.113.7", client)
+}
+
+func TestBlocked(t *testing.T) {
+	until := time.Date(2023, 7, 17, 10, 30, 0, 0, time.UTC)
+	err := Blocked("203.0.113.7", until)
+
+	assert.True(t, errors.Is(err, errors.ErrTooManyRequests))
+
+	e := errors.TryConvert(err)
+	if assert.NotNil(t, e) && assert.Len(t, e.Details, 1) {
+		assert.Equal(t, ReasonBlocked, e.Details[0].Reason)
+		assert.Equal(t, "2023-07-17T10:30:00Z", e.Details[0].Metadata["blockedUntil"])
+	}
+}
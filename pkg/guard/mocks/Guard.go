@@ -0,0 +1,277 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+	guard "url-shortener/pkg/guard"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Guard is an autogenerated mock type for the Guard type
+type Guard struct {
+	mock.Mock
+}
+
+type Guard_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Guard) EXPECT() *Guard_Expecter {
+	return &Guard_Expecter{mock: &_m.Mock}
+}
+
+// Block provides a mock function with given fields: ctx, client, reason, duration
+func (_m *Guard) Block(ctx context.Context, client string, reason string, duration time.Duration) error {
+	ret := _m.Called(ctx, client, reason, duration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) error); ok {
+		r0 = rf(ctx, client, reason, duration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Guard_Block_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Block'
+type Guard_Block_Call struct {
+	*mock.Call
+}
+
+// Block is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+//   - reason string
+//   - duration time.Duration
+func (_e *Guard_Expecter) Block(ctx interface{}, client interface{}, reason interface{}, duration interface{}) *Guard_Block_Call {
+	return &Guard_Block_Call{Call: _e.mock.On("Block", ctx, client, reason, duration)}
+}
+
+func (_c *Guard_Block_Call) Run(run func(ctx context.Context, client string, reason string, duration time.Duration)) *Guard_Block_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *Guard_Block_Call) Return(_a0 error) *Guard_Block_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Guard_Block_Call) RunAndReturn(run func(context.Context, string, string, time.Duration) error) *Guard_Block_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Check provides a mock function with given fields: ctx, client
+func (_m *Guard) Check(ctx context.Context, client string) (guard.Verdict, error) {
+	ret := _m.Called(ctx, client)
+
+	var r0 guard.Verdict
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (guard.Verdict, error)); ok {
+		return rf(ctx, client)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) guard.Verdict); ok {
+		r0 = rf(ctx, client)
+	} else {
+		r0 = ret.Get(0).(guard.Verdict)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Guard_Check_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Check'
+type Guard_Check_Call struct {
+	*mock.Call
+}
+
+// Check is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+func (_e *Guard_Expecter) Check(ctx interface{}, client interface{}) *Guard_Check_Call {
+	return &Guard_Check_Call{Call: _e.mock.On("Check", ctx, client)}
+}
+
+func (_c *Guard_Check_Call) Run(run func(ctx context.Context, client string)) *Guard_Check_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Guard_Check_Call) Return(_a0 guard.Verdict, _a1 error) *Guard_Check_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Guard_Check_Call) RunAndReturn(run func(context.Context, string) (guard.Verdict, error)) *Guard_Check_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBlocks provides a mock function with given fields: ctx
+func (_m *Guard) ListBlocks(ctx context.Context) ([]*guard.Block, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*guard.Block
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*guard.Block, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*guard.Block); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*guard.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Guard_ListBlocks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBlocks'
+type Guard_ListBlocks_Call struct {
+	*mock.Call
+}
+
+// ListBlocks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Guard_Expecter) ListBlocks(ctx interface{}) *Guard_ListBlocks_Call {
+	return &Guard_ListBlocks_Call{Call: _e.mock.On("ListBlocks", ctx)}
+}
+
+func (_c *Guard_ListBlocks_Call) Run(run func(ctx context.Context)) *Guard_ListBlocks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Guard_ListBlocks_Call) Return(_a0 []*guard.Block, _a1 error) *Guard_ListBlocks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Guard_ListBlocks_Call) RunAndReturn(run func(context.Context) ([]*guard.Block, error)) *Guard_ListBlocks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordMiss provides a mock function with given fields: ctx, client
+func (_m *Guard) RecordMiss(ctx context.Context, client string) error {
+	ret := _m.Called(ctx, client)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Guard_RecordMiss_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordMiss'
+type Guard_RecordMiss_Call struct {
+	*mock.Call
+}
+
+// RecordMiss is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+func (_e *Guard_Expecter) RecordMiss(ctx interface{}, client interface{}) *Guard_RecordMiss_Call {
+	return &Guard_RecordMiss_Call{Call: _e.mock.On("RecordMiss", ctx, client)}
+}
+
+func (_c *Guard_RecordMiss_Call) Run(run func(ctx context.Context, client string)) *Guard_RecordMiss_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Guard_RecordMiss_Call) Return(_a0 error) *Guard_RecordMiss_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Guard_RecordMiss_Call) RunAndReturn(run func(context.Context, string) error) *Guard_RecordMiss_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unblock provides a mock function with given fields: ctx, client
+func (_m *Guard) Unblock(ctx context.Context, client string) error {
+	ret := _m.Called(ctx, client)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Guard_Unblock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unblock'
+type Guard_Unblock_Call struct {
+	*mock.Call
+}
+
+// Unblock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client string
+func (_e *Guard_Expecter) Unblock(ctx interface{}, client interface{}) *Guard_Unblock_Call {
+	return &Guard_Unblock_Call{Call: _e.mock.On("Unblock", ctx, client)}
+}
+
+func (_c *Guard_Unblock_Call) Run(run func(ctx context.Context, client string)) *Guard_Unblock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Guard_Unblock_Call) Return(_a0 error) *Guard_Unblock_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Guard_Unblock_Call) RunAndReturn(run func(context.Context, string) error) *Guard_Unblock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewGuard interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewGuard creates a new instance of Guard. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewGuard(t mockConstructorTestingTNewGuard) *Guard {
+	mock := &Guard{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
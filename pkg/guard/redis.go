@@ -0,0 +1,174 @@
+package guard
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	// keyPrefix is prefix of every guard redis key
+	keyPrefix = "guard:"
+
+	// blocksKey is sorted set of blocked clients scored by block expire time
+	blocksKey = keyPrefix + "blocks"
+)
+
+// recordScript count a miss then block the client when it reach the threshold
+//
+// KEYS: miss key, block key, blocks key
+// ARGV: window, block threshold, block duration, client, now, all times are in milliseconds
+var recordScript = redis.NewScript(`
+local misses = redis.call('INCR', KEYS[1])
+if misses == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+
+if misses >= tonumber(ARGV[2]) and redis.call('EXISTS', KEYS[2]) == 0 then
+	redis.call('SET', KEYS[2], 'auto', 'PX', ARGV[3])
+	redis.call('ZADD', KEYS[3], tonumber(ARGV[5]) + tonumber(ARGV[3]), ARGV[4])
+end
+
+return misses
+`)
+
+var _ Guard = &RedisImpl{}
+
+// RedisImpl is Guard shared by all instances through redis
+type RedisImpl struct {
+	rds    *redis.Client
+	config Config
+}
+
+// NewRedisGuard Guard constructor, zero config fields use default
+func NewRedisGuard(rds *redis.Client, config Config) *RedisImpl {
+	return &RedisImpl{
+		rds:    rds,
+		config: config.withDefault(),
+	}
+}
+
+// Check is implementation for Guard
+func (g *RedisImpl) Check(ctx context.Context, client string) (Verdict, error) {
+	var (
+		ttl    *redis.DurationCmd
+		misses *redis.StringCmd
+	)
+	_, err := g.rds.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		ttl = pipe.PTTL(ctx, blockKey(client))
+		misses = pipe.Get(ctx, missKey(client))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return Verdict{}, errors.Wrapf(errors.ErrInternal, "failed to check client = %v err = %v", client, err)
+	}
+
+	if d := ttl.Val(); d > 0 {
+		return Verdict{Blocked: true, BlockedUntil: time.Now().Add(d)}, nil
+	}
+
+	n, _ := misses.Int64()
+	return Verdict{Delay: g.config.delay(n)}, nil
+}
+
+// RecordMiss is implementation for Guard
+func (g *RedisImpl) RecordMiss(ctx context.Context, client string) error {
+	err := recordScript.Run(
+		ctx,
+		g.rds,
+		[]string{missKey(client), blockKey(client), blocksKey},
+		g.config.Window.Milliseconds(),
+		g.config.BlockThreshold,
+		g.config.BlockDuration.Milliseconds(),
+		client,
+		time.Now().UnixMilli(),
+	).Err()
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to record miss of client = %v err = %v", client, err)
+	}
+
+	return nil
+}
+
+// ListBlocks is implementation for Guard
+func (g *RedisImpl) ListBlocks(ctx context.Context) ([]*Block, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	if err := g.rds.ZRemRangeByScore(ctx, blocksKey, "-inf", now).Err(); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to remove expired blocks err = %v", err)
+	}
+
+	zs, err := g.rds.ZRangeWithScores(ctx, blocksKey, 0, -1).Result()
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list blocks err = %v", err)
+	}
+
+	reasons := make([]*redis.StringCmd, len(zs))
+	_, err = g.rds.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, z := range zs {
+			reasons[i] = pipe.Get(ctx, blockKey(z.Member.(string)))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to list block reasons err = %v", err)
+	}
+
+	blocks := make([]*Block, 0, len(zs))
+	for i, z := range zs {
+		// the block key is removed by unblock of another instance
+		if reasons[i].Err() == redis.Nil {
+			continue
+		}
+
+		blocks = append(blocks, &Block{
+			Client:    z.Member.(string),
+			Reason:    reasons[i].Val(),
+			ExpiredAt: time.UnixMilli(int64(z.Score)).UTC(),
+		})
+	}
+
+	return blocks, nil
+}
+
+// Block is implementation for Guard
+func (g *RedisImpl) Block(ctx context.Context, client string, reason string, duration time.Duration) error {
+	expiredAt := time.Now().Add(duration)
+
+	_, err := g.rds.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, blockKey(client), reason, duration)
+		pipe.ZAdd(ctx, blocksKey, redis.Z{Score: float64(expiredAt.UnixMilli()), Member: client})
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to block client = %v err = %v", client, err)
+	}
+
+	return nil
+}
+
+// Unblock is implementation for Guard
+func (g *RedisImpl) Unblock(ctx context.Context, client string) error {
+	_, err := g.rds.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, blockKey(client), missKey(client))
+		pipe.ZRem(ctx, blocksKey, client)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to unblock client = %v err = %v", client, err)
+	}
+
+	return nil
+}
+
+func missKey(client string) string {
+	return keyPrefix + "miss:" + client
+}
+
+func blockKey(client string) string {
+	return keyPrefix + "block:" + client
+}
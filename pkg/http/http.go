@@ -1,8 +1,11 @@
 package http
 
 import (
+	"net"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 
 	"url-shortener/pkg/errors"
 )
@@ -10,6 +13,10 @@ import (
 type Config struct {
 	Mode string `mapstructure:"mode"`
 	Port string `mapstructure:"port"`
+
+	// TrustedProxies is CIDRs of proxies whose X-Forwarded-For header is trusted,
+	// empty means client ip is the remote address
+	TrustedProxies []string `mapstructure:"trustedProxies"`
 }
 
 // NewEcho http handler
@@ -30,10 +37,33 @@ func NewEcho(config Config) *echo.Echo {
 		e.HidePort = false
 	}
 
+	e.IPExtractor = ipExtractor(config.TrustedProxies)
+
 	e.HTTPErrorHandler = EchoErrorHandler
 	return e
 }
 
+// ipExtractor read client ip from X-Forwarded-For when the request comes through trusted proxies
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := make([]echo.TrustOption, 0, len(trustedProxies)+3)
+	// only the listed proxies are trusted, not every private network
+	options = append(options, echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false))
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Warn().Err(err).Msgf("ignore invalid trusted proxy %v", cidr)
+			continue
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 // EchoErrorHandler error handle for echo
 func EchoErrorHandler(err error, c echo.Context) {
 	if err == nil {
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/guard"
+)
+
+// NewGuardMiddleware reject blocked client and delay suspicious client before serving,
+// the client is put into request context so the service can record its misses.
+// Client ip is from echo IPExtractor, IPv6 is grouped by /64. A failed guard allows the request.
+func NewGuardMiddleware(g guard.Guard) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			client := guard.ClientOf(c.RealIP())
+
+			verdict, err := g.Check(ctx, client)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msgf("failed to check client %v", client)
+				verdict = guard.Verdict{}
+			}
+
+			if verdict.Blocked {
+				retryAfter := time.Until(verdict.BlockedUntil)
+				c.Response().Header().Set(echo.HeaderRetryAfter, seconds(retryAfter))
+				return guard.Blocked(client, verdict.BlockedUntil)
+			}
+
+			if verdict.Delay > 0 {
+				timer := time.NewTimer(verdict.Delay)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+
+			c.SetRequest(c.Request().WithContext(guard.NewContext(ctx, client)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/guard"
+	"url-shortener/pkg/guard/mocks"
+	ph "url-shortener/pkg/http"
+	"url-shortener/pkg/http/middleware"
+)
+
+func TestNewGuardMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		client     string
+		verdict    guard.Verdict
+		err        error
+		status     int
+		retryAfter bool
+		minLatency time.Duration
+	}{
+		{
+			name:       "Allowed",
+			remoteAddr: "203.0.113.7:5555",
+			client:     "203.0.113.7",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "IPv6",
+			remoteAddr: "[2001:db8:1:2::abcd]:5555",
+			client:     "2001:db8:1:2::/64",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "Tarpit",
+			remoteAddr: "203.0.113.7:5555",
+			client:     "203.0.113.7",
+			verdict:    guard.Verdict{Delay: 50 * time.Millisecond},
+			status:     http.StatusOK,
+			minLatency: 50 * time.Millisecond,
+		},
+		{
+			name:       "Blocked",
+			remoteAddr: "203.0.113.7:5555",
+			client:     "203.0.113.7",
+			verdict:    guard.Verdict{Blocked: true, BlockedUntil: time.Now().Add(time.Hour)},
+			status:     http.StatusTooManyRequests,
+			retryAfter: true,
+		},
+		{
+			name:       "GuardDown",
+			remoteAddr: "203.0.113.7:5555",
+			client:     "203.0.113.7",
+			err:        errors.New("connection refused"),
+			status:     http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := mocks.NewGuard(t)
+			g.EXPECT().Check(mock.Anything, tt.client).Return(tt.verdict, tt.err)
+
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			e.GET("/:url", func(c echo.Context) error {
+				client, ok := guard.FromContext(c.Request().Context())
+				assert.True(t, ok)
+				assert.Equal(t, tt.client, client)
+				return c.NoContent(http.StatusOK)
+			}, middleware.NewGuardMiddleware(g))
+
+			req := httptest.NewRequest(http.MethodGet, "/K2MY8LEp", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			begin := time.Now()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.retryAfter, rec.Header().Get(echo.HeaderRetryAfter) != "")
+			assert.GreaterOrEqual(t, time.Since(begin), tt.minLatency)
+		})
+	}
+}
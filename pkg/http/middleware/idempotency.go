@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/idempotency"
+)
+
+// idempotencyPollInterval is how often a duplicate request check whether the running one is done
+const idempotencyPollInterval = 100 * time.Millisecond
+
+// NewIdempotencyMiddleware replay the first response of request with the same Idempotency-Key header.
+// Keys are scoped by credential like the rate limit, so the auth middleware must run before it.
+// A key reused with a different method, path, body or varyHeaders gets 409, so does a duplicate of a running
+// request after it waits for wait. Server error is not stored, the request can be retried with the same key.
+// A failed store serves the request without the key
+func NewIdempotencyMiddleware(store idempotency.Store, wait time.Duration, varyHeaders ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(idempotency.HeaderKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > idempotency.MaxKeyLength {
+				return errors.Wrapf(errors.ErrInvalidInput, "idempotency key is longer than %v", idempotency.MaxKeyLength)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return errors.Wrapf(errors.ErrInvalidInput, "failed to read request body %v", err)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := c.Request().Context()
+			storeKey := rateLimitKey(c) + ":" + key
+			fingerprint := requestFingerprint(c.Request(), body, varyHeaders)
+
+			record, err := beginIdempotency(ctx, store, storeKey, fingerprint, wait)
+			switch {
+			case err != nil:
+				log.Ctx(ctx).Error().Err(err).Msgf("failed to check idempotency key %v", key)
+				return next(c)
+			case record == nil:
+			case record.Fingerprint != fingerprint:
+				return idempotency.KeyReused(key)
+			case !record.Done:
+				return idempotency.InFlight(key)
+			default:
+				c.Response().Header().Set(idempotency.HeaderReplayed, "true")
+				return c.Blob(record.Status, record.ContentType, record.Body)
+			}
+
+			// the key is claimed, error is rendered here so its response is stored as well
+			writer := &captureWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = writer
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// the response is sent already, the key is kept even if the client is gone
+			storeCtx := log.Ctx(ctx).WithContext(context.Background())
+			if status := c.Response().Status; status >= http.StatusInternalServerError {
+				err = store.Release(storeCtx, storeKey)
+			} else {
+				err = store.Complete(storeCtx, storeKey, &idempotency.Record{
+					Fingerprint: fingerprint,
+					Done:        true,
+					Status:      status,
+					ContentType: c.Response().Header().Get(echo.HeaderContentType),
+					Body:        writer.body.Bytes(),
+				})
+			}
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msgf("failed to store response of idempotency key %v", key)
+			}
+
+			return nil
+		}
+	}
+}
+
+// beginIdempotency claim key or return its record, a running request of the same fingerprint is waited for
+func beginIdempotency(ctx context.Context, store idempotency.Store, key, fingerprint string, wait time.Duration) (*idempotency.Record, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		record, err := store.Begin(ctx, key, fingerprint)
+		if err != nil || record == nil || record.Done || record.Fingerprint != fingerprint || !time.Now().Before(deadline) {
+			return record, err
+		}
+
+		timer := time.NewTimer(idempotencyPollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return record, nil
+		}
+	}
+}
+
+// requestFingerprint return hex sha256 of method, host, path, vary headers and body of request
+func requestFingerprint(req *http.Request, body []byte, varyHeaders []string) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, req.Method+" "+req.Host+req.URL.RequestURI()+"\n")
+	for _, header := range varyHeaders {
+		_, _ = io.WriteString(h, header+": "+req.Header.Get(header)+"\n")
+	}
+	_, _ = h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// captureWriter copy response body written to it
+type captureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
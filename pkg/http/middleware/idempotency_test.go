@@ -0,0 +1,157 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	ph "url-shortener/pkg/http"
+	"url-shortener/pkg/http/middleware"
+	"url-shortener/pkg/idempotency"
+	"url-shortener/pkg/idempotency/mocks"
+)
+
+func TestNewIdempotencyMiddleware(t *testing.T) {
+	const storeKey = "ip:203.0.113.7:3f1c2a"
+
+	tests := []struct {
+		name     string
+		key      string
+		begin    func(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error)
+		status   int
+		complete bool
+		release  bool
+		served   bool
+		replayed bool
+		body     string
+	}{
+		{
+			name:   "NoKey",
+			status: http.StatusCreated,
+			served: true,
+			body:   `{"short":"K2MY8LEp"}`,
+		},
+		{
+			name:   "KeyTooLong",
+			key:    strings.Repeat("k", idempotency.MaxKeyLength+1),
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "First",
+			key:  "3f1c2a",
+			begin: func(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error) {
+				return nil, nil
+			},
+			status:   http.StatusCreated,
+			complete: true,
+			served:   true,
+			body:     `{"short":"K2MY8LEp"}`,
+		},
+		{
+			name: "Replayed",
+			key:  "3f1c2a",
+			begin: func(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error) {
+				return &idempotency.Record{
+					Fingerprint: fingerprint,
+					Done:        true,
+					Status:      http.StatusCreated,
+					ContentType: echo.MIMEApplicationJSON,
+					Body:        []byte(`{"short":"K2MY8LEp"}`),
+				}, nil
+			},
+			status:   http.StatusCreated,
+			replayed: true,
+			body:     `{"short":"K2MY8LEp"}`,
+		},
+		{
+			name: "KeyReused",
+			key:  "3f1c2a",
+			begin: func(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error) {
+				return &idempotency.Record{Fingerprint: "another", Done: true, Status: http.StatusCreated}, nil
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "InFlight",
+			key:  "3f1c2a",
+			begin: func(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error) {
+				return &idempotency.Record{Fingerprint: fingerprint}, nil
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name: "StoreDown",
+			key:  "3f1c2a",
+			begin: func(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error) {
+				return nil, errors.New("connection refused")
+			},
+			status: http.StatusCreated,
+			served: true,
+			body:   `{"short":"K2MY8LEp"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mocks.NewStore(t)
+			if tt.begin != nil {
+				store.EXPECT().Begin(mock.Anything, storeKey, mock.Anything).RunAndReturn(tt.begin)
+			}
+			if tt.complete {
+				store.EXPECT().Complete(mock.Anything, storeKey, mock.MatchedBy(func(record *idempotency.Record) bool {
+					return record.Done && record.Status == tt.status && strings.TrimSpace(string(record.Body)) == tt.body
+				})).Return(nil)
+			}
+
+			served := false
+			e := ph.NewEcho(ph.Config{Mode: "release"})
+			e.POST("/api/v1/urls", func(c echo.Context) error {
+				served = true
+				return c.JSON(http.StatusCreated, map[string]string{"short": "K2MY8LEp"})
+			}, middleware.NewIdempotencyMiddleware(store, 0))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://www.dcard.tw"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.RemoteAddr = "203.0.113.7:5555"
+			if tt.key != "" {
+				req.Header.Set(idempotency.HeaderKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.served, served)
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, rec.Body.String())
+			}
+			if tt.replayed {
+				assert.Equal(t, "true", rec.Header().Get(idempotency.HeaderReplayed))
+			}
+		})
+	}
+}
+
+func TestNewIdempotencyMiddleware_ServerError(t *testing.T) {
+	store := mocks.NewStore(t)
+	store.EXPECT().Begin(mock.Anything, "ip:203.0.113.7:3f1c2a", mock.Anything).Return(nil, nil)
+	store.EXPECT().Release(mock.Anything, "ip:203.0.113.7:3f1c2a").Return(nil)
+
+	e := ph.NewEcho(ph.Config{Mode: "release"})
+	e.POST("/api/v1/urls", func(c echo.Context) error {
+		return errors.New("database is down")
+	}, middleware.NewIdempotencyMiddleware(store, 0))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://www.dcard.tw"}`))
+	req.RemoteAddr = "203.0.113.7:5555"
+	req.Header.Set(idempotency.HeaderKey, "3f1c2a")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
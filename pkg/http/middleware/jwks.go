@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	jwksFetchTimeout = 5 * time.Second
+
+	// jwksMinRefreshInterval limit refresh triggered by unknown kid,
+	// so tokens with random kid can not flood the identity provider
+	jwksMinRefreshInterval = time.Minute
+
+	defaultJWKSRefreshInterval = time.Hour
+)
+
+// jsonWebKey is RSA or EC public key of JWKS
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// N and E are RSA public key
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// Crv, X and Y are EC public key
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey convert json web key to crypto public key
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Wrapf(errors.ErrInvalidInput, "unknown curve %v", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.Wrap(errors.ErrInvalidInput, "point is not on curve")
+		}
+		return key, nil
+
+	default:
+		return nil, errors.Wrapf(errors.ErrInvalidInput, "unknown key type %v", k.Kty)
+	}
+}
+
+// jwks is cached JSON web key set, it is loaded from file or url
+// and refreshed when it is older than refresh interval or a token has unknown kid
+type jwks struct {
+	file            string
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	loadedAt  time.Time
+	refreshed time.Time // refreshed is the last time a refresh is tried
+}
+
+func newJWKS(file string, url string, refreshInterval time.Duration) *jwks {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+
+	return &jwks{
+		file:            file,
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+
+// key return public key by kid, refresh key set when kid is unknown or key set is stale
+func (set *jwks) key(ctx context.Context, kid string) (crypto.PublicKey, bool) {
+	set.mu.RLock()
+	key, ok := set.keys[kid]
+	stale := time.Since(set.loadedAt) > set.refreshInterval
+	set.mu.RUnlock()
+
+	if ok && !stale {
+		return key, true
+	}
+
+	if err := set.refresh(ctx); err != nil {
+		// keep serving the cached keys when identity provider is not reachable
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to refresh jwks")
+	}
+
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	key, ok = set.keys[kid]
+	return key, ok
+}
+
+// refresh reload key set, at most once per jwksMinRefreshInterval
+func (set *jwks) refresh(ctx context.Context) error {
+	set.mu.Lock()
+	if time.Since(set.refreshed) < jwksMinRefreshInterval {
+		set.mu.Unlock()
+		return nil
+	}
+	set.refreshed = time.Now()
+	set.mu.Unlock()
+
+	return set.load(ctx)
+}
+
+// load read key set from file or url and replace cached keys
+func (set *jwks) load(ctx context.Context) error {
+	data, err := set.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var doc struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to decode jwks err = %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("kid", k.Kid).Msg("skip invalid jwk")
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return errors.Wrap(errors.ErrInternal, "jwks has no signing key")
+	}
+
+	set.mu.Lock()
+	set.keys = keys
+	set.loadedAt = time.Now()
+	set.mu.Unlock()
+
+	return nil
+}
+
+func (set *jwks) read(ctx context.Context) ([]byte, error) {
+	if set.file != "" {
+		data, err := os.ReadFile(set.file)
+		if err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "failed to read jwks file %v err = %v", set.file, err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, set.url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to make jwks request err = %v", err)
+	}
+
+	resp, err := set.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to fetch jwks %v err = %v", set.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to fetch jwks %v status = %v", set.url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to read jwks %v err = %v", set.url, err)
+	}
+
+	return data, nil
+}
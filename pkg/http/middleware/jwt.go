@@ -0,0 +1,292 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+)
+
+const (
+	// MethodJWT is Principal.Method of jwt bearer token
+	MethodJWT = "jwt"
+
+	defaultJWTLeeway = 30 * time.Second
+)
+
+// JWTConfig jwt bearer token authenticator config
+type JWTConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// JWKSFile or JWKSURL is where the signing keys are loaded, file has priority
+	JWKSFile string `mapstructure:"jwksFile"`
+	JWKSURL  string `mapstructure:"jwksURL"`
+
+	// RefreshInterval reload keys after it, unknown kid also reload keys, default 1 hour
+	RefreshInterval time.Duration `mapstructure:"refreshInterval"`
+
+	// Issuer and Audience must match iss and aud claims
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+
+	// Leeway is clock skew allowed when check exp and nbf, default 30 seconds
+	Leeway time.Duration `mapstructure:"leeway"`
+
+	// SubjectClaim is the claim of principal id, default sub
+	SubjectClaim string `mapstructure:"subjectClaim"`
+
+	// ScopeClaim is space separated string or array claim of scopes, default scope
+	ScopeClaim string `mapstructure:"scopeClaim"`
+
+	// ScopePrefix is removed from scopes, e.g. urlshortener: map urlshortener:create to create
+	ScopePrefix string `mapstructure:"scopePrefix"`
+}
+
+// jwtHeader is JOSE header of jwt
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtAlgorithm is supported asymmetric signature algorithm, symmetric and none are rejected
+type jwtAlgorithm struct {
+	hash crypto.Hash
+	rsa  bool
+	pss  bool
+}
+
+var jwtAlgorithms = map[string]jwtAlgorithm{
+	"RS256": {hash: crypto.SHA256, rsa: true},
+	"RS384": {hash: crypto.SHA384, rsa: true},
+	"RS512": {hash: crypto.SHA512, rsa: true},
+	"PS256": {hash: crypto.SHA256, rsa: true, pss: true},
+	"PS384": {hash: crypto.SHA384, rsa: true, pss: true},
+	"PS512": {hash: crypto.SHA512, rsa: true, pss: true},
+	"ES256": {hash: crypto.SHA256},
+	"ES384": {hash: crypto.SHA384},
+	"ES512": {hash: crypto.SHA512},
+}
+
+var _ auth.Authenticator = &JWTAuthenticator{}
+
+// JWTAuthenticator verify jwt bearer token issued by OIDC identity provider
+type JWTAuthenticator struct {
+	config JWTConfig
+	keys   *jwks
+	now    func() time.Time
+}
+
+// NewJWTAuthenticator JWTAuthenticator constructor, keys are loaded once before return
+func NewJWTAuthenticator(ctx context.Context, config JWTConfig) (*JWTAuthenticator, error) {
+	if config.JWKSFile == "" && config.JWKSURL == "" {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "jwks file or url is required")
+	}
+
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, errors.Wrap(errors.ErrInvalidInput, "jwt issuer and audience are required")
+	}
+
+	if config.Leeway <= 0 {
+		config.Leeway = defaultJWTLeeway
+	}
+	if config.SubjectClaim == "" {
+		config.SubjectClaim = "sub"
+	}
+	if config.ScopeClaim == "" {
+		config.ScopeClaim = "scope"
+	}
+
+	a := &JWTAuthenticator{
+		config: config,
+		keys:   newJWKS(config.JWKSFile, config.JWKSURL, config.RefreshInterval),
+		now:    time.Now,
+	}
+
+	if err := a.keys.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Authenticate method is implementation for auth.Authenticator
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	parts := strings.Split(credential, ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+
+	header := &jwtHeader{}
+	if err := decodeJWTSegment(parts[0], header); err != nil {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "jwt header is invalid err = %v", err)
+	}
+
+	alg, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "jwt alg = %v is not supported", header.Alg)
+	}
+
+	key, ok := a.keys.key(ctx, header.Kid)
+	if !ok {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "jwt kid = %v is unknown", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrUnauthorized, "jwt signature is invalid")
+	}
+
+	if !verifyJWTSignature(alg, key, parts[0]+"."+parts[1], signature) {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "jwt signature of kid = %v mismatch", header.Kid)
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "jwt claims is invalid err = %v", err)
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims[a.config.SubjectClaim].(string)
+	if subject == "" {
+		return nil, errors.Wrapf(errors.ErrUnauthorized, "jwt %v claim is missing", a.config.SubjectClaim)
+	}
+
+	jti, _ := claims["jti"].(string)
+
+	return &auth.Principal{
+		ID:     subject,
+		KeyID:  jti,
+		Method: MethodJWT,
+		Scopes: a.scopes(claims[a.config.ScopeClaim]),
+	}, nil
+}
+
+// validateClaims check iss, aud, exp and nbf
+func (a *JWTAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.now()
+
+	if iss, _ := claims["iss"].(string); iss != a.config.Issuer {
+		return errors.Wrapf(errors.ErrUnauthorized, "jwt iss = %v is not trusted", iss)
+	}
+
+	if !hasAudience(claims["aud"], a.config.Audience) {
+		return errors.Wrapf(errors.ErrUnauthorized, "jwt aud = %v is not %v", claims["aud"], a.config.Audience)
+	}
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return errors.Wrap(errors.ErrUnauthorized, "jwt exp claim is missing")
+	}
+	if !now.Before(exp.Add(a.config.Leeway)) {
+		return errors.Wrapf(errors.ErrUnauthorized, "jwt is expired at %v", exp.Format(time.RFC3339))
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(a.config.Leeway).Before(nbf) {
+		return errors.Wrapf(errors.ErrUnauthorized, "jwt is not valid before %v", nbf.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// scopes map scope claim into known scopes, unknown one like openid is ignored
+func (a *JWTAuthenticator) scopes(claim interface{}) []auth.Scope {
+	var names []string
+	switch v := claim.(type) {
+	case string:
+		names = strings.Fields(v)
+	case []interface{}:
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+
+	var scopes []auth.Scope
+	for _, name := range names {
+		if a.config.ScopePrefix != "" {
+			if !strings.HasPrefix(name, a.config.ScopePrefix) {
+				continue
+			}
+			name = strings.TrimPrefix(name, a.config.ScopePrefix)
+		}
+
+		if s, err := auth.ParseScopes(name); err == nil {
+			scopes = append(scopes, s...)
+		}
+	}
+
+	return scopes
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func verifyJWTSignature(alg jwtAlgorithm, key crypto.PublicKey, signed string, signature []byte) bool {
+	h := alg.hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !alg.rsa {
+			return false
+		}
+		if alg.pss {
+			return rsa.VerifyPSS(k, alg.hash, digest, signature, nil) == nil
+		}
+		return rsa.VerifyPKCS1v15(k, alg.hash, digest, signature) == nil
+
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if alg.rsa || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest, r, s)
+
+	default:
+		return false
+	}
+}
+
+// hasAudience check aud claim, it is a string or an array of string
+func hasAudience(claim interface{}, audience string) bool {
+	switch v := claim.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, aud := range v {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// numericDate convert seconds since epoch claim into time
+func numericDate(claim interface{}) (time.Time, bool) {
+	seconds, ok := claim.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
@@ -0,0 +1,252 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/http/middleware"
+)
+
+const (
+	testIssuer   = "https://id.example.com"
+	testAudience = "url-shortener"
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   b64(key.X.FillBytes(make([]byte, 32))),
+		"y":   b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	assert.NoError(t, err)
+	return data
+}
+
+// sign make a jwt, key is *rsa.PrivateKey for RS256 or *ecdsa.PrivateKey for ES256
+func sign(t *testing.T, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	alg := "RS256"
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		alg = "ES256"
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + b64(signature)
+}
+
+func claims(overrides map[string]interface{}) map[string]interface{} {
+	c := map[string]interface{}{
+		"iss":   testIssuer,
+		"aud":   []string{testAudience, "other"},
+		"sub":   "team-a",
+		"jti":   "token-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "openid urlshortener:create urlshortener:read",
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(c, k)
+			continue
+		}
+		c[k] = v
+	}
+	return c
+}
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(file, jwksJSON(t, rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey)), 0o600))
+
+	a, err := middleware.NewJWTAuthenticator(context.Background(), middleware.JWTConfig{
+		JWKSFile:    file,
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		ScopePrefix: "urlshortener:",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name       string
+		credential string
+		principal  *auth.Principal
+		err        error
+	}{
+		{
+			name:       "RS256",
+			credential: sign(t, "rsa-1", rsaKey, claims(nil)),
+			principal: &auth.Principal{
+				ID:     "team-a",
+				KeyID:  "token-1",
+				Method: middleware.MethodJWT,
+				Scopes: []auth.Scope{auth.ScopeCreate, auth.ScopeRead},
+			},
+		},
+		{
+			name:       "ES256",
+			credential: sign(t, "ec-1", ecKey, claims(map[string]interface{}{"jti": nil, "aud": testAudience})),
+			principal: &auth.Principal{
+				ID:     "team-a",
+				Method: middleware.MethodJWT,
+				Scopes: []auth.Scope{auth.ScopeCreate, auth.ScopeRead},
+			},
+		},
+		{
+			name:       "NotJWT",
+			credential: "usk_Xa8LmQ2pRt0z_secret",
+		},
+		{
+			name:       "WrongSigner",
+			credential: sign(t, "rsa-1", otherKey, claims(nil)),
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "UnknownKid",
+			credential: sign(t, "rsa-2", rsaKey, claims(nil)),
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "Expired",
+			credential: sign(t, "rsa-1", rsaKey, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "MissingExp",
+			credential: sign(t, "rsa-1", rsaKey, claims(map[string]interface{}{"exp": nil})),
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "WrongIssuer",
+			credential: sign(t, "rsa-1", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "WrongAudience",
+			credential: sign(t, "rsa-1", rsaKey, claims(map[string]interface{}{"aud": "other"})),
+			err:        errors.ErrUnauthorized,
+		},
+		{
+			name:       "NoneAlgorithm",
+			credential: b64([]byte(`{"alg":"none","kid":"rsa-1"}`)) + "." + b64([]byte(`{"sub":"team-a"}`)) + ".",
+			err:        errors.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(context.Background(), tt.credential)
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "Authenticate() error = %v, expected error %v", err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.principal, p)
+		})
+	}
+}
+
+func TestJWTAuthenticator_KeyRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	var rotated, fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if atomic.LoadInt32(&rotated) == 1 {
+			_, _ = w.Write(jwksJSON(t, rsaJWK("new", newKey)))
+			return
+		}
+		_, _ = w.Write(jwksJSON(t, rsaJWK("old", oldKey)))
+	}))
+	defer server.Close()
+
+	a, err := middleware.NewJWTAuthenticator(context.Background(), middleware.JWTConfig{
+		JWKSURL:  server.URL,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = a.Authenticate(context.Background(), sign(t, "old", oldKey, claims(nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// unknown kid reload the key set from identity provider
+	atomic.StoreInt32(&rotated, 1)
+	p, err := a.Authenticate(context.Background(), sign(t, "new", newKey, claims(nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", p.ID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// reload is limited, another unknown kid does not fetch again
+	_, err = a.Authenticate(context.Background(), sign(t, "unknown", newKey, claims(nil)))
+	assert.True(t, errors.Is(err, errors.ErrUnauthorized))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/ratelimit"
+)
+
+const (
+	// HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset and HeaderRateLimitPolicy
+	// are rate limit headers of IETF draft, reset is in seconds
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// NewRateLimitMiddleware limit requests by policy, named policies count requests separately.
+// Authenticated request is limited by its credential, otherwise by client ip from echo IPExtractor,
+// so the auth middleware must run before it. A failed limiter allows the request.
+func NewRateLimitMiddleware(limiter ratelimit.Limiter, name string, policy ratelimit.Policy) echo.MiddlewareFunc {
+	policyHeader := strconv.Itoa(policy.Rate) + ";w=" + strconv.Itoa(int(math.Ceil(policy.Period.Seconds())))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			result, err := limiter.Allow(ctx, name+":"+rateLimitKey(c), policy)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msgf("failed to check rate limit %v", name)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, seconds(result.ResetAfter))
+			header.Set(HeaderRateLimitPolicy, policyHeader)
+
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, seconds(result.RetryAfter))
+				return errors.Wrapf(errors.ErrTooManyRequests, "rate limit %v is exceeded, retry after %v", name, result.RetryAfter)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// rateLimitKey return api key id, principal id or client ip of request
+func rateLimitKey(c echo.Context) string {
+	if p, ok := auth.FromContext(c.Request().Context()); ok {
+		if p.Method == auth.MethodAPIKey && p.KeyID != "" {
+			return "apikey:" + p.KeyID
+		}
+		return "owner:" + p.ID
+	}
+	return "ip:" + c.RealIP()
+}
+
+// seconds round duration up to whole seconds
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"url-shortener/pkg/auth"
+	ph "url-shortener/pkg/http"
+	"url-shortener/pkg/http/middleware"
+	"url-shortener/pkg/ratelimit"
+	"url-shortener/pkg/ratelimit/mocks"
+)
+
+func TestNewRateLimitMiddleware(t *testing.T) {
+	policy := ratelimit.Policy{Rate: 60, Period: time.Minute, Burst: 10}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		principal  *auth.Principal
+		key        string
+		result     ratelimit.Result
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{
+			name:       "Allowed",
+			remoteAddr: "203.0.113.7:5555",
+			key:        "redirect:ip:203.0.113.7",
+			result:     ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 1500 * time.Millisecond},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "TrustedProxy",
+			remoteAddr: "10.1.2.3:5555",
+			forwarded:  "198.51.100.1, 10.1.2.4",
+			key:        "redirect:ip:198.51.100.1",
+			result:     ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "UntrustedProxy",
+			remoteAddr: "203.0.113.7:5555",
+			forwarded:  "198.51.100.1",
+			key:        "redirect:ip:203.0.113.7",
+			result:     ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "APIKey",
+			remoteAddr: "203.0.113.7:5555",
+			principal:  &auth.Principal{ID: "team-a", KeyID: "Xa8LmQ2pRt0z", Method: auth.MethodAPIKey},
+			key:        "redirect:apikey:Xa8LmQ2pRt0z",
+			result:     ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "Exceeded",
+			remoteAddr: "203.0.113.7:5555",
+			key:        "redirect:ip:203.0.113.7",
+			result:     ratelimit.Result{Limit: 10, ResetAfter: 10 * time.Second, RetryAfter: 200 * time.Millisecond},
+			status:     http.StatusTooManyRequests,
+			retryAfter: "1",
+		},
+		{
+			name:       "LimiterDown",
+			remoteAddr: "203.0.113.7:5555",
+			key:        "redirect:ip:203.0.113.7",
+			err:        errors.New("connection refused"),
+			status:     http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := mocks.NewLimiter(t)
+			limiter.EXPECT().Allow(mock.Anything, tt.key, policy).Return(tt.result, tt.err)
+
+			e := ph.NewEcho(ph.Config{Mode: "release", TrustedProxies: []string{"10.1.0.0/16"}})
+			e.GET("/:url", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if tt.principal != nil {
+						c.SetRequest(c.Request().WithContext(auth.NewContext(c.Request().Context(), tt.principal)))
+					}
+					return next(c)
+				}
+			}, middleware.NewRateLimitMiddleware(limiter, "redirect", policy))
+
+			req := httptest.NewRequest(http.MethodGet, "/K2MY8LEp", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set(echo.HeaderXForwardedFor, tt.forwarded)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.retryAfter, rec.Header().Get(echo.HeaderRetryAfter))
+			if tt.err == nil {
+				assert.Equal(t, "10", rec.Header().Get(middleware.HeaderRateLimitLimit))
+				assert.Equal(t, "60;w=60", rec.Header().Get(middleware.HeaderRateLimitPolicy))
+			}
+		})
+	}
+}
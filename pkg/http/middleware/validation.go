@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/http/openapi"
+)
+
+// NewValidationMiddleware reject request whose parameters, json or form body do not match operation of the document
+// with ErrInvalidInput, the invalid field is in a BadRequest detail. An empty body is left to the handler
+func NewValidationMiddleware(doc *openapi.Document, op *openapi.Operation) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, param := range op.Parameters {
+				if err := doc.ValidateParameter(param, parameterValues(c, param)); err != nil {
+					return invalidRequest(err)
+				}
+			}
+
+			if op.RequestBody == nil {
+				return next(c)
+			}
+
+			contentType := c.Request().Header.Get(echo.HeaderContentType)
+			switch {
+			case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+				content, ok := op.RequestBody.Content[echo.MIMEApplicationJSON]
+				if !ok {
+					break
+				}
+
+				body, err := io.ReadAll(c.Request().Body)
+				if err != nil {
+					return errors.Wrapf(errors.ErrInvalidInput, "failed to read request body %v", err)
+				}
+				c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+				if len(bytes.TrimSpace(body)) == 0 {
+					break
+				}
+
+				var value interface{}
+				if err := json.Unmarshal(body, &value); err != nil {
+					return errors.Wrapf(errors.ErrInvalidInput, "request body is not json %v", err)
+				}
+				if err := doc.Validate(content.Schema, value); err != nil {
+					return invalidRequest(err)
+				}
+			case strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+				content, ok := op.RequestBody.Content[echo.MIMEApplicationForm]
+				if !ok {
+					break
+				}
+
+				form, err := c.FormParams()
+				if err != nil {
+					return errors.Wrapf(errors.ErrInvalidInput, "failed to parse form %v", err)
+				}
+				if err := doc.ValidateForm(content.Schema, form); err != nil {
+					return invalidRequest(err)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// parameterValues return raw values of path, query or header parameter
+func parameterValues(c echo.Context, param *openapi.Parameter) []string {
+	switch param.In {
+	case "path":
+		return []string{c.Param(param.Name)}
+	case "query":
+		return c.QueryParams()[param.Name]
+	case "header":
+		return c.Request().Header.Values(param.Name)
+	}
+	return nil
+}
+
+// invalidRequest return ErrInvalidInput of validation error, its field is json path without root
+func invalidRequest(err error) error {
+	var v *openapi.ValidationError
+	if !errors.As(err, &v) {
+		return errors.Wrapf(errors.ErrInvalidInput, "request does not match schema %v", err)
+	}
+
+	return errors.Wrapf(
+		errors.ErrInvalidInput.WithDetails(errors.Detail{
+			Type:     errors.TypeBadRequest,
+			Metadata: map[string]interface{}{strings.TrimPrefix(v.Path, "$."): v.Message},
+		}),
+		"request does not match schema %v", err,
+	)
+}
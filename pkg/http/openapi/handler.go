@@ -0,0 +1,33 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed swagger.html
+var swaggerHTML string
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(swaggerHTML))
+
+// Handler serve the document as json
+func Handler(doc *Document) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, doc)
+	}
+}
+
+// UIHandler serve Swagger UI page which load document from specURL
+func UIHandler(title string, specURL string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		c.Response().WriteHeader(http.StatusOK)
+		return swaggerTemplate.Execute(c.Response(), map[string]string{
+			"Title":   title,
+			"SpecURL": specURL,
+		})
+	}
+}
@@ -0,0 +1,463 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Version is the OpenAPI specification version
+	Version = "3.0.3"
+
+	mimeJSON = "application/json"
+	mimeForm = "application/x-www-form-urlencoded"
+)
+
+// Document is OpenAPI 3 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info is document metadata
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is api server
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem is operations of a path
+type PathItem map[string]*Operation
+
+// Operation is an api operation
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+// SecurityRequirement is security scheme names and their required scopes,
+// an operation accept any one of its requirements
+type SecurityRequirement map[string][]string
+
+// Parameter is path, query or header parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is operation request body
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is operation response
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header is response header
+type Header struct {
+	Schema *Schema `json:"schema"`
+}
+
+// MediaType is content of media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components is reusable objects
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is how the api is authenticated, e.g. apiKey header or http bearer
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`   // Name is header name of apiKey
+	In          string `json:"in,omitempty"`     // In is location of apiKey, e.g. header
+	Scheme      string `json:"scheme,omitempty"` // Scheme is http auth scheme, e.g. bearer
+}
+
+// Schema is JSON schema subset of OpenAPI
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+}
+
+// Spec describe an operation, the document is generated from request and response types
+type Spec struct {
+	Method      string
+	Path        string // Path is echo route path, e.g. /api/v1/urls/:id
+	OperationID string
+	Summary     string
+	Tags        []string
+
+	// Request is request type, fields with json tag are body,
+	// param tag are path parameters and query tag are query parameters
+	Request interface{}
+
+	// Responses is response by http status
+	Responses map[int]ResponseSpec
+
+	// Security is the requirements of operation, nil means public
+	Security []SecurityRequirement
+}
+
+// ResponseSpec describe a response
+type ResponseSpec struct {
+	Description string
+	Body        interface{} // Body is response body type, nil means no body
+	Headers     []string    // Headers is response header names
+}
+
+// New make document from specs
+func New(info Info, servers []Server, errorBody interface{}, specs ...Spec) *Document {
+	g := &generator{
+		schemas: make(map[string]*Schema),
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	for _, spec := range specs {
+		path := Path(spec.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(spec.Method)] = g.operation(spec, errorBody)
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+var echoParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// Path convert echo route path into OpenAPI path, /:url into /{url}
+func Path(echoPath string) string {
+	p := strings.ReplaceAll(echoPath, `\:`, "\x00")
+	p = echoParam.ReplaceAllString(p, "{$1}")
+	return strings.ReplaceAll(p, "\x00", ":")
+}
+
+// Find find operation by method and echo route path
+func (doc *Document) Find(method string, echoPath string) *Operation {
+	item, ok := doc.Paths[Path(echoPath)]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// Resolve return the schema which ref point to
+func (doc *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// generator generate schema from go type
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func (g *generator) operation(spec Spec, errorBody interface{}) *Operation {
+	op := &Operation{
+		OperationID: spec.OperationID,
+		Summary:     spec.Summary,
+		Tags:        spec.Tags,
+		Responses:   make(map[string]*Response),
+		Security:    spec.Security,
+	}
+
+	if spec.Request != nil {
+		op.Parameters, op.RequestBody = g.request(reflect.TypeOf(spec.Request))
+	}
+
+	statuses := make([]int, 0, len(spec.Responses))
+	for status := range spec.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	for _, status := range statuses {
+		r := spec.Responses[status]
+		resp := &Response{Description: r.Description}
+		if resp.Description == "" {
+			resp.Description = http.StatusText(status)
+		}
+		if r.Body != nil {
+			resp.Content = map[string]*MediaType{
+				mimeJSON: {Schema: g.schema(reflect.TypeOf(r.Body))},
+			}
+		}
+		for _, h := range r.Headers {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]*Header)
+			}
+			resp.Headers[h] = &Header{Schema: &Schema{Type: "string"}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+
+	if errorBody != nil {
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content: map[string]*MediaType{
+				mimeJSON: {Schema: g.schema(reflect.TypeOf(errorBody))},
+			},
+		}
+	}
+
+	return op
+}
+
+// request split request struct fields into parameters and body, body of form fields is form urlencoded
+func (g *generator) request(t reflect.Type) ([]*Parameter, *RequestBody) {
+	t = deref(t)
+	if t.Kind() != reflect.Struct {
+		return nil, &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{mimeJSON: {Schema: g.schema(t)}},
+		}
+	}
+
+	var (
+		params  []*Parameter
+		hasBody bool
+		form    *Schema
+	)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		for _, in := range []string{"param", "query", "header"} {
+			name := strings.Split(f.Tag.Get(in), ",")[0]
+			if name == "" {
+				continue
+			}
+
+			location := in
+			if in == "param" {
+				location = "path"
+			}
+			params = append(params, &Parameter{
+				Name:     name,
+				In:       location,
+				Required: location == "path" || hasRule(f, "required"),
+				Schema:   g.field(f),
+			})
+		}
+
+		if name := jsonName(f); name != "" {
+			hasBody = true
+		}
+
+		if name := strings.Split(f.Tag.Get("form"), ",")[0]; name != "" && name != "-" {
+			if form == nil {
+				form = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			}
+			form.Properties[name] = g.field(f)
+			if hasRule(f, "required") {
+				form.Required = append(form.Required, name)
+			}
+		}
+	}
+
+	if form != nil {
+		return params, &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{mimeForm: {Schema: form}},
+		}
+	}
+
+	if !hasBody {
+		return params, nil
+	}
+
+	return params, &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{mimeJSON: {Schema: g.schema(t)}},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema generate schema of type, named struct is registered into components
+func (g *generator) schema(t reflect.Type) *Schema {
+	nullable := t.Kind() == reflect.Ptr
+	t = deref(t)
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return g.object(t)
+		}
+		if _, ok := g.schemas[name]; !ok {
+			// register before generate fields, so recursive type stop here
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case t.Kind() == reflect.String:
+		s = &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		// interface{} accept any value
+		s = &Schema{}
+	}
+
+	s.Nullable = nullable
+	return s
+}
+
+// object generate schema of struct fields with json tag
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			embedded := g.object(deref(f.Type))
+			for name, p := range embedded.Properties {
+				s.Properties[name] = p
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+
+		s.Properties[name] = g.field(f)
+
+		if hasRule(f, "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	sort.Strings(s.Required)
+	return s
+}
+
+// field generate schema of struct field with its validate rules
+func (g *generator) field(f reflect.StructField) *Schema {
+	s := g.schema(f.Type)
+	if hasRule(f, "http_url") || hasRule(f, "url") {
+		s.Format = "uri"
+	}
+	if max := ruleValue(f, "max"); max != "" && s.Type == "array" {
+		if n, err := strconv.Atoi(max); err == nil {
+			s.MaxItems = &n
+		}
+	}
+	if oneof := ruleValue(f, "oneof"); oneof != "" {
+		s.Enum = strings.Fields(oneof)
+	}
+	return s
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonName return json field name, empty means not in json
+func jsonName(f reflect.StructField) string {
+	tag, ok := f.Tag.Lookup("json")
+	if !ok || tag == "-" {
+		return ""
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// hasRule check go-playground validate tag has rule
+func hasRule(f reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(f.Tag.Get("validate"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleValue return go-playground validate rule value, e.g. max=100
+func ruleValue(f reflect.StructField, rule string) string {
+	for _, r := range strings.Split(f.Tag.Get("validate"), ",") {
+		if strings.HasPrefix(r, rule+"=") {
+			return strings.TrimPrefix(r, rule+"=")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ValidationError is the first value which does not match the schema, Path is JSON path of it, e.g. $.tags[0]
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// invalid return ValidationError of path
+func invalid(path string, format string, args ...interface{}) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate check decoded json value match the schema, the error is *ValidationError
+// value is the result of json.Unmarshal into interface{}
+func (doc *Document) Validate(schema *Schema, value interface{}) error {
+	return doc.validate("$", schema, value)
+}
+
+// ValidateParameter check raw values of path, query or header parameter match its schema, no value means it is missing.
+// Values are converted into the json type of the schema first, so "10" is an integer
+func (doc *Document) ValidateParameter(param *Parameter, values []string) error {
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		if param.Required {
+			return invalid(param.Name, "is required")
+		}
+		return nil
+	}
+
+	schema := doc.Resolve(param.Schema)
+	if schema != nil && schema.Type == "array" {
+		items := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			items = append(items, parseValue(doc.Resolve(schema.Items), v))
+		}
+		return doc.validate(param.Name, schema, items)
+	}
+
+	return doc.validate(param.Name, schema, parseValue(schema, values[0]))
+}
+
+// ValidateForm check form values match the object schema of form urlencoded body
+func (doc *Document) ValidateForm(schema *Schema, form url.Values) error {
+	schema = doc.Resolve(schema)
+	if schema == nil {
+		return invalid("$", "schema not found")
+	}
+
+	obj := make(map[string]interface{}, len(form))
+	for name, values := range form {
+		if len(values) == 0 {
+			continue
+		}
+		obj[name] = parseValue(doc.Resolve(schema.Properties[name]), values[0])
+	}
+
+	return doc.validate("$", schema, obj)
+}
+
+// parseValue convert raw value into the json type of schema, value which can not be converted is kept as string
+// and fail the validation
+func parseValue(schema *Schema, raw string) interface{} {
+	if schema == nil {
+		return raw
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+func (doc *Document) validate(path string, schema *Schema, value interface{}) error {
+	schema = doc.Resolve(schema)
+	if schema == nil {
+		return invalid(path, "schema not found")
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return invalid(path, "must not be null")
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return invalid(path, "must be object")
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return invalid(path+"."+name, "is required")
+			}
+		}
+
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			p, ok := schema.Properties[name]
+			if !ok {
+				p = schema.AdditionalProperties
+			}
+			if p == nil {
+				return invalid(path+"."+name, "is not defined")
+			}
+			if err := doc.validate(path+"."+name, p, obj[name]); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return invalid(path, "must be array")
+		}
+		if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+			return invalid(path, "must have at most %v items", *schema.MaxItems)
+		}
+		for i, item := range arr {
+			if err := doc.validate(fmt.Sprintf("%v[%v]", path, i), schema.Items, item); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return invalid(path, "must be string")
+		}
+		if err := validateFormat(schema.Format, s); err != nil {
+			return invalid(path, "%v", err)
+		}
+		if len(schema.Enum) != 0 && !contains(schema.Enum, s) {
+			return invalid(path, "must be one of %v", schema.Enum)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return invalid(path, "must be integer")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return invalid(path, "must be number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid(path, "must be boolean")
+		}
+	}
+
+	return nil
+}
+
+func validateFormat(format string, s string) error {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("must be date-time")
+		}
+	case "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return fmt.Errorf("must be uri")
+		}
+	}
+	return nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	// HeaderKey is request header of idempotency key
+	HeaderKey = "Idempotency-Key"
+
+	// HeaderReplayed is response header set on a replayed response
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// MaxKeyLength is the max length of idempotency key
+	MaxKeyLength = 255
+
+	ReasonKeyReused = "IDEMPOTENCY_KEY_REUSED" // ReasonKeyReused is error detail reason of key reused with another request
+	ReasonInFlight  = "IDEMPOTENCY_IN_FLIGHT"  // ReasonInFlight is error detail reason of key whose first request is running
+	Domain          = "idempotency"            // Domain is error detail domain
+)
+
+// Config idempotency key config
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// TTL is how long the first response is replayed, default 24 hours
+	TTL time.Duration `mapstructure:"ttl"`
+
+	// LockTTL is how long a running request hold its key, so a crashed request does not hold it forever, default 1 minute
+	LockTTL time.Duration `mapstructure:"lockTTL"`
+
+	// Wait is how long a duplicate request wait for the running one before it gets 409, zero does not wait
+	Wait time.Duration `mapstructure:"wait"`
+}
+
+// withDefault fill zero fields with default
+func (c Config) withDefault() Config {
+	if c.TTL <= 0 {
+		c.TTL = 24 * time.Hour
+	}
+	if c.LockTTL <= 0 {
+		c.LockTTL = time.Minute
+	}
+	return c
+}
+
+// Record is the state of an idempotency key, Fingerprint identify the request which claimed the key
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+
+	// Done is false while the first request is running, the response is only set when it is done
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keep idempotency keys and the first response of them
+//
+//go:generate mockery --name Store --with-expecter
+type Store interface {
+	// Begin claim unused key for the request of fingerprint and return nil,
+	// otherwise return the record of key without claiming it
+	Begin(ctx context.Context, key string, fingerprint string) (*Record, error)
+
+	// Complete store the response of claimed key
+	Complete(ctx context.Context, key string, record *Record) error
+
+	// Release drop claimed key, so the request can be retried with the same key
+	Release(ctx context.Context, key string) error
+}
+
+// KeyReused return ErrConflict of key reused with a different request
+func KeyReused(key string) error {
+	return errors.Wrapf(
+		errors.ErrConflict.WithDetails(errors.Detail{Reason: ReasonKeyReused, Domain: Domain}),
+		"idempotency key %v is used by a different request", key,
+	)
+}
+
+// InFlight return ErrConflict of key whose first request is still running
+func InFlight(key string) error {
+	return errors.Wrapf(
+		errors.ErrConflict.WithDetails(errors.Detail{Reason: ReasonInFlight, Domain: Domain}),
+		"request of idempotency key %v is in flight", key,
+	)
+}
@@ -0,0 +1,181 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	idempotency "url-shortener/pkg/idempotency"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+type Store_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Store) EXPECT() *Store_Expecter {
+	return &Store_Expecter{mock: &_m.Mock}
+}
+
+// Begin provides a mock function with given fields: ctx, key, fingerprint
+func (_m *Store) Begin(ctx context.Context, key string, fingerprint string) (*idempotency.Record, error) {
+	ret := _m.Called(ctx, key, fingerprint)
+
+	var r0 *idempotency.Record
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*idempotency.Record, error)); ok {
+		return rf(ctx, key, fingerprint)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *idempotency.Record); ok {
+		r0 = rf(ctx, key, fingerprint)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*idempotency.Record)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, key, fingerprint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store_Begin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Begin'
+type Store_Begin_Call struct {
+	*mock.Call
+}
+
+// Begin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - fingerprint string
+func (_e *Store_Expecter) Begin(ctx interface{}, key interface{}, fingerprint interface{}) *Store_Begin_Call {
+	return &Store_Begin_Call{Call: _e.mock.On("Begin", ctx, key, fingerprint)}
+}
+
+func (_c *Store_Begin_Call) Run(run func(ctx context.Context, key string, fingerprint string)) *Store_Begin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Store_Begin_Call) Return(_a0 *idempotency.Record, _a1 error) *Store_Begin_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Store_Begin_Call) RunAndReturn(run func(context.Context, string, string) (*idempotency.Record, error)) *Store_Begin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Complete provides a mock function with given fields: ctx, key, record
+func (_m *Store) Complete(ctx context.Context, key string, record *idempotency.Record) error {
+	ret := _m.Called(ctx, key, record)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *idempotency.Record) error); ok {
+		r0 = rf(ctx, key, record)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type Store_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - record *idempotency.Record
+func (_e *Store_Expecter) Complete(ctx interface{}, key interface{}, record interface{}) *Store_Complete_Call {
+	return &Store_Complete_Call{Call: _e.mock.On("Complete", ctx, key, record)}
+}
+
+func (_c *Store_Complete_Call) Run(run func(ctx context.Context, key string, record *idempotency.Record)) *Store_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*idempotency.Record))
+	})
+	return _c
+}
+
+func (_c *Store_Complete_Call) Return(_a0 error) *Store_Complete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Store_Complete_Call) RunAndReturn(run func(context.Context, string, *idempotency.Record) error) *Store_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Release provides a mock function with given fields: ctx, key
+func (_m *Store) Release(ctx context.Context, key string) error {
+	ret := _m.Called(ctx, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type Store_Release_Call struct {
+	*mock.Call
+}
+
+// Release is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *Store_Expecter) Release(ctx interface{}, key interface{}) *Store_Release_Call {
+	return &Store_Release_Call{Call: _e.mock.On("Release", ctx, key)}
+}
+
+func (_c *Store_Release_Call) Run(run func(ctx context.Context, key string)) *Store_Release_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Store_Release_Call) Return(_a0 error) *Store_Release_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Store_Release_Call) RunAndReturn(run func(context.Context, string) error) *Store_Release_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewStore interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewStore(t mockConstructorTestingTNewStore) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,86 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+
+	"url-shortener/pkg/errors"
+)
+
+// keyPrefix is prefix of every idempotency redis key
+const keyPrefix = "idempotency:"
+
+// beginScript return the record of key, or set the pending record when key is unused
+//
+// KEYS: key
+// ARGV: pending record, lock ttl in milliseconds
+var beginScript = redis.NewScript(`
+local record = redis.call('GET', KEYS[1])
+if record then
+	return record
+end
+
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return false
+`)
+
+var _ Store = &RedisImpl{}
+
+// RedisImpl is Store shared by all instances through redis
+type RedisImpl struct {
+	rds    *redis.Client
+	config Config
+}
+
+// NewRedisStore Store constructor, zero config fields use default
+func NewRedisStore(rds *redis.Client, config Config) *RedisImpl {
+	return &RedisImpl{
+		rds:    rds,
+		config: config.withDefault(),
+	}
+}
+
+// Begin is implementation for Store
+func (s *RedisImpl) Begin(ctx context.Context, key string, fingerprint string) (*Record, error) {
+	pending, _ := json.Marshal(&Record{Fingerprint: fingerprint})
+
+	data, err := beginScript.Run(ctx, s.rds, []string{keyPrefix + key}, pending, s.config.LockTTL.Milliseconds()).Text()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to begin idempotency key = %v err = %v", key, err)
+	}
+
+	record := &Record{}
+	if err := json.Unmarshal([]byte(data), record); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to decode idempotency key = %v err = %v", key, err)
+	}
+
+	return record, nil
+}
+
+// Complete is implementation for Store
+func (s *RedisImpl) Complete(ctx context.Context, key string, record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to encode idempotency key = %v err = %v", key, err)
+	}
+
+	if err := s.rds.Set(ctx, keyPrefix+key, data, s.config.TTL).Err(); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to complete idempotency key = %v err = %v", key, err)
+	}
+
+	return nil
+}
+
+// Release is implementation for Store
+func (s *RedisImpl) Release(ctx context.Context, key string) error {
+	if err := s.rds.Del(ctx, keyPrefix+key).Err(); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to release idempotency key = %v err = %v", key, err)
+	}
+
+	return nil
+}
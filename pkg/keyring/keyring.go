@@ -0,0 +1,264 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	keySize = 32 // AES-256
+)
+
+// Config keyring config
+type Config struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	KeyringPath string `mapstructure:"keyringPath"`
+}
+
+// File define keyring file format
+//
+//	{
+//	  "primary": "2023-07",
+//	  "keys": [
+//	    {"id": "2023-07", "secret": "<base64 encoded 32 bytes>"}
+//	  ],
+//	  "hashKey": "<base64 encoded 32 bytes>"
+//	}
+//
+// hashKey keys Hash and must not change when primary rotates, so it is required in the file.
+type File struct {
+	Primary string    `json:"primary"`
+	Keys    []FileKey `json:"keys"`
+	HashKey string    `json:"hashKey,omitempty"`
+}
+
+// FileKey define a key entry in keyring file
+type FileKey struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// Keyring hold key encryption keys by key id.
+// The primary key is used to seal new data, the others only open old data.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+	hashKey []byte
+}
+
+// Load read keyring file from path
+func Load(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to read keyring file %v err = %v", path, err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to decode keyring file %v err = %v", path, err)
+	}
+
+	keys := make(map[string][]byte, len(f.Keys))
+	for _, k := range f.Keys {
+		secret, err := base64.StdEncoding.DecodeString(k.Secret)
+		if err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "key id = %v secret is not base64 err = %v", k.ID, err)
+		}
+		keys[k.ID] = secret
+	}
+
+	k, err := New(f.Primary, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// a hash key derived from the primary key would change every hash at rotation
+	hashKey, err := base64.StdEncoding.DecodeString(f.HashKey)
+	if err != nil || len(hashKey) != keySize {
+		return nil, errors.Wrapf(errors.ErrInternal, "hash key must be base64 encoded %v bytes", keySize)
+	}
+	k.hashKey = hashKey
+
+	return k, nil
+}
+
+// New keyring constructor
+func New(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, errors.Wrapf(errors.ErrInternal, "primary key id = %v not in keyring", primary)
+	}
+
+	k := &Keyring{
+		primary: primary,
+		keys:    make(map[string]cipher.AEAD, len(keys)),
+		hashKey: deriveKey(keys[primary], "hash"),
+	}
+	for id, secret := range keys {
+		if id == "" {
+			return nil, errors.Wrap(errors.ErrInternal, "keyring key id is empty")
+		}
+
+		aead, err := newAEAD(secret)
+		if err != nil {
+			return nil, errors.Wrapf(errors.ErrInternal, "key id = %v is invalid err = %v", id, err)
+		}
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+// PrimaryKeyID return the key id used to seal new data
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// Hash return hex hmac-sha256 of data, equal data has equal hash but the hash can not be guessed without the keyring
+func (k *Keyring) Hash(data []byte) string {
+	mac := hmac.New(sha256.New, k.hashKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Seal encrypt plaintext with envelope encryption.
+// A random data key encrypts the plaintext, then the primary key encrypts the data key.
+// additionalData is authenticated but not encrypted, it binds the ciphertext to its owner.
+// The returned ciphertext is base64 encoded.
+func (k *Keyring) Seal(plaintext, additionalData []byte) (keyID string, ciphertext string, err error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", "", errors.Wrapf(errors.ErrInternal, "failed to generate data key err = %v", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", "", errors.Wrapf(errors.ErrInternal, "failed to create data cipher err = %v", err)
+	}
+
+	wrapped, err := seal(k.keys[k.primary], dataKey, additionalData)
+	if err != nil {
+		return "", "", err
+	}
+
+	sealed, err := seal(dataAEAD, plaintext, additionalData)
+	if err != nil {
+		return "", "", err
+	}
+
+	return k.primary, base64.StdEncoding.EncodeToString(append(wrapped, sealed...)), nil
+}
+
+// Open decrypt ciphertext which sealed by Seal
+func (k *Keyring) Open(keyID string, ciphertext string, additionalData []byte) ([]byte, error) {
+	dataKey, sealed, err := k.unwrap(keyID, ciphertext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to create data cipher err = %v", err)
+	}
+
+	return open(dataAEAD, sealed, additionalData)
+}
+
+// Rewrap re-encrypt the data key of ciphertext with the primary key.
+// The payload is untouched, so rotation never handle the plaintext.
+func (k *Keyring) Rewrap(keyID string, ciphertext string, additionalData []byte) (newKeyID string, newCiphertext string, err error) {
+	if keyID == k.primary {
+		return keyID, ciphertext, nil
+	}
+
+	dataKey, sealed, err := k.unwrap(keyID, ciphertext, additionalData)
+	if err != nil {
+		return "", "", err
+	}
+
+	wrapped, err := seal(k.keys[k.primary], dataKey, additionalData)
+	if err != nil {
+		return "", "", err
+	}
+
+	return k.primary, base64.StdEncoding.EncodeToString(append(wrapped, sealed...)), nil
+}
+
+// unwrap decode ciphertext and decrypt the data key
+func (k *Keyring) unwrap(keyID string, ciphertext string, additionalData []byte) (dataKey []byte, sealed []byte, err error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, nil, errors.Wrapf(errors.ErrInternal, "key id = %v not in keyring", keyID)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, nil, errors.Wrapf(errors.ErrInternal, "ciphertext is not base64 err = %v", err)
+	}
+
+	// wrapped data key is nonce + key + tag
+	wrappedSize := aead.NonceSize() + keySize + aead.Overhead()
+	if len(data) < wrappedSize {
+		return nil, nil, errors.Wrap(errors.ErrInternal, "ciphertext is too short")
+	}
+
+	dataKey, err = open(aead, data[:wrappedSize], additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dataKey, data[wrappedSize:], nil
+}
+
+// deriveKey derive a sub key of secret for purpose, so the secret is never used twice
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, errors.Wrapf(errors.ErrInternal, "key size must be %v bytes", keySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypt plaintext and prepend random nonce
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to generate nonce err = %v", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open split nonce and decrypt
+func open(aead cipher.AEAD, data, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.Wrap(errors.ErrInternal, "ciphertext is too short")
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to decrypt err = %v", err)
+	}
+
+	return plaintext, nil
+}
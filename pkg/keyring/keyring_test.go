@@ -0,0 +1,158 @@
+package keyring_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"url-shortener/pkg/keyring"
+)
+
+func TestKeyring_SealOpen(t *testing.T) {
+	k, err := keyring.New("k1", map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		plaintext string
+		sealAD    string
+		openAD    string
+		wantErr   bool
+	}{
+		{
+			name:      "Success",
+			plaintext: "https://www.dcard.tw/f?token=secret",
+			sealAD:    "6Xme5Xwp",
+			openAD:    "6Xme5Xwp",
+		},
+		{
+			name:      "AdditionalDataMismatch",
+			plaintext: "https://www.dcard.tw/f?token=secret",
+			sealAD:    "6Xme5Xwp",
+			openAD:    "K2MY8LEp",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyID, ciphertext, err := k.Seal([]byte(tt.plaintext), []byte(tt.sealAD))
+			assert.NoError(t, err)
+			assert.Equal(t, "k1", keyID)
+			assert.NotContains(t, ciphertext, tt.plaintext)
+
+			actual, err := k.Open(keyID, ciphertext, []byte(tt.openAD))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.plaintext, string(actual))
+		})
+	}
+}
+
+func TestKeyring_Rewrap(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+	ad := []byte("6Xme5Xwp")
+	plaintext := "https://www.dcard.tw/f"
+
+	before, err := keyring.New("k1", map[string][]byte{"k1": oldKey})
+	assert.NoError(t, err)
+
+	keyID, ciphertext, err := before.Seal([]byte(plaintext), ad)
+	assert.NoError(t, err)
+
+	after, err := keyring.New("k2", map[string][]byte{"k1": oldKey, "k2": newKey})
+	assert.NoError(t, err)
+
+	newKeyID, newCiphertext, err := after.Rewrap(keyID, ciphertext, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, "k2", newKeyID)
+
+	// old key is no longer required after rewrap
+	rotated, err := keyring.New("k2", map[string][]byte{"k2": newKey})
+	assert.NoError(t, err)
+
+	actual, err := rotated.Open(newKeyID, newCiphertext, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, string(actual))
+}
+
+func TestKeyring_Hash(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+	data := []byte("https://www.dcard.tw/f")
+
+	k1, err := keyring.New("k1", map[string][]byte{"k1": oldKey})
+	assert.NoError(t, err)
+	k2, err := keyring.New("k2", map[string][]byte{"k2": newKey})
+	assert.NoError(t, err)
+
+	assert.Equal(t, k1.Hash(data), k1.Hash(data))
+	assert.NotEqual(t, k1.Hash(data), k1.Hash([]byte("https://www.dcard.tw/f/sale")))
+	assert.NotEqual(t, k1.Hash(data), k2.Hash(data))
+
+	// plain sha256 can be guessed from the url, keyed hash can not
+	sum := sha256.Sum256(data)
+	assert.NotEqual(t, hex.EncodeToString(sum[:]), k1.Hash(data))
+}
+
+func TestLoad(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "Success",
+			content: `{"primary":"k1","keys":[{"id":"k1","secret":"` + secret + `"}],"hashKey":"` + secret + `"}`,
+		},
+		{
+			name:    "PrimaryNotFound",
+			content: `{"primary":"k2","keys":[{"id":"k1","secret":"` + secret + `"}],"hashKey":"` + secret + `"}`,
+			wantErr: true,
+		},
+		{
+			name:    "MissingHashKey",
+			content: `{"primary":"k1","keys":[{"id":"k1","secret":"` + secret + `"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidHashKey",
+			content: `{"primary":"k1","keys":[{"id":"k1","secret":"` + secret + `"}],"hashKey":"c2hvcnQ="}`,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidKeySize",
+			content: `{"primary":"k1","keys":[{"id":"k1","secret":"c2hvcnQ="}],"hashKey":"` + secret + `"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keyring.json")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			k, err := keyring.Load(path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "k1", k.PrimaryKeyID())
+		})
+	}
+}
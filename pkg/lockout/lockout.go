@@ -0,0 +1,75 @@
+package lockout
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	KindLink   = "link"   // KindLink is failures of one link from all clients
+	KindClient = "client" // KindClient is failures of one client on all links
+
+	// ReasonLockedOut is errors.Detail reason of too many failed attempts
+	ReasonLockedOut = "LOCKED_OUT"
+
+	// Domain is errors.Detail domain of lockout
+	Domain = "lockout"
+)
+
+// Config define how many failed attempts are allowed in a window
+type Config struct {
+	// Window is how long a failure is counted from the first failure, default 15 minutes
+	Window time.Duration `mapstructure:"window"`
+
+	// PerLink is failures allowed of a link, default 10
+	PerLink int64 `mapstructure:"perLink"`
+
+	// PerClient is failures allowed of a client ip or IPv6 /64, default 20
+	PerClient int64 `mapstructure:"perClient"`
+}
+
+// withDefault fill zero fields with default
+func (c Config) withDefault() Config {
+	if c.Window <= 0 {
+		c.Window = 15 * time.Minute
+	}
+	if c.PerLink <= 0 {
+		c.PerLink = 10
+	}
+	if c.PerClient <= 0 {
+		c.PerClient = 20
+	}
+	return c
+}
+
+// Lockout count failed attempts, e.g. wrong passwords, against the link and the client.
+// An attempt is counted before it is verified, so concurrent attempts never exceed the limits,
+// then it is taken back when it succeeds
+//
+//go:generate mockery --name Lockout --with-expecter
+type Lockout interface {
+	// Attempt count an attempt against both link and client,
+	// return ErrTooManyRequests with ReasonLockedOut detail without counting when link or client has too many failures
+	Attempt(ctx context.Context, link string, client string) error
+
+	// Succeed take back the attempt of link and client, it is not a failure
+	Succeed(ctx context.Context, link string, client string) error
+}
+
+// LockedOut return ErrTooManyRequests of kind with when it is unlocked in detail metadata
+func LockedOut(kind string, retryAfter time.Duration) error {
+	return errors.Wrapf(
+		errors.ErrTooManyRequests.WithDetails(errors.Detail{
+			Reason: ReasonLockedOut,
+			Domain: Domain,
+			Metadata: map[string]interface{}{
+				"kind":       kind,
+				"retryAfter": int64(math.Ceil(retryAfter.Seconds())),
+			},
+		}),
+		"%v has too many failed attempts, retry after %v", kind, retryAfter,
+	)
+}
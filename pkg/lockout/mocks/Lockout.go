@@ -0,0 +1,125 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Lockout is an autogenerated mock type for the Lockout type
+type Lockout struct {
+	mock.Mock
+}
+
+type Lockout_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Lockout) EXPECT() *Lockout_Expecter {
+	return &Lockout_Expecter{mock: &_m.Mock}
+}
+
+// Attempt provides a mock function with given fields: ctx, link, client
+func (_m *Lockout) Attempt(ctx context.Context, link string, client string) error {
+	ret := _m.Called(ctx, link, client)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, link, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Lockout_Attempt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Attempt'
+type Lockout_Attempt_Call struct {
+	*mock.Call
+}
+
+// Attempt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link string
+//   - client string
+func (_e *Lockout_Expecter) Attempt(ctx interface{}, link interface{}, client interface{}) *Lockout_Attempt_Call {
+	return &Lockout_Attempt_Call{Call: _e.mock.On("Attempt", ctx, link, client)}
+}
+
+func (_c *Lockout_Attempt_Call) Run(run func(ctx context.Context, link string, client string)) *Lockout_Attempt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Lockout_Attempt_Call) Return(_a0 error) *Lockout_Attempt_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Lockout_Attempt_Call) RunAndReturn(run func(context.Context, string, string) error) *Lockout_Attempt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Succeed provides a mock function with given fields: ctx, link, client
+func (_m *Lockout) Succeed(ctx context.Context, link string, client string) error {
+	ret := _m.Called(ctx, link, client)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, link, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Lockout_Succeed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Succeed'
+type Lockout_Succeed_Call struct {
+	*mock.Call
+}
+
+// Succeed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link string
+//   - client string
+func (_e *Lockout_Expecter) Succeed(ctx interface{}, link interface{}, client interface{}) *Lockout_Succeed_Call {
+	return &Lockout_Succeed_Call{Call: _e.mock.On("Succeed", ctx, link, client)}
+}
+
+func (_c *Lockout_Succeed_Call) Run(run func(ctx context.Context, link string, client string)) *Lockout_Succeed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Lockout_Succeed_Call) Return(_a0 error) *Lockout_Succeed_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Lockout_Succeed_Call) RunAndReturn(run func(context.Context, string, string) error) *Lockout_Succeed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewLockout interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewLockout creates a new instance of Lockout. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewLockout(t mockConstructorTestingTNewLockout) *Lockout {
+	mock := &Lockout{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
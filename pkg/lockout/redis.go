@@ -0,0 +1,97 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"url-shortener/pkg/errors"
+)
+
+// keyPrefix is prefix of every lockout redis key
+const keyPrefix = "lockout:"
+
+// attemptScript count an attempt of every key and start the window at the first attempt,
+// nothing is counted when a key already reach its limit
+//
+// KEYS: failure counter keys
+// ARGV: window in milliseconds, then limit of every key
+// return: {0, 0} when counted, otherwise {index of the locked out key, its ttl in milliseconds}
+var attemptScript = redis.NewScript(`
+for i = 1, #KEYS do
+	local failures = tonumber(redis.call('GET', KEYS[i]) or '0')
+	if failures >= tonumber(ARGV[i + 1]) then
+		return {i, redis.call('PTTL', KEYS[i])}
+	end
+end
+for i = 1, #KEYS do
+	if redis.call('INCR', KEYS[i]) == 1 then
+		redis.call('PEXPIRE', KEYS[i], ARGV[1])
+	end
+end
+return {0, 0}
+`)
+
+// succeedScript take back an attempt of every key, the window is kept
+//
+// KEYS: failure counter keys
+var succeedScript = redis.NewScript(`
+for i = 1, #KEYS do
+	if tonumber(redis.call('GET', KEYS[i]) or '0') > 0 then
+		redis.call('DECR', KEYS[i])
+	end
+end
+return #KEYS
+`)
+
+var _ Lockout = &RedisImpl{}
+
+// RedisImpl is Lockout shared by all instances through redis
+type RedisImpl struct {
+	rds    *redis.Client
+	config Config
+}
+
+// NewRedisLockout Lockout constructor, zero config fields use default
+func NewRedisLockout(rds *redis.Client, config Config) *RedisImpl {
+	return &RedisImpl{
+		rds:    rds,
+		config: config.withDefault(),
+	}
+}
+
+// Attempt is implementation for Lockout
+func (l *RedisImpl) Attempt(ctx context.Context, link string, client string) error {
+	kinds := []string{KindLink, KindClient}
+	keys := []string{linkKey(link), clientKey(client)}
+
+	result, err := attemptScript.Run(ctx, l.rds, keys, l.config.Window.Milliseconds(), l.config.PerLink, l.config.PerClient).Int64Slice()
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to count attempt of link = %v client = %v err = %v", link, client, err)
+	}
+
+	if len(result) != 2 || result[0] < 1 || int(result[0]) > len(kinds) {
+		return nil
+	}
+
+	return LockedOut(kinds[result[0]-1], time.Duration(result[1])*time.Millisecond)
+}
+
+// Succeed is implementation for Lockout
+func (l *RedisImpl) Succeed(ctx context.Context, link string, client string) error {
+	keys := []string{linkKey(link), clientKey(client)}
+	if err := succeedScript.Run(ctx, l.rds, keys).Err(); err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to take back attempt of link = %v client = %v err = %v", link, client, err)
+	}
+
+	return nil
+}
+
+func linkKey(link string) string {
+	return keyPrefix + KindLink + ":" + link
+}
+
+func clientKey(client string) string {
+	return keyPrefix + KindClient + ":" + client
+}
@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+
+	"url-shortener/pkg/errors"
+)
+
+// Reload load blocklist files again, the current lists are kept when any file fail to load
+func (e *Engine) Reload() error {
+	domains, err := readLines(e.config.DomainBlocklist)
+	if err != nil {
+		return err
+	}
+
+	lines, err := readLines(e.config.IPBlocklist)
+	if err != nil {
+		return err
+	}
+
+	nets := make([]*net.IPNet, 0, len(lines))
+	for _, line := range lines {
+		n, err := parseNet(line)
+		if err != nil {
+			return errors.Wrapf(errors.ErrInvalidInput, "ip blocklist %v has invalid ip or CIDR %q", e.config.IPBlocklist, line)
+		}
+		nets = append(nets, n)
+	}
+
+	e.mu.Lock()
+	e.domains = domainSet(domains)
+	e.nets = nets
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Watch reload blocklists when their files change until ctx is done.
+// Directories are watched instead of files, so files replaced by rename are reloaded too
+func (e *Engine) Watch(ctx context.Context) error {
+	dirs := make(map[string]bool, 2)
+	for _, file := range []string{e.config.DomainBlocklist, e.config.IPBlocklist} {
+		if file != "" {
+			dirs[filepath.Dir(file)] = true
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrapf(errors.ErrInternal, "failed to create blocklist watcher err = %v", err)
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return errors.Wrapf(errors.ErrInternal, "failed to watch blocklist dir = %v err = %v", dir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		logger := log.Ctx(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if err := e.Reload(); err != nil {
+					logger.Error().Err(err).Msgf("failed to reload url blocklists on %v", event)
+					continue
+				}
+				logger.Info().Msgf("url blocklists are reloaded on %v", event)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error().Err(err).Msg("url blocklist watcher error")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// readLines read non empty lines of file without comments, empty name has no lines
+func readLines(name string) ([]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to open blocklist %v err = %v", name, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(errors.ErrInternal, "failed to read blocklist %v err = %v", name, err)
+	}
+
+	return lines, nil
+}
+
+// parseNet parse CIDR or single ip as a full length network
+func parseNet(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, &net.ParseError{Type: "IP address", Text: s}
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
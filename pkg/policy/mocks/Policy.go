@@ -0,0 +1,80 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Policy is an autogenerated mock type for the Policy type
+type Policy struct {
+	mock.Mock
+}
+
+type Policy_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Policy) EXPECT() *Policy_Expecter {
+	return &Policy_Expecter{mock: &_m.Mock}
+}
+
+// Check provides a mock function with given fields: ctx, rawURL
+func (_m *Policy) Check(ctx context.Context, rawURL string) error {
+	ret := _m.Called(ctx, rawURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, rawURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Policy_Check_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Check'
+type Policy_Check_Call struct {
+	*mock.Call
+}
+
+// Check is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rawURL string
+func (_e *Policy_Expecter) Check(ctx interface{}, rawURL interface{}) *Policy_Check_Call {
+	return &Policy_Check_Call{Call: _e.mock.On("Check", ctx, rawURL)}
+}
+
+func (_c *Policy_Check_Call) Run(run func(ctx context.Context, rawURL string)) *Policy_Check_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Policy_Check_Call) Return(_a0 error) *Policy_Check_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Policy_Check_Call) RunAndReturn(run func(context.Context, string) error) *Policy_Check_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewPolicy interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPolicy creates a new instance of Policy. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPolicy(t mockConstructorTestingTNewPolicy) *Policy {
+	mock := &Policy{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
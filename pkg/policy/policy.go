@@ -0,0 +1,240 @@
+package policy
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	ReasonInvalidURL       = "INVALID_URL"        // ReasonInvalidURL is url which can not be parsed or has no host
+	ReasonURLTooLong       = "URL_TOO_LONG"       // ReasonURLTooLong is url longer than max length
+	ReasonSchemeNotAllowed = "SCHEME_NOT_ALLOWED" // ReasonSchemeNotAllowed is scheme not in allowlist, e.g. javascript
+	ReasonSelfLoop         = "SELF_LOOP"          // ReasonSelfLoop is url point back to our own short domain
+	ReasonShortLinkChain   = "SHORT_LINK_CHAIN"   // ReasonShortLinkChain is url of another url shortener
+	ReasonPrivateNetwork   = "PRIVATE_NETWORK"    // ReasonPrivateNetwork is url of loopback, private or link local address
+	ReasonIPBlocked        = "IP_BLOCKED"         // ReasonIPBlocked is url of ip in ip blocklist
+	ReasonDomainBlocked    = "DOMAIN_BLOCKED"     // ReasonDomainBlocked is url of domain or its subdomain in domain blocklist
+
+	Domain = "policy" // Domain is error detail domain
+)
+
+// Config destination url policy config
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Schemes is allowed url schemes, default http and https
+	Schemes []string `mapstructure:"schemes"`
+
+	// MaxLength is max bytes of url, default 2048
+	MaxLength int `mapstructure:"maxLength"`
+
+	// BlockPrivate reject url of loopback, private and link local ip or localhost
+	BlockPrivate bool `mapstructure:"blockPrivate"`
+
+	// DomainBlocklist and IPBlocklist are files of one domain, ip or CIDR per line, # start a comment.
+	// They are reloaded when changed
+	DomainBlocklist string `mapstructure:"domainBlocklist"`
+	IPBlocklist     string `mapstructure:"ipBlocklist"`
+
+	// SelfHosts is our own short domains, url to them would redirect in a loop
+	SelfHosts []string `mapstructure:"selfHosts"`
+
+	// Shorteners is domains of other url shorteners, url to them would hide the final destination
+	Shorteners []string `mapstructure:"shorteners"`
+}
+
+// Policy check destination url before it is shortened
+//
+//go:generate mockery --name Policy --with-expecter
+type Policy interface {
+	// Check return ErrInvalidInput with a detail reason when url is rejected
+	Check(ctx context.Context, rawURL string) error
+}
+
+// HostResolver report whether host is our own short domain, e.g. verified workspace domain
+type HostResolver func(ctx context.Context, host string) bool
+
+// An Option is passed to Engine constructor
+type Option interface {
+	apply(*Engine)
+}
+
+type setOwnHost struct{ resolver HostResolver }
+
+func (opt *setOwnHost) apply(e *Engine) { e.ownHost = opt.resolver }
+
+// WithOwnHost reject url to host which resolver report as our own besides SelfHosts
+func WithOwnHost(resolver HostResolver) Option {
+	return &setOwnHost{resolver: resolver}
+}
+
+var _ Policy = &Engine{}
+
+// Engine is Policy of config, blocklists are swapped on reload
+type Engine struct {
+	config     Config
+	schemes    map[string]bool
+	selfHosts  map[string]bool
+	shorteners map[string]bool
+	ownHost    HostResolver
+
+	mu      sync.RWMutex
+	domains map[string]bool
+	nets    []*net.IPNet
+}
+
+// New Engine constructor, blocklist files are loaded at once
+func New(config Config, opts ...Option) (*Engine, error) {
+	if len(config.Schemes) == 0 {
+		config.Schemes = []string{"http", "https"}
+	}
+	if config.MaxLength <= 0 {
+		config.MaxLength = 2048
+	}
+
+	e := &Engine{
+		config:     config,
+		schemes:    make(map[string]bool, len(config.Schemes)),
+		selfHosts:  domainSet(config.SelfHosts),
+		shorteners: domainSet(config.Shorteners),
+	}
+	for _, scheme := range config.Schemes {
+		e.schemes[strings.ToLower(scheme)] = true
+	}
+	for _, opt := range opts {
+		opt.apply(e)
+	}
+
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Check is implementation for Policy
+func (e *Engine) Check(ctx context.Context, rawURL string) error {
+	if len(rawURL) > e.config.MaxLength {
+		return rejected(ReasonURLTooLong, "", "url is longer than %v", e.config.MaxLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rejected(ReasonInvalidURL, "", "failed to parse url err = %v", err)
+	}
+
+	if !e.schemes[strings.ToLower(u.Scheme)] {
+		return rejected(ReasonSchemeNotAllowed, "", "scheme %q is not allowed", u.Scheme)
+	}
+
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return rejected(ReasonInvalidURL, "", "url %v has no host", rawURL)
+	}
+
+	if e.selfHosts[host] || (e.ownHost != nil && e.ownHost(ctx, host)) {
+		return rejected(ReasonSelfLoop, host, "url point to our own host %v", host)
+	}
+
+	if matchDomain(e.shorteners, host) {
+		return rejected(ReasonShortLinkChain, host, "url point to url shortener %v", host)
+	}
+
+	ip := parseHostIP(host)
+	if e.config.BlockPrivate && isPrivate(host, ip) {
+		return rejected(ReasonPrivateNetwork, host, "url point to private network %v", host)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if ip != nil {
+		for _, n := range e.nets {
+			if n.Contains(ip) {
+				return rejected(ReasonIPBlocked, host, "ip %v is blocked by %v", host, n)
+			}
+		}
+	}
+
+	if matchDomain(e.domains, host) {
+		return rejected(ReasonDomainBlocked, host, "domain %v is blocked", host)
+	}
+
+	return nil
+}
+
+// rejected return ErrInvalidInput with the reason and host in detail
+func rejected(reason string, host string, format string, args ...interface{}) error {
+	detail := errors.Detail{
+		Reason: reason,
+		Domain: Domain,
+	}
+	if host != "" {
+		detail.Metadata = map[string]interface{}{"host": host}
+	}
+
+	return errors.Wrapf(errors.ErrInvalidInput.WithDetails(detail), format, args...)
+}
+
+// normalizeHost return lower case host without the trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// domainSet return set of normalized domains
+func domainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		if domain = normalizeHost(strings.TrimSpace(domain)); domain != "" {
+			set[domain] = true
+		}
+	}
+	return set
+}
+
+// matchDomain check host or any of its parent domains is in set
+func matchDomain(set map[string]bool, host string) bool {
+	for {
+		if set[host] {
+			return true
+		}
+
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return false
+		}
+		host = host[i+1:]
+	}
+}
+
+// parseHostIP parse ip host, a single decimal number is IPv4 like browsers do, e.g. 2130706433 is 127.0.0.1
+func parseHostIP(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip
+	}
+
+	if n, err := strconv.ParseUint(host, 10, 32); err == nil {
+		return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+
+	return nil
+}
+
+// isPrivate check host is localhost or ip is not public
+func isPrivate(host string, ip net.IP) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	return ip != nil && (ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified())
+}
@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"url-shortener/pkg/errors"
+)
+
+func TestEngine_Check(t *testing.T) {
+	dir := t.TempDir()
+	domains := filepath.Join(dir, "domains.txt")
+	ips := filepath.Join(dir, "ips.txt")
+	writeFile(t, domains, "# phishing\nevil.example\n\nMalware.Example. # trailing dot\n")
+	writeFile(t, ips, "203.0.113.0/24\n2001:db8::1\n")
+
+	e, err := New(Config{
+		MaxLength:       100,
+		BlockPrivate:    true,
+		DomainBlocklist: domains,
+		IPBlocklist:     ips,
+		SelfHosts:       []string{"sho.rt"},
+		Shorteners:      []string{"bit.ly"},
+	}, WithOwnHost(func(ctx context.Context, host string) bool {
+		return host == "go.team-a.com"
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		url    string
+		reason string
+	}{
+		{url: "https://www.dcard.tw/f?tab=hot"},
+		{url: "HTTPS://WWW.DCARD.TW/"},
+		{url: "https://notevil.example/"},
+		{url: "https://8.8.8.8/"},
+		{url: "javascript:alert(1)", reason: ReasonSchemeNotAllowed},
+		{url: "ftp://files.example/a", reason: ReasonSchemeNotAllowed},
+		{url: "www.dcard.tw", reason: ReasonSchemeNotAllowed},
+		{url: "https:///path", reason: ReasonInvalidURL},
+		{url: "https://www.dcard.tw/" + strings.Repeat("a", 100), reason: ReasonURLTooLong},
+		{url: "https://sho.rt/K2MY8LEp", reason: ReasonSelfLoop},
+		{url: "https://SHO.RT./K2MY8LEp", reason: ReasonSelfLoop},
+		{url: "https://go.team-a.com/K2MY8LEp", reason: ReasonSelfLoop},
+		{url: "https://bit.ly/3xYz", reason: ReasonShortLinkChain},
+		{url: "https://j.mp.bit.ly/3xYz", reason: ReasonShortLinkChain},
+		{url: "http://localhost:8080/", reason: ReasonPrivateNetwork},
+		{url: "http://127.0.0.1/", reason: ReasonPrivateNetwork},
+		{url: "http://2130706433/", reason: ReasonPrivateNetwork},
+		{url: "http://10.1.2.3/", reason: ReasonPrivateNetwork},
+		{url: "http://169.254.169.254/latest/meta-data", reason: ReasonPrivateNetwork},
+		{url: "http://[::1]/", reason: ReasonPrivateNetwork},
+		{url: "http://[::ffff:192.168.0.1]/", reason: ReasonPrivateNetwork},
+		{url: "https://203.0.113.9/", reason: ReasonIPBlocked},
+		{url: "https://[2001:db8::1]/", reason: ReasonIPBlocked},
+		{url: "https://evil.example/login", reason: ReasonDomainBlocked},
+		{url: "https://login.evil.example/", reason: ReasonDomainBlocked},
+		{url: "https://cdn.malware.example/", reason: ReasonDomainBlocked},
+	}
+	for _, tt := range tests {
+		err := e.Check(context.Background(), tt.url)
+		if tt.reason == "" {
+			assert.NoErrorf(t, err, "Check(%v)", tt.url)
+			continue
+		}
+
+		assert.Truef(t, errors.Is(err, errors.ErrInvalidInput), "Check(%v) error = %v", tt.url, err)
+		if e := errors.TryConvert(err); assert.NotNilf(t, e, "Check(%v)", tt.url) && assert.Len(t, e.Details, 1) {
+			assert.Equalf(t, tt.reason, e.Details[0].Reason, "Check(%v)", tt.url)
+		}
+	}
+}
+
+func TestEngine_CheckAllowPrivate(t *testing.T) {
+	e, err := New(Config{Schemes: []string{"https"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, e.Check(context.Background(), "https://10.1.2.3/"))
+	assert.Error(t, e.Check(context.Background(), "http://10.1.2.3/"))
+}
+
+func TestNew_InvalidBlocklist(t *testing.T) {
+	ips := filepath.Join(t.TempDir(), "ips.txt")
+	writeFile(t, ips, "203.0.113.0/24\nnot-an-ip\n")
+
+	_, err := New(Config{IPBlocklist: ips})
+	assert.Error(t, err)
+
+	_, err = New(Config{DomainBlocklist: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+}
+
+func TestEngine_Watch(t *testing.T) {
+	domains := filepath.Join(t.TempDir(), "domains.txt")
+	writeFile(t, domains, "evil.example\n")
+
+	e, err := New(Config{DomainBlocklist: domains})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if !assert.NoError(t, e.Watch(ctx)) {
+		return
+	}
+
+	assert.NoError(t, e.Check(ctx, "https://phishing.example/"))
+
+	// replace by rename like config management tools do
+	tmp := domains + ".tmp"
+	writeFile(t, tmp, "evil.example\nphishing.example\n")
+	assert.NoError(t, os.Rename(tmp, domains))
+
+	assert.Eventually(t, func() bool {
+		return e.Check(ctx, "https://phishing.example/") != nil
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Error(t, e.Check(ctx, "https://evil.example/"))
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,172 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	quota "url-shortener/pkg/quota"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Quota is an autogenerated mock type for the Quota type
+type Quota struct {
+	mock.Mock
+}
+
+type Quota_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Quota) EXPECT() *Quota_Expecter {
+	return &Quota_Expecter{mock: &_m.Mock}
+}
+
+// Consume provides a mock function with given fields: ctx, subjects, links
+func (_m *Quota) Consume(ctx context.Context, subjects []quota.Subject, links []quota.Link) error {
+	ret := _m.Called(ctx, subjects, links)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []quota.Subject, []quota.Link) error); ok {
+		r0 = rf(ctx, subjects, links)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Quota_Consume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Consume'
+type Quota_Consume_Call struct {
+	*mock.Call
+}
+
+// Consume is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subjects []quota.Subject
+//   - links []quota.Link
+func (_e *Quota_Expecter) Consume(ctx interface{}, subjects interface{}, links interface{}) *Quota_Consume_Call {
+	return &Quota_Consume_Call{Call: _e.mock.On("Consume", ctx, subjects, links)}
+}
+
+func (_c *Quota_Consume_Call) Run(run func(ctx context.Context, subjects []quota.Subject, links []quota.Link)) *Quota_Consume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]quota.Subject), args[2].([]quota.Link))
+	})
+	return _c
+}
+
+func (_c *Quota_Consume_Call) Return(_a0 error) *Quota_Consume_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Quota_Consume_Call) RunAndReturn(run func(context.Context, []quota.Subject, []quota.Link) error) *Quota_Consume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Release provides a mock function with given fields: ctx, keys, refund
+func (_m *Quota) Release(ctx context.Context, keys []string, refund bool) {
+	_m.Called(ctx, keys, refund)
+}
+
+// Quota_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type Quota_Release_Call struct {
+	*mock.Call
+}
+
+// Release is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keys []string
+//   - refund bool
+func (_e *Quota_Expecter) Release(ctx interface{}, keys interface{}, refund interface{}) *Quota_Release_Call {
+	return &Quota_Release_Call{Call: _e.mock.On("Release", ctx, keys, refund)}
+}
+
+func (_c *Quota_Release_Call) Run(run func(ctx context.Context, keys []string, refund bool)) *Quota_Release_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *Quota_Release_Call) Return() *Quota_Release_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *Quota_Release_Call) RunAndReturn(run func(context.Context, []string, bool)) *Quota_Release_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Usage provides a mock function with given fields: ctx, subject
+func (_m *Quota) Usage(ctx context.Context, subject quota.Subject) (*quota.Usage, error) {
+	ret := _m.Called(ctx, subject)
+
+	var r0 *quota.Usage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, quota.Subject) (*quota.Usage, error)); ok {
+		return rf(ctx, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, quota.Subject) *quota.Usage); ok {
+		r0 = rf(ctx, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*quota.Usage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, quota.Subject) error); ok {
+		r1 = rf(ctx, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Quota_Usage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Usage'
+type Quota_Usage_Call struct {
+	*mock.Call
+}
+
+// Usage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject quota.Subject
+func (_e *Quota_Expecter) Usage(ctx interface{}, subject interface{}) *Quota_Usage_Call {
+	return &Quota_Usage_Call{Call: _e.mock.On("Usage", ctx, subject)}
+}
+
+func (_c *Quota_Usage_Call) Run(run func(ctx context.Context, subject quota.Subject)) *Quota_Usage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(quota.Subject))
+	})
+	return _c
+}
+
+func (_c *Quota_Usage_Call) Return(_a0 *quota.Usage, _a1 error) *Quota_Usage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Quota_Usage_Call) RunAndReturn(run func(context.Context, quota.Subject) (*quota.Usage, error)) *Quota_Usage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewQuota interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewQuota creates a new instance of Quota. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewQuota(t mockConstructorTestingTNewQuota) *Quota {
+	mock := &Quota{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,149 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"url-shortener/pkg/errors"
+)
+
+const (
+	KindAPIKey    = "apikey"    // KindAPIKey is subject of an api key
+	KindOwner     = "owner"     // KindOwner is subject of a principal without api key, e.g. jwt subject
+	KindWorkspace = "workspace" // KindWorkspace is subject of a workspace
+
+	WindowDay    = "day"    // WindowDay is links created in the current UTC day
+	WindowMonth  = "month"  // WindowMonth is links created in the current UTC month
+	WindowActive = "active" // WindowActive is links not expired and not deleted yet
+
+	// ReasonExceeded is errors.Detail reason of exceeded quota
+	ReasonExceeded = "QUOTA_EXCEEDED"
+
+	// Domain is errors.Detail domain of quota
+	Domain = "quota"
+)
+
+// Limits define quota of a subject, zero means unlimited
+type Limits struct {
+	PerDay    int64 `mapstructure:"perDay"`
+	PerMonth  int64 `mapstructure:"perMonth"`
+	MaxActive int64 `mapstructure:"maxActive"`
+}
+
+// Override define limits of one subject, e.g. apikey:Xa8LmQ2pRt0z or workspace:team-a
+type Override struct {
+	Subject string `mapstructure:"subject"`
+	Limits  `mapstructure:",squash"`
+}
+
+// Config quota config
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// APIKey, Owner and Workspace are default limits of each subject kind
+	APIKey    Limits `mapstructure:"apiKey"`
+	Owner     Limits `mapstructure:"owner"`
+	Workspace Limits `mapstructure:"workspace"`
+
+	// Overrides replace the default limits of listed subjects
+	Overrides []Override `mapstructure:"overrides"`
+}
+
+// Limits return limits of subject, override first then default of its kind
+func (c *Config) Limits(subject Subject) Limits {
+	for _, o := range c.Overrides {
+		if o.Subject == subject.String() {
+			return o.Limits
+		}
+	}
+
+	switch subject.Kind {
+	case KindAPIKey:
+		return c.APIKey
+	case KindOwner:
+		return c.Owner
+	case KindWorkspace:
+		return c.Workspace
+	default:
+		return Limits{}
+	}
+}
+
+// Subject is who the quota is charged to
+type Subject struct {
+	Kind string
+	ID   string
+}
+
+// String return kind:id
+func (s Subject) String() string {
+	return s.Kind + ":" + s.ID
+}
+
+// Link is a link charged to quota, it stays active until ExpiredAt
+type Link struct {
+	Key       string
+	ExpiredAt time.Time
+}
+
+// Counter is consumption of one window, zero Limit means unlimited,
+// ResetAt is when the window restart, or when the earliest active link expire
+type Counter struct {
+	Used    int64
+	Limit   int64
+	ResetAt time.Time
+}
+
+// Usage is consumption of a subject
+type Usage struct {
+	Subject string
+	Day     Counter
+	Month   Counter
+	Active  Counter
+}
+
+// Quota define per subject link creation quota
+//
+//go:generate mockery --name Quota --with-expecter
+type Quota interface {
+	// Consume charge links to every subject at once,
+	// ErrTooManyRequests with ReasonExceeded detail when any subject would exceed its limits and nothing is charged
+	Consume(ctx context.Context, subjects []Subject, links []Link) error
+
+	// Release remove links from active links of subjects which consumed them,
+	// refund also give back the day and month consumption, e.g. the links are failed to store
+	Release(ctx context.Context, keys []string, refund bool)
+
+	// Usage return consumption of subject
+	Usage(ctx context.Context, subject Subject) (*Usage, error)
+}
+
+// dayReset return start of the next UTC day
+func dayReset(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthReset return start of the next UTC month
+func monthReset(now time.Time) time.Time {
+	y, m, _ := now.UTC().Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// exceeded return ErrTooManyRequests with the exceeded window in detail metadata
+func exceeded(subject Subject, window string, used int64, limit int64, resetAt time.Time) error {
+	return errors.Wrapf(
+		errors.ErrTooManyRequests.WithDetails(errors.Detail{
+			Reason: ReasonExceeded,
+			Domain: Domain,
+			Metadata: map[string]interface{}{
+				"subject": subject.String(),
+				"window":  window,
+				"used":    used,
+				"limit":   limit,
+				"resetAt": resetAt.UTC().Format(time.RFC3339),
+			},
+		}),
+		"quota of %v per %v is exceeded, used %v of %v", subject, window, used, limit,
+	)
+}
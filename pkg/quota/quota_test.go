@@ -0,0 +1,65 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"url-shortener/pkg/errors"
+)
+
+func TestConfig_Limits(t *testing.T) {
+	config := &Config{
+		APIKey:    Limits{PerDay: 100},
+		Owner:     Limits{PerDay: 10},
+		Workspace: Limits{PerMonth: 1000, MaxActive: 500},
+		Overrides: []Override{
+			{Subject: "apikey:Xa8LmQ2pRt0z", Limits: Limits{PerDay: 5000}},
+		},
+	}
+
+	tests := []struct {
+		subject  Subject
+		expected Limits
+	}{
+		{subject: Subject{Kind: KindAPIKey, ID: "Xa8LmQ2pRt0z"}, expected: Limits{PerDay: 5000}},
+		{subject: Subject{Kind: KindAPIKey, ID: "xa8lmq2prt0z"}, expected: Limits{PerDay: 100}},
+		{subject: Subject{Kind: KindOwner, ID: "alice"}, expected: Limits{PerDay: 10}},
+		{subject: Subject{Kind: KindWorkspace, ID: "team-a"}, expected: Limits{PerMonth: 1000, MaxActive: 500}},
+		{subject: Subject{Kind: "unknown", ID: "x"}, expected: Limits{}},
+	}
+	for _, tt := range tests {
+		assert.Equalf(t, tt.expected, config.Limits(tt.subject), "Limits(%v)", tt.subject)
+	}
+}
+
+func Test_reset(t *testing.T) {
+	now := time.Date(2023, 12, 31, 23, 59, 0, 0, time.FixedZone("UTC+8", 8*60*60))
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), dayReset(now))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), monthReset(now))
+
+	now = time.Date(2023, 7, 17, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2023, 7, 18, 0, 0, 0, 0, time.UTC), dayReset(now))
+	assert.Equal(t, time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), monthReset(now))
+}
+
+func Test_exceeded(t *testing.T) {
+	resetAt := time.Date(2023, 7, 18, 0, 0, 0, 0, time.UTC)
+	err := exceeded(Subject{Kind: KindAPIKey, ID: "Xa8LmQ2pRt0z"}, WindowDay, 100, 100, resetAt)
+
+	assert.True(t, errors.Is(err, errors.ErrTooManyRequests))
+
+	e := errors.TryConvert(err)
+	if assert.NotNil(t, e) && assert.Len(t, e.Details, 1) {
+		assert.Equal(t, ReasonExceeded, e.Details[0].Reason)
+		assert.Equal(t, map[string]interface{}{
+			"subject": "apikey:Xa8LmQ2pRt0z",
+			"window":  "day",
+			"used":    int64(100),
+			"limit":   int64(100),
+			"resetAt": "2023-07-18T00:00:00Z",
+		}, e.Details[0].Metadata)
+	}
+}
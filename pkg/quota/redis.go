@@ -0,0 +1,241 @@
+package quota
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// keyPrefix is prefix of every quota redis key
+const keyPrefix = "quota:"
+
+// consumeScript check limits of every subject then charge links to all of them,
+// the link index remember which counters a link is charged to, so Release can find them.
+//
+// KEYS: day, month and active key of every subject, then link index key of every link
+// ARGV: now, day reset, month reset, subject count, link count,
+// subject, per day, per month and max active of every subject,
+// key and expired at of every link
+var consumeScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local dayReset = tonumber(ARGV[2])
+local monthReset = tonumber(ARGV[3])
+local s = tonumber(ARGV[4])
+local n = tonumber(ARGV[5])
+local linkArg = s * 4 + 6
+
+for i = 0, s - 1 do
+	local day, month, active = KEYS[i * 3 + 1], KEYS[i * 3 + 2], KEYS[i * 3 + 3]
+	local perDay = tonumber(ARGV[i * 4 + 7])
+	local perMonth = tonumber(ARGV[i * 4 + 8])
+	local maxActive = tonumber(ARGV[i * 4 + 9])
+
+	redis.call('ZREMRANGEBYSCORE', active, '-inf', now)
+
+	local used = tonumber(redis.call('GET', day) or '0')
+	if perDay > 0 and used + n > perDay then
+		return {i, 'day', used, perDay, dayReset}
+	end
+
+	used = tonumber(redis.call('GET', month) or '0')
+	if perMonth > 0 and used + n > perMonth then
+		return {i, 'month', used, perMonth, monthReset}
+	end
+
+	used = redis.call('ZCARD', active)
+	if maxActive > 0 and used + n > maxActive then
+		local first = redis.call('ZRANGE', active, 0, 0, 'WITHSCORES')
+		return {i, 'active', used, maxActive, tonumber(first[2] or now)}
+	end
+end
+
+for i = 0, s - 1 do
+	local day, month, active = KEYS[i * 3 + 1], KEYS[i * 3 + 2], KEYS[i * 3 + 3]
+	local counters = day .. ' ' .. month .. ' ' .. active
+
+	redis.call('INCRBY', day, n)
+	redis.call('PEXPIREAT', day, dayReset)
+	redis.call('INCRBY', month, n)
+	redis.call('PEXPIREAT', month, monthReset)
+
+	for j = 0, n - 1 do
+		redis.call('ZADD', active, ARGV[linkArg + j * 2 + 1], ARGV[linkArg + j * 2])
+		redis.call('HSET', KEYS[s * 3 + j + 1], ARGV[i * 4 + 6], counters)
+	end
+
+	local last = redis.call('ZRANGE', active, -1, -1, 'WITHSCORES')
+	if last[2] then
+		redis.call('PEXPIREAT', active, last[2])
+	end
+end
+
+for j = 0, n - 1 do
+	redis.call('PEXPIREAT', KEYS[s * 3 + j + 1], math.max(tonumber(ARGV[linkArg + j * 2 + 1]), monthReset))
+end
+
+return {}
+`)
+
+// releaseScript remove links from active key of every subject they are charged to,
+// and decrease day and month counters when refund
+//
+// KEYS: link index key of every link
+// ARGV: refund, key of every link
+var releaseScript = redis.NewScript(`
+local refund = ARGV[1] == '1'
+
+for j = 1, #KEYS do
+	local fields = redis.call('HGETALL', KEYS[j])
+	for k = 2, #fields, 2 do
+		local day, month, active = string.match(fields[k], '(%S+) (%S+) (%S+)')
+		redis.call('ZREM', active, ARGV[j + 1])
+		if refund then
+			if redis.call('EXISTS', day) == 1 then
+				redis.call('DECR', day)
+			end
+			if redis.call('EXISTS', month) == 1 then
+				redis.call('DECR', month)
+			end
+		end
+	end
+	redis.call('DEL', KEYS[j])
+end
+
+return #KEYS
+`)
+
+var _ Quota = &RedisImpl{}
+
+// RedisImpl is Quota implementation by redis lua script, so check and charge are atomic across instances.
+// Keys of all subjects are touched by one script, it needs a single redis instance instead of cluster.
+type RedisImpl struct {
+	rds    *redis.Client
+	config Config
+}
+
+// NewRedisQuota Quota constructor
+func NewRedisQuota(rds *redis.Client, config Config) *RedisImpl {
+	return &RedisImpl{
+		rds:    rds,
+		config: config,
+	}
+}
+
+// Consume is implementation for Quota,
+// when redis is down links are not charged, so creating links keep working
+func (q *RedisImpl) Consume(ctx context.Context, subjects []Subject, links []Link) error {
+	if len(subjects) == 0 || len(links) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	day, month := dayReset(now), monthReset(now)
+
+	keys := make([]string, 0, len(subjects)*3+len(links))
+	args := make([]interface{}, 0, 5+len(subjects)*4+len(links)*2)
+	args = append(args, now.UnixMilli(), day.UnixMilli(), month.UnixMilli(), len(subjects), len(links))
+	for _, subject := range subjects {
+		limits := q.config.Limits(subject)
+		keys = append(keys, dayKey(subject, now), monthKey(subject, now), activeKey(subject))
+		args = append(args, subject.String(), limits.PerDay, limits.PerMonth, limits.MaxActive)
+	}
+	for _, link := range links {
+		keys = append(keys, linkKey(link.Key))
+		args = append(args, link.Key, link.ExpiredAt.UnixMilli())
+	}
+
+	result, err := consumeScript.Run(ctx, q.rds, keys, args...).Slice()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to consume quota of %v links", len(links))
+		return nil
+	}
+
+	// empty result means every subject is charged
+	if len(result) != 5 {
+		return nil
+	}
+
+	i, _ := result[0].(int64)
+	window, _ := result[1].(string)
+	used, _ := result[2].(int64)
+	limit, _ := result[3].(int64)
+	resetAt, _ := result[4].(int64)
+
+	return exceeded(subjects[i], window, used, limit, time.UnixMilli(resetAt))
+}
+
+// Release is implementation for Quota
+func (q *RedisImpl) Release(ctx context.Context, keys []string, refund bool) {
+	if len(keys) == 0 {
+		return
+	}
+
+	indexes := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, refund)
+	for _, key := range keys {
+		indexes = append(indexes, linkKey(key))
+		args = append(args, key)
+	}
+
+	if err := releaseScript.Run(ctx, q.rds, indexes, args...).Err(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to release quota of %v links", len(keys))
+	}
+}
+
+// Usage is implementation for Quota
+func (q *RedisImpl) Usage(ctx context.Context, subject Subject) (*Usage, error) {
+	now := time.Now().UTC()
+	limits := q.config.Limits(subject)
+	min := strconv.FormatInt(now.UnixMilli(), 10)
+
+	var (
+		day, month *redis.StringCmd
+		active     *redis.IntCmd
+		first      *redis.ZSliceCmd
+	)
+	_, err := q.rds.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		day = pipe.Get(ctx, dayKey(subject, now))
+		month = pipe.Get(ctx, monthKey(subject, now))
+		active = pipe.ZCount(ctx, activeKey(subject), "("+min, "+inf")
+		first = pipe.ZRangeByScoreWithScores(ctx, activeKey(subject), &redis.ZRangeBy{Min: "(" + min, Max: "+inf", Count: 1})
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	usage := &Usage{
+		Subject: subject.String(),
+		Day:     Counter{Limit: limits.PerDay, ResetAt: dayReset(now)},
+		Month:   Counter{Limit: limits.PerMonth, ResetAt: monthReset(now)},
+		Active:  Counter{Used: active.Val(), Limit: limits.MaxActive},
+	}
+	usage.Day.Used, _ = day.Int64()
+	usage.Month.Used, _ = month.Int64()
+	// the earliest expire link frees a slot, zero when no link is active
+	if zs := first.Val(); len(zs) != 0 {
+		usage.Active.ResetAt = time.UnixMilli(int64(zs[0].Score)).UTC()
+	}
+
+	return usage, nil
+}
+
+func dayKey(subject Subject, now time.Time) string {
+	return keyPrefix + subject.String() + ":day:" + now.Format("20060102")
+}
+
+func monthKey(subject Subject, now time.Time) string {
+	return keyPrefix + subject.String() + ":month:" + now.Format("200601")
+}
+
+func activeKey(subject Subject) string {
+	return keyPrefix + subject.String() + ":active"
+}
+
+func linkKey(key string) string {
+	return keyPrefix + "link:" + key
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// fallbackCooldown is how long the primary limiter is skipped after it fails
+const fallbackCooldown = 5 * time.Second
+
+var _ Limiter = &FallbackImpl{}
+
+// FallbackImpl use the primary limiter, and the fallback one when the primary fails, e.g. redis is down
+type FallbackImpl struct {
+	primary  Limiter
+	fallback Limiter
+
+	// downUntil is unix nano until which the primary is skipped
+	downUntil int64
+}
+
+// NewFallbackLimiter Limiter constructor
+func NewFallbackLimiter(primary Limiter, fallback Limiter) *FallbackImpl {
+	return &FallbackImpl{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Allow is implementation for Limiter
+func (l *FallbackImpl) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	if time.Now().UnixNano() >= atomic.LoadInt64(&l.downUntil) {
+		result, err := l.primary.Allow(ctx, key, policy)
+		if err == nil {
+			return result, nil
+		}
+
+		atomic.StoreInt64(&l.downUntil, time.Now().Add(fallbackCooldown).UnixNano())
+		log.Ctx(ctx).Warn().Err(err).Msgf("rate limiter is down, fallback for %v", fallbackCooldown)
+	}
+
+	return l.fallback.Allow(ctx, key, policy)
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+	pg "url-shortener/pkg/grpc"
+)
+
+// MetadataRetryAfter is the response header metadata of exceeded request, in seconds
+const MetadataRetryAfter = "retry-after"
+
+// UnaryServerInterceptor limit requests by the named policy of full method name, method not in it is unlimited.
+// Authenticated request is limited by its credential, otherwise by peer ip,
+// so the auth interceptor must run before it. A failed limiter allows the request.
+func UnaryServerInterceptor(limiter Limiter, methodPolicies map[string]string, policies map[string]Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		name, ok := methodPolicies[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		policy, ok := policies[name]
+		if !ok || !policy.Enabled() {
+			return handler(ctx, req)
+		}
+
+		result, err := limiter.Allow(ctx, name+":"+grpcKey(ctx), policy)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to check rate limit %v", name)
+			return handler(ctx, req)
+		}
+
+		if !result.Allowed {
+			retryAfter := strconv.FormatInt(int64(math.Ceil(result.RetryAfter.Seconds())), 10)
+			_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRetryAfter, retryAfter))
+			return nil, errors.Wrapf(errors.ErrTooManyRequests, "rate limit %v is exceeded, retry after %v", name, result.RetryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcKey return api key id, principal id or peer ip of request
+func grpcKey(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		if p.Method == auth.MethodAPIKey && p.KeyID != "" {
+			return "apikey:" + p.KeyID
+		}
+		return "owner:" + p.ID
+	}
+	return "ip:" + pg.PeerIP(ctx)
+}
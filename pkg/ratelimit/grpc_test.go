@@ -0,0 +1,79 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"url-shortener/pkg/auth"
+	"url-shortener/pkg/errors"
+	"url-shortener/pkg/ratelimit"
+	"url-shortener/pkg/ratelimit/mocks"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/urlshortener.v1.URLShortener/Resolve"
+	policy := ratelimit.Policy{Rate: 60, Period: time.Minute}
+
+	tests := []struct {
+		name      string
+		method    string
+		principal *auth.Principal
+		key       string
+		allowed   bool
+		err       error
+	}{
+		{
+			name:    "Allowed",
+			method:  method,
+			key:     "redirect:ip:203.0.113.7",
+			allowed: true,
+		},
+		{
+			name:      "APIKey",
+			method:    method,
+			principal: &auth.Principal{ID: "team-a", KeyID: "k1", Method: auth.MethodAPIKey},
+			key:       "redirect:apikey:k1",
+			allowed:   true,
+		},
+		{
+			name:   "Exceeded",
+			method: method,
+			key:    "redirect:ip:203.0.113.7",
+			err:    errors.ErrTooManyRequests,
+		},
+		{
+			name:   "NotLimited",
+			method: "/urlshortener.v1.URLShortener/ShortURL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := mocks.NewLimiter(t)
+			if tt.key != "" {
+				limiter.EXPECT().Allow(mock.Anything, tt.key, policy).Return(ratelimit.Result{Allowed: tt.allowed, RetryAfter: time.Second}, nil)
+			}
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 5555}})
+			if tt.principal != nil {
+				ctx = auth.NewContext(ctx, tt.principal)
+			}
+			interceptor := ratelimit.UnaryServerInterceptor(limiter, map[string]string{method: "redirect"}, map[string]ratelimit.Policy{"redirect": policy})
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+			if tt.err != nil {
+				assert.Truef(t, errors.Is(err, tt.err), "interceptor() error = %v, expected %v", err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepSize is the least number of keys which trigger removing expired keys
+const sweepSize = 10000
+
+var _ Limiter = &MemoryImpl{}
+
+// MemoryImpl is Limiter in process memory, every instance limit requests alone
+type MemoryImpl struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+	now  func() time.Time
+
+	// sweepAt is the number of keys which trigger the next sweep, it is twice the keys left by the last sweep,
+	// so a sweep is amortized over the keys added since it even when most keys are not expired
+	sweepAt int
+}
+
+// NewMemoryLimiter Limiter constructor
+func NewMemoryLimiter() *MemoryImpl {
+	return &MemoryImpl{
+		tats:    make(map[string]time.Time),
+		now:     time.Now,
+		sweepAt: sweepSize,
+	}
+}
+
+// Allow is implementation for Limiter
+func (l *MemoryImpl) Allow(_ context.Context, key string, policy Policy) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.tats) >= l.sweepAt {
+		l.sweep(now)
+	}
+
+	result, tat := gcra(now, l.tats[key], policy)
+	if result.Allowed {
+		l.tats[key] = tat
+	}
+
+	return result, nil
+}
+
+// sweep remove keys whose tat is passed, they are the same as new keys, then schedule the next sweep
+func (l *MemoryImpl) sweep(now time.Time) {
+	for key, tat := range l.tats {
+		if !tat.After(now) {
+			delete(l.tats, key)
+		}
+	}
+
+	l.sweepAt = 2 * len(l.tats)
+	if l.sweepAt < sweepSize {
+		l.sweepAt = sweepSize
+	}
+}
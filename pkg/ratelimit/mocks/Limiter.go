@@ -0,0 +1,92 @@
+// Code generated by mockery v2.26.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	ratelimit "url-shortener/pkg/ratelimit"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Limiter is an autogenerated mock type for the Limiter type
+type Limiter struct {
+	mock.Mock
+}
+
+type Limiter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Limiter) EXPECT() *Limiter_Expecter {
+	return &Limiter_Expecter{mock: &_m.Mock}
+}
+
+// Allow provides a mock function with given fields: ctx, key, policy
+func (_m *Limiter) Allow(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Result, error) {
+	ret := _m.Called(ctx, key, policy)
+
+	var r0 ratelimit.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ratelimit.Policy) (ratelimit.Result, error)); ok {
+		return rf(ctx, key, policy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ratelimit.Policy) ratelimit.Result); ok {
+		r0 = rf(ctx, key, policy)
+	} else {
+		r0 = ret.Get(0).(ratelimit.Result)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ratelimit.Policy) error); ok {
+		r1 = rf(ctx, key, policy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Limiter_Allow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Allow'
+type Limiter_Allow_Call struct {
+	*mock.Call
+}
+
+// Allow is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - policy ratelimit.Policy
+func (_e *Limiter_Expecter) Allow(ctx interface{}, key interface{}, policy interface{}) *Limiter_Allow_Call {
+	return &Limiter_Allow_Call{Call: _e.mock.On("Allow", ctx, key, policy)}
+}
+
+func (_c *Limiter_Allow_Call) Run(run func(ctx context.Context, key string, policy ratelimit.Policy)) *Limiter_Allow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(ratelimit.Policy))
+	})
+	return _c
+}
+
+func (_c *Limiter_Allow_Call) Return(_a0 ratelimit.Result, _a1 error) *Limiter_Allow_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Limiter_Allow_Call) RunAndReturn(run func(context.Context, string, ratelimit.Policy) (ratelimit.Result, error)) *Limiter_Allow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+type mockConstructorTestingTNewLimiter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewLimiter creates a new instance of Limiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewLimiter(t mockConstructorTestingTNewLimiter) *Limiter {
+	mock := &Limiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Config rate limit config
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Create is policy of creating short urls
+	Create Policy `mapstructure:"create"`
+
+	// Redirect is policy of redirect by short url
+	Redirect Policy `mapstructure:"redirect"`
+
+	// Report is policy of abuse reports
+	Report Policy `mapstructure:"report"`
+}
+
+// Policy allow Rate requests per Period, and at most Burst requests at once, zero Rate means unlimited
+type Policy struct {
+	Rate   int           `mapstructure:"rate"`
+	Period time.Duration `mapstructure:"period"`
+
+	// Burst default Rate
+	Burst int `mapstructure:"burst"`
+}
+
+// Enabled check policy limit requests
+func (p Policy) Enabled() bool {
+	return p.Rate > 0 && p.Period > 0
+}
+
+// interval is the emission interval of GCRA, a request is allowed every interval in average
+func (p Policy) interval() time.Duration {
+	return p.Period / time.Duration(p.Rate)
+}
+
+// burst return max requests at once
+func (p Policy) burst() int {
+	if p.Burst <= 0 {
+		return p.Rate
+	}
+	return p.Burst
+}
+
+// Result is the decision of a request
+type Result struct {
+	Allowed bool
+
+	// Limit is the max requests at once, Remaining is requests allowed now after this one
+	Limit     int
+	Remaining int
+
+	// ResetAfter is when the limit is fully available again
+	ResetAfter time.Duration
+
+	// RetryAfter is when the next request is allowed, zero when Allowed
+	RetryAfter time.Duration
+}
+
+// Limiter decide whether request of key is allowed by policy, implementations use GCRA
+//
+//go:generate mockery --name Limiter --with-expecter
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// gcra is generic cell rate algorithm, tat is theoretical arrival time of the next request,
+// return the result and the new tat to store, the new tat is zero when request is not allowed
+func gcra(now time.Time, tat time.Time, policy Policy) (Result, time.Time) {
+	interval := policy.interval()
+	burst := policy.burst()
+
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-time.Duration(burst) * interval)
+
+	if now.Before(allowAt) {
+		return Result{
+			Limit:      burst,
+			ResetAfter: tat.Sub(now),
+			RetryAfter: allowAt.Sub(now),
+		}, time.Time{}
+	}
+
+	return Result{
+		Allowed:    true,
+		Limit:      burst,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, newTAT
+}
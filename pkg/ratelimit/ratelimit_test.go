@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryImpl_Allow(t *testing.T) {
+	now := time.Date(2023, 7, 17, 9, 30, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	// 60 requests per minute, at most 3 at once
+	policy := Policy{Rate: 60, Period: time.Minute, Burst: 3}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := l.Allow(ctx, "ip:10.0.0.1", policy)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, _ := l.Allow(ctx, "ip:10.0.0.1", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.ResetAfter)
+
+	// other key has its own limit
+	result, _ = l.Allow(ctx, "ip:10.0.0.2", policy)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(time.Second)
+	result, _ = l.Allow(ctx, "ip:10.0.0.1", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	now = now.Add(time.Minute)
+	result, _ = l.Allow(ctx, "ip:10.0.0.1", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryImpl_sweep(t *testing.T) {
+	now := time.Date(2023, 7, 17, 9, 30, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	policy := Policy{Rate: 1, Period: time.Hour}
+	ctx := context.Background()
+
+	// no key is expired, the next sweep wait until the keys are doubled
+	for i := 0; i < sweepSize+1; i++ {
+		_, _ = l.Allow(ctx, fmt.Sprintf("ip:%v", i), policy)
+	}
+	assert.Equal(t, sweepSize+1, len(l.tats))
+	assert.Equal(t, 2*sweepSize, l.sweepAt)
+
+	// the old keys are expired, they are removed when the keys are doubled
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < sweepSize; i++ {
+		_, _ = l.Allow(ctx, fmt.Sprintf("ip:new:%v", i), policy)
+	}
+	assert.Equal(t, sweepSize, len(l.tats))
+	assert.Equal(t, 2*(sweepSize-1), l.sweepAt)
+}
+
+func TestPolicy_burst(t *testing.T) {
+	assert.Equal(t, 10, Policy{Rate: 10, Period: time.Second}.burst())
+	assert.Equal(t, 2, Policy{Rate: 10, Period: time.Second, Burst: 2}.burst())
+	assert.False(t, Policy{Period: time.Second}.Enabled())
+	assert.False(t, Policy{Rate: 10}.Enabled())
+}
+
+// failLimiter always fail like redis is down
+type failLimiter struct{ calls int }
+
+func (l *failLimiter) Allow(context.Context, string, Policy) (Result, error) {
+	l.calls++
+	return Result{}, errors.New("connection refused")
+}
+
+func TestFallbackImpl_Allow(t *testing.T) {
+	primary := &failLimiter{}
+	l := NewFallbackLimiter(primary, NewMemoryLimiter())
+
+	policy := Policy{Rate: 1, Period: time.Minute}
+	ctx := context.Background()
+
+	result, err := l.Allow(ctx, "ip:10.0.0.1", policy)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// the primary is skipped during cool down
+	result, err = l.Allow(ctx, "ip:10.0.0.1", policy)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 1, primary.calls)
+}
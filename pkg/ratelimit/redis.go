@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix is prefix of every rate limit redis key
+const keyPrefix = "ratelimit:"
+
+// gcraScript is the same algorithm as gcra, time is read from redis so instances with clock skew share one clock
+//
+// KEYS: tat key
+// ARGV: interval and burst, interval is in microseconds
+var gcraScript = redis.NewScript(`
+redis.replicate_commands()
+
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local newTAT = tat + interval
+local allowAt = newTAT - burst * interval
+
+if now < allowAt then
+	return {0, 0, tat - now, allowAt - now}
+end
+
+redis.call('SET', KEYS[1], newTAT, 'PX', math.ceil((newTAT - now) / 1000))
+return {1, math.floor((now - allowAt) / interval), newTAT - now, 0}
+`)
+
+var _ Limiter = &RedisImpl{}
+
+// RedisImpl is Limiter shared by all instances through redis
+type RedisImpl struct {
+	rds *redis.Client
+}
+
+// NewRedisLimiter Limiter constructor
+func NewRedisLimiter(rds *redis.Client) *RedisImpl {
+	return &RedisImpl{rds: rds}
+}
+
+// Allow is implementation for Limiter
+func (l *RedisImpl) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	values, err := gcraScript.Run(
+		ctx,
+		l.rds,
+		[]string{keyPrefix + key},
+		policy.interval().Microseconds(),
+		policy.burst(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.burst(),
+		Remaining:  int(values[1]),
+		ResetAfter: time.Duration(values[2]) * time.Microsecond,
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}